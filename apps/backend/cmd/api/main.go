@@ -13,10 +13,12 @@ import (
 
 	"github.com/friend-bets/backend/internal/config"
 	"github.com/friend-bets/backend/internal/grpc"
+	applog "github.com/friend-bets/backend/internal/logger"
 	"github.com/friend-bets/backend/internal/notify"
 	"github.com/friend-bets/backend/internal/rate"
 	"github.com/friend-bets/backend/internal/solana"
 	"github.com/friend-bets/backend/internal/store"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 const (
@@ -29,7 +31,9 @@ func main() {
 	var (
 		configFile = flag.String("config", "", "Path to configuration file")
 		logLevel   = flag.String("log-level", "info", "Log level (debug, info, warn, error)")
+		logFormat  = flag.String("log-format", "", "Log format (dev, json). Defaults to dev when ENV=development, json otherwise")
 		showVer    = flag.Bool("version", false, "Show version information")
+		dev        = flag.Bool("dev", false, "Run GORM AutoMigrate on startup instead of relying on the migrate CLI (local development only)")
 	)
 	flag.Parse()
 
@@ -39,7 +43,7 @@ func main() {
 	}
 
 	// Initialize logger
-	logger := setupLogger(*logLevel)
+	logger := setupLogger(*logLevel, *logFormat)
 	logger.Info("starting API server", "service", serviceName, "version", version)
 
 	// Load configuration
@@ -60,7 +64,7 @@ func main() {
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 
 	// Initialize services
-	services, err := initializeServices(ctx, cfg, logger)
+	services, err := initializeServices(ctx, cfg, *dev, logger)
 	if err != nil {
 		logger.Error("failed to initialize services", "error", err)
 		os.Exit(1)
@@ -105,28 +109,35 @@ type Services struct {
 }
 
 // initializeServices initializes all application services
-func initializeServices(ctx context.Context, cfg *config.Config, logger *slog.Logger) (*Services, error) {
+func initializeServices(ctx context.Context, cfg *config.Config, dev bool, logger *slog.Logger) (*Services, error) {
 	services := &Services{}
 
 	// Initialize database
 	logger.Info("initializing database connection")
-	db, err := store.NewDB(cfg.Database.URL, logger)
+	db, err := store.NewDB(cfg.Database.URL, dev, logger)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize database: %w", err)
 	}
 	services.DB = db
 
-	// Run database migrations
-	logger.Info("running database migrations")
-	if err := store.AutoMigrate(db.DB); err != nil {
-		return nil, fmt.Errorf("failed to run migrations: %w", err)
-	}
-
 	// Initialize repository
 	services.Repository = store.NewRepository(db)
 
 	// Initialize analytics
 	services.Analytics = store.NewAnalytics(services.Repository, logger)
+	if err := services.Analytics.RegisterMetrics(ctx, prometheus.DefaultRegisterer); err != nil {
+		return nil, fmt.Errorf("failed to register analytics metrics: %w", err)
+	}
+
+	// Initialize the rolling accumulator for recent-activity analytics (volume, bets
+	// placed, active users), backfilled from the last window of DB rows so a restart
+	// doesn't momentarily zero the metrics
+	rollingWindow := time.Duration(cfg.Worker.RollingWindowSec) * time.Second
+	rolling := store.NewRollingAccumulator(store.NewInMemoryBackend(), rollingWindow)
+	if err := rolling.Backfill(ctx, services.Repository); err != nil {
+		logger.Error("failed to backfill rolling accumulator", "error", err)
+	}
+	services.Analytics.UseRollingAccumulator(rolling)
 
 	// Initialize Solana client
 	logger.Info("initializing Solana client")
@@ -143,6 +154,9 @@ func initializeServices(ctx context.Context, cfg *config.Config, logger *slog.Lo
 	// Initialize notifier
 	logger.Info("initializing notification service")
 	services.Notifier = notify.NewNotifier(&cfg.Notify, services.Repository, logger)
+	if err := services.Notifier.RegisterMetrics(prometheus.DefaultRegisterer); err != nil {
+		return nil, fmt.Errorf("failed to register notification delivery metrics: %w", err)
+	}
 
 	// Initialize gRPC server
 	logger.Info("initializing gRPC server")
@@ -152,6 +166,7 @@ func initializeServices(ctx context.Context, cfg *config.Config, logger *slog.Lo
 		services.SolanaClient,
 		services.Notifier,
 		services.RateLimiter,
+		services.Analytics,
 		logger,
 	)
 
@@ -221,6 +236,18 @@ func stopServices(ctx context.Context, services *Services, logger *slog.Logger)
 		logger.Warn("shutdown timeout, some services may not have stopped cleanly")
 	}
 
+	// Drain the delivery queue so in-flight and already-buffered notification sends
+	// finish before the process exits, then the notification hub so any blocked
+	// SubscribeMarketEvents readers unblock and their goroutines exit
+	if services.Notifier != nil {
+		logger.Info("draining notification delivery queue")
+		if err := services.Notifier.Shutdown(ctx); err != nil {
+			logger.Warn("notification delivery queue did not drain before shutdown timeout", "error", err)
+		}
+		logger.Info("closing notification hub")
+		services.Notifier.CloseHub()
+	}
+
 	// Close database connection
 	if services.DB != nil {
 		logger.Info("closing database connection")
@@ -233,8 +260,10 @@ func stopServices(ctx context.Context, services *Services, logger *slog.Logger)
 	return nil
 }
 
-// setupLogger configures the structured logger
-func setupLogger(level string) *slog.Logger {
+// setupLogger configures the structured logger. format selects dev (colorized,
+// human-readable) vs json output; an empty format falls back to ENV=development, mirroring
+// the previous behavior before --log-format existed.
+func setupLogger(level, format string) *slog.Logger {
 	var logLevel slog.Level
 	switch level {
 	case "debug":
@@ -254,16 +283,15 @@ func setupLogger(level string) *slog.Logger {
 		AddSource: true,
 	}
 
-	var handler slog.Handler
-	if os.Getenv("ENV") == "development" {
-		// Pretty text logging for development
-		handler = slog.NewTextHandler(os.Stdout, opts)
-	} else {
-		// JSON logging for production
-		handler = slog.NewJSONHandler(os.Stdout, opts)
+	if format == "" {
+		if os.Getenv("ENV") == "development" {
+			format = "dev"
+		} else {
+			format = "json"
+		}
 	}
 
-	logger := slog.New(handler)
+	logger := slog.New(applog.NewHandler(format, os.Stdout, opts))
 	slog.SetDefault(logger)
 
 	return logger
@@ -295,4 +323,3 @@ func healthCheck(services *Services) error {
 
 	return nil
 }
-
@@ -0,0 +1,82 @@
+// Command migrate applies the versioned SQL migrations under migrations/ to the
+// configured database, replacing the ad-hoc AutoMigrate calls `api`/`worker` made on
+// every startup.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/friend-bets/backend/internal/config"
+	"github.com/friend-bets/backend/internal/store"
+)
+
+const serviceName = "friend-bets-migrate"
+
+func main() {
+	var (
+		configFile = flag.String("config", "", "Path to configuration file")
+		target     = flag.Int64("target", 0, "Migration version to stop at for up/down (0 = latest/all)")
+	)
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "usage: %s [flags] up|down|status|redo|backfill-user-stats\n", serviceName)
+		os.Exit(2)
+	}
+	command := flag.Arg(0)
+
+	cfg, err := config.Load(*configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	db, err := store.NewDB(cfg.Database.URL, false, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to connect to database: %v\n", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	if command == "backfill-user-stats" {
+		repo := store.NewRepository(db)
+		if err := store.NewUserStatsAggregator(repo).Backfill(context.Background()); err != nil {
+			fmt.Fprintf(os.Stderr, "backfill-user-stats failed: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("backfill-user-stats completed")
+		return
+	}
+
+	direction := store.MigrateDirection(command)
+	switch direction {
+	case store.MigrateUp, store.MigrateDown, store.MigrateStatus, store.MigrateRedo:
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q: expected up, down, status, redo, or backfill-user-stats\n", command)
+		os.Exit(2)
+	}
+
+	sqlDB, err := db.DB.DB()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to get underlying sql.DB: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := store.Migrate(context.Background(), sqlDB, direction, *target); err != nil {
+		fmt.Fprintf(os.Stderr, "migrate %s failed: %v\n", direction, err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("migrate %s (target=%s) completed\n", direction, targetLabel(*target))
+}
+
+func targetLabel(target int64) string {
+	if target == 0 {
+		return "latest"
+	}
+	return strconv.FormatInt(target, 10)
+}
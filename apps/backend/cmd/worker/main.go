@@ -13,10 +13,16 @@ import (
 
 	"github.com/friend-bets/backend/internal/config"
 	"github.com/friend-bets/backend/internal/core"
+	"github.com/friend-bets/backend/internal/hedge"
+	applog "github.com/friend-bets/backend/internal/logger"
+	"github.com/friend-bets/backend/internal/mm"
 	"github.com/friend-bets/backend/internal/notify"
+	"github.com/friend-bets/backend/internal/rate"
+	"github.com/friend-bets/backend/internal/risk"
 	"github.com/friend-bets/backend/internal/scheduler"
 	"github.com/friend-bets/backend/internal/solana"
 	"github.com/friend-bets/backend/internal/store"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 const (
@@ -29,8 +35,10 @@ func main() {
 	var (
 		configFile = flag.String("config", "", "Path to configuration file")
 		logLevel   = flag.String("log-level", "info", "Log level (debug, info, warn, error)")
+		logFormat  = flag.String("log-format", "", "Log format (dev, json). Defaults to dev when ENV=development, json otherwise")
 		showVer    = flag.Bool("version", false, "Show version information")
 		runOnce    = flag.Bool("run-once", false, "Run jobs once and exit (useful for testing)")
+		dev        = flag.Bool("dev", false, "Run GORM AutoMigrate on startup instead of relying on the migrate CLI (local development only)")
 	)
 	flag.Parse()
 
@@ -47,7 +55,7 @@ func main() {
 	}
 
 	// Initialize logger with config
-	logger := setupLogger(*logLevel, cfg)
+	logger := setupLogger(*logLevel, *logFormat, cfg)
 	logger.Info("starting worker service", "service", serviceName, "version", version)
 
 	logger.Info("configuration loaded", "config_file", *configFile)
@@ -67,7 +75,7 @@ func main() {
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 
 	// Initialize services
-	services, err := initializeServices(ctx, cfg, logger)
+	services, err := initializeServices(ctx, cfg, *dev, logger)
 	if err != nil {
 		logger.Error("failed to initialize services", "error", err)
 		os.Exit(1)
@@ -106,42 +114,82 @@ func main() {
 
 // WorkerServices holds all worker services
 type WorkerServices struct {
-	DB           *store.DB
-	Repository   *store.Repository
-	Analytics    *store.Analytics
-	UseCases     *core.UseCases
-	SolanaClient *solana.AnchorClient
-	EventIndexer *solana.EventIndexer
-	Notifier     *notify.Notifier
-	Scheduler    *scheduler.Scheduler
+	DB             *store.DB
+	Repository     *store.Repository
+	Analytics      *store.Analytics
+	UseCases       *core.UseCases
+	SolanaClient   *solana.AnchorClient
+	EventIndexer   *solana.EventIndexer
+	FilterRegistry *solana.FilterRegistry
+	Notifier       *notify.Notifier
+	Scheduler      *scheduler.Scheduler
+	MMEngine       *mm.Engine
 }
 
 // initializeServices initializes all worker services
-func initializeServices(ctx context.Context, cfg *config.Config, logger *slog.Logger) (*WorkerServices, error) {
+func initializeServices(ctx context.Context, cfg *config.Config, dev bool, logger *slog.Logger) (*WorkerServices, error) {
 	services := &WorkerServices{}
 
 	// Initialize database
 	logger.Info("initializing database connection")
-	db, err := store.NewDB(cfg.Database.URL, logger)
+	db, err := store.NewDB(cfg.Database.URL, dev, logger)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize database: %w", err)
 	}
 	services.DB = db
 
-	// Run database migrations
-	logger.Info("running database migrations")
-	if err := store.AutoMigrate(db.DB); err != nil {
-		return nil, fmt.Errorf("failed to run migrations: %w", err)
-	}
-
 	// Initialize repository
 	services.Repository = store.NewRepository(db)
 
 	// Initialize analytics
 	services.Analytics = store.NewAnalytics(services.Repository, logger)
+	if err := services.Analytics.RegisterMetrics(ctx, prometheus.DefaultRegisterer); err != nil {
+		return nil, fmt.Errorf("failed to register analytics metrics: %w", err)
+	}
+
+	// Initialize the rolling accumulator for recent-activity analytics (volume, bets
+	// placed, active users), backfilled from the last window of DB rows so a restart
+	// doesn't momentarily zero the metrics
+	rollingWindow := time.Duration(cfg.Worker.RollingWindowSec) * time.Second
+	rolling := store.NewRollingAccumulator(store.NewInMemoryBackend(), rollingWindow)
+	if err := rolling.Backfill(ctx, services.Repository); err != nil {
+		logger.Error("failed to backfill rolling accumulator", "error", err)
+	}
+	services.Analytics.UseRollingAccumulator(rolling)
+
+	// Initialize notifier
+	logger.Info("initializing notification service")
+	services.Notifier = notify.NewNotifier(&cfg.Notify, services.Repository, logger)
+	if err := services.Notifier.RegisterMetrics(prometheus.DefaultRegisterer); err != nil {
+		return nil, fmt.Errorf("failed to register notification delivery metrics: %w", err)
+	}
+
+	// Initialize risk circuit breakers, if enabled
+	var riskMon *risk.Monitor
+	if cfg.Risk.Enabled {
+		breaker := risk.NewMultiBreaker(
+			risk.NewWalletConcentrationBreaker(services.Repository, cfg.Risk.MaxWalletShareBps, logger),
+			risk.NewOddsMovementBreaker(cfg.Risk.MaxOddsMoveBps, time.Duration(cfg.Risk.OddsMoveWindowSec)*time.Second),
+			risk.NewDisputeFrequencyBreaker(services.Repository, cfg.Risk.MaxDisputesPerCreator, time.Duration(cfg.Risk.DisputeWindowSec)*time.Second, logger),
+			risk.NewResolverHistoryBreaker(services.Repository, cfg.Risk.MaxResolverDeadlineViolations, logger),
+		)
+		riskMon = risk.NewMonitor(services.Repository, breaker, logger)
+	}
+
+	// Initialize cross-venue hedging, if enabled. No concrete exchange adapter ships
+	// with this deployment yet, so factories is empty until one is registered.
+	var hedgeMon *hedge.Monitor
+	if cfg.Hedge.Enabled {
+		keys, err := hedge.NewKeyStore(services.Repository, cfg.Hedge.EncryptionKeyHex)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize hedge key store: %w", err)
+		}
+		hedgeLimiter := rate.NewLimiter(&config.RateConfig{}, services.Repository, logger)
+		hedgeMon = hedge.NewMonitor(services.Repository, keys, map[string]hedge.ExchangeFactory{}, hedgeLimiter, &cfg.Hedge, logger)
+	}
 
 	// Initialize use cases
-	services.UseCases = core.NewUseCases(services.Repository, cfg, logger)
+	services.UseCases = core.NewUseCases(services.Repository, cfg, services.Notifier, services.Analytics, riskMon, hedgeMon, logger)
 
 	// Initialize Solana client
 	logger.Info("initializing Solana client")
@@ -158,22 +206,45 @@ func initializeServices(ctx context.Context, cfg *config.Config, logger *slog.Lo
 		if err != nil {
 			return nil, fmt.Errorf("failed to initialize event indexer: %w", err)
 		}
+		filterRegistry := solana.NewFilterRegistry(services.Repository)
+		eventIndexer.SetFilterRegistry(filterRegistry)
+		filterRegistry.StartGC(ctx, time.Minute)
 		services.EventIndexer = eventIndexer
+		services.FilterRegistry = filterRegistry
+
+		if cfg.Solana.GeyserEndpoint != "" {
+			// A GeyserEventSource needs a GeyserTransactionStream backed by a
+			// generated Yellowstone/Geyser gRPC client, which isn't vendored in
+			// this deployment yet; fall back to RPC+WebSocket-only indexing
+			// rather than silently ignoring the configured endpoint.
+			logger.Warn("geyser endpoint configured but no GeyserTransactionStream implementation is vendored; falling back to RPC+WebSocket indexing only", "endpoint", cfg.Solana.GeyserEndpoint)
+		}
 	}
 
-	// Initialize notifier
-	logger.Info("initializing notification service")
-	services.Notifier = notify.NewNotifier(&cfg.Notify, services.Repository, logger)
-
 	// Initialize scheduler
 	logger.Info("initializing scheduler")
 	services.Scheduler = scheduler.NewScheduler(
 		&cfg.Worker,
+		services.DB,
+		services.Repository,
 		services.UseCases,
 		services.Notifier,
 		services.Analytics,
 		logger,
 	)
+	if err := services.Scheduler.RegisterMetrics(prometheus.DefaultRegisterer); err != nil {
+		return nil, fmt.Errorf("failed to register scheduler metrics: %w", err)
+	}
+
+	// Initialize market-maker bot subsystem
+	if cfg.MM.Enabled {
+		logger.Info("initializing market-maker engine")
+		mmEngine, err := mm.NewEngine(&cfg.MM, services.UseCases, services.SolanaClient, services.Repository, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize market-maker engine: %w", err)
+		}
+		services.MMEngine = mmEngine
+	}
 
 	logger.Info("all worker services initialized successfully")
 	return services, nil
@@ -199,6 +270,21 @@ func startServices(ctx context.Context, services *WorkerServices, logger *slog.L
 		}()
 	}
 
+	// Start market-maker engine
+	if services.MMEngine != nil && !runOnce {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			logger.Info("starting market-maker engine")
+			if err := services.MMEngine.Start(ctx); err != nil {
+				select {
+				case errCh <- fmt.Errorf("market-maker engine error: %w", err):
+				default:
+				}
+			}
+		}()
+	}
+
 	// Start scheduler
 	if !runOnce {
 		wg.Add(1)
@@ -256,6 +342,12 @@ func runCriticalJobsOnce(ctx context.Context, services *WorkerServices, logger *
 		return fmt.Errorf("failed to process expired markets: %w", err)
 	}
 
+	// Finalize markets whose challenge window has closed
+	if err := services.UseCases.ProcessChallengeWindows(ctx); err != nil {
+		logger.Error("failed to process challenge windows", "error", err)
+		return fmt.Errorf("failed to process challenge windows: %w", err)
+	}
+
 	// Process daily analytics rollup
 	yesterday := time.Now().AddDate(0, 0, -1)
 	if err := services.Analytics.ProcessDailyRollup(ctx, yesterday); err != nil {
@@ -281,6 +373,18 @@ func stopServices(ctx context.Context, services *WorkerServices, logger *slog.Lo
 		}()
 	}
 
+	// Stop market-maker engine
+	if services.MMEngine != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			logger.Info("stopping market-maker engine")
+			if err := services.MMEngine.Stop(ctx); err != nil {
+				logger.Error("error stopping market-maker engine", "error", err)
+			}
+		}()
+	}
+
 	// Stop scheduler
 	if services.Scheduler != nil {
 		wg.Add(1)
@@ -307,6 +411,15 @@ func stopServices(ctx context.Context, services *WorkerServices, logger *slog.Lo
 		logger.Warn("shutdown timeout, some services may not have stopped cleanly")
 	}
 
+	// Drain the delivery queue so in-flight and already-buffered notification sends
+	// (e.g. market-expiring emails from the scheduler) finish before the process exits
+	if services.Notifier != nil {
+		logger.Info("draining notification delivery queue")
+		if err := services.Notifier.Shutdown(ctx); err != nil {
+			logger.Warn("notification delivery queue did not drain before shutdown timeout", "error", err)
+		}
+	}
+
 	// Close database connection
 	if services.DB != nil {
 		logger.Info("closing database connection")
@@ -355,6 +468,9 @@ func performHealthCheck(ctx context.Context, services *WorkerServices) error {
 		if err := services.EventIndexer.Health(healthCtx); err != nil {
 			return fmt.Errorf("event indexer unhealthy: %w", err)
 		}
+		if lag, err := services.EventIndexer.Lag(healthCtx); err == nil && lag > 1000 {
+			return fmt.Errorf("event indexer lagging: %d slots behind", lag)
+		}
 	}
 
 	// Check scheduler
@@ -364,6 +480,13 @@ func performHealthCheck(ctx context.Context, services *WorkerServices) error {
 		}
 	}
 
+	// Check market-maker engine
+	if services.MMEngine != nil {
+		if err := services.MMEngine.Health(); err != nil {
+			return fmt.Errorf("market-maker engine unhealthy: %w", err)
+		}
+	}
+
 	// Check notifier
 	if err := services.Notifier.Health(); err != nil {
 		return fmt.Errorf("notifier unhealthy: %w", err)
@@ -372,8 +495,10 @@ func performHealthCheck(ctx context.Context, services *WorkerServices) error {
 	return nil
 }
 
-// setupLogger configures the structured logger
-func setupLogger(level string, cfg *config.Config) *slog.Logger {
+// setupLogger configures the structured logger. format selects dev (colorized,
+// human-readable) vs json output; an empty format falls back to cfg.Environment, mirroring
+// the previous behavior before --log-format existed.
+func setupLogger(level, format string, cfg *config.Config) *slog.Logger {
 	var logLevel slog.Level
 	switch level {
 	case "debug":
@@ -393,16 +518,15 @@ func setupLogger(level string, cfg *config.Config) *slog.Logger {
 		AddSource: true,
 	}
 
-	var handler slog.Handler
-	if cfg.Environment == "development" {
-		// Pretty text logging for development
-		handler = slog.NewTextHandler(os.Stdout, opts)
-	} else {
-		// JSON logging for production
-		handler = slog.NewJSONHandler(os.Stdout, opts)
+	if format == "" {
+		if cfg.Environment == "development" {
+			format = "dev"
+		} else {
+			format = "json"
+		}
 	}
 
-	logger := slog.New(handler)
+	logger := slog.New(applog.NewHandler(format, os.Stdout, opts))
 	slog.SetDefault(logger)
 
 	return logger
@@ -435,10 +559,12 @@ func runManualJob(ctx context.Context, services *WorkerServices, jobName string,
 		return services.UseCases.ProcessMarketsNearEnd(ctx)
 	case "auto_cancel":
 		return services.UseCases.ProcessExpiredMarkets(ctx)
+	case "challenge_windows":
+		return services.UseCases.ProcessChallengeWindows(ctx)
 	case "daily_rollup":
 		yesterday := time.Now().AddDate(0, 0, -1)
 		return services.Analytics.ProcessDailyRollup(ctx, yesterday)
 	default:
 		return fmt.Errorf("unknown job: %s", jobName)
 	}
-}
\ No newline at end of file
+}
@@ -16,6 +16,11 @@ type Config struct {
 	Worker      WorkerConfig   `yaml:"worker"`
 	Notify      NotifyConfig   `yaml:"notify"`
 	Rate        RateConfig     `yaml:"rate"`
+	Resolve     ResolveConfig  `yaml:"resolve"`
+	MM          MMConfig       `yaml:"mm"`
+	Risk        RiskConfig     `yaml:"risk"`
+	Hedge       HedgeConfig    `yaml:"hedge"`
+	Auth        AuthConfig     `yaml:"auth"`
 }
 
 type ServerConfig struct {
@@ -38,15 +43,90 @@ type SolanaConfig struct {
 	ProgramID        string `yaml:"program_id"`
 	MaintenanceKey   string `yaml:"maintenance_key_path"`
 	ConfirmationMode string `yaml:"confirmation_mode"`
+	// BackfillWorkers is how many goroutines concurrently claim and process historical
+	// backfill chunks; BackfillChunkSize is how many slots each chunk covers.
+	BackfillWorkers   int `yaml:"backfill_workers"`
+	BackfillChunkSize int `yaml:"backfill_chunk_size"`
+	// IDLPath points to the program's Anchor IDL JSON, used to decode "Program data:"
+	// event logs via their binary discriminator instead of grep-matching human-readable
+	// "Program log:" lines. Empty disables IDL-based decoding entirely.
+	IDLPath string `yaml:"idl_path"`
+	// GeyserEndpoint, if set, starts a Yellowstone/Geyser gRPC EventSource alongside
+	// the built-in RPC+WebSocket indexing path, subscribing to program-scoped
+	// transaction updates with far lower latency. GeyserToken is sent as the
+	// "x-token" auth metadata some Geyser plugin deployments require; empty means
+	// none is sent.
+	GeyserEndpoint string `yaml:"geyser_endpoint"`
+	GeyserToken    string `yaml:"geyser_token"`
+	// WebhookHMACSecret, if set, enables the Helius-style transaction webhook
+	// receiver mounted on internal/grpc.Server at WebhookPath; requests are
+	// authenticated by an HMAC-SHA256 signature over the raw body. Empty disables
+	// the receiver entirely.
+	WebhookHMACSecret string `yaml:"webhook_hmac_secret"`
+	// WebhookPath is the HTTP path the webhook receiver is mounted at. Defaults to
+	// "/webhooks/solana".
+	WebhookPath string `yaml:"webhook_path"`
+	// MinPriorityFeeMicroLamports and MaxPriorityFeeMicroLamports bound the
+	// per-compute-unit price solana.PriorityFeeEstimator attaches to outgoing
+	// transactions via SetComputeUnitPrice, regardless of what
+	// getRecentPrioritizationFees reports. Min guarantees a floor when recent
+	// samples are all zero; max caps worst-case fee spend during a congestion
+	// spike. Both default to 0, meaning no floor and no cap.
+	MinPriorityFeeMicroLamports uint64 `yaml:"min_priority_fee_microlamports"`
+	MaxPriorityFeeMicroLamports uint64 `yaml:"max_priority_fee_microlamports"`
 }
 
 type WorkerConfig struct {
-	Enabled             bool   `yaml:"enabled"`
-	CheckIntervalSec    int    `yaml:"check_interval_sec"`
-	AutoCloseEnabled    bool   `yaml:"auto_close_enabled"`
-	AutoCancelEnabled   bool   `yaml:"auto_cancel_enabled"`
-	IndexerEnabled      bool   `yaml:"indexer_enabled"`
-	IndexerStartSlot    uint64 `yaml:"indexer_start_slot"`
+	Enabled           bool   `yaml:"enabled"`
+	CheckIntervalSec  int    `yaml:"check_interval_sec"`
+	AutoCloseEnabled  bool   `yaml:"auto_close_enabled"`
+	AutoCancelEnabled bool   `yaml:"auto_cancel_enabled"`
+	IndexerEnabled    bool   `yaml:"indexer_enabled"`
+	IndexerStartSlot  uint64 `yaml:"indexer_start_slot"`
+	// RollingWindowSec is the window size of the analytics RollingAccumulator (e.g.
+	// recent volume/bets/active users); 0 disables it
+	RollingWindowSec int `yaml:"rolling_window_sec"`
+	// SchedulerStyle selects how cron ticks are dispatched to jobs: "basic" spawns
+	// a goroutine per tick (the historical behavior), "advanced" gives each job its
+	// own bounded work queue and dedicated worker goroutine so a stalled job can't
+	// pile up unbounded concurrent runs. Defaults to "basic".
+	SchedulerStyle string `yaml:"scheduler_style"`
+	// JobQueueSize is the buffer size of each job's work queue in "advanced"
+	// scheduler style; ticks that arrive while the queue is full are dropped
+	// rather than blocking. Defaults to 1.
+	JobQueueSize int `yaml:"job_queue_size"`
+	// TimeZone is the IANA zone (e.g. "America/New_York") the cron engine uses
+	// by default for schedules that don't carry their own CRON_TZ= prefix.
+	// Empty means the server's local time zone.
+	TimeZone string `yaml:"time_zone"`
+	// Jobs optionally overrides the schedule, time zone, enabled state, and
+	// timeout of a built-in job by ID, so operators can retune or disable a
+	// job (e.g. move analytics_daily_rollup to run at a different hour in a
+	// different region) without recompiling. A job ID with no matching entry
+	// here keeps its built-in defaults.
+	Jobs []JobSpec `yaml:"jobs"`
+	// EventRetention bounds how long EventLog and JobRun rows are kept before
+	// cleanupOldEvents deletes them (e.g. "720h" for 30 days); parsed via
+	// time.ParseDuration.
+	EventRetention string `yaml:"event_retention"`
+	// EventCleanupBatchSize caps how many rows cleanupOldEvents deletes per
+	// batch, so a large backlog doesn't hold a long-running delete lock.
+	EventCleanupBatchSize int `yaml:"event_cleanup_batch_size"`
+	// IdempotencyRetention bounds how long request_idempotency rows are kept
+	// before cleanupIdempotencyRecords deletes them (e.g. "24h", matching the
+	// dedup window IdempotencyInterceptor honors); parsed via time.ParseDuration.
+	IdempotencyRetention string `yaml:"idempotency_retention"`
+}
+
+// JobSpec overrides one built-in scheduler job, matched by ID.
+type JobSpec struct {
+	ID       string `yaml:"id"`
+	Schedule string `yaml:"schedule"`
+	// TimeZone is an IANA zone applied to Schedule via a CRON_TZ= prefix; empty
+	// means use the scheduler's default TimeZone.
+	TimeZone   string `yaml:"time_zone"`
+	Enabled    bool   `yaml:"enabled"`
+	TimeoutSec int    `yaml:"timeout_sec"`
 }
 
 type NotifyConfig struct {
@@ -58,16 +138,261 @@ type NotifyConfig struct {
 		From     string `yaml:"from"`
 	} `yaml:"smtp"`
 	WebPush struct {
-		Enabled    bool   `yaml:"enabled"`
-		VapidKey   string `yaml:"vapid_key"`
+		Enabled bool `yaml:"enabled"`
+		// VapidKey is the VAPID public key (uncompressed P-256 point, base64url,
+		// unpadded) handed to browsers as the applicationServerKey when they create a
+		// push subscription; it must match VapidPrivateKey.
+		VapidKey string `yaml:"vapid_key"`
+		// VapidPrivateKey is the VAPID signing key: a raw P-256 scalar, base64url,
+		// unpadded. webpush.go uses it to sign the VAPID JWT (RFC 8292) that
+		// authenticates this server to the push service.
+		VapidPrivateKey string `yaml:"vapid_private_key"`
+		// VapidEmail becomes the JWT's "sub" claim as "mailto:<VapidEmail>", the
+		// contact address a push service can use if it needs to reach this server's
+		// operator (e.g. to report abuse).
 		VapidEmail string `yaml:"vapid_email"`
 	} `yaml:"web_push"`
+	FCM struct {
+		Enabled bool `yaml:"enabled"`
+		// ProjectID is the Firebase project ID, used both as the FCM HTTP v1 API's
+		// path segment and as the service account JWT's audience.
+		ProjectID string `yaml:"project_id"`
+		// ServiceAccountKeyPath points to a Firebase service account JSON key file
+		// (the same file downloadable from the Firebase console); its private_key is
+		// used to sign the OAuth2 JWT-bearer token exchanged for an access token, so
+		// no Firebase Admin SDK or golang.org/x/oauth2 dependency is needed.
+		ServiceAccountKeyPath string `yaml:"service_account_key_path"`
+	} `yaml:"fcm"`
+	APNs struct {
+		Enabled bool `yaml:"enabled"`
+		// KeyPath points to the .p8 APNs Auth Key downloaded from the Apple Developer
+		// portal; it holds a raw EC P-256 private key, signed the same way VapidPrivateKey
+		// signs the web push VAPID JWT.
+		KeyPath string `yaml:"key_path"`
+		// KeyID and TeamID are the APNs Auth Key's key ID and the developer team ID,
+		// carried in the JWT's header ("kid") and claims ("iss") respectively.
+		KeyID  string `yaml:"key_id"`
+		TeamID string `yaml:"team_id"`
+		// Topic is the apns-topic header value, normally the app's bundle ID.
+		Topic string `yaml:"topic"`
+		// Sandbox selects the development APNs host (api.sandbox.push.apple.com)
+		// instead of the production one, for TestFlight/debug builds.
+		Sandbox bool `yaml:"sandbox"`
+	} `yaml:"apns"`
+	// Queue configures the async delivery queue notify.DeliveryQueue runs
+	// sends through, instead of sendNotifications dialing a transport inline.
+	Queue struct {
+		// WorkerCount is how many goroutines drain the delivery queue concurrently.
+		WorkerCount int `yaml:"worker_count"`
+		// BufferSize is the delivery channel's capacity; Enqueue blocks once it's full.
+		BufferSize int `yaml:"buffer_size"`
+		// MaxAttempts caps how many times a failed delivery is retried before it's
+		// marked dead in notification_deliveries.
+		MaxAttempts int `yaml:"max_attempts"`
+		// InitialBackoffSec and MaxBackoffSec bound the exponential retry backoff
+		// (doubling per attempt, ±20% jitter), mirroring scheduler.RetryPolicy.
+		InitialBackoffSec int `yaml:"initial_backoff_sec"`
+		MaxBackoffSec     int `yaml:"max_backoff_sec"`
+	} `yaml:"queue"`
+	// TemplateDir, if set, points to a directory of
+	// "<event>.<locale>.<kind>.tmpl" files (kind is one of subject/text/html/
+	// webpush.json) that notify.TemplateRegistry loads notification content from
+	// instead of the built-in generate*Text/HTML functions, letting operators
+	// customize or translate copy without recompiling. Empty disables the
+	// registry entirely and every notification uses its built-in default content.
+	TemplateDir string `yaml:"template_dir"`
+	// DefaultLocale is the locale notifications are rendered in until per-user
+	// locale preferences exist; passed to notify.TemplateRegistry.Render's
+	// Accept-Language-style fallback chain.
+	DefaultLocale string `yaml:"default_locale"`
+	// Links configures the signed one-click links (RFC 8058 unsubscribe, and
+	// preference editing) embedded in every outbound email.
+	Links struct {
+		// Secret signs each link's token (HMAC-SHA256 over the user ID); empty
+		// disables link signing, so sendEmailNotification skips the
+		// List-Unsubscribe header entirely rather than emit an unverifiable link.
+		Secret string `yaml:"secret"`
+		// BaseURL is the externally-reachable HTTPS origin these links point at,
+		// e.g. "https://api.friend-bets.example"; joined with
+		// /notifications/unsubscribe and /notifications/preferences.
+		BaseURL string `yaml:"base_url"`
+		// MailTo is the mailto: address offered alongside the HTTPS link in
+		// List-Unsubscribe, for mail clients that only support the mailto form.
+		MailTo string `yaml:"mailto"`
+	} `yaml:"links"`
+	// Webhooks are external services notified over HTTP whenever notify.Bus
+	// publishes an event, alongside the built-in email/web_push/fcm/apns
+	// fanout. Empty disables outbound webhook dispatch entirely.
+	Webhooks []WebhookConfig `yaml:"webhooks"`
+	// RatePerMinute caps outbound sends per provider per minute, so a burst of
+	// notifications (e.g. a popular market resolving) can't overrun an SMTP
+	// relay's or push service's own rate limits.
+	RatePerMinute struct {
+		Email   int `yaml:"email"`
+		WebPush int `yaml:"web_push"`
+		FCM     int `yaml:"fcm"`
+		APNs    int `yaml:"apns"`
+	} `yaml:"rate_per_minute"`
+}
+
+// WebhookConfig is one external service subscribed to notify.Bus events.
+type WebhookConfig struct {
+	// URL is the endpoint POSTed a JSON-encoded event payload.
+	URL string `yaml:"url"`
+	// Secret signs each POST body as a hex-encoded HMAC-SHA256 in the
+	// X-Signature header, the same scheme internal/solana's inbound
+	// WebhookReceiver verifies, just applied in the outbound direction.
+	Secret string `yaml:"secret"`
+	// Events restricts delivery to these event types (e.g. "market_resolved");
+	// empty means every event type.
+	Events []string `yaml:"events"`
 }
 
 type RateConfig struct {
 	CreateMarketPerHour int `yaml:"create_market_per_hour"`
 	PlaceBetPerMinute   int `yaml:"place_bet_per_minute"`
-	IPRatePerMinute     int `yaml:"ip_rate_per_minute"`
+	// ResolvePerMinute and ClaimPerMinute are the per-user quotas for Resolve and
+	// Claim, mirroring PlaceBetPerMinute; both default to 0 (RateLimitInterceptor
+	// falls back to 20/min, the same default place_bet uses).
+	ResolvePerMinute int `yaml:"resolve_per_minute"`
+	ClaimPerMinute   int `yaml:"claim_per_minute"`
+	IPRatePerMinute  int `yaml:"ip_rate_per_minute"`
+	// MaxWatchStreamsPerUser bounds how many concurrent WatchEvents streams a single
+	// caller may have open at once; 0 disables the limit. WatchEvents carries no
+	// verified wallet identity (it's registered PermPublic in the MethodRegistry,
+	// see grpc.registerBetsServiceMethods), so in practice this is keyed by peer
+	// IP rather than user ID until streaming calls are authenticated.
+	MaxWatchStreamsPerUser int `yaml:"max_watch_streams_per_user"`
+	// MaxTrackedKeys bounds how many distinct rate-limit keys the in-memory limiter
+	// keeps at once; least-recently-used keys are evicted once this is exceeded
+	MaxTrackedKeys int `yaml:"max_tracked_keys"`
+	// MaxFutureReserveSec bounds how far into the future Limiter.Reserve will let a
+	// caller reserve tokens; reservations that would need a longer wait fail fast
+	// with ErrRateLimitExceeded instead of blocking
+	MaxFutureReserveSec int `yaml:"max_future_reserve_sec"`
+	// BytesPerSecond is the global bandwidth cap enforced by rate.Throttler
+	BytesPerSecond int64 `yaml:"bytes_per_second"`
+	// BurstBytes is how many bytes a bandwidth bucket can hold above the steady
+	// bytes_per_second rate, allowing short bursts (e.g. one upload chunk) through
+	// without waiting
+	BurstBytes int64 `yaml:"burst_bytes"`
+	// DurableAudit enables a background write of every admitted Limiter.Allow call
+	// to the RateCounter table, for operators who want a durable record of rate
+	// limit activity. It is off by default: the admission decision itself is always
+	// made from the in-memory bucket, so this flag only controls an optional audit
+	// sink, not correctness, and a Postgres write per rate-limited action doesn't
+	// scale to real traffic.
+	DurableAudit bool `yaml:"durable_audit"`
+	// RedisAddr, if set, backs distributed rate limiting (rate.NewRedisDistributedLimiter)
+	// with Redis INCR/EXPIRE instead of the Postgres-backed RateCounter table, so
+	// multi-replica counters stay off the primary database.
+	RedisAddr string `yaml:"redis_addr"`
+}
+
+// MMConfig configures the built-in market-maker bot subsystem
+type MMConfig struct {
+	Enabled             bool          `yaml:"enabled"`
+	OperatorKeypairPath string        `yaml:"operator_keypair_path"`
+	Bots                []MMBotConfig `yaml:"bots"`
+}
+
+// MMBotConfig configures a single market-maker bot strategy
+type MMBotConfig struct {
+	ID                string  `yaml:"id"`
+	TitleFilter       string  `yaml:"title_filter"` // regex matched against market title
+	MinEndTsSec       int64   `yaml:"min_end_ts_sec"`
+	MaxEndTsSec       int64   `yaml:"max_end_ts_sec"`
+	Mint              string  `yaml:"mint"`
+	TargetImbalanceLo float64 `yaml:"target_imbalance_lo"` // lower bound on StakedA/(StakedA+StakedB)
+	TargetImbalanceHi float64 `yaml:"target_imbalance_hi"` // upper bound on StakedA/(StakedA+StakedB)
+	MaxExposure       uint64  `yaml:"max_exposure"`        // max cumulative stake per market
+	RefillIntervalSec int     `yaml:"refill_interval_sec"`
+}
+
+// RiskConfig configures the risk package's circuit breakers, which can automatically
+// halt a market exhibiting abnormal activity.
+type RiskConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// MaxWalletShareBps trips WalletConcentrationBreaker once one wallet's positions
+	// exceed this share (in basis points) of a market's total staked amount.
+	MaxWalletShareBps int `yaml:"max_wallet_share_bps"`
+	// MaxOddsMoveBps and OddsMoveWindowSec trip OddsMovementBreaker when a market's
+	// side A probability moves by more than MaxOddsMoveBps basis points within
+	// OddsMoveWindowSec seconds.
+	MaxOddsMoveBps    int `yaml:"max_odds_move_bps"`
+	OddsMoveWindowSec int `yaml:"odds_move_window_sec"`
+	// MaxDisputesPerCreator and DisputeWindowSec trip DisputeFrequencyBreaker when a
+	// market's creator has had more than MaxDisputesPerCreator disputes filed across
+	// any of their markets within DisputeWindowSec seconds.
+	MaxDisputesPerCreator int `yaml:"max_disputes_per_creator"`
+	DisputeWindowSec      int `yaml:"dispute_window_sec"`
+	// MaxResolverDeadlineViolations trips ResolverHistoryBreaker when a market's
+	// resolver has more than this many past resolve-deadline violations on record.
+	MaxResolverDeadlineViolations int `yaml:"max_resolver_deadline_violations"`
+}
+
+// HedgeConfig configures the optional hedge package, which opens an offsetting perp
+// position on an external exchange for markets that opt in via CreateMarketRequest.
+type HedgeConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// DeltaThresholdBps is how far a market's StakedA/StakedB imbalance (in basis
+	// points of total staked) must drift before the hedge position is adjusted.
+	DeltaThresholdBps int `yaml:"delta_threshold_bps"`
+	// MaxNotionalPerMarket caps the hedge order size placed for any single market,
+	// regardless of how large its imbalance grows. 0 means unbounded.
+	MaxNotionalPerMarket uint64 `yaml:"max_notional_per_market"`
+	// OrdersPerMinute is the token-bucket rate limit on hedge orders placed per
+	// creator, reusing the internal/rate limiter rather than a bespoke one.
+	OrdersPerMinute int `yaml:"orders_per_minute"`
+	// EncryptionKeyHex is the 32-byte (64 hex char) AES-256 key used to encrypt
+	// creator exchange API credentials at rest; never persisted to the database.
+	EncryptionKeyHex string `yaml:"encryption_key_hex"`
+}
+
+// AuthConfig configures wallet-signature authentication enforced by
+// grpc.AuthInterceptor.
+type AuthConfig struct {
+	// NonceMaxAgeSec bounds how old a SolanaSig header's timestamp may be before
+	// it's rejected as stale, and how long a signature is remembered to reject
+	// replays of the same header.
+	NonceMaxAgeSec int `yaml:"nonce_max_age_sec"`
+	// DevBearerToken, if set, lets requests authenticate with
+	// "Authorization: Bearer <token>" matching this value exactly, bypassing
+	// wallet-signature verification entirely. For local development only; leave
+	// empty in any shared environment.
+	DevBearerToken string `yaml:"dev_bearer_token"`
+	// Domain is bound into the Sign-In-With-Solana challenge message
+	// (grpc.SessionAuthService) so a signature collected for this deployment
+	// can't be replayed against another one using the same scheme.
+	Domain string `yaml:"domain"`
+	// JWTSecret signs session tokens issued by SessionAuthService.VerifyChallenge.
+	// Empty disables the challenge/session-token login flow entirely; per-request
+	// SolanaSig signing (AuthInterceptor.verifyWalletSignature) still works either way.
+	JWTSecret string `yaml:"jwt_secret"`
+	// SessionTTLSec is how long a session token issued by VerifyChallenge stays
+	// valid; defaults to 24h if unset.
+	SessionTTLSec int `yaml:"session_ttl_sec"`
+	// ChallengeTTLSec is how long a RequestChallenge nonce stays valid and
+	// single-use-redeemable before it expires; defaults to 5 minutes if unset.
+	ChallengeTTLSec int `yaml:"challenge_ttl_sec"`
+	// ResolverPubkeys grants grpc.PermResolver (see grpc.MethodRegistry) to
+	// these base58 wallet pubkeys, on top of whatever grpc.PermUser allows.
+	ResolverPubkeys []string `yaml:"resolver_pubkeys"`
+	// AdminPubkeys grants grpc.PermAdmin to these base58 wallet pubkeys.
+	AdminPubkeys []string `yaml:"admin_pubkeys"`
+}
+
+type ResolveConfig struct {
+	// ChallengeWindowSec is how long a proposed outcome stays challengeable before a
+	// market with no disputes can be finalized as resolved
+	ChallengeWindowSec int `yaml:"challenge_window_sec"`
+	// DisputeThresholdBps is the fraction (in basis points) of the losing side's pool
+	// that dispute stake must exceed to push a market into the disputed state
+	DisputeThresholdBps int `yaml:"dispute_threshold_bps"`
+	// DisputeQuorumBps is the fraction (in basis points) of a disputed market's total
+	// staked pool that must be represented by weighted votes before CastDisputeVote
+	// will finalize it.
+	DisputeQuorumBps int `yaml:"dispute_quorum_bps"`
 }
 
 // Load loads configuration from file with environment variable overrides
@@ -122,10 +447,37 @@ func applyDefaults(cfg *Config) {
 	if cfg.Solana.ConfirmationMode == "" {
 		cfg.Solana.ConfirmationMode = "confirmed"
 	}
+	if cfg.Solana.BackfillWorkers == 0 {
+		cfg.Solana.BackfillWorkers = 4
+	}
+	if cfg.Solana.BackfillChunkSize == 0 {
+		cfg.Solana.BackfillChunkSize = 1000
+	}
+	if cfg.Solana.WebhookPath == "" {
+		cfg.Solana.WebhookPath = "/webhooks/solana"
+	}
 
 	if cfg.Worker.CheckIntervalSec == 0 {
 		cfg.Worker.CheckIntervalSec = 60
 	}
+	if cfg.Worker.RollingWindowSec == 0 {
+		cfg.Worker.RollingWindowSec = 24 * 60 * 60
+	}
+	if cfg.Worker.SchedulerStyle == "" {
+		cfg.Worker.SchedulerStyle = "basic"
+	}
+	if cfg.Worker.JobQueueSize == 0 {
+		cfg.Worker.JobQueueSize = 1
+	}
+	if cfg.Worker.EventRetention == "" {
+		cfg.Worker.EventRetention = "720h"
+	}
+	if cfg.Worker.EventCleanupBatchSize == 0 {
+		cfg.Worker.EventCleanupBatchSize = 1000
+	}
+	if cfg.Worker.IdempotencyRetention == "" {
+		cfg.Worker.IdempotencyRetention = "24h"
+	}
 
 	if cfg.Rate.CreateMarketPerHour == 0 {
 		cfg.Rate.CreateMarketPerHour = 10
@@ -133,11 +485,109 @@ func applyDefaults(cfg *Config) {
 	if cfg.Rate.PlaceBetPerMinute == 0 {
 		cfg.Rate.PlaceBetPerMinute = 20
 	}
+	if cfg.Rate.ResolvePerMinute == 0 {
+		cfg.Rate.ResolvePerMinute = 20
+	}
+	if cfg.Rate.ClaimPerMinute == 0 {
+		cfg.Rate.ClaimPerMinute = 20
+	}
+	if cfg.Rate.MaxWatchStreamsPerUser == 0 {
+		cfg.Rate.MaxWatchStreamsPerUser = 5
+	}
 	if cfg.Rate.IPRatePerMinute == 0 {
 		cfg.Rate.IPRatePerMinute = 100
 	}
+	if cfg.Rate.MaxTrackedKeys == 0 {
+		cfg.Rate.MaxTrackedKeys = 100000
+	}
+	if cfg.Rate.MaxFutureReserveSec == 0 {
+		cfg.Rate.MaxFutureReserveSec = 30
+	}
+	if cfg.Rate.BytesPerSecond == 0 {
+		cfg.Rate.BytesPerSecond = 10 * 1024 * 1024 // 10 MB/s
+	}
+	if cfg.Rate.BurstBytes == 0 {
+		cfg.Rate.BurstBytes = 20 * 1024 * 1024 // 20 MB
+	}
 
 	cfg.Notify.SMTP.Port = getIntOrDefault(cfg.Notify.SMTP.Port, 587)
+
+	if cfg.Notify.DefaultLocale == "" {
+		cfg.Notify.DefaultLocale = "en"
+	}
+
+	if cfg.Notify.Queue.WorkerCount == 0 {
+		cfg.Notify.Queue.WorkerCount = 4
+	}
+	if cfg.Notify.Queue.BufferSize == 0 {
+		cfg.Notify.Queue.BufferSize = 256
+	}
+	if cfg.Notify.Queue.MaxAttempts == 0 {
+		cfg.Notify.Queue.MaxAttempts = 6
+	}
+	if cfg.Notify.Queue.InitialBackoffSec == 0 {
+		cfg.Notify.Queue.InitialBackoffSec = 5
+	}
+	if cfg.Notify.Queue.MaxBackoffSec == 0 {
+		cfg.Notify.Queue.MaxBackoffSec = 300
+	}
+	if cfg.Notify.RatePerMinute.Email == 0 {
+		cfg.Notify.RatePerMinute.Email = 60
+	}
+	if cfg.Notify.RatePerMinute.WebPush == 0 {
+		cfg.Notify.RatePerMinute.WebPush = 120
+	}
+	if cfg.Notify.RatePerMinute.FCM == 0 {
+		cfg.Notify.RatePerMinute.FCM = 120
+	}
+	if cfg.Notify.RatePerMinute.APNs == 0 {
+		cfg.Notify.RatePerMinute.APNs = 120
+	}
+
+	if cfg.Resolve.ChallengeWindowSec == 0 {
+		cfg.Resolve.ChallengeWindowSec = 3600 // 1 hour
+	}
+	if cfg.Resolve.DisputeThresholdBps == 0 {
+		cfg.Resolve.DisputeThresholdBps = 500 // 5%
+	}
+	if cfg.Resolve.DisputeQuorumBps == 0 {
+		cfg.Resolve.DisputeQuorumBps = 5000 // 50%
+	}
+
+	if cfg.Risk.MaxWalletShareBps == 0 {
+		cfg.Risk.MaxWalletShareBps = 5000 // 50%
+	}
+	if cfg.Risk.MaxOddsMoveBps == 0 {
+		cfg.Risk.MaxOddsMoveBps = 3000 // 30%
+	}
+	if cfg.Risk.OddsMoveWindowSec == 0 {
+		cfg.Risk.OddsMoveWindowSec = 60
+	}
+	if cfg.Risk.MaxDisputesPerCreator == 0 {
+		cfg.Risk.MaxDisputesPerCreator = 3
+	}
+	if cfg.Risk.DisputeWindowSec == 0 {
+		cfg.Risk.DisputeWindowSec = 24 * 60 * 60
+	}
+	if cfg.Risk.MaxResolverDeadlineViolations == 0 {
+		cfg.Risk.MaxResolverDeadlineViolations = 2
+	}
+	if cfg.Hedge.DeltaThresholdBps == 0 {
+		cfg.Hedge.DeltaThresholdBps = 1000 // 10%
+	}
+	if cfg.Hedge.OrdersPerMinute == 0 {
+		cfg.Hedge.OrdersPerMinute = 5
+	}
+
+	if cfg.Auth.NonceMaxAgeSec == 0 {
+		cfg.Auth.NonceMaxAgeSec = 60
+	}
+	if cfg.Auth.SessionTTLSec == 0 {
+		cfg.Auth.SessionTTLSec = 24 * 60 * 60
+	}
+	if cfg.Auth.ChallengeTTLSec == 0 {
+		cfg.Auth.ChallengeTTLSec = 5 * 60
+	}
 }
 
 func applyEnvOverrides(cfg *Config) {
@@ -156,6 +606,12 @@ func applyEnvOverrides(cfg *Config) {
 	if v := os.Getenv("MAINTENANCE_KEYPAIR_PATH"); v != "" {
 		cfg.Solana.MaintenanceKey = v
 	}
+	if v := os.Getenv("AUTH_DEV_BEARER_TOKEN"); v != "" {
+		cfg.Auth.DevBearerToken = v
+	}
+	if v := os.Getenv("AUTH_JWT_SECRET"); v != "" {
+		cfg.Auth.JWTSecret = v
+	}
 	if v := os.Getenv("BACKEND_ADDR"); v != "" {
 		// Parse host:port
 		// Simple parsing for now
@@ -171,4 +627,4 @@ func getIntOrDefault(value, defaultValue int) int {
 		return defaultValue
 	}
 	return value
-}
\ No newline at end of file
+}
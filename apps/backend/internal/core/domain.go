@@ -3,6 +3,8 @@ package core
 import (
 	"encoding/json"
 	"fmt"
+	"log/slog"
+	"math"
 	"time"
 
 	"github.com/shopspring/decimal"
@@ -10,10 +12,24 @@ import (
 
 // Market status constants
 const (
-	MarketStatusOpen          = "open"
-	MarketStatusPendingResolve = "pending_resolve"
-	MarketStatusResolved      = "resolved"
-	MarketStatusCancelled     = "cancelled"
+	MarketStatusOpen             = "open"
+	MarketStatusPendingResolve   = "pending_resolve"
+	MarketStatusPendingChallenge = "pending_challenge"
+	MarketStatusDisputed         = "disputed"
+	MarketStatusResolved         = "resolved"
+	MarketStatusCancelled        = "cancelled"
+	// MarketStatusHalted is set by the risk package's circuit breakers when a market
+	// exhibits abnormal activity; like every other non-open status it rejects new
+	// bets via ValidatePlaceBet until an admin resets the breaker and reopens it.
+	MarketStatusHalted = "halted"
+)
+
+// Market type constants. MarketTypeParimutuel is the original pooled-staking model
+// (odds = totalStaked / sideStaked); MarketTypeLMSR uses Hanson's Logarithmic Market
+// Scoring Rule so a quote is available even before both sides have liquidity.
+const (
+	MarketTypeParimutuel = "parimutuel"
+	MarketTypeLMSR       = "lmsr"
 )
 
 // Bet side constants
@@ -31,6 +47,13 @@ const (
 	EventCancelled           = "Cancelled"
 	EventClaimed             = "Claimed"
 	EventCreatorFeeWithdrawn = "CreatorFeeWithdrawn"
+	// EventCircuitBreakerTripped is recorded by the risk package when a Breaker halts
+	// a market; its Data carries the tripping breaker's reason.
+	EventCircuitBreakerTripped = "CircuitBreakerTripped"
+	// EventReorged is broadcast when a previously pending event's slot is orphaned by
+	// a chain reorg before it could be promoted and applied; it was never reflected in
+	// application state, so there's nothing to roll back beyond notifying subscribers.
+	EventReorged = "event_reorged"
 )
 
 // Market represents a betting market
@@ -49,6 +72,29 @@ type Market struct {
 	CreatorFeeWithdrawn bool
 	Title               string
 	CreatedAt           time.Time
+	ParentID            *string
+	SuccessorIDs        []string
+	ProposedOutcome     *string
+	ChallengeEndsAt     *time.Time
+
+	// LMSR-only fields; zero/empty for MarketTypeParimutuel markets. MarketType
+	// defaults to MarketTypeParimutuel for markets created before this field existed.
+	MarketType     string
+	LiquidityParam float64 // b in the LMSR cost function
+	SharesA        float64 // qA, outstanding shares of side A
+	SharesB        float64 // qB, outstanding shares of side B
+
+	// Hedge fields; empty/false unless the creator opted in at market creation.
+	// See package hedge for how HedgeExchange/HedgeSymbol drive an offsetting
+	// perp position as StakedA/StakedB drift.
+	HedgeEnabled  bool
+	HedgeExchange string
+	HedgeSymbol   string
+}
+
+// isLMSR reports whether m uses the LMSR pricing model rather than parimutuel pooling.
+func (m *Market) isLMSR() bool {
+	return m.MarketType == MarketTypeLMSR
 }
 
 // Position represents a user's betting position
@@ -69,6 +115,26 @@ type MarketEvent struct {
 	Data        map[string]interface{}
 	Slot        uint64
 	BlockTime   time.Time
+	// Seq is the EventLog row ID this event was promoted to, used as a resumption
+	// cursor by WatchEvents. 0 for events that were never persisted to EventLog
+	// (e.g. a synthetic reorg notification).
+	Seq uint64
+}
+
+// LogValue implements slog.LogValuer so logging a MarketEvent renders a compact summary
+// (tx signature truncated, field count for Data) instead of dumping the full Data map.
+func (e MarketEvent) LogValue() slog.Value {
+	sig := e.TxSignature
+	if len(sig) > 12 {
+		sig = sig[:8] + ".." + sig[len(sig)-4:]
+	}
+	return slog.GroupValue(
+		slog.String("tx", sig),
+		slog.String("event_type", e.EventType),
+		slog.String("market_id", e.MarketID),
+		slog.Uint64("slot", e.Slot),
+		slog.Int("data_fields", len(e.Data)),
+	)
 }
 
 // Odds calculates betting odds for a market
@@ -93,6 +159,17 @@ type CreateMarketRequest struct {
 	EndTs             time.Time
 	ResolveDeadlineTs time.Time
 	Title             string
+	// MarketType selects the pricing model; empty defaults to MarketTypeParimutuel.
+	MarketType string
+	// LiquidityParam is the LMSR liquidity parameter b; required (and must be > 0)
+	// when MarketType is MarketTypeLMSR, ignored otherwise.
+	LiquidityParam float64
+	// HedgeEnabled opts this market into the hedge package's offsetting perp
+	// position worker; HedgeExchange/HedgeSymbol are required when set (e.g.
+	// exchange "binance", symbol "BTCUSDT").
+	HedgeEnabled  bool
+	HedgeExchange string
+	HedgeSymbol   string
 }
 
 // PlaceBetRequest represents a request to place a bet
@@ -116,6 +193,39 @@ type ClaimRequest struct {
 	Owner    string
 }
 
+// DisputeResolutionRequest represents a stake-weighted challenge against a market's
+// proposed outcome, raised during its challenge window. Stake is verified against the
+// disputer's actual position in the market rather than trusted as-is - see
+// UseCases.DisputeResolution.
+type DisputeResolutionRequest struct {
+	MarketID        string
+	Disputer        string
+	ProposedOutcome string
+	Stake           uint64
+}
+
+// CastDisputeVoteRequest represents one staker's stake-weighted vote on how a market
+// stuck in MarketStatusDisputed should be finalized. Weight is the voter's own
+// position size in the market, not a self-reported amount - see
+// UseCases.CastDisputeVote.
+type CastDisputeVoteRequest struct {
+	MarketID string
+	Voter    string
+	Outcome  string
+}
+
+// CreateSuccessorMarketRequest represents a request to chain a new market off a resolved
+// or cancelled one, optionally seeding it with the parent's losing-side pool
+type CreateSuccessorMarketRequest struct {
+	Creator           string
+	Title             string // overrides parent title if set
+	Mint              string // overrides parent mint if set
+	FeeBps            *uint16 // overrides parent fee if set
+	EndTs             time.Time
+	ResolveDeadlineTs time.Time
+	SeedSide          string // if set (A or B), migrate unclaimed positions into this side
+}
+
 // Validation methods
 
 // ValidateCreateMarket validates a create market request
@@ -141,6 +251,12 @@ func ValidateCreateMarket(req *CreateMarketRequest) error {
 	if req.ResolveDeadlineTs.Before(req.EndTs) {
 		return fmt.Errorf("resolve deadline must be after end time")
 	}
+	if req.MarketType == MarketTypeLMSR && req.LiquidityParam <= 0 {
+		return fmt.Errorf("liquidity parameter b must be greater than 0 for an LMSR market")
+	}
+	if req.HedgeEnabled && (req.HedgeExchange == "" || req.HedgeSymbol == "") {
+		return fmt.Errorf("hedge exchange and symbol are required when hedge is enabled")
+	}
 	return nil
 }
 
@@ -164,11 +280,21 @@ func ValidatePlaceBet(req *PlaceBetRequest, market *Market) error {
 	if time.Now().After(market.EndTs) {
 		return fmt.Errorf("betting period has ended")
 	}
+	if market.isLMSR() {
+		// req.Amount is a share count being purchased, not a nominal stake; the
+		// caller's actual vault deposit is CalculateCostToBuy(side, amount).
+		if market.LiquidityParam <= 0 {
+			return fmt.Errorf("market has an invalid liquidity parameter")
+		}
+	}
 	return nil
 }
 
-// ValidateResolveMarket validates a resolve market request
-func ValidateResolveMarket(req *ResolveMarketRequest, market *Market) error {
+// ValidateResolveMarket validates a resolve market request. challengeWindow is the
+// minimum time a proposed outcome must stay open to disputes before it can be
+// finalized; the resolve deadline must leave room for the full window to close,
+// otherwise the challenge period would be unenforceable.
+func ValidateResolveMarket(req *ResolveMarketRequest, market *Market, challengeWindow time.Duration) error {
 	if req.MarketID == "" {
 		return fmt.Errorf("market ID is required")
 	}
@@ -187,13 +313,89 @@ func ValidateResolveMarket(req *ResolveMarketRequest, market *Market) error {
 	if time.Now().After(market.ResolveDeadlineTs) {
 		return fmt.Errorf("resolution deadline has passed")
 	}
+	if market.ResolveDeadlineTs.Before(time.Now().Add(challengeWindow)) {
+		return fmt.Errorf("resolve deadline does not leave room for the challenge window to close")
+	}
+	return nil
+}
+
+// ValidateDisputeResolution validates a stake-weighted challenge against a market's
+// proposed outcome
+func ValidateDisputeResolution(req *DisputeResolutionRequest, market *Market) error {
+	if req.MarketID == "" {
+		return fmt.Errorf("market ID is required")
+	}
+	if req.Disputer == "" {
+		return fmt.Errorf("disputer is required")
+	}
+	if req.ProposedOutcome != BetSideA && req.ProposedOutcome != BetSideB {
+		return fmt.Errorf("proposed outcome must be A or B")
+	}
+	if req.Stake == 0 {
+		return fmt.Errorf("stake must be greater than 0")
+	}
+	if market.Status != MarketStatusPendingChallenge {
+		return fmt.Errorf("market is not in its challenge window")
+	}
+	if market.ChallengeEndsAt == nil || time.Now().After(*market.ChallengeEndsAt) {
+		return fmt.Errorf("challenge window has closed")
+	}
+	return nil
+}
+
+// ValidateCastDisputeVote validates a stake-weighted vote on how to finalize a
+// disputed market
+func ValidateCastDisputeVote(req *CastDisputeVoteRequest, market *Market) error {
+	if req.MarketID == "" {
+		return fmt.Errorf("market ID is required")
+	}
+	if req.Voter == "" {
+		return fmt.Errorf("voter is required")
+	}
+	if req.Outcome != BetSideA && req.Outcome != BetSideB {
+		return fmt.Errorf("outcome must be A or B")
+	}
+	if market.Status != MarketStatusDisputed {
+		return fmt.Errorf("market is not awaiting a dispute quorum vote")
+	}
+	return nil
+}
+
+// ValidateCreateSuccessorMarket validates a successor market request against its parent
+func ValidateCreateSuccessorMarket(req *CreateSuccessorMarketRequest, parent *Market) error {
+	if req.Creator == "" {
+		return fmt.Errorf("creator is required")
+	}
+	if parent.Status != MarketStatusResolved && parent.Status != MarketStatusCancelled {
+		return fmt.Errorf("parent market must be resolved or cancelled")
+	}
+	if req.SeedSide != "" && req.SeedSide != BetSideA && req.SeedSide != BetSideB {
+		return fmt.Errorf("seed side must be A or B")
+	}
+	if req.EndTs.Before(time.Now()) {
+		return fmt.Errorf("end time must be in the future")
+	}
+	if req.ResolveDeadlineTs.Before(req.EndTs) {
+		return fmt.Errorf("resolve deadline must be after end time")
+	}
 	return nil
 }
 
 // Business logic methods
 
-// CalculateOdds calculates current odds for a market
+// CalculateOdds calculates current odds for a market. For an LMSR market this returns
+// the instantaneous probability quote for each side (which sum to 1) rather than a
+// parimutuel payout multiplier.
 func (m *Market) CalculateOdds() Odds {
+	if m.isLMSR() {
+		priceA, _ := m.lmsrPrice(BetSideA)
+		priceB, _ := m.lmsrPrice(BetSideB)
+		return Odds{
+			SideA: decimal.NewFromFloat(priceA),
+			SideB: decimal.NewFromFloat(priceB),
+		}
+	}
+
 	if m.StakedA == 0 && m.StakedB == 0 {
 		return Odds{
 			SideA: decimal.NewFromInt(1),
@@ -225,6 +427,10 @@ func (m *Market) CalculateOdds() Odds {
 
 // CalculatePayout calculates the payout for a position
 func (m *Market) CalculatePayout(position *Position) PayoutInfo {
+	if m.isLMSR() {
+		return m.calculateLMSRPayout(position)
+	}
+
 	totalStaked := m.StakedA + m.StakedB
 	feeAmount := uint64(decimal.NewFromInt(int64(totalStaked)).Mul(decimal.NewFromInt(int64(m.FeeBps))).Div(decimal.NewFromInt(10000)).IntPart())
 	distributable := totalStaked - feeAmount
@@ -259,6 +465,129 @@ func (m *Market) CalculatePayout(position *Position) PayoutInfo {
 	}
 }
 
+// calculateLMSRPayout pays 1 unit per winning share (position.Amount holds the share
+// count, not a token stake) and refunds position.Amount unchanged if the market was
+// cancelled; there is no fee deduction on an LMSR market since fees are already priced
+// into the LMSR cost curve rather than skimmed from a pooled payout.
+func (m *Market) calculateLMSRPayout(position *Position) PayoutInfo {
+	var userPayout uint64
+
+	switch {
+	case m.Status == MarketStatusCancelled:
+		userPayout = position.Amount
+	case m.Status == MarketStatusResolved && m.Outcome != nil && position.Side == *m.Outcome:
+		userPayout = position.Amount
+	}
+
+	return PayoutInfo{
+		TotalStaked:   position.Amount,
+		Distributable: position.Amount,
+		UserPayout:    userPayout,
+	}
+}
+
+// lmsrCost returns C(qA, qB) = b * ln(exp(qA/b) + exp(qB/b)), computed via the
+// log-sum-exp trick (subtracting max(qA/b, qB/b) before exponentiating) so large q/b
+// doesn't overflow math.Exp.
+func (m *Market) lmsrCost(qA, qB float64) float64 {
+	b := m.LiquidityParam
+	zA, zB := qA/b, qB/b
+	maxZ := math.Max(zA, zB)
+	return b * (maxZ + math.Log(math.Exp(zA-maxZ)+math.Exp(zB-maxZ)))
+}
+
+// lmsrPrice returns the instantaneous probability quote for side: exp(qA/b) /
+// (exp(qA/b) + exp(qB/b)), via the same log-sum-exp trick as lmsrCost.
+func (m *Market) lmsrPrice(side string) (float64, error) {
+	if m.LiquidityParam <= 0 {
+		return 0, fmt.Errorf("liquidity parameter b must be greater than 0")
+	}
+
+	b := m.LiquidityParam
+	zA, zB := m.SharesA/b, m.SharesB/b
+	maxZ := math.Max(zA, zB)
+	eA, eB := math.Exp(zA-maxZ), math.Exp(zB-maxZ)
+
+	switch side {
+	case BetSideA:
+		return eA / (eA + eB), nil
+	case BetSideB:
+		return eB / (eA + eB), nil
+	default:
+		return 0, fmt.Errorf("side must be A or B")
+	}
+}
+
+// CalculateCostToBuy returns the cost (in base token units) to buy an additional
+// `shares` of side under this LMSR market's current state: C(q+Δ) - C(q).
+func (m *Market) CalculateCostToBuy(side string, shares float64) (float64, error) {
+	if !m.isLMSR() {
+		return 0, fmt.Errorf("market %s is not an LMSR market", m.ID)
+	}
+	if m.LiquidityParam <= 0 {
+		return 0, fmt.Errorf("liquidity parameter b must be greater than 0")
+	}
+	if shares <= 0 {
+		return 0, fmt.Errorf("shares must be greater than 0")
+	}
+
+	before := m.lmsrCost(m.SharesA, m.SharesB)
+
+	var after float64
+	switch side {
+	case BetSideA:
+		after = m.lmsrCost(m.SharesA+shares, m.SharesB)
+	case BetSideB:
+		after = m.lmsrCost(m.SharesA, m.SharesB+shares)
+	default:
+		return 0, fmt.Errorf("side must be A or B")
+	}
+
+	return after - before, nil
+}
+
+// CalculateSharesForCost inverts CalculateCostToBuy: given a budget the caller is
+// willing to spend, it finds how many shares of side that buys. CalculateCostToBuy is
+// strictly increasing in shares, so this bisects over an exponentially-grown upper
+// bound down to costPrecision.
+func (m *Market) CalculateSharesForCost(side string, cost float64) (float64, error) {
+	if !m.isLMSR() {
+		return 0, fmt.Errorf("market %s is not an LMSR market", m.ID)
+	}
+	if cost <= 0 {
+		return 0, fmt.Errorf("cost must be greater than 0")
+	}
+
+	const costPrecision = 1e-9
+
+	lo, hi := 0.0, 1.0
+	for {
+		got, err := m.CalculateCostToBuy(side, hi)
+		if err != nil {
+			return 0, err
+		}
+		if got >= cost || hi > 1e18 {
+			break
+		}
+		hi *= 2
+	}
+
+	for i := 0; i < 100 && hi-lo > costPrecision; i++ {
+		mid := (lo + hi) / 2
+		got, err := m.CalculateCostToBuy(side, mid)
+		if err != nil {
+			return 0, err
+		}
+		if got < cost {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+
+	return lo, nil
+}
+
 // IsExpired checks if the market is past its resolve deadline
 func (m *Market) IsExpired() bool {
 	return time.Now().After(m.ResolveDeadlineTs) && m.Status == MarketStatusPendingResolve
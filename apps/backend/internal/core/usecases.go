@@ -8,25 +8,71 @@ import (
 	"time"
 
 	"github.com/friend-bets/backend/internal/config"
+	"github.com/friend-bets/backend/internal/hedge"
+	"github.com/friend-bets/backend/internal/notify"
+	"github.com/friend-bets/backend/internal/risk"
 	"github.com/friend-bets/backend/internal/store"
+	"github.com/shopspring/decimal"
 )
 
 // UseCases implements business logic layer
 type UseCases struct {
-	repo   *store.Repository
-	config *config.Config
-	logger *slog.Logger
+	repo      *store.Repository
+	config    *config.Config
+	notifier  *notify.Notifier
+	analytics *store.Analytics
+	riskMon   *risk.Monitor
+	hedgeMon  *hedge.Monitor
+	userStats *store.UserStatsAggregator
+	logger    *slog.Logger
 }
 
-// NewUseCases creates a new UseCases instance
-func NewUseCases(repo *store.Repository, cfg *config.Config, logger *slog.Logger) *UseCases {
+// NewUseCases creates a new UseCases instance. notifier, analytics, riskMon, and
+// hedgeMon may be nil (e.g. in tests), in which case event processing skips
+// broadcasting, metrics recording, circuit-breaker checks, and hedge adjustment
+// respectively.
+func NewUseCases(repo *store.Repository, cfg *config.Config, notifier *notify.Notifier, analytics *store.Analytics, riskMon *risk.Monitor, hedgeMon *hedge.Monitor, logger *slog.Logger) *UseCases {
 	return &UseCases{
-		repo:   repo,
-		config: cfg,
-		logger: logger,
+		repo:      repo,
+		config:    cfg,
+		notifier:  notifier,
+		analytics: analytics,
+		riskMon:   riskMon,
+		hedgeMon:  hedgeMon,
+		userStats: store.NewUserStatsAggregator(repo),
+		logger:    logger,
 	}
 }
 
+// broadcast publishes a notification through the notifier hub, if one is configured.
+// txSignature is the on-chain transaction that produced the event, if any (empty for
+// events with no corresponding transaction, e.g. EventCircuitBreakerTripped). seq is
+// the event's EventLog row ID, if it was persisted there (0 otherwise); WatchEvents
+// surfaces it to clients as a resumable stream cursor.
+func (uc *UseCases) broadcast(marketID, userID, eventType, txSignature string, seq uint64, data map[string]interface{}) {
+	if uc.notifier == nil {
+		return
+	}
+	uc.notifier.Broadcast(notify.Notification{
+		MarketID:    marketID,
+		UserID:      userID,
+		EventType:   eventType,
+		TxSignature: txSignature,
+		Seq:         seq,
+		Data:        data,
+	})
+}
+
+// NotifyEventReorged broadcasts that a pending event was orphaned by a chain reorg
+// before it could be promoted and applied, so subscribers that may have optimistically
+// surfaced it (e.g. from an unconfirmed WatchFilteredEvents push) know to retract it.
+func (uc *UseCases) NotifyEventReorged(marketID, txSignature, eventType string) {
+	uc.broadcast(marketID, "", EventReorged, txSignature, 0, map[string]interface{}{
+		"tx_signature": txSignature,
+		"event_type":   eventType,
+	})
+}
+
 // Market Use Cases
 
 // ValidateCreateMarket validates a market creation request without persisting it
@@ -47,6 +93,11 @@ func (uc *UseCases) CreateMarket(ctx context.Context, req *CreateMarketRequest)
 		marketID = generateMarketID()
 	}
 
+	marketType := req.MarketType
+	if marketType == "" {
+		marketType = MarketTypeParimutuel
+	}
+
 	// Create market domain object
 	market := &Market{
 		ID:                marketID,
@@ -61,6 +112,11 @@ func (uc *UseCases) CreateMarket(ctx context.Context, req *CreateMarketRequest)
 		Status:            MarketStatusOpen,
 		Title:             strings.TrimSpace(req.Title),
 		CreatedAt:         time.Now(),
+		MarketType:        marketType,
+		LiquidityParam:    req.LiquidityParam,
+		HedgeEnabled:      req.HedgeEnabled,
+		HedgeExchange:     req.HedgeExchange,
+		HedgeSymbol:       req.HedgeSymbol,
 	}
 
 	// Convert to store model
@@ -77,6 +133,11 @@ func (uc *UseCases) CreateMarket(ctx context.Context, req *CreateMarketRequest)
 		Status:            market.Status,
 		Title:             market.Title,
 		CreatedAt:         market.CreatedAt,
+		MarketType:        market.MarketType,
+		LiquidityParam:    market.LiquidityParam,
+		HedgeEnabled:      market.HedgeEnabled,
+		HedgeExchange:     market.HedgeExchange,
+		HedgeSymbol:       market.HedgeSymbol,
 	}
 
 	// Save to database
@@ -90,6 +151,126 @@ func (uc *UseCases) CreateMarket(ctx context.Context, req *CreateMarketRequest)
 	return market, nil
 }
 
+// CreateSuccessorMarket creates a new market chained off a resolved or cancelled parent,
+// inheriting its metadata unless overridden and optionally seeding it with the parent's
+// unclaimed losing-side positions.
+func (uc *UseCases) CreateSuccessorMarket(ctx context.Context, parentID string, req *CreateSuccessorMarketRequest) (*Market, error) {
+	parent, err := uc.GetMarket(ctx, parentID)
+	if err != nil {
+		return nil, fmt.Errorf("parent market not found: %w", err)
+	}
+
+	if err := ValidateCreateSuccessorMarket(req, parent); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	title := req.Title
+	if title == "" {
+		title = parent.Title
+	}
+	mint := req.Mint
+	if mint == "" {
+		mint = parent.Mint
+	}
+	feeBps := parent.FeeBps
+	if req.FeeBps != nil {
+		feeBps = *req.FeeBps
+	}
+
+	marketID := generateMarketID()
+	parentIDCopy := parent.ID
+	market := &Market{
+		ID:                marketID,
+		Creator:           req.Creator,
+		Mint:              mint,
+		FeeBps:            feeBps,
+		EndTs:             req.EndTs,
+		ResolveDeadlineTs: req.ResolveDeadlineTs,
+		Status:            MarketStatusOpen,
+		Title:             strings.TrimSpace(title),
+		CreatedAt:         time.Now(),
+		ParentID:          &parentIDCopy,
+	}
+
+	marketView := &store.MarketView{
+		ID:                market.ID,
+		Creator:           market.Creator,
+		Mint:              market.Mint,
+		FeeBps:            market.FeeBps,
+		EndTs:             market.EndTs,
+		ResolveDeadlineTs: market.ResolveDeadlineTs,
+		Status:            market.Status,
+		Title:             market.Title,
+		CreatedAt:         market.CreatedAt,
+		ParentID:          market.ParentID,
+	}
+
+	if err := uc.repo.CreateMarket(marketView); err != nil {
+		uc.logger.Error("failed to create successor market", "error", err, "market_id", marketID, "parent_id", parentID)
+		return nil, fmt.Errorf("failed to create successor market: %w", err)
+	}
+
+	if parent.Status == MarketStatusCancelled && req.SeedSide != "" {
+		if err := uc.migrateUnclaimedPositions(parentID, marketID, req.SeedSide); err != nil {
+			uc.logger.Error("failed to migrate unclaimed positions to successor market", "error", err, "parent_id", parentID, "successor_id", marketID)
+		}
+	}
+
+	uc.logger.Info("successor market created", "market_id", marketID, "parent_id", parentID, "creator", req.Creator)
+
+	return market, nil
+}
+
+// migrateUnclaimedPositions carries unclaimed positions from a cancelled parent market
+// into the successor as pre-placed positions on the designated seed side.
+func (uc *UseCases) migrateUnclaimedPositions(parentID, successorID, seedSide string) error {
+	positions, err := uc.repo.GetPositionsByMarket(parentID)
+	if err != nil {
+		return fmt.Errorf("failed to get parent positions: %w", err)
+	}
+
+	for _, pos := range positions {
+		if pos.Claimed {
+			continue
+		}
+		seeded := &store.PositionView{
+			ID:       generatePositionID(),
+			MarketID: successorID,
+			Owner:    pos.Owner,
+			Side:     seedSide,
+			Amount:   pos.Amount,
+			Claimed:  false,
+		}
+		if err := uc.repo.CreateOrUpdatePosition(seeded); err != nil {
+			return fmt.Errorf("failed to seed position for %s: %w", pos.Owner, err)
+		}
+		pos.Claimed = true
+		if err := uc.repo.CreateOrUpdatePosition(&pos); err != nil {
+			return fmt.Errorf("failed to mark migrated position claimed: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// resolveSuccessorIDs returns the successor market IDs for a parent by querying the
+// store directly. This used to be served from an in-memory successorIndex cache that
+// only fell back to the store when empty, but that cache only ever saw edges created
+// by the replica holding it - in a multi-replica deployment a parent whose successors
+// were created on other replicas would appear to have none. GetMarketsByParentID is a
+// single indexed query, so there's no real cost to always hitting the store instead.
+func (uc *UseCases) resolveSuccessorIDs(marketID string) []string {
+	successors, err := uc.repo.GetMarketsByParentID(marketID)
+	if err != nil {
+		return nil
+	}
+	ids := make([]string, 0, len(successors))
+	for _, s := range successors {
+		ids = append(ids, s.ID)
+	}
+	return ids
+}
+
 // GetMarket retrieves a market by ID
 func (uc *UseCases) GetMarket(ctx context.Context, marketID string) (*Market, error) {
 	marketView, err := uc.repo.GetMarket(marketID)
@@ -161,7 +342,27 @@ func (uc *UseCases) PlaceBet(ctx context.Context, req *PlaceBetRequest) (*Positi
 		return nil, fmt.Errorf("failed to create position: %w", err)
 	}
 
-	uc.logger.Info("bet placed", "position_id", positionID, "market_id", req.MarketID, "owner", req.Owner, "side", req.Side, "amount", req.Amount)
+	if market.isLMSR() {
+		// req.Amount is the share count being purchased on an LMSR market; the vault
+		// deposit the caller is actually charged is the LMSR cost of those shares, not
+		// a nominal stake. SharesA/SharesB themselves are updated by the event indexer
+		// once the (currently parimutuel-only) on-chain program emits LMSR state.
+		cost, costErr := market.CalculateCostToBuy(req.Side, float64(req.Amount))
+		if costErr != nil {
+			uc.logger.Error("failed to compute LMSR cost to buy", "error", costErr, "market_id", req.MarketID)
+		} else {
+			uc.logger.Info("bet placed", "position_id", positionID, "market_id", req.MarketID, "owner", req.Owner, "side", req.Side, "shares", req.Amount, "lmsr_cost", cost)
+		}
+	} else {
+		uc.logger.Info("bet placed", "position_id", positionID, "market_id", req.MarketID, "owner", req.Owner, "side", req.Side, "amount", req.Amount)
+	}
+
+	if uc.analytics != nil {
+		uc.analytics.RecordBetPlaced(position.Side)
+
+		odds := market.CalculateOdds()
+		uc.analytics.RecordMarketOdds(market.ID, store.Odds{A: odds.SideA, B: odds.SideB})
+	}
 
 	return position, nil
 }
@@ -175,7 +376,8 @@ func (uc *UseCases) ResolveMarket(ctx context.Context, req *ResolveMarketRequest
 	}
 
 	// Validate request
-	if err := ValidateResolveMarket(req, market); err != nil {
+	challengeWindow := time.Duration(uc.config.Resolve.ChallengeWindowSec) * time.Second
+	if err := ValidateResolveMarket(req, market, challengeWindow); err != nil {
 		return fmt.Errorf("validation failed: %w", err)
 	}
 
@@ -281,6 +483,168 @@ func (uc *UseCases) ProcessExpiredMarkets(ctx context.Context) error {
 	return nil
 }
 
+// ProcessChallengeWindows finalizes markets whose challenge window has closed without
+// disputed stake exceeding the configured threshold
+func (uc *UseCases) ProcessChallengeWindows(ctx context.Context) error {
+	markets, err := uc.repo.GetMarketsByStatus(MarketStatusPendingChallenge)
+	if err != nil {
+		return fmt.Errorf("failed to get pending-challenge markets: %w", err)
+	}
+
+	for _, market := range markets {
+		if market.ChallengeEndsAt == nil || time.Now().Before(*market.ChallengeEndsAt) {
+			continue
+		}
+
+		market.Status = MarketStatusResolved
+		market.Outcome = market.ProposedOutcome
+		if err := uc.repo.UpdateMarket(&market); err != nil {
+			uc.logger.Error("failed to finalize challenge window", "error", err, "market_id", market.ID)
+			continue
+		}
+		uc.logger.Info("challenge window closed, market resolved", "market_id", market.ID, "outcome", *market.Outcome)
+	}
+
+	return nil
+}
+
+// DisputeResolution escrows a stake-weighted challenge against a market's proposed
+// outcome. If disputed stake against the proposal exceeds the configured fraction of
+// the losing side's pool, the market moves to disputed and awaits a quorum vote among
+// stakers (see CastDisputeVote) instead of auto-finalizing at the end of the challenge
+// window. req.Stake is verified against the disputer's actual position in the market -
+// a disputer can't claim more stake than they actually hold. A disputer may raise
+// exactly one dispute per market; CreateDispute rejects a second via its unique index
+// (market_id, disputer_id) rather than letting a retried call double-count their stake
+// toward the dispute threshold.
+func (uc *UseCases) DisputeResolution(ctx context.Context, req *DisputeResolutionRequest) error {
+	marketView, err := uc.repo.GetMarket(req.MarketID)
+	if err != nil {
+		return fmt.Errorf("market not found: %w", err)
+	}
+	market := uc.convertMarketViewToDomain(marketView)
+
+	if err := ValidateDisputeResolution(req, market); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	position, err := uc.repo.GetUserPosition(req.MarketID, req.Disputer)
+	if err != nil {
+		return fmt.Errorf("disputer has no position in this market: %w", err)
+	}
+	if req.Stake > position.Amount {
+		return fmt.Errorf("disputed stake %d exceeds disputer's position size %d", req.Stake, position.Amount)
+	}
+
+	dispute := &store.Dispute{
+		MarketID:        req.MarketID,
+		DisputerID:      req.Disputer,
+		Reason:          "stake-weighted challenge",
+		Status:          "pending",
+		ProposedOutcome: req.ProposedOutcome,
+		StakeAmount:     req.Stake,
+	}
+	if err := uc.repo.CreateDispute(dispute); err != nil {
+		return fmt.Errorf("failed to create dispute (already disputed by this wallet?): %w", err)
+	}
+
+	stakeByOutcome, err := uc.repo.SumDisputeStakeByOutcome(req.MarketID)
+	if err != nil {
+		return fmt.Errorf("failed to sum dispute stake: %w", err)
+	}
+
+	loserPool := market.StakedA
+	if *market.ProposedOutcome == BetSideA {
+		loserPool = market.StakedB
+	}
+
+	threshold := uint64(decimal.NewFromInt(int64(loserPool)).
+		Mul(decimal.NewFromInt(int64(uc.config.Resolve.DisputeThresholdBps))).
+		Div(decimal.NewFromInt(10000)).IntPart())
+
+	for outcome, staked := range stakeByOutcome {
+		if outcome == *market.ProposedOutcome {
+			continue
+		}
+		if staked > threshold {
+			marketView.Status = MarketStatusDisputed
+			if err := uc.repo.UpdateMarket(marketView); err != nil {
+				return fmt.Errorf("failed to mark market disputed: %w", err)
+			}
+			uc.logger.Info("market moved to disputed", "market_id", req.MarketID, "disputed_stake", staked, "threshold", threshold)
+			break
+		}
+	}
+
+	uc.logger.Info("dispute resolution submitted", "market_id", req.MarketID, "disputer", req.Disputer, "proposed_outcome", req.ProposedOutcome, "stake", req.Stake)
+
+	return nil
+}
+
+// CastDisputeVote records voter's vote on how to finalize a market stuck in
+// MarketStatusDisputed, weighted by their own position size in that market (not a
+// self-reported amount). A voter may cast exactly one vote per market; CreateDisputeVote
+// rejects a second vote via its unique index rather than letting it overwrite the first,
+// since a staker's weight is fixed at the moment they vote. Once an outcome's total
+// weighted votes reach the configured fraction of the market's total staked pool, the
+// market resolves to that outcome - this is the only path that finalizes a disputed
+// market, since ProcessChallengeWindows only ever scans pending-challenge markets.
+func (uc *UseCases) CastDisputeVote(ctx context.Context, req *CastDisputeVoteRequest) error {
+	marketView, err := uc.repo.GetMarket(req.MarketID)
+	if err != nil {
+		return fmt.Errorf("market not found: %w", err)
+	}
+	market := uc.convertMarketViewToDomain(marketView)
+
+	if err := ValidateCastDisputeVote(req, market); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	position, err := uc.repo.GetUserPosition(req.MarketID, req.Voter)
+	if err != nil {
+		return fmt.Errorf("voter has no position in this market: %w", err)
+	}
+
+	vote := &store.DisputeVote{
+		MarketID: req.MarketID,
+		Voter:    req.Voter,
+		Outcome:  req.Outcome,
+		Weight:   position.Amount,
+	}
+	if err := uc.repo.CreateDisputeVote(vote); err != nil {
+		return fmt.Errorf("failed to record dispute vote (already voted?): %w", err)
+	}
+
+	weightByOutcome, err := uc.repo.SumDisputeVoteWeightByOutcome(req.MarketID)
+	if err != nil {
+		return fmt.Errorf("failed to sum dispute votes: %w", err)
+	}
+
+	totalStaked := market.StakedA + market.StakedB
+	quorum := uint64(decimal.NewFromInt(int64(totalStaked)).
+		Mul(decimal.NewFromInt(int64(uc.config.Resolve.DisputeQuorumBps))).
+		Div(decimal.NewFromInt(10000)).IntPart())
+
+	for outcome, weight := range weightByOutcome {
+		if weight < quorum {
+			continue
+		}
+		winner := outcome
+		marketView.Status = MarketStatusResolved
+		marketView.Outcome = &winner
+		marketView.ProposedOutcome = nil
+		marketView.ChallengeEndsAt = nil
+		if err := uc.repo.UpdateMarket(marketView); err != nil {
+			return fmt.Errorf("failed to finalize disputed market: %w", err)
+		}
+		uc.logger.Info("disputed market finalized by quorum vote", "market_id", req.MarketID, "outcome", winner, "weight", weight, "quorum", quorum)
+		uc.broadcast(req.MarketID, "", EventResolved, "", 0, map[string]interface{}{"outcome": winner, "via": "dispute_quorum_vote"})
+		break
+	}
+
+	return nil
+}
+
 // Event processing for indexer
 
 // ProcessMarketEvent processes a market event from Solana
@@ -291,7 +655,7 @@ func (uc *UseCases) ProcessMarketEvent(ctx context.Context, event *MarketEvent)
 	case EventMarketInitialized:
 		return uc.processMarketInitialized(event)
 	case EventBetPlaced:
-		return uc.processBetPlaced(event)
+		return uc.processBetPlaced(ctx, event)
 	case EventBettingClosed:
 		return uc.processBettingClosed(event)
 	case EventResolved:
@@ -299,7 +663,7 @@ func (uc *UseCases) ProcessMarketEvent(ctx context.Context, event *MarketEvent)
 	case EventCancelled:
 		return uc.processCancelled(event)
 	case EventClaimed:
-		return uc.processClaimed(event)
+		return uc.processClaimed(ctx, event)
 	case EventCreatorFeeWithdrawn:
 		return uc.processCreatorFeeWithdrawn(event)
 	default:
@@ -326,6 +690,17 @@ func (uc *UseCases) convertMarketViewToDomain(mv *store.MarketView) *Market {
 		CreatorFeeWithdrawn: mv.CreatorFeeWithdrawn,
 		Title:               mv.Title,
 		CreatedAt:           mv.CreatedAt,
+		ParentID:            mv.ParentID,
+		SuccessorIDs:        uc.resolveSuccessorIDs(mv.ID),
+		ProposedOutcome:     mv.ProposedOutcome,
+		ChallengeEndsAt:     mv.ChallengeEndsAt,
+		MarketType:          mv.MarketType,
+		LiquidityParam:      mv.LiquidityParam,
+		SharesA:             mv.SharesA,
+		SharesB:             mv.SharesB,
+		HedgeEnabled:        mv.HedgeEnabled,
+		HedgeExchange:       mv.HedgeExchange,
+		HedgeSymbol:         mv.HedgeSymbol,
 	}
 }
 
@@ -340,6 +715,53 @@ func (uc *UseCases) convertPositionViewToDomain(pv *store.PositionView) *Positio
 	}
 }
 
+// MarketToView converts a domain Market back into the store's MarketView shape, for
+// callers (grpc, scheduler) that hold a *Market but need to hand it to a package like
+// notify that works with store views rather than importing core's domain types.
+// SuccessorIDs has no MarketView column (it's derived via resolveSuccessorIDs) and is
+// dropped on this side of the round trip.
+func MarketToView(m *Market) *store.MarketView {
+	return &store.MarketView{
+		ID:                  m.ID,
+		Creator:             m.Creator,
+		Mint:                m.Mint,
+		Vault:               m.Vault,
+		FeeBps:              m.FeeBps,
+		EndTs:               m.EndTs,
+		ResolveDeadlineTs:   m.ResolveDeadlineTs,
+		StakedA:             m.StakedA,
+		StakedB:             m.StakedB,
+		Status:              m.Status,
+		Outcome:             m.Outcome,
+		CreatorFeeWithdrawn: m.CreatorFeeWithdrawn,
+		Title:               m.Title,
+		CreatedAt:           m.CreatedAt,
+		ParentID:            m.ParentID,
+		ProposedOutcome:     m.ProposedOutcome,
+		ChallengeEndsAt:     m.ChallengeEndsAt,
+		MarketType:          m.MarketType,
+		LiquidityParam:      m.LiquidityParam,
+		SharesA:             m.SharesA,
+		SharesB:             m.SharesB,
+		HedgeEnabled:        m.HedgeEnabled,
+		HedgeExchange:       m.HedgeExchange,
+		HedgeSymbol:         m.HedgeSymbol,
+	}
+}
+
+// PositionToView converts a domain Position back into the store's PositionView shape,
+// for the same reason as MarketToView.
+func PositionToView(p *Position) *store.PositionView {
+	return &store.PositionView{
+		ID:       p.ID,
+		MarketID: p.MarketID,
+		Owner:    p.Owner,
+		Side:     p.Side,
+		Amount:   p.Amount,
+		Claimed:  p.Claimed,
+	}
+}
+
 func (uc *UseCases) processMarketInitialized(event *MarketEvent) error {
 	// Update market with on-chain data
 	market, err := uc.repo.GetMarket(event.MarketID)
@@ -352,10 +774,15 @@ func (uc *UseCases) processMarketInitialized(event *MarketEvent) error {
 		market.Vault = vault
 	}
 
-	return uc.repo.UpdateMarket(market)
+	if err := uc.repo.UpdateMarket(market); err != nil {
+		return err
+	}
+
+	uc.broadcast(event.MarketID, "", EventMarketInitialized, event.TxSignature, event.Seq, event.Data)
+	return nil
 }
 
-func (uc *UseCases) processBetPlaced(event *MarketEvent) error {
+func (uc *UseCases) processBetPlaced(ctx context.Context, event *MarketEvent) error {
 	// Update market stakes and position
 	market, err := uc.repo.GetMarket(event.MarketID)
 	if err != nil {
@@ -389,7 +816,38 @@ func (uc *UseCases) processBetPlaced(event *MarketEvent) error {
 		Claimed:  false,
 	}
 
-	return uc.repo.CreateOrUpdatePosition(position)
+	if err := uc.repo.CreateOrUpdatePosition(position); err != nil {
+		return err
+	}
+
+	if uc.analytics != nil {
+		if err := uc.analytics.RecordPosition(ctx, position); err != nil {
+			uc.logger.Error("failed to record rolling position", "error", err, "position_id", positionID)
+		}
+	}
+
+	if err := uc.userStats.RecordBetPlaced(ctx, owner, uint64(amount)); err != nil {
+		uc.logger.Error("failed to record user stats for bet placed", "error", err, "owner", owner)
+	}
+
+	uc.broadcast(event.MarketID, owner, EventBetPlaced, event.TxSignature, event.Seq, event.Data)
+
+	if uc.riskMon != nil {
+		decision, err := uc.riskMon.Observe(uc.convertMarketViewToDomain(market), event)
+		if err != nil {
+			uc.logger.Error("risk monitor failed to observe bet placed event", "error", err, "market_id", event.MarketID)
+		} else if decision.Trip {
+			uc.broadcast(event.MarketID, "", EventCircuitBreakerTripped, event.TxSignature, event.Seq, map[string]interface{}{"reason": decision.Reason})
+		}
+	}
+
+	if uc.hedgeMon != nil && market.HedgeEnabled {
+		if err := uc.hedgeMon.Observe(ctx, market); err != nil {
+			uc.logger.Error("hedge monitor failed to adjust position", "error", err, "market_id", event.MarketID)
+		}
+	}
+
+	return nil
 }
 
 func (uc *UseCases) processBettingClosed(event *MarketEvent) error {
@@ -399,7 +857,12 @@ func (uc *UseCases) processBettingClosed(event *MarketEvent) error {
 	}
 
 	market.Status = MarketStatusPendingResolve
-	return uc.repo.UpdateMarket(market)
+	if err := uc.repo.UpdateMarket(market); err != nil {
+		return err
+	}
+
+	uc.broadcast(event.MarketID, "", EventBettingClosed, event.TxSignature, event.Seq, event.Data)
+	return nil
 }
 
 func (uc *UseCases) processResolved(event *MarketEvent) error {
@@ -408,11 +871,20 @@ func (uc *UseCases) processResolved(event *MarketEvent) error {
 		return fmt.Errorf("market not found: %w", err)
 	}
 
+	// The resolver's outcome is only proposed at this point; it becomes final once the
+	// challenge window closes without a successful dispute (see ProcessChallengeWindows).
 	outcome, _ := event.Data["outcome"].(string)
-	market.Status = MarketStatusResolved
-	market.Outcome = &outcome
+	challengeEndsAt := time.Now().Add(time.Duration(uc.config.Resolve.ChallengeWindowSec) * time.Second)
+	market.Status = MarketStatusPendingChallenge
+	market.ProposedOutcome = &outcome
+	market.ChallengeEndsAt = &challengeEndsAt
+
+	if err := uc.repo.UpdateMarket(market); err != nil {
+		return err
+	}
 
-	return uc.repo.UpdateMarket(market)
+	uc.broadcast(event.MarketID, "", EventResolved, event.TxSignature, event.Seq, event.Data)
+	return nil
 }
 
 func (uc *UseCases) processCancelled(event *MarketEvent) error {
@@ -422,10 +894,15 @@ func (uc *UseCases) processCancelled(event *MarketEvent) error {
 	}
 
 	market.Status = MarketStatusCancelled
-	return uc.repo.UpdateMarket(market)
+	if err := uc.repo.UpdateMarket(market); err != nil {
+		return err
+	}
+
+	uc.broadcast(event.MarketID, "", EventCancelled, event.TxSignature, event.Seq, event.Data)
+	return nil
 }
 
-func (uc *UseCases) processClaimed(event *MarketEvent) error {
+func (uc *UseCases) processClaimed(ctx context.Context, event *MarketEvent) error {
 	owner, _ := event.Data["owner"].(string)
 	position, err := uc.repo.GetUserPosition(event.MarketID, owner)
 	if err != nil {
@@ -433,7 +910,22 @@ func (uc *UseCases) processClaimed(event *MarketEvent) error {
 	}
 
 	position.Claimed = true
-	return uc.repo.CreateOrUpdatePosition(position)
+	if err := uc.repo.CreateOrUpdatePosition(position); err != nil {
+		return err
+	}
+
+	if market, err := uc.repo.GetMarket(event.MarketID); err != nil {
+		uc.logger.Error("failed to load market to record user stats for claim", "error", err, "market_id", event.MarketID)
+	} else {
+		won := market.Outcome != nil && *market.Outcome == position.Side
+		netProfit := store.ComputePositionNetProfit(market, position)
+		if err := uc.userStats.RecordClaim(ctx, owner, netProfit, won); err != nil {
+			uc.logger.Error("failed to record user stats for claim", "error", err, "owner", owner)
+		}
+	}
+
+	uc.broadcast(event.MarketID, owner, EventClaimed, event.TxSignature, event.Seq, event.Data)
+	return nil
 }
 
 func (uc *UseCases) processCreatorFeeWithdrawn(event *MarketEvent) error {
@@ -443,9 +935,13 @@ func (uc *UseCases) processCreatorFeeWithdrawn(event *MarketEvent) error {
 	}
 
 	market.CreatorFeeWithdrawn = true
-	return uc.repo.UpdateMarket(market)
-}
+	if err := uc.repo.UpdateMarket(market); err != nil {
+		return err
+	}
 
+	uc.broadcast(event.MarketID, "", EventCreatorFeeWithdrawn, event.TxSignature, event.Seq, event.Data)
+	return nil
+}
 
 // Utility functions
 
@@ -455,4 +951,4 @@ func generateMarketID() string {
 
 func generatePositionID() string {
 	return fmt.Sprintf("position_%d", time.Now().UnixNano())
-}
\ No newline at end of file
+}
@@ -2,40 +2,31 @@ package grpc
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"strconv"
-	"sync"
+	"strings"
 	"time"
 
 	"connectrpc.com/connect"
 	betsv1 "github.com/friend-bets/backend/gen/proto/bets/v1"
 	"github.com/friend-bets/backend/internal/core"
 	"github.com/friend-bets/backend/internal/notify"
+	"github.com/friend-bets/backend/internal/rate"
 	"github.com/friend-bets/backend/internal/solana"
+	"github.com/friend-bets/backend/internal/store"
 )
 
 // BetsService implements the betting service
 type BetsService struct {
-	useCases     *core.UseCases
-	solanaClient *solana.AnchorClient
-	notifier     *notify.Notifier
-	logger       *slog.Logger
-
-	// Event streaming
-	eventStreams map[string]chan *betsv1.MarketEvent
-	streamsMux   sync.RWMutex
-}
-
-// MarketEvent represents a streaming market event
-type MarketEvent struct {
-	ID          string
-	MarketID    string
-	EventType   string
-	Data        string
-	Timestamp   int64
-	TxSignature string
+	useCases       *core.UseCases
+	solanaClient   *solana.AnchorClient
+	notifier       *notify.Notifier
+	rateLimiter    *rate.Limiter
+	filterRegistry *solana.FilterRegistry
+	logger         *slog.Logger
 }
 
 // NewBetsService creates a new betting service
@@ -43,14 +34,17 @@ func NewBetsService(
 	useCases *core.UseCases,
 	solanaClient *solana.AnchorClient,
 	notifier *notify.Notifier,
+	rateLimiter *rate.Limiter,
+	filterRegistry *solana.FilterRegistry,
 	logger *slog.Logger,
 ) *BetsService {
 	return &BetsService{
-		useCases:     useCases,
-		solanaClient: solanaClient,
-		notifier:     notifier,
-		logger:       logger,
-		eventStreams: make(map[string]chan *betsv1.MarketEvent),
+		useCases:       useCases,
+		solanaClient:   solanaClient,
+		notifier:       notifier,
+		rateLimiter:    rateLimiter,
+		filterRegistry: filterRegistry,
+		logger:         logger,
 	}
 }
 
@@ -109,7 +103,9 @@ func (s *BetsService) ListMarkets(
 		NextPageToken: nextPageToken,
 	}
 
-	return connect.NewResponse(response), nil
+	resp := connect.NewResponse(response)
+	setMarketStatusHeader(resp.Header(), markets...)
+	return resp, nil
 }
 
 // CreateMarket creates a new betting market record after successful on-chain transaction
@@ -117,11 +113,10 @@ func (s *BetsService) CreateMarket(
 	ctx context.Context,
 	req *connect.Request[betsv1.CreateMarketRequest],
 ) (*connect.Response[betsv1.CreateMarketResponse], error) {
-	// Extract creator from auth context (MVP: use dummy value if not authenticated)
+	// Creator is the wallet AuthInterceptor verified, never a client-asserted value.
 	creator, ok := ctx.Value("user_id").(string)
 	if !ok || creator == "" {
-		// For MVP: use a default creator when auth is disabled
-		creator = "mvp-user-" + req.Msg.Creator // Use creator from request for MVP
+		return nil, connect.NewError(connect.CodeUnauthenticated, fmt.Errorf("authentication required"))
 	}
 
 	// Create domain request
@@ -146,7 +141,7 @@ func (s *BetsService) CreateMarket(
 	// Send notification
 	if s.notifier != nil {
 		go func() {
-			if err := s.notifier.NotifyMarketCreated(context.Background(), market); err != nil {
+			if err := s.notifier.NotifyMarketCreated(context.Background(), core.MarketToView(market)); err != nil {
 				s.logger.Error("failed to send market created notification", "error", err)
 			}
 		}()
@@ -166,11 +161,10 @@ func (s *BetsService) PlaceBet(
 	ctx context.Context,
 	req *connect.Request[betsv1.PlaceBetRequest],
 ) (*connect.Response[betsv1.PlaceBetResponse], error) {
-	// Extract owner from auth context (MVP: use dummy value if not authenticated)
+	// Owner is the wallet AuthInterceptor verified, never a client-asserted value.
 	owner, ok := ctx.Value("user_id").(string)
 	if !ok || owner == "" {
-		// For MVP: use a default owner when auth is disabled
-		owner = "mvp-user-" + req.Msg.Owner // Use owner from request for MVP
+		return nil, connect.NewError(connect.CodeUnauthenticated, fmt.Errorf("authentication required"))
 	}
 
 	// Convert side
@@ -203,7 +197,7 @@ func (s *BetsService) PlaceBet(
 	// Send notification
 	if s.notifier != nil {
 		go func() {
-			if err := s.notifier.NotifyBetPlaced(context.Background(), position); err != nil {
+			if err := s.notifier.NotifyBetPlaced(context.Background(), core.PositionToView(position)); err != nil {
 				s.logger.Error("failed to send bet placed notification", "error", err)
 			}
 		}()
@@ -223,11 +217,10 @@ func (s *BetsService) Resolve(
 	ctx context.Context,
 	req *connect.Request[betsv1.ResolveRequest],
 ) (*connect.Response[betsv1.ResolveResponse], error) {
-	// Extract resolver from auth context (MVP: use dummy value if not authenticated)
+	// Resolver is the wallet AuthInterceptor verified, never a client-asserted value.
 	resolver, ok := ctx.Value("user_id").(string)
 	if !ok || resolver == "" {
-		// For MVP: use a default resolver when auth is disabled
-		resolver = "mvp-user-" + req.Msg.Resolver // Use resolver from request for MVP
+		return nil, connect.NewError(connect.CodeUnauthenticated, fmt.Errorf("authentication required"))
 	}
 
 	// Convert outcome
@@ -266,7 +259,7 @@ func (s *BetsService) Resolve(
 		go func() {
 			market, err := s.useCases.GetMarket(context.Background(), req.Msg.MarketId)
 			if err == nil {
-				if err := s.notifier.NotifyMarketResolved(context.Background(), market); err != nil {
+				if err := s.notifier.NotifyMarketResolved(context.Background(), core.MarketToView(market)); err != nil {
 					s.logger.Error("failed to send market resolved notification", "error", err)
 				}
 			}
@@ -278,7 +271,151 @@ func (s *BetsService) Resolve(
 		Signature:        txResult.Signature,
 	}
 
-	return connect.NewResponse(response), nil
+	resp := connect.NewResponse(response)
+	setComputeBudgetHeaders(resp.Header(), txResult)
+	return resp, nil
+}
+
+// CreateSuccessorMarketRequest and the other dispute/successor request and response
+// types below don't have generated betsv1 equivalents yet (gen/proto isn't checked
+// into this tree to regenerate), so they're wired as local request/response pairs the
+// same way CreateFilter/GetLogs/WatchFilteredEvents are further down this file.
+type CreateSuccessorMarketRequest struct {
+	ParentMarketID    string  `json:"parent_market_id"`
+	Title             string  `json:"title"`
+	Mint              string  `json:"mint"`
+	FeeBps            *uint32 `json:"fee_bps,omitempty"`
+	EndTs             int64   `json:"end_ts"`
+	ResolveDeadlineTs int64   `json:"resolve_deadline_ts"`
+	SeedSide          string  `json:"seed_side,omitempty"`
+}
+
+type CreateSuccessorMarketResponse struct {
+	Market *betsv1.Market `json:"market"`
+}
+
+// CreateSuccessorMarket chains a new market off a resolved or cancelled one, optionally
+// seeding it with the parent's losing-side pool.
+func (s *BetsService) CreateSuccessorMarket(
+	ctx context.Context,
+	req *connect.Request[CreateSuccessorMarketRequest],
+) (*connect.Response[CreateSuccessorMarketResponse], error) {
+	// Creator is the wallet AuthInterceptor verified, never a client-asserted value.
+	creator, ok := ctx.Value("user_id").(string)
+	if !ok || creator == "" {
+		return nil, connect.NewError(connect.CodeUnauthenticated, fmt.Errorf("authentication required"))
+	}
+
+	var feeBps *uint16
+	if req.Msg.FeeBps != nil {
+		v := uint16(*req.Msg.FeeBps)
+		feeBps = &v
+	}
+
+	successorReq := &core.CreateSuccessorMarketRequest{
+		Creator:           creator,
+		Title:             req.Msg.Title,
+		Mint:              req.Msg.Mint,
+		FeeBps:            feeBps,
+		EndTs:             time.Unix(req.Msg.EndTs, 0),
+		ResolveDeadlineTs: time.Unix(req.Msg.ResolveDeadlineTs, 0),
+		SeedSide:          req.Msg.SeedSide,
+	}
+
+	market, err := s.useCases.CreateSuccessorMarket(ctx, req.Msg.ParentMarketID, successorReq)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("failed to create successor market: %w", err))
+	}
+
+	s.logger.Info("successor market created", "market_id", market.ID, "parent_id", req.Msg.ParentMarketID, "creator", creator)
+
+	return connect.NewResponse(&CreateSuccessorMarketResponse{Market: s.convertMarketToProto(market)}), nil
+}
+
+type DisputeResolutionRequest struct {
+	MarketID        string `json:"market_id"`
+	ProposedOutcome string `json:"proposed_outcome"`
+	Stake           uint64 `json:"stake"`
+}
+
+type DisputeResolutionResponse struct{}
+
+// DisputeResolution escrows a stake-weighted challenge against a market's proposed
+// outcome during its challenge window; see core.UseCases.DisputeResolution.
+func (s *BetsService) DisputeResolution(
+	ctx context.Context,
+	req *connect.Request[DisputeResolutionRequest],
+) (*connect.Response[DisputeResolutionResponse], error) {
+	// Disputer is the wallet AuthInterceptor verified, never a client-asserted value.
+	disputer, ok := ctx.Value("user_id").(string)
+	if !ok || disputer == "" {
+		return nil, connect.NewError(connect.CodeUnauthenticated, fmt.Errorf("authentication required"))
+	}
+
+	var proposedOutcome string
+	switch req.Msg.ProposedOutcome {
+	case core.BetSideA, core.BetSideB:
+		proposedOutcome = req.Msg.ProposedOutcome
+	default:
+		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("invalid proposed outcome"))
+	}
+
+	disputeReq := &core.DisputeResolutionRequest{
+		MarketID:        req.Msg.MarketID,
+		Disputer:        disputer,
+		ProposedOutcome: proposedOutcome,
+		Stake:           req.Msg.Stake,
+	}
+
+	if err := s.useCases.DisputeResolution(ctx, disputeReq); err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("failed to dispute resolution: %w", err))
+	}
+
+	s.logger.Info("dispute submitted", "market_id", req.Msg.MarketID, "disputer", disputer, "stake", req.Msg.Stake)
+
+	return connect.NewResponse(&DisputeResolutionResponse{}), nil
+}
+
+type CastDisputeVoteRequest struct {
+	MarketID string `json:"market_id"`
+	Outcome  string `json:"outcome"`
+}
+
+type CastDisputeVoteResponse struct{}
+
+// CastDisputeVote records the caller's stake-weighted vote on how a market stuck in
+// MarketStatusDisputed should be finalized; see core.UseCases.CastDisputeVote.
+func (s *BetsService) CastDisputeVote(
+	ctx context.Context,
+	req *connect.Request[CastDisputeVoteRequest],
+) (*connect.Response[CastDisputeVoteResponse], error) {
+	// Voter is the wallet AuthInterceptor verified, never a client-asserted value.
+	voter, ok := ctx.Value("user_id").(string)
+	if !ok || voter == "" {
+		return nil, connect.NewError(connect.CodeUnauthenticated, fmt.Errorf("authentication required"))
+	}
+
+	var outcome string
+	switch req.Msg.Outcome {
+	case core.BetSideA, core.BetSideB:
+		outcome = req.Msg.Outcome
+	default:
+		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("invalid outcome"))
+	}
+
+	voteReq := &core.CastDisputeVoteRequest{
+		MarketID: req.Msg.MarketID,
+		Voter:    voter,
+		Outcome:  outcome,
+	}
+
+	if err := s.useCases.CastDisputeVote(ctx, voteReq); err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("failed to cast dispute vote: %w", err))
+	}
+
+	s.logger.Info("dispute vote cast", "market_id", req.Msg.MarketID, "voter", voter, "outcome", outcome)
+
+	return connect.NewResponse(&CastDisputeVoteResponse{}), nil
 }
 
 // Claim claims winnings from a resolved market
@@ -286,11 +423,10 @@ func (s *BetsService) Claim(
 	ctx context.Context,
 	req *connect.Request[betsv1.ClaimRequest],
 ) (*connect.Response[betsv1.ClaimResponse], error) {
-	// Extract owner from auth context (MVP: use dummy value if not authenticated)
+	// Owner is the wallet AuthInterceptor verified, never a client-asserted value.
 	owner, ok := ctx.Value("user_id").(string)
 	if !ok || owner == "" {
-		// For MVP: use a default owner when auth is disabled
-		owner = "mvp-user-" + req.Msg.Owner // Use owner from request for MVP
+		return nil, connect.NewError(connect.CodeUnauthenticated, fmt.Errorf("authentication required"))
 	}
 
 	// Create domain request
@@ -340,7 +476,47 @@ func (s *BetsService) Claim(
 		Signature:        txResult.Signature,
 	}
 
-	return connect.NewResponse(response), nil
+	resp := connect.NewResponse(response)
+	setComputeBudgetHeaders(resp.Header(), txResult)
+	return resp, nil
+}
+
+// setComputeBudgetHeaders surfaces the compute unit limit and priority-fee
+// price buildTransaction attached to txResult, so a client can display
+// expected priority-fee cost before signing. These aren't ResolveResponse/
+// ClaimResponse fields because the buf-generated client (gen/proto) isn't
+// checked into this tree to extend; headers are a wire-compatible stand-in
+// until it's regenerated (see WatchEvents for the same pattern).
+func setComputeBudgetHeaders(header http.Header, txResult *solana.TransactionResult) {
+	header.Set("X-Compute-Units", strconv.FormatUint(uint64(txResult.ComputeUnits), 10))
+	header.Set("X-Priority-Price-Microlamports", strconv.FormatUint(txResult.PriorityPriceMicroLamports, 10))
+}
+
+// setMarketStatusHeader surfaces the exact domain MarketStatus (not just the
+// PENDING_RESOLVE bucket convertMarketToProto folds pending_challenge/disputed
+// into) as "id:status" pairs, so a staker deciding whether to call
+// CastDisputeVote can tell a disputed market apart from an ordinary pending
+// one. Same gen/proto-isn't-checked-in stand-in as setComputeBudgetHeaders.
+func setMarketStatusHeader(header http.Header, markets ...*core.Market) {
+	pairs := make([]string, len(markets))
+	for i, market := range markets {
+		pairs[i] = market.ID + ":" + market.Status
+	}
+	header.Set("X-Market-Status", strings.Join(pairs, ","))
+}
+
+// setMarketGraphHeader surfaces a market's successor-chain graph the same way
+// setMarketStatusHeader surfaces its exact status: betsv1.Market has no
+// ParentId/SuccessorIds fields yet (gen/proto isn't checked into this tree to
+// extend), so CreateSuccessorMarket's chain rides along as headers on
+// GetMarket until it is.
+func setMarketGraphHeader(header http.Header, market *core.Market) {
+	if market.ParentID != nil {
+		header.Set("X-Market-Parent-Id", *market.ParentID)
+	}
+	if len(market.SuccessorIDs) > 0 {
+		header.Set("X-Market-Successor-Ids", strings.Join(market.SuccessorIDs, ","))
+	}
 }
 
 // GetMarket gets a single market by ID
@@ -362,7 +538,10 @@ func (s *BetsService) GetMarket(
 		Market: s.convertMarketToProto(market),
 	}
 
-	return connect.NewResponse(response), nil
+	resp := connect.NewResponse(response)
+	setMarketStatusHeader(resp.Header(), market)
+	setMarketGraphHeader(resp.Header(), market)
+	return resp, nil
 }
 
 // GetPosition gets a user's position in a specific market
@@ -377,9 +556,14 @@ func (s *BetsService) GetPosition(
 		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("owner is required"))
 	}
 
+	// AuthInterceptor already required a verified wallet identity to reach this
+	// handler; the queried owner is still whatever the caller asked for.
+	if _, ok := ctx.Value("user_id").(string); !ok {
+		return nil, connect.NewError(connect.CodeUnauthenticated, fmt.Errorf("authentication required"))
+	}
+
 	// Get position from use cases
-	owner := "mvp-user-" + req.Msg.Owner // Use owner from request for MVP
-	position, err := s.useCases.GetPosition(ctx, req.Msg.MarketId, owner)
+	position, err := s.useCases.GetPosition(ctx, req.Msg.MarketId, req.Msg.Owner)
 	if err != nil {
 		return nil, connect.NewError(connect.CodeNotFound, fmt.Errorf("position not found: %w", err))
 	}
@@ -400,6 +584,12 @@ func (s *BetsService) GetUserPositions(
 		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("owner is required"))
 	}
 
+	// AuthInterceptor already required a verified wallet identity to reach this
+	// handler; the queried owner is still whatever the caller asked for.
+	if _, ok := ctx.Value("user_id").(string); !ok {
+		return nil, connect.NewError(connect.CodeUnauthenticated, fmt.Errorf("authentication required"))
+	}
+
 	// Parse pagination
 	limit := int(req.Msg.PageSize)
 	if limit <= 0 || limit > 100 {
@@ -415,8 +605,7 @@ func (s *BetsService) GetUserPositions(
 	}
 
 	// Get positions from use cases
-	owner := "mvp-user-" + req.Msg.Owner // Use owner from request for MVP
-	positions, err := s.useCases.GetUserPositionsWithPagination(ctx, owner, limit, offset)
+	positions, err := s.useCases.GetUserPositionsWithPagination(ctx, req.Msg.Owner, limit, offset)
 	if err != nil {
 		return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to get user positions: %w", err))
 	}
@@ -441,96 +630,476 @@ func (s *BetsService) GetUserPositions(
 	return connect.NewResponse(response), nil
 }
 
-// WatchEvents streams market events
+// watchEventsHeartbeatInterval is how often WatchEvents sends a heartbeat event on
+// an otherwise idle stream, so intermediate proxies don't time it out.
+const watchEventsHeartbeatInterval = 15 * time.Second
+
+// watchEventsReplayBatchSize bounds how many persisted EventLog rows WatchEvents
+// fetches per catch-up query when resuming from a cursor.
+const watchEventsReplayBatchSize = 500
+
+// watchEventsHeartbeatType marks a synthetic MarketEvent with no on-chain meaning,
+// sent purely to keep an idle stream alive.
+const watchEventsHeartbeatType = "Heartbeat"
+
+// watchEventsLaggingType marks a synthetic MarketEvent WatchEvents sends right before
+// it replays a gap caused by hub backpressure (see notify.LaggingEventType), so the
+// client knows a discontinuity is about to be backfilled rather than silently missed.
+const watchEventsLaggingType = "Lagging"
+
+// WatchEvents streams market events reconciled from the on-chain indexer (see
+// internal/solana.EventIndexer), including the TxSignature of the transaction that
+// produced each one. It subscribes to the same notify hub as SubscribeMarketEvents with
+// an empty filter (UseCases.ProcessMarketEvent broadcasts every on-chain event it
+// handles), then filters client-side by MarketIds since the hub only filters on a
+// single market ID per subscriber.
+//
+// A client that was disconnected can resume where it left off by sending the
+// "X-Since-Cursor" header set to the Id of the last MarketEvent it received (events
+// persisted to EventLog carry their row ID as Id); rows with a higher ID are replayed
+// from storage before the stream switches to live delivery. "X-Include-Historical:
+// true" with no cursor replays the full retained history from the start. Live events
+// that arrive while the catch-up query is still running sit harmlessly in the hub
+// subscription's own buffered channel (see notify.Hub.Subscribe) until the main loop
+// below gets to them, and are de-duplicated against the replay by seq.
+//
+// If this stream's subscriber falls behind (the hub couldn't enqueue a notification
+// within its backpressure timeout), the hub sends a notify.LaggingEventType sentinel
+// instead of dropping events silently; WatchEvents relays that as a watchEventsLaggingType
+// MarketEvent, replays the gap from EventLog the same way it does on resume, then clears
+// the hub's lagging flag and resumes live delivery.
+//
+// since_cursor/include_historical aren't WatchEventsRequest fields because the
+// buf-generated client (gen/proto) isn't checked into this tree to extend; headers
+// are a wire-compatible stand-in until it's regenerated.
 func (s *BetsService) WatchEvents(
 	ctx context.Context,
 	req *connect.Request[betsv1.WatchEventsRequest],
 	stream *connect.ServerStream[betsv1.WatchEventsResponse],
 ) error {
-	// Generate stream ID
-	streamID := fmt.Sprintf("stream_%d", time.Now().UnixNano())
+	var cursor uint64
+	if v := req.Header().Get("X-Since-Cursor"); v != "" {
+		if parsed, err := strconv.ParseUint(v, 10, 64); err == nil {
+			cursor = parsed
+		}
+	}
+	includeHistorical := cursor > 0 || req.Header().Get("X-Include-Historical") == "true"
 
-	// Create event channel for this stream
-	eventChan := make(chan *betsv1.MarketEvent, 100)
+	marketIDs := req.Msg.MarketIds
+	matchesFilter := func(marketID string) bool {
+		if len(marketIDs) == 0 {
+			return true
+		}
+		for _, id := range marketIDs {
+			if id == marketID {
+				return true
+			}
+		}
+		return false
+	}
+
+	subID, eventCh := s.notifier.Subscribe("", "", "", 100)
+	defer s.notifier.Unsubscribe(subID)
 
-	// Register stream
-	s.streamsMux.Lock()
-	s.eventStreams[streamID] = eventChan
-	s.streamsMux.Unlock()
+	s.logger.Info("event stream started", "subscriber_id", subID, "market_count", len(marketIDs), "since_cursor", cursor)
 
-	// Cleanup on exit
-	defer func() {
-		s.streamsMux.Lock()
-		delete(s.eventStreams, streamID)
-		close(eventChan)
-		s.streamsMux.Unlock()
-	}()
+	lastSeq := cursor
+	if includeHistorical && s.filterRegistry != nil {
+		replayed, err := s.replayEventsSince(ctx, stream, cursor, matchesFilter)
+		if err != nil {
+			s.logger.Error("failed to replay historical events", "error", err, "subscriber_id", subID)
+		} else if replayed > lastSeq {
+			lastSeq = replayed
+		}
+	}
 
-	s.logger.Info("event stream started", "stream_id", streamID, "market_count", len(req.Msg.MarketIds))
+	heartbeat := time.NewTicker(watchEventsHeartbeatInterval)
+	defer heartbeat.Stop()
 
-	// Stream events
 	for {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
-		case event, ok := <-eventChan:
+		case <-heartbeat.C:
+			hb := &betsv1.WatchEventsResponse{
+				Event: &betsv1.MarketEvent{
+					Id:        watchEventsHeartbeatType,
+					EventType: watchEventsHeartbeatType,
+					Timestamp: time.Now().Unix(),
+				},
+			}
+			if err := stream.Send(hb); err != nil {
+				s.logger.Error("failed to send heartbeat", "error", err, "subscriber_id", subID)
+				return err
+			}
+		case n, ok := <-eventCh:
 			if !ok {
 				return nil
 			}
 
-			// Filter by requested market IDs
-			if len(req.Msg.MarketIds) > 0 {
-				found := false
-				for _, marketID := range req.Msg.MarketIds {
-					if marketID == event.MarketId {
-						found = true
-						break
-					}
+			if n.EventType == notify.LaggingEventType {
+				lag := &betsv1.WatchEventsResponse{
+					Event: &betsv1.MarketEvent{
+						Id:        watchEventsLaggingType,
+						EventType: watchEventsLaggingType,
+						Timestamp: time.Now().Unix(),
+					},
 				}
-				if !found {
-					continue
+				if err := stream.Send(lag); err != nil {
+					s.logger.Error("failed to send lagging notice", "error", err, "subscriber_id", subID)
+					return err
 				}
+
+				replayed, err := s.replayEventsSince(ctx, stream, lastSeq, matchesFilter)
+				if err != nil {
+					s.logger.Error("failed to replay after lagging", "error", err, "subscriber_id", subID)
+				} else if replayed > lastSeq {
+					lastSeq = replayed
+				}
+				s.notifier.ClearLagging(subID)
+				continue
+			}
+
+			// Already delivered during the catch-up replay above.
+			if n.Seq != 0 && n.Seq <= lastSeq {
+				continue
+			}
+
+			if !matchesFilter(n.MarketID) {
+				continue
+			}
+
+			dataJSON, err := json.Marshal(n.Data)
+			if err != nil {
+				s.logger.Error("failed to marshal event data", "error", err, "subscriber_id", subID)
+				continue
 			}
 
-			// Send event to client
 			response := &betsv1.WatchEventsResponse{
 				Event: &betsv1.MarketEvent{
-					Id:          event.Id,
-					MarketId:    event.MarketId,
-					EventType:   event.EventType,
-					Data:        event.Data,
-					Timestamp:   event.Timestamp,
-					TxSignature: event.TxSignature,
+					Id:          watchEventsCursorID(n.Seq, n.EventType, n.Timestamp),
+					MarketId:    n.MarketID,
+					EventType:   n.EventType,
+					Data:        string(dataJSON),
+					Timestamp:   n.Timestamp.Unix(),
+					TxSignature: n.TxSignature,
 				},
 			}
 
 			if err := stream.Send(response); err != nil {
-				s.logger.Error("failed to send event to stream", "error", err, "stream_id", streamID)
+				s.logger.Error("failed to send event to stream", "error", err, "subscriber_id", subID)
+				return err
+			}
+
+			if n.Seq > lastSeq {
+				lastSeq = n.Seq
+			}
+		}
+	}
+}
+
+// watchEventsCursorID is the MarketEvent.Id WatchEvents sends for an event: the
+// EventLog sequence number when the event was persisted (so clients can pass it back
+// as X-Since-Cursor to resume), falling back to the old type-and-timestamp form for
+// events with no EventLog row (e.g. a synthetic reorg notice).
+func watchEventsCursorID(seq uint64, eventType string, ts time.Time) string {
+	if seq != 0 {
+		return strconv.FormatUint(seq, 10)
+	}
+	return fmt.Sprintf("%s-%d", eventType, ts.UnixNano())
+}
+
+// replayEventsSince sends every persisted EventLog row with ID greater than cursor,
+// matching keep, to stream, paging through watchEventsReplayBatchSize rows at a time.
+// It returns the highest row ID seen (whether or not it matched keep, so resuming
+// from it never re-replays a row WatchEvents already looked at).
+func (s *BetsService) replayEventsSince(ctx context.Context, stream *connect.ServerStream[betsv1.WatchEventsResponse], cursor uint64, keep func(marketID string) bool) (uint64, error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			return cursor, err
+		}
+
+		events, err := s.filterRegistry.GetEventsSince(cursor, watchEventsReplayBatchSize)
+		if err != nil {
+			return cursor, fmt.Errorf("failed to query events since cursor %d: %w", cursor, err)
+		}
+		if len(events) == 0 {
+			return cursor, nil
+		}
+
+		for _, ev := range events {
+			marketID := ""
+			if ev.MarketID != nil {
+				marketID = *ev.MarketID
+			}
+			cursor = uint64(ev.ID)
+
+			if !keep(marketID) {
+				continue
+			}
+
+			response := &betsv1.WatchEventsResponse{
+				Event: &betsv1.MarketEvent{
+					Id:          strconv.FormatUint(uint64(ev.ID), 10),
+					MarketId:    marketID,
+					EventType:   ev.EventType,
+					Data:        ev.Data,
+					Timestamp:   ev.BlockTime.Unix(),
+					TxSignature: ev.TxSignature,
+				},
+			}
+			if err := stream.Send(response); err != nil {
+				return cursor, err
+			}
+		}
+
+		if len(events) < watchEventsReplayBatchSize {
+			return cursor, nil
+		}
+	}
+}
+
+// SubscribeMarketEvents streams real-time notifications from the notify hub,
+// filtered by market ID, user, and/or event type. Unlike WatchEvents (which only
+// forwards on-chain indexer events), this also surfaces notifications published by
+// UseCases.process* handlers as they happen.
+func (s *BetsService) SubscribeMarketEvents(
+	ctx context.Context,
+	req *connect.Request[SubscribeMarketEventsRequest],
+	stream *connect.ServerStream[SubscribeMarketEventsResponse],
+) error {
+	clientKey := req.Msg.UserID
+	if clientKey == "" {
+		clientKey = "anonymous"
+	}
+
+	if s.rateLimiter != nil && !s.rateLimiter.Allow(ctx, clientKey, "subscribe_connect", time.Minute, 5) {
+		return connect.NewError(connect.CodeResourceExhausted, fmt.Errorf("too many subscription connections"))
+	}
+
+	subID, eventCh := s.notifier.Subscribe(req.Msg.MarketID, req.Msg.UserID, req.Msg.EventType, 100)
+	defer s.notifier.Unsubscribe(subID)
+
+	s.logger.Info("market event subscription started", "subscriber_id", subID, "market_id", req.Msg.MarketID, "user_id", req.Msg.UserID, "event_type", req.Msg.EventType)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case n, ok := <-eventCh:
+			if !ok {
+				return nil
+			}
+
+			if s.rateLimiter != nil && !s.rateLimiter.Allow(ctx, clientKey, "subscribe_message", time.Minute, 120) {
+				s.logger.Warn("dropping subscription message, rate limit exceeded", "subscriber_id", subID)
+				continue
+			}
+
+			if err := stream.Send(&SubscribeMarketEventsResponse{
+				MarketID:  n.MarketID,
+				UserID:    n.UserID,
+				EventType: n.EventType,
+				Timestamp: n.Timestamp.Unix(),
+			}); err != nil {
+				s.logger.Error("failed to send subscription event", "error", err, "subscriber_id", subID)
 				return err
 			}
 		}
 	}
 }
 
-// BroadcastEvent broadcasts an event to all active streams
-func (s *BetsService) BroadcastEvent(event *MarketEvent) {
-	s.streamsMux.RLock()
-	defer s.streamsMux.RUnlock()
+// SubscribeMarketEventsRequest filters a SubscribeMarketEvents stream; an empty
+// field matches any value for that field
+type SubscribeMarketEventsRequest struct {
+	MarketID  string `json:"market_id"`
+	UserID    string `json:"user_id"`
+	EventType string `json:"event_type"`
+}
+
+// SubscribeMarketEventsResponse is one notification delivered over the stream
+type SubscribeMarketEventsResponse struct {
+	MarketID  string `json:"market_id"`
+	UserID    string `json:"user_id"`
+	EventType string `json:"event_type"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// Log filter API (eth_getLogs-style), backed by internal/solana.FilterRegistry.
+//
+// CreateFilter registers a FilterSpec and returns a handle; GetFilterChanges polls it
+// for events matched since the last poll (or since creation); GetLogs runs a one-shot
+// historical query with no filter/cursor involved; WatchFilteredEvents streams matches
+// live. Live delivery only sees events ingested by the EventIndexer instance sharing
+// this server's FilterRegistry — see FilterRegistry's doc comment.
+
+// FilterSpecRequest is the wire form of solana.FilterSpec; FromTimeUnix/ToTimeUnix of
+// 0 mean unbounded, same as the zero time.Time they're parsed into.
+type FilterSpecRequest struct {
+	EventTypes  []string `json:"event_types"`
+	MarketIDs   []string `json:"market_ids"`
+	Creator     string   `json:"creator"`
+	FromSlot    uint64   `json:"from_slot"`
+	ToSlot      uint64   `json:"to_slot"`
+	FromTimeSec int64    `json:"from_time_sec"`
+	ToTimeSec   int64    `json:"to_time_sec"`
+}
+
+func (r *FilterSpecRequest) toSpec() solana.FilterSpec {
+	spec := solana.FilterSpec{
+		EventTypes: r.EventTypes,
+		MarketIDs:  r.MarketIDs,
+		Creator:    r.Creator,
+		FromSlot:   r.FromSlot,
+		ToSlot:     r.ToSlot,
+	}
+	if r.FromTimeSec > 0 {
+		spec.FromTime = time.Unix(r.FromTimeSec, 0)
+	}
+	if r.ToTimeSec > 0 {
+		spec.ToTime = time.Unix(r.ToTimeSec, 0)
+	}
+	return spec
+}
 
-	for streamID, eventChan := range s.eventStreams {
+// EventLogEntry is the wire form of one store.EventLog row.
+type EventLogEntry struct {
+	ID            uint   `json:"id"`
+	TxSignature   string `json:"tx_signature"`
+	EventType     string `json:"event_type"`
+	MarketID      string `json:"market_id,omitempty"`
+	Data          string `json:"data"`
+	Slot          uint64 `json:"slot"`
+	BlockTimeUnix int64  `json:"block_time_unix"`
+}
+
+func toEventLogEntry(e *store.EventLog) *EventLogEntry {
+	entry := &EventLogEntry{
+		ID:            e.ID,
+		TxSignature:   e.TxSignature,
+		EventType:     e.EventType,
+		Data:          e.Data,
+		Slot:          e.Slot,
+		BlockTimeUnix: e.BlockTime.Unix(),
+	}
+	if e.MarketID != nil {
+		entry.MarketID = *e.MarketID
+	}
+	return entry
+}
+
+type CreateFilterRequest struct {
+	Filter FilterSpecRequest `json:"filter"`
+}
+
+type CreateFilterResponse struct {
+	FilterID string `json:"filter_id"`
+}
+
+// CreateFilter registers a new event filter and returns its ID for GetFilterChanges
+// or WatchFilteredEvents.
+func (s *BetsService) CreateFilter(
+	ctx context.Context,
+	req *connect.Request[CreateFilterRequest],
+) (*connect.Response[CreateFilterResponse], error) {
+	if s.filterRegistry == nil {
+		return nil, connect.NewError(connect.CodeUnavailable, fmt.Errorf("filter API is not enabled"))
+	}
+	filterID := s.filterRegistry.CreateFilter(req.Msg.Filter.toSpec())
+	return connect.NewResponse(&CreateFilterResponse{FilterID: filterID}), nil
+}
+
+type GetFilterChangesRequest struct {
+	FilterID string `json:"filter_id"`
+}
+
+type GetFilterChangesResponse struct {
+	Events []*EventLogEntry `json:"events"`
+}
+
+// GetFilterChanges returns events matched by a registered filter since the last poll.
+func (s *BetsService) GetFilterChanges(
+	ctx context.Context,
+	req *connect.Request[GetFilterChangesRequest],
+) (*connect.Response[GetFilterChangesResponse], error) {
+	if s.filterRegistry == nil {
+		return nil, connect.NewError(connect.CodeUnavailable, fmt.Errorf("filter API is not enabled"))
+	}
+	events, err := s.filterRegistry.GetFilterChanges(req.Msg.FilterID)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeNotFound, err)
+	}
+	resp := &GetFilterChangesResponse{Events: make([]*EventLogEntry, len(events))}
+	for i := range events {
+		resp.Events[i] = toEventLogEntry(&events[i])
+	}
+	return connect.NewResponse(resp), nil
+}
+
+type GetLogsRequest struct {
+	Filter FilterSpecRequest `json:"filter"`
+}
+
+type GetLogsResponse struct {
+	Events []*EventLogEntry `json:"events"`
+}
+
+// GetLogs runs a one-shot historical query against persisted EventLog rows, with no
+// filter registration or cursor involved.
+func (s *BetsService) GetLogs(
+	ctx context.Context,
+	req *connect.Request[GetLogsRequest],
+) (*connect.Response[GetLogsResponse], error) {
+	if s.filterRegistry == nil {
+		return nil, connect.NewError(connect.CodeUnavailable, fmt.Errorf("filter API is not enabled"))
+	}
+	events, err := s.filterRegistry.GetLogs(req.Msg.Filter.toSpec())
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	resp := &GetLogsResponse{Events: make([]*EventLogEntry, len(events))}
+	for i := range events {
+		resp.Events[i] = toEventLogEntry(&events[i])
+	}
+	return connect.NewResponse(resp), nil
+}
+
+type WatchFilteredEventsRequest struct {
+	FilterID string `json:"filter_id"`
+}
+
+type WatchFilteredEventsResponse struct {
+	Event *EventLogEntry `json:"event"`
+}
+
+// WatchFilteredEvents streams events matched by a registered filter as they're
+// ingested, in addition to whatever GetFilterChanges polling already returns. See
+// FilterRegistry's doc comment for the live-delivery process-locality caveat.
+func (s *BetsService) WatchFilteredEvents(
+	ctx context.Context,
+	req *connect.Request[WatchFilteredEventsRequest],
+	stream *connect.ServerStream[WatchFilteredEventsResponse],
+) error {
+	if s.filterRegistry == nil {
+		return connect.NewError(connect.CodeUnavailable, fmt.Errorf("filter API is not enabled"))
+	}
+
+	eventCh, ok := s.filterRegistry.Watch(req.Msg.FilterID)
+	if !ok {
+		return connect.NewError(connect.CodeNotFound, fmt.Errorf("unknown filter %q", req.Msg.FilterID))
+	}
+
+	for {
 		select {
-		case eventChan <- &betsv1.MarketEvent{
-			Id:          event.ID,
-			MarketId:    event.MarketID,
-			EventType:   event.EventType,
-			Data:        event.Data,
-			Timestamp:   event.Timestamp,
-			TxSignature: event.TxSignature,
-		}:
-			// Event sent successfully
-		default:
-			// Channel is full, skip this stream
-			s.logger.Warn("event channel full, dropping event", "stream_id", streamID)
+		case <-ctx.Done():
+			return ctx.Err()
+		case log, ok := <-eventCh:
+			if !ok {
+				return nil
+			}
+			if err := stream.Send(&WatchFilteredEventsResponse{Event: toEventLogEntry(&log)}); err != nil {
+				return err
+			}
 		}
 	}
 }
@@ -554,11 +1123,17 @@ func (s *BetsService) convertMarketToProto(market *core.Market) *betsv1.Market {
 		CreatedAt:           market.CreatedAt.Unix(),
 	}
 
-	// Convert status
+	// Convert status. MarketStatusPendingChallenge/Disputed/Halted don't have
+	// a dedicated proto value yet (gen/proto isn't checked into this tree to
+	// extend), so they fold into PENDING_RESOLVE, the closest "not open, not
+	// yet terminal" bucket; setMarketStatusHeader on GetMarket/ListMarkets
+	// carries the exact domain status for callers (e.g. CastDisputeVote, or
+	// anything needing to know a market was halted by the circuit breaker)
+	// that need to tell them apart.
 	switch market.Status {
 	case core.MarketStatusOpen:
 		pbMarket.Status = betsv1.MarketStatus_MARKET_STATUS_OPEN
-	case core.MarketStatusPendingResolve:
+	case core.MarketStatusPendingResolve, core.MarketStatusPendingChallenge, core.MarketStatusDisputed, core.MarketStatusHalted:
 		pbMarket.Status = betsv1.MarketStatus_MARKET_STATUS_PENDING_RESOLVE
 	case core.MarketStatusResolved:
 		pbMarket.Status = betsv1.MarketStatus_MARKET_STATUS_RESOLVED
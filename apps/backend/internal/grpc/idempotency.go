@@ -0,0 +1,168 @@
+package grpc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"connectrpc.com/connect"
+	betsv1 "github.com/friend-bets/backend/gen/proto/bets/v1"
+	"github.com/friend-bets/backend/internal/store"
+	"google.golang.org/protobuf/proto"
+	"gorm.io/gorm"
+)
+
+// idempotentMethods maps the mutating RPCs that create a DB row tied to an on-chain
+// tx to a constructor for their response message, so IdempotencyInterceptor can
+// deserialize a cached response without a type switch per method.
+var idempotentMethods = map[string]func() proto.Message{
+	"/bets.v1.BetsService/CreateMarket": func() proto.Message { return &betsv1.CreateMarketResponse{} },
+	"/bets.v1.BetsService/PlaceBet":     func() proto.Message { return &betsv1.PlaceBetResponse{} },
+	"/bets.v1.BetsService/Resolve":      func() proto.Message { return &betsv1.ResolveResponse{} },
+	"/bets.v1.BetsService/Claim":        func() proto.Message { return &betsv1.ClaimResponse{} },
+}
+
+// IdempotencyInterceptor makes CreateMarket/PlaceBet/Resolve/Claim safe to retry: a
+// client resubmission (network blip, double-click) carrying the same Idempotency-Key
+// header as an earlier successful call on the same identity gets back the exact
+// original response instead of re-running the use case and creating a duplicate
+// position/market. A key reused with a different request body is rejected with
+// CodeAlreadyExists rather than silently replayed. Idempotency-Key is a header rather
+// than a request field because the buf-generated client (gen/proto) isn't checked into
+// this tree to extend with a new field.
+//
+// Only successful calls are cached: replaying a stored error risks masking a
+// transient failure (e.g. a dropped DB connection) that would succeed on retry, so an
+// error response is left to the client's normal retry path instead.
+//
+// This must run after AuthInterceptor in the chain, since it keys records on the
+// verified wallet identity AuthInterceptor sets on ctx.
+//
+// Cached responses are persisted to request_idempotency (Postgres, via repo)
+// rather than Redis: this deployment has no Redis instance wired up anywhere
+// by default (see rate.RedisDistributedLimiter/store.RedisBackend, both
+// opt-in and unused), and a DB row with a unique (user_id, method, key) index
+// plus the scheduler's cleanupIdempotencyRecords job already gives durable,
+// TTL-bounded caching without adding an operational dependency. What a
+// DB-only design doesn't give you for free is protection against two
+// concurrent requests racing in before either's response is persisted;
+// inflight closes that gap by making the first caller's in-progress call
+// shared rather than duplicated.
+type IdempotencyInterceptor struct {
+	repo     *store.Repository
+	logger   *slog.Logger
+	inflight *singleflightGroup
+}
+
+// NewIdempotencyInterceptor creates a new idempotency-key interceptor backed by repo.
+func NewIdempotencyInterceptor(repo *store.Repository, logger *slog.Logger) connect.UnaryInterceptorFunc {
+	interceptor := &IdempotencyInterceptor{repo: repo, logger: logger, inflight: newSingleflightGroup()}
+	return interceptor.Intercept
+}
+
+// Intercept implements the idempotency-key interceptor.
+func (i *IdempotencyInterceptor) Intercept(next connect.UnaryFunc) connect.UnaryFunc {
+	return connect.UnaryFunc(func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		method := req.Spec().Procedure
+		newResponse, ok := idempotentMethods[method]
+		if !ok {
+			return next(ctx, req)
+		}
+
+		var key string
+		if req.Header() != nil {
+			key = req.Header().Get("Idempotency-Key")
+		}
+		if key == "" {
+			return next(ctx, req)
+		}
+
+		userID, _ := ctx.Value("user_id").(string)
+		if userID == "" {
+			return next(ctx, req)
+		}
+
+		requestMsg, ok := req.Any().(proto.Message)
+		if !ok {
+			return next(ctx, req)
+		}
+		body, err := proto.Marshal(requestMsg)
+		if err != nil {
+			return next(ctx, req)
+		}
+		hashed := sha256.Sum256(body)
+		requestHash := hex.EncodeToString(hashed[:])
+
+		// Collapse concurrent callers sharing (userID, method, key) into one
+		// execution, so a retry that races in before the first call's response is
+		// persisted waits for it instead of re-running the use case.
+		inflightKey := userID + "|" + method + "|" + key
+		result, _ := i.inflight.Do(inflightKey, func() (interface{}, error) {
+			resp, err := i.runIdempotent(ctx, req, next, newResponse, userID, method, key, requestHash)
+			return idempotencyResult{resp: resp, err: err}, nil
+		})
+		r := result.(idempotencyResult)
+		return r.resp, r.err
+	})
+}
+
+// idempotencyResult bundles a connect.UnaryFunc's two return values so they
+// can travel through singleflightGroup.Do's single interface{} result.
+type idempotencyResult struct {
+	resp connect.AnyResponse
+	err  error
+}
+
+// runIdempotent does the actual lookup-or-run-and-cache work for one
+// (userID, method, key); see Intercept's singleflight wrapping for why
+// concurrent duplicates never enter this function at the same time.
+func (i *IdempotencyInterceptor) runIdempotent(ctx context.Context, req connect.AnyRequest, next connect.UnaryFunc, newResponse func() proto.Message, userID, method, key, requestHash string) (connect.AnyResponse, error) {
+	rec, err := i.repo.GetIdempotencyRecord(userID, method, key)
+	if err == nil {
+		if rec.RequestHash != requestHash {
+			conflictErr := connect.NewError(connect.CodeAlreadyExists, fmt.Errorf("idempotency key %q was already used with a different request", key))
+			conflictErr.Meta().Set("Idempotency-Conflict", "request-body-mismatch")
+			return nil, conflictErr
+		}
+
+		cached := newResponse()
+		if err := proto.Unmarshal(rec.ResponseData, cached); err != nil {
+			i.logger.Error("failed to replay cached idempotent response, re-running instead", "method", method, "error", err)
+			return next(ctx, req)
+		}
+		return connect.NewResponse(cached), nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		i.logger.Error("failed to look up idempotency record, proceeding without it", "method", method, "error", err)
+	}
+
+	resp, err := next(ctx, req)
+	if err != nil {
+		return resp, err
+	}
+
+	responseMsg, ok := resp.Any().(proto.Message)
+	if !ok {
+		return resp, nil
+	}
+	responseData, err := proto.Marshal(responseMsg)
+	if err != nil {
+		i.logger.Error("failed to marshal response for idempotency cache", "method", method, "error", err)
+		return resp, nil
+	}
+
+	if saveErr := i.repo.SaveIdempotencyRecord(&store.RequestIdempotency{
+		UserID:       userID,
+		Method:       method,
+		Key:          key,
+		RequestHash:  requestHash,
+		ResponseData: responseData,
+	}); saveErr != nil {
+		i.logger.Error("failed to persist idempotency record", "method", method, "error", saveErr)
+	}
+
+	return resp, nil
+}
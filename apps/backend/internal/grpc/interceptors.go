@@ -5,11 +5,17 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"connectrpc.com/connect"
+	"github.com/friend-bets/backend/internal/config"
+	"github.com/friend-bets/backend/internal/logger"
 	"github.com/friend-bets/backend/internal/rate"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/proto"
 )
 
 // LoggingInterceptor logs incoming requests
@@ -23,11 +29,23 @@ func NewLoggingInterceptor(logger *slog.Logger) connect.UnaryInterceptorFunc {
 	return interceptor.Intercept
 }
 
-// Intercept implements the logging interceptor
+// Intercept implements the logging interceptor. It stamps every request with a request
+// ID (reusing an inbound "X-Request-Id" header when the caller already has one) so it
+// carries through ctx to every downstream logger.With-derived log line, not just the
+// lines this interceptor itself emits.
 func (i *LoggingInterceptor) Intercept(next connect.UnaryFunc) connect.UnaryFunc {
 	return connect.UnaryFunc(func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
 		start := time.Now()
-		
+
+		requestID := ""
+		if req.Header() != nil {
+			requestID = req.Header().Get("X-Request-Id")
+		}
+		if requestID == "" {
+			requestID = uuid.NewString()
+		}
+		ctx = logger.WithRequestID(ctx, requestID)
+
 		// Extract client IP
 		clientIP := "unknown"
 		if peer := req.Peer(); peer != nil {
@@ -40,7 +58,7 @@ func (i *LoggingInterceptor) Intercept(next connect.UnaryFunc) connect.UnaryFunc
 			userAgent = req.Header().Get("User-Agent")
 		}
 
-		i.logger.Info("grpc request started",
+		i.logger.InfoContext(ctx, "grpc request started",
 			"method", req.Spec().Procedure,
 			"client_ip", clientIP,
 			"user_agent", userAgent,
@@ -48,18 +66,18 @@ func (i *LoggingInterceptor) Intercept(next connect.UnaryFunc) connect.UnaryFunc
 
 		// Call next handler
 		resp, err := next(ctx, req)
-		
+
 		// Log completion
 		duration := time.Since(start)
 		if err != nil {
-			i.logger.Error("grpc request failed",
+			i.logger.ErrorContext(ctx, "grpc request failed",
 				"method", req.Spec().Procedure,
 				"client_ip", clientIP,
 				"duration_ms", duration.Milliseconds(),
 				"error", err,
 			)
 		} else {
-			i.logger.Info("grpc request completed",
+			i.logger.InfoContext(ctx, "grpc request completed",
 				"method", req.Spec().Procedure,
 				"client_ip", clientIP,
 				"duration_ms", duration.Milliseconds(),
@@ -70,14 +88,31 @@ func (i *LoggingInterceptor) Intercept(next connect.UnaryFunc) connect.UnaryFunc
 	})
 }
 
-// RateLimitInterceptor implements rate limiting
+// RateLimitInterceptor implements rate limiting. Each action is checked against a
+// CompositeLimiter covering per-user, per-IP, and global quotas atomically, so a
+// request that fails one stage doesn't leave it having already spent tokens in
+// another (the bug with calling Limiter.Allow once per stage separately).
 type RateLimitInterceptor struct {
 	rateLimiter *rate.Limiter
+	composites  map[string]*rate.CompositeLimiter
+	logger      *slog.Logger
 }
 
-// NewRateLimitInterceptor creates a new rate limiting interceptor
-func NewRateLimitInterceptor(rateLimiter *rate.Limiter) connect.UnaryInterceptorFunc {
-	interceptor := &RateLimitInterceptor{rateLimiter: rateLimiter}
+// NewRateLimitInterceptor creates a new rate limiting interceptor. cfg supplies the
+// per-user quotas for Resolve and Claim (create_market/place_bet keep their existing
+// literal quotas below rather than being migrated to config in the same change).
+func NewRateLimitInterceptor(rateLimiter *rate.Limiter, cfg config.RateConfig, logger *slog.Logger) connect.UnaryInterceptorFunc {
+	interceptor := &RateLimitInterceptor{
+		rateLimiter: rateLimiter,
+		logger:      logger,
+		composites: map[string]*rate.CompositeLimiter{
+			"create_market": rate.NewComposite(rateLimiter).PerUser(10, time.Hour).PerIP(50, time.Hour).Global(1000, time.Hour),
+			"place_bet":     rate.NewComposite(rateLimiter).PerUser(20, time.Minute).PerIP(100, time.Minute).Global(5000, time.Minute),
+			"resolve":       rate.NewComposite(rateLimiter).PerUser(cfg.ResolvePerMinute, time.Minute).PerIP(50, time.Minute).Global(2000, time.Minute),
+			"claim":         rate.NewComposite(rateLimiter).PerUser(cfg.ClaimPerMinute, time.Minute).PerIP(50, time.Minute).Global(2000, time.Minute),
+			"general":       rate.NewComposite(rateLimiter).PerIP(100, time.Minute).Global(10000, time.Minute),
+		},
+	}
 	return interceptor.Intercept
 }
 
@@ -97,9 +132,23 @@ func (i *RateLimitInterceptor) Intercept(next connect.UnaryFunc) connect.UnaryFu
 		method := req.Spec().Procedure
 		action := i.methodToAction(method)
 
-		// Check rate limits
-		if err := i.checkRateLimits(ctx, clientIP, userID, action); err != nil {
-			return nil, connect.NewError(connect.CodeResourceExhausted, err)
+		composite, ok := i.composites[action]
+		if !ok {
+			composite = i.composites["general"]
+		}
+
+		results, allowed := composite.Allow(action, userID, clientIP)
+		if !allowed {
+			retryAfter := time.Duration(0)
+			for _, r := range results {
+				if !r.Allowed && r.RetryAfter > retryAfter {
+					retryAfter = r.RetryAfter
+				}
+			}
+			i.logger.Debug("rate limit exceeded", "method", method, "action", action, "results", results)
+			rateLimitErr := connect.NewError(connect.CodeResourceExhausted, fmt.Errorf("rate limit exceeded, retry after %s", retryAfter))
+			rateLimitErr.Meta().Set("Retry-After", fmt.Sprintf("%.0f", retryAfter.Seconds()))
+			return nil, rateLimitErr
 		}
 
 		return next(ctx, req)
@@ -135,123 +184,242 @@ func (i *RateLimitInterceptor) methodToAction(method string) string {
 		return "create_market"
 	case strings.Contains(method, "PlaceBet"):
 		return "place_bet"
+	case strings.Contains(method, "Resolve"):
+		return "resolve"
+	case strings.Contains(method, "Claim"):
+		return "claim"
 	default:
 		return "general"
 	}
 }
 
-// checkRateLimits checks various rate limits
-func (i *RateLimitInterceptor) checkRateLimits(ctx context.Context, clientIP, userID, action string) error {
-	// Check IP-based rate limit
-	ipKey := fmt.Sprintf("ip:%s", clientIP)
-	if !i.rateLimiter.Allow(ctx, ipKey, "general", time.Minute, 100) {
-		return fmt.Errorf("IP rate limit exceeded")
+// StreamConcurrencyInterceptor bounds how many concurrent server-streaming calls
+// (e.g. WatchEvents) a single caller can have open at once. Unlike the other
+// interceptors in this file, it implements the full connect.Interceptor interface
+// rather than connect.UnaryInterceptorFunc: UnaryInterceptorFunc.WrapStreamingHandler
+// is a no-op, so a unary-only interceptor never sees streaming RPCs at all.
+//
+// Streaming RPCs carry no verified wallet identity in this server (AuthInterceptor is
+// itself unary-only for the same reason, and WatchEvents is registered as PermPublic
+// in the MethodRegistry), so callers are keyed by peer IP rather than user ID.
+type StreamConcurrencyInterceptor struct {
+	maxPerCaller int
+	logger       *slog.Logger
+
+	mu     sync.Mutex
+	active map[string]int
+}
+
+// NewStreamConcurrencyInterceptor creates a connect.Interceptor that rejects a new
+// streaming call with CodeResourceExhausted once its caller already has maxPerCaller
+// open. maxPerCaller <= 0 disables the limit.
+func NewStreamConcurrencyInterceptor(maxPerCaller int, logger *slog.Logger) connect.Interceptor {
+	return &StreamConcurrencyInterceptor{
+		maxPerCaller: maxPerCaller,
+		logger:       logger,
+		active:       make(map[string]int),
 	}
+}
 
-	// Check user-based rate limits if user ID is available
-	if userID != "" {
-		userKey := fmt.Sprintf("user:%s", userID)
-		
-		switch action {
-		case "create_market":
-			if !i.rateLimiter.Allow(ctx, userKey, action, time.Hour, 10) {
-				return fmt.Errorf("create market rate limit exceeded")
-			}
-		case "place_bet":
-			if !i.rateLimiter.Allow(ctx, userKey, action, time.Minute, 20) {
-				return fmt.Errorf("place bet rate limit exceeded")
-			}
+// WrapUnary leaves unary calls untouched; this interceptor only bounds streams.
+func (i *StreamConcurrencyInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return next
+}
+
+// WrapStreamingClient leaves outbound streaming calls untouched; this interceptor only
+// guards the handler side.
+func (i *StreamConcurrencyInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+// WrapStreamingHandler enforces the per-caller concurrent stream cap.
+func (i *StreamConcurrencyInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return connect.StreamingHandlerFunc(func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		if i.maxPerCaller <= 0 {
+			return next(ctx, conn)
 		}
-	}
 
-	return nil
+		key := i.callerKey(conn)
+
+		i.mu.Lock()
+		if i.active[key] >= i.maxPerCaller {
+			i.mu.Unlock()
+			i.logger.Debug("concurrent stream limit exceeded", "caller", key, "method", conn.Spec().Procedure)
+			return connect.NewError(connect.CodeResourceExhausted, fmt.Errorf("too many concurrent streams open"))
+		}
+		i.active[key]++
+		i.mu.Unlock()
+
+		defer func() {
+			i.mu.Lock()
+			i.active[key]--
+			if i.active[key] <= 0 {
+				delete(i.active, key)
+			}
+			i.mu.Unlock()
+		}()
+
+		return next(ctx, conn)
+	})
+}
+
+// callerKey identifies a streaming caller for the concurrency cap: the peer IP, since
+// streaming calls carry no verified wallet identity in this server (see
+// StreamConcurrencyInterceptor's doc comment).
+func (i *StreamConcurrencyInterceptor) callerKey(conn connect.StreamingHandlerConn) string {
+	if peer := conn.Peer(); peer.Addr != "" {
+		return peer.Addr
+	}
+	return "unknown"
 }
 
-// AuthInterceptor handles authentication
+// AuthInterceptor authenticates requests to identity-bearing methods by verifying
+// an ed25519 signature from the caller's Solana wallet (see wallet_auth.go for the
+// SolanaSig header format and canonical signing string). On success it sets the
+// verified base58 public key on ctx as "user_id", the same key handlers already
+// read via ctx.Value("user_id"). Which methods need a verified identity at all,
+// and how privileged that identity must be, is decided by looking the procedure
+// up in methods (see MethodRegistry) rather than a hardcoded list here.
 type AuthInterceptor struct {
-	logger *slog.Logger
+	logger          *slog.Logger
+	devBearerToken  string
+	nonceMaxAge     time.Duration
+	nonces          *nonceCache
+	sessionAuth     *SessionAuthService
+	methods         *MethodRegistry
+	resolverPubkeys pubkeyAllowlist
+	adminPubkeys    pubkeyAllowlist
 }
 
-// NewAuthInterceptor creates a new auth interceptor
-func NewAuthInterceptor(logger *slog.Logger) connect.UnaryInterceptorFunc {
-	interceptor := &AuthInterceptor{logger: logger}
+// NewAuthInterceptor creates a new auth interceptor. sessionAuth may be nil
+// (the challenge/session login flow is disabled), in which case Bearer tokens
+// other than the dev token are always rejected. methods decides the
+// permission required for each procedure; anything never registered defaults
+// to PermAdmin.
+func NewAuthInterceptor(cfg config.AuthConfig, sessionAuth *SessionAuthService, methods *MethodRegistry, logger *slog.Logger) connect.UnaryInterceptorFunc {
+	maxAge := time.Duration(cfg.NonceMaxAgeSec) * time.Second
+	if maxAge <= 0 {
+		maxAge = 60 * time.Second
+	}
+
+	interceptor := &AuthInterceptor{
+		logger:          logger,
+		devBearerToken:  cfg.DevBearerToken,
+		nonceMaxAge:     maxAge,
+		nonces:          newNonceCache(maxAge),
+		sessionAuth:     sessionAuth,
+		methods:         methods,
+		resolverPubkeys: newPubkeyAllowlist(cfg.ResolverPubkeys),
+		adminPubkeys:    newPubkeyAllowlist(cfg.AdminPubkeys),
+	}
 	return interceptor.Intercept
 }
 
 // Intercept implements the auth interceptor
 func (i *AuthInterceptor) Intercept(next connect.UnaryFunc) connect.UnaryFunc {
 	return connect.UnaryFunc(func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
-		// Extract auth token
-		var token string
-		if req.Header() != nil {
-			auth := req.Header().Get("Authorization")
-			if strings.HasPrefix(auth, "Bearer ") {
-				token = auth[7:]
-			}
+		method := req.Spec().Procedure
+		required := i.methods.RequiredPermission(method)
+		if required == PermPublic {
+			return next(ctx, req)
 		}
 
-		// Determine if auth is required for this method
-		method := req.Spec().Procedure
-		if i.requiresAuth(method) {
-			if token == "" {
-				return nil, connect.NewError(connect.CodeUnauthenticated, fmt.Errorf("authentication required"))
-			}
+		var header string
+		if req.Header() != nil {
+			header = req.Header().Get("Authorization")
+		}
+		if header == "" {
+			return nil, connect.NewError(connect.CodeUnauthenticated, fmt.Errorf("authentication required"))
+		}
 
-			// Validate token and extract user info
-			userID, err := i.validateToken(ctx, token)
-			if err != nil {
-				return nil, connect.NewError(connect.CodeUnauthenticated, fmt.Errorf("invalid token: %w", err))
-			}
+		userID, granted, err := i.authenticate(method, req, header)
+		if err != nil {
+			i.logger.Debug("authentication rejected", "method", method, "error", err)
+			return nil, connect.NewError(connect.CodeUnauthenticated, err)
+		}
 
-			// Add user info to context
-			ctx = context.WithValue(ctx, "user_id", userID)
-			ctx = context.WithValue(ctx, "auth_token", token)
+		if granted < required {
+			return nil, connect.NewError(connect.CodePermissionDenied, fmt.Errorf("caller lacks the permission required for %s", method))
 		}
 
+		ctx = context.WithValue(ctx, "user_id", userID)
 		return next(ctx, req)
 	})
 }
 
-// requiresAuth determines if a method requires authentication
-func (i *AuthInterceptor) requiresAuth(method string) bool {
-	// Most methods require auth, except for listing markets and watching events
-	publicMethods := []string{
-		"/bets.v1.BetsService/ListMarkets",
-		"/bets.v1.BetsService/WatchEvents",
-		"/grpc.health.v1.Health/Check",
+// authenticate verifies header by whichever scheme it carries (the dev bearer
+// token, a session JWT, or a per-request SolanaSig signature), returning the
+// caller's verified pubkey and the Permission it's been granted.
+func (i *AuthInterceptor) authenticate(method string, req connect.AnyRequest, header string) (userID string, granted Permission, err error) {
+	if i.devBearerToken != "" && header == "Bearer "+i.devBearerToken {
+		return "dev-wallet", PermAdmin, nil
 	}
 
-	for _, publicMethod := range publicMethods {
-		if method == publicMethod {
-			return false
+	if strings.HasPrefix(header, "Bearer ") {
+		if i.sessionAuth == nil {
+			return "", PermPublic, fmt.Errorf("bearer token auth is not enabled")
 		}
+		userID, err := i.sessionAuth.VerifySessionToken(strings.TrimPrefix(header, "Bearer "))
+		if err != nil {
+			return "", PermPublic, fmt.Errorf("invalid session token: %w", err)
+		}
+		return userID, i.grantedPermission(userID), nil
+	}
+
+	userID, err = i.verifyWalletSignature(method, req, header)
+	if err != nil {
+		return "", PermPublic, fmt.Errorf("invalid signature: %w", err)
 	}
+	return userID, i.grantedPermission(userID), nil
+}
 
-	return true
+// grantedPermission looks pubkey up against the configured resolver/admin
+// allowlists; any other verified wallet gets PermUser.
+func (i *AuthInterceptor) grantedPermission(pubkey string) Permission {
+	if i.adminPubkeys[pubkey] {
+		return PermAdmin
+	}
+	if i.resolverPubkeys[pubkey] {
+		return PermResolver
+	}
+	return PermUser
 }
 
-// validateToken validates an auth token and returns user ID
-func (i *AuthInterceptor) validateToken(ctx context.Context, token string) (string, error) {
-	// In a real implementation, this would:
-	// 1. Verify the token signature
-	// 2. Check token expiration
-	// 3. Validate against a user database
-	// 4. Handle different token types (JWT, API key, etc.)
-	
-	// For now, we'll do a simple validation
-	if len(token) < 10 {
-		return "", fmt.Errorf("token too short")
+// verifyWalletSignature parses and verifies a "SolanaSig <pubkey>:<sig>:<ts>"
+// Authorization header against req, returning the caller's verified public key.
+func (i *AuthInterceptor) verifyWalletSignature(method string, req connect.AnyRequest, header string) (string, error) {
+	pubkeyB58, sigB58, ts, err := parseSolanaSigHeader(header)
+	if err != nil {
+		return "", err
+	}
+
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid timestamp")
+	}
+	if age := time.Since(time.Unix(sec, 0)); age > i.nonceMaxAge || age < -i.nonceMaxAge {
+		return "", fmt.Errorf("stale or future-dated signature")
+	}
+
+	msg, ok := req.Any().(proto.Message)
+	if !ok {
+		return "", fmt.Errorf("unsupported request type")
+	}
+	body, err := proto.Marshal(msg)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode request body: %w", err)
+	}
+
+	pubkey, err := verifySolanaSig(pubkeyB58, sigB58, canonicalSignString(method, body, ts))
+	if err != nil {
+		return "", err
 	}
 
-	// In Solana context, the "token" might be a public key
-	// We could validate it's a valid base58 public key
-	if len(token) == 44 || len(token) == 43 {
-		// Looks like a Solana public key
-		return token, nil
+	if i.nonces.SeenBefore(pubkeyB58+":"+sigB58, time.Now()) {
+		return "", fmt.Errorf("replayed signature")
 	}
 
-	// For development, accept any non-empty token
-	return token, nil
+	return pubkey, nil
 }
 
 // Recovery interceptor to handle panics
@@ -346,4 +514,4 @@ func (i *TimeoutInterceptor) Intercept(next connect.UnaryFunc) connect.UnaryFunc
 
 		return next(ctx, req)
 	})
-}
\ No newline at end of file
+}
@@ -0,0 +1,70 @@
+package grpc
+
+import "sync"
+
+// Permission is a capability level a caller must hold to invoke a given RPC.
+// Levels are cumulative: a caller holding PermAdmin can do anything PermUser
+// or PermResolver can, the same way an on-chain program's upgrade authority
+// can do anything a regular signer can.
+type Permission int
+
+const (
+	// PermPublic requires no verified identity at all.
+	PermPublic Permission = iota
+	// PermUser requires any verified wallet signature (SolanaSig header or a
+	// session JWT from SessionAuthService), but no special standing.
+	PermUser
+	// PermResolver requires a wallet on the configured resolver allowlist, on
+	// top of everything PermUser allows.
+	PermResolver
+	// PermAdmin requires a wallet on the configured admin allowlist, or the
+	// dev bearer token. Also the default for any method nobody registered, so
+	// a new RPC fails closed instead of shipping world-readable.
+	PermAdmin
+)
+
+// MethodRegistry maps a Connect-RPC procedure path (req.Spec().Procedure) to
+// the Permission a caller must hold to invoke it. AuthInterceptor consults it
+// instead of a hardcoded list of protected methods, so adding a new RPC can't
+// silently leave it unauthenticated (or silently lock it down) - whoever wires
+// the handler up registers its permission alongside it.
+type MethodRegistry struct {
+	mu    sync.RWMutex
+	perms map[string]Permission
+}
+
+// NewMethodRegistry returns an empty registry; every method is PermAdmin
+// (fail-closed) until Register is called for it.
+func NewMethodRegistry() *MethodRegistry {
+	return &MethodRegistry{perms: make(map[string]Permission)}
+}
+
+// Register sets the permission required to call procedure.
+func (r *MethodRegistry) Register(procedure string, perm Permission) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.perms[procedure] = perm
+}
+
+// RequiredPermission returns the permission registered for procedure, or
+// PermAdmin if nothing was ever registered for it.
+func (r *MethodRegistry) RequiredPermission(procedure string) Permission {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if perm, ok := r.perms[procedure]; ok {
+		return perm
+	}
+	return PermAdmin
+}
+
+// pubkeyAllowlist is a set of base58 wallet pubkeys granted some Permission,
+// e.g. the resolver or admin allowlist from AuthConfig.
+type pubkeyAllowlist map[string]bool
+
+func newPubkeyAllowlist(pubkeys []string) pubkeyAllowlist {
+	set := make(pubkeyAllowlist, len(pubkeys))
+	for _, pk := range pubkeys {
+		set[pk] = true
+	}
+	return set
+}
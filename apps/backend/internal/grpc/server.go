@@ -2,10 +2,12 @@ package grpc
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"net"
 	"net/http"
+	"strconv"
 	"time"
 
 	"connectrpc.com/connect"
@@ -13,23 +15,38 @@ import (
 	"github.com/friend-bets/backend/gen/proto/bets/v1/betsv1connect"
 	"github.com/friend-bets/backend/internal/config"
 	"github.com/friend-bets/backend/internal/core"
+	"github.com/friend-bets/backend/internal/hedge"
 	"github.com/friend-bets/backend/internal/notify"
 	"github.com/friend-bets/backend/internal/rate"
+	"github.com/friend-bets/backend/internal/risk"
 	"github.com/friend-bets/backend/internal/solana"
 	"github.com/friend-bets/backend/internal/store"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rs/cors"
 	"golang.org/x/net/http2"
 	"golang.org/x/net/http2/h2c"
 )
 
+// filterGCInterval is how often Server reaps expired event filters (see
+// solana.FilterRegistry.GC).
+const filterGCInterval = time.Minute
+
 // Server represents the gRPC server
 type Server struct {
 	config       *config.Config
 	httpServer   *http.Server
+	repo         *store.Repository
 	useCases     *core.UseCases
 	solanaClient *solana.AnchorClient
 	notifier     *notify.Notifier
 	rateLimiter  *rate.Limiter
+	analytics    *store.Analytics
+	riskMon      *risk.Monitor
+	hedgeMon     *hedge.Monitor
+	filterReg    *solana.FilterRegistry
+	webhookRecv  *solana.WebhookReceiver
+	sessionAuth  *SessionAuthService
+	methods      *MethodRegistry
 	logger       *slog.Logger
 }
 
@@ -40,37 +57,142 @@ func NewServer(
 	solanaClient *solana.AnchorClient,
 	notifier *notify.Notifier,
 	rateLimiter *rate.Limiter,
+	analytics *store.Analytics,
 	logger *slog.Logger,
 ) *Server {
-	useCases := core.NewUseCases(repo, cfg, logger)
+	riskMon := newRiskMonitor(cfg, repo, logger)
+	hedgeMon, err := newHedgeMonitor(cfg, repo, rateLimiter, logger)
+	if err != nil {
+		logger.Error("hedge monitoring disabled: failed to initialize", "error", err)
+	}
+	useCases := core.NewUseCases(repo, cfg, notifier, analytics, riskMon, hedgeMon, logger)
+	filterReg := solana.NewFilterRegistry(repo)
+
+	var webhookRecv *solana.WebhookReceiver
+	if cfg.Solana.WebhookHMACSecret != "" {
+		webhookRecv = solana.NewWebhookReceiver(repo, cfg.Solana.WebhookHMACSecret, logger)
+	}
+
+	sessionAuth := NewSessionAuthService(cfg.Auth, logger)
+	methods := NewMethodRegistry()
+	registerBetsServiceMethods(methods)
 
 	return &Server{
 		config:       cfg,
+		repo:         repo,
 		useCases:     useCases,
 		solanaClient: solanaClient,
 		notifier:     notifier,
 		rateLimiter:  rateLimiter,
+		analytics:    analytics,
+		riskMon:      riskMon,
+		hedgeMon:     hedgeMon,
+		filterReg:    filterReg,
+		webhookRecv:  webhookRecv,
+		sessionAuth:  sessionAuth,
+		methods:      methods,
 		logger:       logger,
 	}
 }
 
+// registerBetsServiceMethods declares the permission every BetsService RPC
+// requires. Every procedure BetsService exposes must be registered here: an
+// RPC left out defaults to PermAdmin (MethodRegistry.RequiredPermission's
+// fail-closed default), not silently public.
+func registerBetsServiceMethods(methods *MethodRegistry) {
+	const svc = "/bets.v1.BetsService/"
+
+	methods.Register(svc+"ListMarkets", PermPublic)
+	methods.Register(svc+"GetMarket", PermPublic)
+	methods.Register(svc+"WatchEvents", PermPublic)
+	methods.Register(svc+"SubscribeMarketEvents", PermPublic)
+	methods.Register(svc+"CreateFilter", PermPublic)
+	methods.Register(svc+"GetFilterChanges", PermPublic)
+	methods.Register(svc+"GetLogs", PermPublic)
+	methods.Register(svc+"WatchFilteredEvents", PermPublic)
+
+	methods.Register(svc+"CreateMarket", PermUser)
+	methods.Register(svc+"PlaceBet", PermUser)
+	methods.Register(svc+"Claim", PermUser)
+	methods.Register(svc+"GetPosition", PermUser)
+	methods.Register(svc+"GetUserPositions", PermUser)
+	methods.Register(svc+"CreateSuccessorMarket", PermUser)
+	methods.Register(svc+"DisputeResolution", PermUser)
+	methods.Register(svc+"CastDisputeVote", PermUser)
+
+	// Resolve still independently checks that the caller matches the specific
+	// market's Creator (see core/domain.go's ValidateResolveMarket); requiring
+	// PermResolver here additionally restricts it to wallets on the configured
+	// resolver allowlist, so a compromised-but-unlisted creator key can't
+	// resolve a market on its own.
+	methods.Register(svc+"Resolve", PermResolver)
+}
+
+// RegisterMethod declares the Permission a caller must hold to invoke
+// procedure (e.g. "/bets.v1.BetsService/Resolve"), consulted by
+// AuthInterceptor on every call. Must be called before Start.
+func (s *Server) RegisterMethod(procedure string, perm Permission) {
+	s.methods.Register(procedure, perm)
+}
+
+// newRiskMonitor builds the circuit-breaker monitor from config, or returns nil if risk
+// monitoring is disabled so that UseCases skips it entirely.
+func newRiskMonitor(cfg *config.Config, repo *store.Repository, logger *slog.Logger) *risk.Monitor {
+	if !cfg.Risk.Enabled {
+		return nil
+	}
+
+	breaker := risk.NewMultiBreaker(
+		risk.NewWalletConcentrationBreaker(repo, cfg.Risk.MaxWalletShareBps, logger),
+		risk.NewOddsMovementBreaker(cfg.Risk.MaxOddsMoveBps, time.Duration(cfg.Risk.OddsMoveWindowSec)*time.Second),
+		risk.NewDisputeFrequencyBreaker(repo, cfg.Risk.MaxDisputesPerCreator, time.Duration(cfg.Risk.DisputeWindowSec)*time.Second, logger),
+		risk.NewResolverHistoryBreaker(repo, cfg.Risk.MaxResolverDeadlineViolations, logger),
+	)
+	return risk.NewMonitor(repo, breaker, logger)
+}
+
+// newHedgeMonitor builds the cross-venue hedging monitor from config, or returns nil if
+// hedging is disabled so that UseCases skips it entirely. No concrete exchange adapter
+// ships with this deployment yet; factories is empty until one is registered, so
+// hedge.Monitor.Observe logs and skips any market referencing an unconfigured exchange.
+func newHedgeMonitor(cfg *config.Config, repo *store.Repository, rateLimiter *rate.Limiter, logger *slog.Logger) (*hedge.Monitor, error) {
+	if !cfg.Hedge.Enabled {
+		return nil, nil
+	}
+
+	keys, err := hedge.NewKeyStore(repo, cfg.Hedge.EncryptionKeyHex)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize hedge key store: %w", err)
+	}
+
+	factories := map[string]hedge.ExchangeFactory{}
+	return hedge.NewMonitor(repo, keys, factories, rateLimiter, &cfg.Hedge, logger), nil
+}
+
 // Start starts the gRPC server
 func (s *Server) Start(ctx context.Context) error {
+	s.filterReg.StartGC(ctx, filterGCInterval)
+
 	// Create main HTTP mux
 	mainMux := http.NewServeMux()
 
 	// Create gRPC mux
 	grpcMux := http.NewServeMux()
 
-	// Create interceptors for MVP (auth disabled)
+	// Create interceptors. Auth runs before Idempotency and RateLimit so both can key
+	// off the verified wallet identity set on ctx; Idempotency runs before RateLimit so
+	// a replayed request returns its cached response without spending a rate-limit
+	// token on work that doesn't actually re-run.
 	interceptors := connect.WithInterceptors(
 		NewLoggingInterceptor(s.logger),
-		// NewRateLimitInterceptor(s.rateLimiter), // Disabled for MVP
-		// NewAuthInterceptor(s.logger), // Disabled for MVP
+		NewAuthInterceptor(s.config.Auth, s.sessionAuth, s.methods, s.logger),
+		NewIdempotencyInterceptor(s.repo, s.logger),
+		NewRateLimitInterceptor(s.rateLimiter, s.config.Rate, s.logger),
+		NewStreamConcurrencyInterceptor(s.config.Rate.MaxWatchStreamsPerUser, s.logger),
 	)
 
 	// Enable betting service for MVP
-	betsService := NewBetsService(s.useCases, s.solanaClient, s.notifier, s.logger)
+	betsService := NewBetsService(s.useCases, s.solanaClient, s.notifier, s.rateLimiter, s.filterReg, s.logger)
 	betsServicePath, betsServiceHandler := betsv1connect.NewBetsServiceHandler(betsService, interceptors)
 	grpcMux.Handle(betsServicePath, betsServiceHandler)
 
@@ -96,6 +218,39 @@ func (s *Server) Start(ctx context.Context) error {
 		w.Write([]byte(`{"status":"ok","service":"friend-bets-api"}`))
 	})
 
+	// Add Prometheus scrape endpoint
+	mainMux.Handle("/metrics", promhttp.Handler())
+
+	// Add admin endpoint listing recent scheduler job runs
+	mainMux.HandleFunc("/admin/jobs", s.handleAdminJobs)
+
+	// Add admin endpoint to reopen a market halted by a risk circuit breaker
+	mainMux.HandleFunc("/admin/risk/reset", s.handleAdminRiskReset)
+	mainMux.HandleFunc("/admin/backfill/reindex", s.handleAdminBackfillReindex)
+
+	// End-user notification preference management. Unlike the admin endpoints
+	// above, these are end-user-facing but still unauthenticated by any login
+	// session — this codebase has no session-based auth for plain HTTP (only
+	// AuthInterceptor's wallet-signature flow, which is Connect-RPC only), so
+	// identity here is proven by a signed, per-user link token instead (see
+	// notify.Notifier.VerifyLinkToken), the same token embedded in every email's
+	// List-Unsubscribe header and preferences link.
+	mainMux.HandleFunc("/notifications/unsubscribe", s.handleNotificationUnsubscribe)
+	mainMux.HandleFunc("/notifications/preferences", s.handleNotificationPreferences)
+
+	if s.webhookRecv != nil {
+		mainMux.HandleFunc(s.config.Solana.WebhookPath, s.webhookRecv.Handler())
+	}
+
+	// Sign-In-With-Solana challenge/session login, same plain-HTTP pattern as
+	// the notification endpoints above since there's no Connect-RPC layer for
+	// it. Absent when auth.jwt_secret isn't configured; AuthInterceptor's
+	// per-request SolanaSig signing keeps working either way.
+	if s.sessionAuth != nil {
+		mainMux.HandleFunc("/auth/challenge", s.handleAuthChallenge)
+		mainMux.HandleFunc("/auth/verify", s.handleAuthVerify)
+	}
+
 	// Create HTTP server
 	s.httpServer = &http.Server{
 		Addr:    fmt.Sprintf("%s:%d", s.config.Server.Host, s.config.Server.Port),
@@ -167,6 +322,315 @@ func (s *Server) addCORS(handler http.Handler) http.Handler {
 	})
 }
 
+// adminJobRunView is the JSON shape returned by /admin/jobs, adding a computed
+// duration alongside the raw store.JobRun fields.
+type adminJobRunView struct {
+	JobID      string     `json:"job_id"`
+	Status     string     `json:"status"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	DurationMs *int64     `json:"duration_ms,omitempty"`
+	Attempt    int        `json:"attempt"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// handleAdminJobs serves recent scheduler job runs with durations and errors,
+// for operators checking whether cron jobs are running and succeeding.
+func (s *Server) handleAdminJobs(w http.ResponseWriter, r *http.Request) {
+	runs, err := s.repo.GetRecentJobRuns(100)
+	if err != nil {
+		s.logger.Error("failed to list recent job runs", "error", err)
+		http.Error(w, "failed to list job runs", http.StatusInternalServerError)
+		return
+	}
+
+	views := make([]adminJobRunView, len(runs))
+	for i, run := range runs {
+		view := adminJobRunView{
+			JobID:      run.JobID,
+			Status:     run.Status,
+			StartedAt:  run.StartedAt,
+			FinishedAt: run.FinishedAt,
+			Attempt:    run.Attempt,
+			Error:      run.Error,
+		}
+		if run.FinishedAt != nil {
+			durationMs := run.FinishedAt.Sub(run.StartedAt).Milliseconds()
+			view.DurationMs = &durationMs
+		}
+		views[i] = view
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(views); err != nil {
+		s.logger.Error("failed to encode job runs response", "error", err)
+	}
+}
+
+// handleAdminRiskReset reopens a market halted by a risk circuit breaker, for operators
+// who have investigated a trip and confirmed it's safe to resume trading.
+func (s *Server) handleAdminRiskReset(w http.ResponseWriter, r *http.Request) {
+	if s.riskMon == nil {
+		http.Error(w, "risk monitoring is disabled", http.StatusNotFound)
+		return
+	}
+
+	marketID := r.URL.Query().Get("market_id")
+	if marketID == "" {
+		http.Error(w, "market_id is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.riskMon.Reset(marketID); err != nil {
+		s.logger.Error("failed to reset halted market", "error", err, "market_id", marketID)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]string{"market_id": marketID, "status": "reopened"}); err != nil {
+		s.logger.Error("failed to encode risk reset response", "error", err)
+	}
+}
+
+// handleAdminBackfillReindex re-enqueues a slot range as pending backfill chunks for
+// the configured Solana program, without wiping any other chunk's state, so an
+// operator can re-scan a range they suspect missed events without restarting the
+// whole historical backfill. The worker process's EventIndexer (wherever it's
+// running) picks the re-enqueued chunks up the next time its backfill workers poll.
+func (s *Server) handleAdminBackfillReindex(w http.ResponseWriter, r *http.Request) {
+	startSlot, err := strconv.ParseUint(r.URL.Query().Get("start_slot"), 10, 64)
+	if err != nil {
+		http.Error(w, "start_slot is required and must be a non-negative integer", http.StatusBadRequest)
+		return
+	}
+	endSlot, err := strconv.ParseUint(r.URL.Query().Get("end_slot"), 10, 64)
+	if err != nil || endSlot <= startSlot {
+		http.Error(w, "end_slot is required and must be greater than start_slot", http.StatusBadRequest)
+		return
+	}
+
+	chunkSize := uint64(s.config.Solana.BackfillChunkSize)
+	if chunkSize == 0 {
+		chunkSize = 1000
+	}
+
+	if err := s.repo.EnqueueReindexRange(s.config.Solana.ProgramID, startSlot, endSlot, chunkSize); err != nil {
+		s.logger.Error("failed to enqueue reindex range", "error", err, "start_slot", startSlot, "end_slot", endSlot)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"program":    s.config.Solana.ProgramID,
+		"start_slot": startSlot,
+		"end_slot":   endSlot,
+		"status":     "enqueued",
+	}); err != nil {
+		s.logger.Error("failed to encode backfill reindex response", "error", err)
+	}
+}
+
+// handleNotificationUnsubscribe implements RFC 8058 one-click unsubscribe: a mail
+// client POSTs here with no user interaction when a recipient clicks
+// "unsubscribe" in their mail app, using the user_id/token query params from the
+// email's List-Unsubscribe header (see notify.Notifier.UnsubscribeLink). Disables
+// every notification_preferences row for that user, plus a disabled catch-all row
+// so event types with no existing preference are suppressed too.
+func (s *Server) handleNotificationUnsubscribe(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := r.URL.Query().Get("user_id")
+	token := r.URL.Query().Get("token")
+	if userID == "" || !s.notifier.VerifyLinkToken(userID, token) {
+		http.Error(w, "invalid or missing token", http.StatusUnauthorized)
+		return
+	}
+
+	if err := s.repo.DisableAllNotificationPreferences(userID); err != nil {
+		s.logger.Error("failed to unsubscribe user", "error", err, "user_id", userID)
+		http.Error(w, "failed to unsubscribe", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// notificationPreferenceView is the JSON shape for GET/POST
+// /notifications/preferences, one entry per (event_type, channel).
+type notificationPreferenceView struct {
+	EventType       string `json:"event_type"`
+	Channel         string `json:"channel"`
+	Enabled         bool   `json:"enabled"`
+	MinSeverity     string `json:"min_severity,omitempty"`
+	BatchWindow     string `json:"batch_window,omitempty"`
+	QuietHoursStart *int   `json:"quiet_hours_start,omitempty"`
+	QuietHoursEnd   *int   `json:"quiet_hours_end,omitempty"`
+	Timezone        string `json:"timezone,omitempty"`
+}
+
+// handleNotificationPreferences lets a user list (GET) or edit (POST) their
+// per-event-type, per-channel notification preferences, authenticated the same
+// way handleNotificationUnsubscribe is: a signed user_id/token pair.
+func (s *Server) handleNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	token := r.URL.Query().Get("token")
+	if userID == "" || !s.notifier.VerifyLinkToken(userID, token) {
+		http.Error(w, "invalid or missing token", http.StatusUnauthorized)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		prefs, err := s.repo.GetNotificationPreferences(userID)
+		if err != nil {
+			s.logger.Error("failed to list notification preferences", "error", err, "user_id", userID)
+			http.Error(w, "failed to list preferences", http.StatusInternalServerError)
+			return
+		}
+
+		views := make([]notificationPreferenceView, len(prefs))
+		for i, pref := range prefs {
+			views[i] = notificationPreferenceView{
+				EventType:       pref.EventType,
+				Channel:         pref.Channel,
+				Enabled:         pref.Enabled,
+				MinSeverity:     pref.MinSeverity,
+				BatchWindow:     pref.BatchWindow,
+				QuietHoursStart: pref.QuietHoursStart,
+				QuietHoursEnd:   pref.QuietHoursEnd,
+				Timezone:        pref.Timezone,
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(views); err != nil {
+			s.logger.Error("failed to encode notification preferences response", "error", err)
+		}
+
+	case http.MethodPost:
+		var view notificationPreferenceView
+		if err := json.NewDecoder(r.Body).Decode(&view); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		if view.EventType == "" || view.Channel == "" {
+			http.Error(w, "event_type and channel are required", http.StatusBadRequest)
+			return
+		}
+		if view.BatchWindow == "" {
+			view.BatchWindow = "immediate"
+		}
+
+		pref := &store.NotificationPreference{
+			UserID:          userID,
+			EventType:       view.EventType,
+			Channel:         view.Channel,
+			Enabled:         view.Enabled,
+			MinSeverity:     view.MinSeverity,
+			BatchWindow:     view.BatchWindow,
+			QuietHoursStart: view.QuietHoursStart,
+			QuietHoursEnd:   view.QuietHoursEnd,
+			Timezone:        view.Timezone,
+		}
+		if err := s.repo.UpsertNotificationPreference(pref); err != nil {
+			s.logger.Error("failed to upsert notification preference", "error", err, "user_id", userID)
+			http.Error(w, "failed to save preference", http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// authChallengeRequest is the JSON body POSTed to /auth/challenge.
+type authChallengeRequest struct {
+	Pubkey string `json:"pubkey"`
+}
+
+// authChallengeResponse carries the message the wallet must sign.
+type authChallengeResponse struct {
+	Message   string `json:"message"`
+	ExpiresIn int64  `json:"expires_in_sec"`
+}
+
+// handleAuthChallenge issues a single-use Sign-In-With-Solana challenge message
+// for the caller to sign with their wallet and redeem at /auth/verify.
+func (s *Server) handleAuthChallenge(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req authChallengeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Pubkey == "" {
+		http.Error(w, "pubkey is required", http.StatusBadRequest)
+		return
+	}
+
+	message, ttl, err := s.sessionAuth.RequestChallenge(r.Context(), req.Pubkey)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(authChallengeResponse{
+		Message:   message,
+		ExpiresIn: int64(ttl.Seconds()),
+	}); err != nil {
+		s.logger.Error("failed to encode auth challenge response", "error", err)
+	}
+}
+
+// authVerifyRequest is the JSON body POSTed to /auth/verify.
+type authVerifyRequest struct {
+	Pubkey    string `json:"pubkey"`
+	Message   string `json:"message"`
+	Signature string `json:"signature"`
+}
+
+// authVerifyResponse carries the session token a client presents as
+// "Authorization: Bearer <token>" on subsequent Connect-RPC calls.
+type authVerifyResponse struct {
+	SessionToken string `json:"session_token"`
+}
+
+// handleAuthVerify redeems a challenge issued by handleAuthChallenge: on a
+// valid signature it returns a session JWT; otherwise the challenge is
+// consumed and the caller must request a new one.
+func (s *Server) handleAuthVerify(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req authVerifyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Pubkey == "" || req.Message == "" || req.Signature == "" {
+		http.Error(w, "pubkey, message, and signature are required", http.StatusBadRequest)
+		return
+	}
+
+	token, err := s.sessionAuth.VerifyChallenge(r.Context(), req.Pubkey, req.Message, req.Signature)
+	if err != nil {
+		s.logger.Debug("session login rejected", "error", err)
+		http.Error(w, "invalid challenge response", http.StatusUnauthorized)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(authVerifyResponse{SessionToken: token}); err != nil {
+		s.logger.Error("failed to encode auth verify response", "error", err)
+	}
+}
+
 // Health check endpoint
 func (s *Server) Health() error {
 	// Check if server is running
@@ -0,0 +1,268 @@
+package grpc
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/friend-bets/backend/internal/config"
+	"github.com/mr-tron/base58"
+)
+
+// sessionLoginDomain-bound challenge message wallets sign to prove control of a
+// pubkey without signing every individual API call the way SolanaSig does (see
+// wallet_auth.go); issuing a session token this way suits long-lived client
+// sessions (e.g. a browser tab) where re-signing per request is impractical.
+const challengeMessagePrefix = "friend-bets-login"
+
+// ChallengeStore issues and redeems the single-use login nonces
+// SessionAuthService hands out from RequestChallenge and consumes in
+// VerifyChallenge. The default, newInMemoryChallengeStore, is a single
+// process's in-memory map; NewRedisChallengeStore backs it with Redis instead
+// so a RequestChallenge/VerifyChallenge pair can land on different replicas
+// behind a load balancer, mirroring how rate.RedisDistributedLimiter and
+// store.RedisBackend offer the same in-memory-vs-Redis choice elsewhere.
+type ChallengeStore interface {
+	// Issue generates and stores a new nonce for pubkey, valid for ttl.
+	Issue(ctx context.Context, pubkey string, ttl time.Duration) (string, error)
+	// Consume atomically checks and deletes the stored nonce for pubkey,
+	// reporting whether it matched and hadn't already been used or expired.
+	Consume(ctx context.Context, pubkey, nonce string) (bool, error)
+}
+
+// inMemoryChallengeStore is the default ChallengeStore, sufficient for a
+// single replica or local development.
+type inMemoryChallengeStore struct {
+	mu      sync.Mutex
+	nonces  map[string]string
+	expires map[string]time.Time
+}
+
+func newInMemoryChallengeStore() *inMemoryChallengeStore {
+	return &inMemoryChallengeStore{
+		nonces:  make(map[string]string),
+		expires: make(map[string]time.Time),
+	}
+}
+
+func (s *inMemoryChallengeStore) Issue(ctx context.Context, pubkey string, ttl time.Duration) (string, error) {
+	nonce, err := randomNonce()
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nonces[pubkey] = nonce
+	s.expires[pubkey] = time.Now().Add(ttl)
+	return nonce, nil
+}
+
+func (s *inMemoryChallengeStore) Consume(ctx context.Context, pubkey, nonce string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.nonces[pubkey]
+	if !ok || stored != nonce || time.Now().After(s.expires[pubkey]) {
+		return false, nil
+	}
+	delete(s.nonces, pubkey)
+	delete(s.expires, pubkey)
+	return true, nil
+}
+
+// randomNonce returns a 256-bit random value, base64url-encoded.
+func randomNonce() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return base64urlEncodeGRPC(b), nil
+}
+
+func base64urlEncodeGRPC(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// SessionAuthService implements the Sign-In-With-Solana challenge/verify login
+// flow: RequestChallenge hands a wallet a single-use nonce to sign,
+// VerifyChallenge checks the signature and exchanges it for a session JWT
+// AuthInterceptor will accept as a bearer token. It's a separate, opt-in login
+// path alongside AuthInterceptor's existing per-request SolanaSig signing, for
+// clients (e.g. a browser session) that would rather sign once than sign every
+// call.
+type SessionAuthService struct {
+	challenges   ChallengeStore
+	jwtSecret    []byte
+	domain       string
+	challengeTTL time.Duration
+	sessionTTL   time.Duration
+	logger       *slog.Logger
+}
+
+// NewSessionAuthService returns nil if cfg.JWTSecret is empty, so callers can
+// treat a nil *SessionAuthService as "challenge/session login disabled" the
+// same way notify.Notifier treats an unconfigured WebPush/Links section.
+func NewSessionAuthService(cfg config.AuthConfig, logger *slog.Logger) *SessionAuthService {
+	if cfg.JWTSecret == "" {
+		return nil
+	}
+
+	challengeTTL := time.Duration(cfg.ChallengeTTLSec) * time.Second
+	if challengeTTL <= 0 {
+		challengeTTL = 5 * time.Minute
+	}
+	sessionTTL := time.Duration(cfg.SessionTTLSec) * time.Second
+	if sessionTTL <= 0 {
+		sessionTTL = 24 * time.Hour
+	}
+
+	return &SessionAuthService{
+		challenges:   newInMemoryChallengeStore(),
+		jwtSecret:    []byte(cfg.JWTSecret),
+		domain:       cfg.Domain,
+		challengeTTL: challengeTTL,
+		sessionTTL:   sessionTTL,
+		logger:       logger,
+	}
+}
+
+// RequestChallenge issues a single-use nonce for pubkeyB58 to sign, returning
+// the exact message the wallet must sign (see canonicalChallengeMessage) and
+// how long it stays valid.
+func (s *SessionAuthService) RequestChallenge(ctx context.Context, pubkeyB58 string) (message string, ttl time.Duration, err error) {
+	if err := validatePubkey(pubkeyB58); err != nil {
+		return "", 0, err
+	}
+
+	nonce, err := s.challenges.Issue(ctx, pubkeyB58, s.challengeTTL)
+	if err != nil {
+		return "", 0, err
+	}
+	return s.canonicalChallengeMessage(nonce), s.challengeTTL, nil
+}
+
+// VerifyChallenge redeems the nonce embedded in message (previously handed out
+// by RequestChallenge), verifies sigB58 is pubkeyB58's ed25519 signature over
+// it, and on success mints a session JWT whose "sub" claim is pubkeyB58.
+func (s *SessionAuthService) VerifyChallenge(ctx context.Context, pubkeyB58, message, sigB58 string) (token string, err error) {
+	nonce, ok := s.nonceFromChallengeMessage(message)
+	if !ok {
+		return "", fmt.Errorf("malformed challenge message")
+	}
+
+	consumed, err := s.challenges.Consume(ctx, pubkeyB58, nonce)
+	if err != nil {
+		return "", err
+	}
+	if !consumed {
+		return "", fmt.Errorf("unknown, expired, or already-used challenge")
+	}
+
+	if _, err := verifySolanaSig(pubkeyB58, sigB58, message); err != nil {
+		return "", err
+	}
+
+	return s.mintSessionToken(pubkeyB58)
+}
+
+// canonicalChallengeMessage is the exact string a wallet signs:
+// "friend-bets-login:<nonce>:<domain>".
+func (s *SessionAuthService) canonicalChallengeMessage(nonce string) string {
+	return fmt.Sprintf("%s:%s:%s", challengeMessagePrefix, nonce, s.domain)
+}
+
+// nonceFromChallengeMessage extracts the nonce from a canonicalChallengeMessage,
+// so VerifyChallenge doesn't require the caller to resend the nonce separately
+// from the signed message.
+func (s *SessionAuthService) nonceFromChallengeMessage(message string) (string, bool) {
+	parts := strings.SplitN(message, ":", 3)
+	if len(parts) != 3 || parts[0] != challengeMessagePrefix || parts[2] != s.domain {
+		return "", false
+	}
+	return parts[1], true
+}
+
+// sessionClaims is the JWT payload minted by mintSessionToken.
+type sessionClaims struct {
+	Sub string `json:"sub"`
+	IAT int64  `json:"iat"`
+	Exp int64  `json:"exp"`
+}
+
+// mintSessionToken signs a minimal HS256 JWT the same hand-rolled way
+// notify/push.go signs its provider JWTs, just HMAC instead of RSA/ECDSA since
+// this server is both issuer and verifier.
+func (s *SessionAuthService) mintSessionToken(pubkeyB58 string) (string, error) {
+	now := time.Now()
+	header := map[string]string{"alg": "HS256", "typ": "JWT"}
+	claims := sessionClaims{Sub: pubkeyB58, IAT: now.Unix(), Exp: now.Add(s.sessionTTL).Unix()}
+
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return "", err
+	}
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64urlEncodeGRPC(headerJSON) + "." + base64urlEncodeGRPC(claimsJSON)
+	return signingInput + "." + base64urlEncodeGRPC(s.sign(signingInput)), nil
+}
+
+// VerifySessionToken checks token's HMAC signature and expiry, returning its
+// "sub" claim (the base58 wallet pubkey) on success.
+func (s *SessionAuthService) VerifySessionToken(token string) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", fmt.Errorf("malformed session token")
+	}
+
+	signingInput := parts[0] + "." + parts[1]
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", fmt.Errorf("malformed session token signature")
+	}
+	if !hmac.Equal(sig, s.sign(signingInput)) {
+		return "", fmt.Errorf("invalid session token signature")
+	}
+
+	claimsJSON, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", fmt.Errorf("malformed session token claims")
+	}
+	var claims sessionClaims
+	if err := json.Unmarshal(claimsJSON, &claims); err != nil {
+		return "", fmt.Errorf("malformed session token claims")
+	}
+	if time.Now().Unix() > claims.Exp {
+		return "", fmt.Errorf("session token expired")
+	}
+
+	return claims.Sub, nil
+}
+
+func (s *SessionAuthService) sign(signingInput string) []byte {
+	mac := hmac.New(sha256.New, s.jwtSecret)
+	mac.Write([]byte(signingInput))
+	return mac.Sum(nil)
+}
+
+// validatePubkey reports whether pubkeyB58 decodes to a well-formed ed25519
+// public key.
+func validatePubkey(pubkeyB58 string) error {
+	decoded, err := base58.Decode(pubkeyB58)
+	if err != nil || len(decoded) != 32 {
+		return fmt.Errorf("invalid public key")
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package grpc
+
+import "sync"
+
+// singleflightGroup collapses concurrent callers sharing the same key into a
+// single execution: the first caller runs fn, and every other caller that
+// arrives before it finishes blocks on and receives the same result instead
+// of running fn itself. This closes the race IdempotencyInterceptor would
+// otherwise have: two requests carrying the same Idempotency-Key arriving
+// close enough together that neither sees the other's cached response yet
+// would both reach the underlying use case and create a duplicate
+// market/position.
+type singleflightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*singleflightCall
+}
+
+type singleflightCall struct {
+	done chan struct{}
+	val  interface{}
+	err  error
+}
+
+func newSingleflightGroup() *singleflightGroup {
+	return &singleflightGroup{calls: make(map[string]*singleflightCall)}
+}
+
+// Do executes fn for key, or waits for and returns an in-flight call's result
+// if one is already running.
+func (g *singleflightGroup) Do(key string, fn func() (interface{}, error)) (interface{}, error) {
+	g.mu.Lock()
+	if call, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		<-call.done
+		return call.val, call.err
+	}
+
+	call := &singleflightCall{done: make(chan struct{})}
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	call.val, call.err = fn()
+	close(call.done)
+
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+
+	return call.val, call.err
+}
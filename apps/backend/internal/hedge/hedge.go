@@ -0,0 +1,59 @@
+// Package hedge implements an optional cross-venue hedging worker for market creators:
+// when a hedge-enabled market's StakedA/StakedB imbalance crosses a configurable delta,
+// it opens or adjusts an offsetting perpetual futures position on an external exchange.
+// Exchange is the same abstraction pattern bbgo's types.Exchange uses, so a concrete
+// venue (Binance, OKX, ...) can be plugged in via an ExchangeFactory without the engine
+// itself depending on any particular venue's SDK; no concrete adapter ships here.
+package hedge
+
+import "context"
+
+// Side is the direction of a hedge order. A market over-weighted on side A means the
+// creator's pool is exposed to side A winning, so the offsetting position is a short;
+// an imbalance the other way calls for a long.
+type Side string
+
+const (
+	SideLong  Side = "long"
+	SideShort Side = "short"
+)
+
+// OrderRequest describes one hedge order to place on an external exchange.
+type OrderRequest struct {
+	Symbol string
+	Side   Side
+	// Notional is the USD-denominated size of the order.
+	Notional int64
+}
+
+// Position is an external exchange's current perpetual position for a symbol.
+type Position struct {
+	Symbol   string
+	Side     Side
+	Notional int64
+}
+
+// Exchange is the external venue abstraction a concrete adapter (Binance, OKX, ...)
+// implements, modeled on bbgo's types.Exchange so hedge logic never depends on a
+// specific venue's SDK.
+type Exchange interface {
+	// Name returns the exchange's identifier, matching CreatorExchangeKey.Exchange.
+	Name() string
+	// PlaceOrder opens or adjusts a position per req, returning the venue's order ID.
+	PlaceOrder(ctx context.Context, req OrderRequest) (orderID string, err error)
+	// GetPosition returns the account's current position for symbol.
+	GetPosition(ctx context.Context, symbol string) (Position, error)
+}
+
+// ExchangeFactory constructs an authenticated Exchange client from one creator's
+// decrypted API credentials, deferring the concrete venue's SDK and auth handshake to
+// the adapter registered under its exchange name.
+type ExchangeFactory func(apiKey, apiSecret string) (Exchange, error)
+
+// oppositeSide returns the other side of s.
+func oppositeSide(s Side) Side {
+	if s == SideLong {
+		return SideShort
+	}
+	return SideLong
+}
@@ -0,0 +1,123 @@
+package hedge
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/friend-bets/backend/internal/store"
+)
+
+// KeyStore persists per-creator exchange API credentials encrypted at rest with
+// AES-256-GCM; the decryption key lives only in process memory (derived from
+// config), never in the database alongside the ciphertext it protects.
+type KeyStore struct {
+	repo *store.Repository
+	key  []byte
+}
+
+// NewKeyStore creates a KeyStore from a hex-encoded 32-byte AES-256 key, failing at
+// construction so a misconfigured key is caught at startup rather than on first use.
+func NewKeyStore(repo *store.Repository, encryptionKeyHex string) (*KeyStore, error) {
+	key, err := hex.DecodeString(encryptionKeyHex)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode hedge encryption key: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("hedge encryption key must be 32 bytes (64 hex chars), got %d", len(key))
+	}
+	return &KeyStore{repo: repo, key: key}, nil
+}
+
+// SetCredentials encrypts and persists a creator's API key/secret for exchange.
+func (k *KeyStore) SetCredentials(creator, exchange, apiKey, apiSecret string) error {
+	encKey, err := encryptSecret(k.key, apiKey)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt api key: %w", err)
+	}
+	encSecret, err := encryptSecret(k.key, apiSecret)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt api secret: %w", err)
+	}
+	return k.repo.UpsertCreatorExchangeKey(&store.CreatorExchangeKey{
+		Creator:            creator,
+		Exchange:           exchange,
+		EncryptedAPIKey:    encKey,
+		EncryptedAPISecret: encSecret,
+	})
+}
+
+// Credentials loads and decrypts a creator's API key/secret for exchange.
+func (k *KeyStore) Credentials(creator, exchange string) (apiKey, apiSecret string, err error) {
+	row, err := k.repo.GetCreatorExchangeKey(creator, exchange)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to load exchange credentials: %w", err)
+	}
+
+	apiKey, err = decryptSecret(k.key, row.EncryptedAPIKey)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to decrypt api key: %w", err)
+	}
+	apiSecret, err = decryptSecret(k.key, row.EncryptedAPISecret)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to decrypt api secret: %w", err)
+	}
+	return apiKey, apiSecret, nil
+}
+
+// encryptSecret seals plaintext with AES-256-GCM under key, returning a base64 string
+// of nonce||ciphertext so it can be stored in a single column.
+func encryptSecret(key []byte, plaintext string) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// decryptSecret reverses encryptSecret.
+func decryptSecret(key []byte, encoded string) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode ciphertext: %w", err)
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonceSize := gcm.NonceSize()
+	if len(sealed) < nonceSize {
+		return "", fmt.Errorf("ciphertext too short")
+	}
+
+	nonce, ciphertext := sealed[:nonceSize], sealed[nonceSize:]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt ciphertext: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize GCM: %w", err)
+	}
+	return gcm, nil
+}
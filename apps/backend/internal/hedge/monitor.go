@@ -0,0 +1,142 @@
+package hedge
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/friend-bets/backend/internal/config"
+	"github.com/friend-bets/backend/internal/rate"
+	"github.com/friend-bets/backend/internal/store"
+)
+
+// Monitor watches the market event stream for hedge-enabled markets and keeps each
+// one's HedgePosition reconciled against its current StakedA/StakedB imbalance. It
+// doesn't hold a notify.Notifier itself, mirroring risk.Monitor: callers that want to
+// surface an adjustment broadcast it through their own notifier.
+type Monitor struct {
+	repo      *store.Repository
+	keys      *KeyStore
+	factories map[string]ExchangeFactory
+	limiter   *rate.Limiter
+	cfg       *config.HedgeConfig
+	logger    *slog.Logger
+}
+
+// NewMonitor creates a Monitor. factories maps an exchange name (as recorded on
+// Market.HedgeExchange) to the adapter that authenticates and places orders there;
+// an exchange with no registered factory is logged and skipped rather than erroring,
+// since a creator could reference a venue this deployment hasn't wired up yet.
+func NewMonitor(repo *store.Repository, keys *KeyStore, factories map[string]ExchangeFactory, limiter *rate.Limiter, cfg *config.HedgeConfig, logger *slog.Logger) *Monitor {
+	return &Monitor{repo: repo, keys: keys, factories: factories, limiter: limiter, cfg: cfg, logger: logger}
+}
+
+// Observe checks whether market's current imbalance requires adjusting its hedge
+// position and, if so, places the delta order and persists the result. It is a no-op
+// for markets that didn't opt into hedging at creation. It takes the store's
+// MarketView rather than core.Market so this package doesn't need to import core,
+// which would otherwise cycle back through core's hedgeMon field.
+func (m *Monitor) Observe(ctx context.Context, market *store.MarketView) error {
+	if !market.HedgeEnabled || market.HedgeExchange == "" || market.HedgeSymbol == "" {
+		return nil
+	}
+
+	total := market.StakedA + market.StakedB
+	if total == 0 {
+		return nil
+	}
+
+	net := int64(market.StakedA) - int64(market.StakedB)
+	deltaBps := int(absInt64(net) * 10000 / int64(total))
+	if deltaBps < m.cfg.DeltaThresholdBps {
+		return nil
+	}
+
+	targetNotional := absInt64(net)
+	if m.cfg.MaxNotionalPerMarket > 0 && uint64(targetNotional) > m.cfg.MaxNotionalPerMarket {
+		targetNotional = int64(m.cfg.MaxNotionalPerMarket)
+	}
+	side := SideShort
+	if net < 0 {
+		side = SideLong
+	}
+
+	pos, err := m.repo.GetOrCreateHedgePosition(market.ID, market.Creator, market.HedgeExchange, market.HedgeSymbol)
+	if err != nil {
+		return fmt.Errorf("failed to load hedge position: %w", err)
+	}
+	pos.TargetNotional = targetNotional
+
+	delta := targetNotional - pos.ActualNotional
+	if delta == 0 {
+		return nil
+	}
+
+	if !m.limiter.Allow(ctx, market.Creator, "hedge_order", time.Minute, m.cfg.OrdersPerMinute) {
+		m.logger.Warn("hedge order rate limited, deferring to next observation", "market_id", market.ID, "creator", market.Creator)
+		return nil
+	}
+
+	if err := m.adjust(ctx, market, pos, side, delta); err != nil {
+		pos.Covered = false
+		if saveErr := m.repo.UpdateHedgePosition(pos); saveErr != nil {
+			m.logger.Error("failed to mark hedge position uncovered", "error", saveErr, "market_id", market.ID)
+		}
+		return err
+	}
+
+	pos.ActualNotional = targetNotional
+	pos.Side = string(side)
+	pos.Covered = true
+	if err := m.repo.UpdateHedgePosition(pos); err != nil {
+		return fmt.Errorf("failed to persist hedge position: %w", err)
+	}
+
+	m.logger.Info("adjusted hedge position", "market_id", market.ID, "exchange", market.HedgeExchange,
+		"symbol", market.HedgeSymbol, "side", side, "notional", targetNotional)
+
+	return nil
+}
+
+// adjust places the order needed to move pos's actual notional by delta (signed: a
+// negative delta reduces exposure on side, i.e. trades the opposite direction), and
+// records the resulting order ID on pos.
+func (m *Monitor) adjust(ctx context.Context, market *store.MarketView, pos *store.HedgePosition, side Side, delta int64) error {
+	factory, ok := m.factories[market.HedgeExchange]
+	if !ok {
+		return fmt.Errorf("no exchange adapter registered for %q", market.HedgeExchange)
+	}
+
+	apiKey, apiSecret, err := m.keys.Credentials(market.Creator, market.HedgeExchange)
+	if err != nil {
+		return err
+	}
+
+	exchange, err := factory(apiKey, apiSecret)
+	if err != nil {
+		return fmt.Errorf("failed to construct %s exchange client: %w", market.HedgeExchange, err)
+	}
+
+	orderSide := side
+	notional := delta
+	if notional < 0 {
+		orderSide = oppositeSide(side)
+		notional = -notional
+	}
+
+	orderID, err := exchange.PlaceOrder(ctx, OrderRequest{Symbol: market.HedgeSymbol, Side: orderSide, Notional: notional})
+	if err != nil {
+		return fmt.Errorf("failed to place hedge order: %w", err)
+	}
+
+	pos.LastOrderID = orderID
+	return nil
+}
+
+func absInt64(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,97 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+)
+
+// ctxKey is an unexported type so values stashed by this package can never collide with
+// context keys set by unrelated code.
+type ctxKey int
+
+const (
+	requestIDKey ctxKey = iota
+	marketIDKey
+	walletKey
+)
+
+// WithRequestID returns a copy of ctx carrying id, picked up automatically by any
+// logger wrapped with NewContextHandler.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestID returns the request ID stashed in ctx by WithRequestID, if any.
+func RequestID(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey).(string)
+	return id, ok
+}
+
+// WithMarketID returns a copy of ctx carrying marketID.
+func WithMarketID(ctx context.Context, marketID string) context.Context {
+	return context.WithValue(ctx, marketIDKey, marketID)
+}
+
+// MarketID returns the market ID stashed in ctx by WithMarketID, if any.
+func MarketID(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(marketIDKey).(string)
+	return id, ok
+}
+
+// WithWallet returns a copy of ctx carrying a wallet public key (base58).
+func WithWallet(ctx context.Context, wallet string) context.Context {
+	return context.WithValue(ctx, walletKey, wallet)
+}
+
+// Wallet returns the wallet stashed in ctx by WithWallet, if any.
+func Wallet(ctx context.Context) (string, bool) {
+	w, ok := ctx.Value(walletKey).(string)
+	return w, ok
+}
+
+// ContextHandler wraps another slog.Handler, automatically attaching request_id,
+// market_id, and wallet attrs pulled from context.Context on every Handle call. This is
+// what lets request-scoped values attached by, say, the Connect logging interceptor
+// (WithRequestID) or EventIndexer (WithMarketID) show up on every log line for that
+// request/event without every call site having to pass them explicitly — as long as
+// callers use the *Context logging methods (InfoContext, ErrorContext, ...) so ctx
+// actually reaches Handle. Trace/span IDs can be attached the same way once tracing is
+// wired into this service.
+type ContextHandler struct {
+	next slog.Handler
+}
+
+// NewContextHandler wraps next so context-carried request/market/wallet values are
+// attached to every record it handles.
+func NewContextHandler(next slog.Handler) *ContextHandler {
+	return &ContextHandler{next: next}
+}
+
+// Enabled delegates to the wrapped handler.
+func (h *ContextHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle adds context-carried attrs to record and delegates to the wrapped handler.
+func (h *ContextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id, ok := RequestID(ctx); ok {
+		record.AddAttrs(slog.String("request_id", id))
+	}
+	if id, ok := MarketID(ctx); ok {
+		record.AddAttrs(slog.String("market_id", id))
+	}
+	if w, ok := Wallet(ctx); ok {
+		record.AddAttrs(slog.String("wallet", w))
+	}
+	return h.next.Handle(ctx, record)
+}
+
+// WithAttrs delegates to the wrapped handler, preserving the context-extraction wrapper.
+func (h *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ContextHandler{next: h.next.WithAttrs(attrs)}
+}
+
+// WithGroup delegates to the wrapped handler, preserving the context-extraction wrapper.
+func (h *ContextHandler) WithGroup(name string) slog.Handler {
+	return &ContextHandler{next: h.next.WithGroup(name)}
+}
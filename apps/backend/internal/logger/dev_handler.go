@@ -10,10 +10,16 @@ import (
 	"strings"
 )
 
-// DevHandler is a custom slog handler for development that provides cleaner output
+// DevHandler is a custom slog handler for development that provides cleaner, colorized
+// output than slog.TextHandler. Like the handlers in the standard library, a DevHandler
+// is immutable: WithAttrs and WithGroup return a new handler carrying the accumulated
+// attrs/group prefix rather than mutating the receiver, so a logger tree built with
+// logger.With(...)/logger.WithGroup(...) (e.g. per-request loggers) behaves correctly.
 type DevHandler struct {
-	w    io.Writer
-	opts *slog.HandlerOptions
+	w     io.Writer
+	opts  *slog.HandlerOptions
+	attrs []slog.Attr // accumulated via WithAttrs, already group-prefixed
+	group string      // dotted group prefix accumulated via WithGroup, e.g. "request.meta"
 }
 
 // NewDevHandler creates a new development handler
@@ -31,13 +37,9 @@ func (h *DevHandler) Enabled(ctx context.Context, level slog.Level) bool {
 
 // Handle handles the Record
 func (h *DevHandler) Handle(ctx context.Context, record slog.Record) error {
-	// Format time as HH:MM:SS
 	timeStr := record.Time.Format("15:04:05")
-	
-	// Get level with color
 	levelStr := h.formatLevel(record.Level)
-	
-	// Format source to show only filename
+
 	sourceStr := ""
 	if h.opts.AddSource && record.PC != 0 {
 		frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
@@ -45,39 +47,65 @@ func (h *DevHandler) Handle(ctx context.Context, record slog.Record) error {
 			sourceStr = fmt.Sprintf(" %s:%d", filepath.Base(frame.File), frame.Line)
 		}
 	}
-	
-	// Build the main message
+
 	msg := fmt.Sprintf("%s %s %s%s", timeStr, levelStr, record.Message, sourceStr)
-	
-	// Add attributes
-	attrs := make([]string, 0)
-	record.Attrs(func(attr slog.Attr) bool {
+
+	attrs := make([]string, 0, len(h.attrs)+record.NumAttrs())
+	for _, attr := range h.attrs {
 		attrs = append(attrs, h.formatAttr(attr))
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		attrs = append(attrs, h.formatAttr(h.prefixAttr(attr)))
 		return true
 	})
-	
+
 	if len(attrs) > 0 {
 		msg += " " + strings.Join(attrs, " ")
 	}
-	
+
 	msg += "\n"
-	
+
 	_, err := h.w.Write([]byte(msg))
 	return err
 }
 
-// WithAttrs returns a new Handler whose attributes consist of both the receiver's attributes and the arguments
+// WithAttrs returns a new Handler whose attributes consist of both the receiver's
+// attributes and the arguments, each prefixed by the receiver's current group.
 func (h *DevHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
-	// For simplicity, we'll just return the same handler
-	// In a full implementation, you'd want to store these attrs
-	return h
+	if len(attrs) == 0 {
+		return h
+	}
+	next := *h
+	next.attrs = make([]slog.Attr, len(h.attrs), len(h.attrs)+len(attrs))
+	copy(next.attrs, h.attrs)
+	for _, attr := range attrs {
+		next.attrs = append(next.attrs, h.prefixAttr(attr))
+	}
+	return &next
 }
 
-// WithGroup returns a new Handler with the given group appended to the receiver's existing groups
+// WithGroup returns a new Handler with the given group appended to the receiver's
+// existing groups; subsequent attrs (from WithAttrs or the record itself) are prefixed
+// with "group.".
 func (h *DevHandler) WithGroup(name string) slog.Handler {
-	// For simplicity, we'll just return the same handler
-	// In a full implementation, you'd want to handle groups
-	return h
+	if name == "" {
+		return h
+	}
+	next := *h
+	if h.group == "" {
+		next.group = name
+	} else {
+		next.group = h.group + "." + name
+	}
+	return &next
+}
+
+// prefixAttr qualifies attr's key with the handler's current group, if any.
+func (h *DevHandler) prefixAttr(attr slog.Attr) slog.Attr {
+	if h.group == "" || attr.Key == "" {
+		return attr
+	}
+	return slog.Attr{Key: h.group + "." + attr.Key, Value: attr.Value}
 }
 
 // formatLevel formats the log level with color for terminal output
@@ -86,9 +114,9 @@ func (h *DevHandler) formatLevel(level slog.Level) string {
 	case slog.LevelDebug:
 		return "\033[36mDEBUG\033[0m" // Cyan
 	case slog.LevelInfo:
-		return "\033[32mINFO\033[0m"  // Green
+		return "\033[32mINFO\033[0m" // Green
 	case slog.LevelWarn:
-		return "\033[33mWARN\033[0m"  // Yellow
+		return "\033[33mWARN\033[0m" // Yellow
 	case slog.LevelError:
 		return "\033[31mERROR\033[0m" // Red
 	default:
@@ -96,15 +124,15 @@ func (h *DevHandler) formatLevel(level slog.Level) string {
 	}
 }
 
-// formatAttr formats a single attribute
+// formatAttr formats a single attribute, resolving LogValuers (e.g. core.MarketEvent)
+// before rendering so structured values stay compact in dev output.
 func (h *DevHandler) formatAttr(attr slog.Attr) string {
 	if attr.Key == "" {
 		return ""
 	}
-	
-	value := attr.Value.String()
-	
-	// Special formatting for certain keys
+
+	value := attr.Value.Resolve().String()
+
 	switch attr.Key {
 	case "error":
 		return fmt.Sprintf("\033[31m%s\033[0m=%s", attr.Key, value) // Red key for errors
@@ -115,4 +143,4 @@ func (h *DevHandler) formatAttr(attr slog.Attr) string {
 	default:
 		return fmt.Sprintf("%s=%s", attr.Key, value)
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,20 @@
+package logger
+
+import (
+	"io"
+	"log/slog"
+)
+
+// NewHandler builds the base slog.Handler for format ("dev" for colorized
+// human-readable output, anything else including "json" or "" for structured JSON
+// suitable for shipping to Loki/ELK), wrapped in a ContextHandler so request-scoped
+// values (see WithRequestID/WithMarketID/WithWallet) are attached automatically.
+func NewHandler(format string, w io.Writer, opts *slog.HandlerOptions) slog.Handler {
+	var base slog.Handler
+	if format == "dev" {
+		base = NewDevHandler(w, opts)
+	} else {
+		base = slog.NewJSONHandler(w, opts)
+	}
+	return NewContextHandler(base)
+}
@@ -0,0 +1,308 @@
+// Package mm implements a built-in liquidity-provider / market-maker bot subsystem
+// that bootstraps thin liquidity on open parimutuel markets by keeping each side's
+// stake within a configured target imbalance ratio.
+package mm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/blocto/solana-go-sdk/types"
+	"github.com/friend-bets/backend/internal/config"
+	"github.com/friend-bets/backend/internal/core"
+	"github.com/friend-bets/backend/internal/solana"
+	"github.com/friend-bets/backend/internal/store"
+)
+
+// Engine runs configured market-maker bots against open markets on a fixed cadence
+type Engine struct {
+	config       *config.MMConfig
+	useCases     *core.UseCases
+	solanaClient *solana.AnchorClient
+	repo         *store.Repository
+	operator     types.Account
+	logger       *slog.Logger
+
+	bots []*bot
+
+	mu      sync.RWMutex
+	running bool
+	stopCh  chan struct{}
+	doneCh  chan struct{}
+}
+
+// bot is the runtime state for one configured MMBotConfig
+type bot struct {
+	cfg        config.MMBotConfig
+	titleRegex *regexp.Regexp
+	lastRun    time.Time
+	lastError  error
+}
+
+// NewEngine creates a market-maker engine. It loads the operator keypair eagerly so
+// misconfiguration fails at startup rather than on the first tick.
+func NewEngine(cfg *config.MMConfig, useCases *core.UseCases, solanaClient *solana.AnchorClient, repo *store.Repository, logger *slog.Logger) (*Engine, error) {
+	operator, err := loadOperatorKeypair(cfg.OperatorKeypairPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load mm operator keypair: %w", err)
+	}
+
+	bots := make([]*bot, 0, len(cfg.Bots))
+	for _, botCfg := range cfg.Bots {
+		var re *regexp.Regexp
+		if botCfg.TitleFilter != "" {
+			re, err = regexp.Compile(botCfg.TitleFilter)
+			if err != nil {
+				return nil, fmt.Errorf("bot %s: invalid title filter: %w", botCfg.ID, err)
+			}
+		}
+		bots = append(bots, &bot{cfg: botCfg, titleRegex: re})
+	}
+
+	return &Engine{
+		config:       cfg,
+		useCases:     useCases,
+		solanaClient: solanaClient,
+		repo:         repo,
+		operator:     operator,
+		bots:         bots,
+		logger:       logger,
+		stopCh:       make(chan struct{}),
+		doneCh:       make(chan struct{}),
+	}, nil
+}
+
+// loadOperatorKeypair reads a Solana CLI-format keypair file (a JSON array of 64 bytes)
+func loadOperatorKeypair(path string) (types.Account, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return types.Account{}, fmt.Errorf("failed to read keypair file: %w", err)
+	}
+
+	var raw []byte
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return types.Account{}, fmt.Errorf("failed to parse keypair file: %w", err)
+	}
+
+	account, err := types.AccountFromBytes(raw)
+	if err != nil {
+		return types.Account{}, fmt.Errorf("failed to derive account from keypair bytes: %w", err)
+	}
+
+	return account, nil
+}
+
+// Start begins polling markets on each bot's refill cadence until Stop is called
+func (e *Engine) Start(ctx context.Context) error {
+	e.mu.Lock()
+	if e.running {
+		e.mu.Unlock()
+		return fmt.Errorf("mm engine already running")
+	}
+	e.running = true
+	e.mu.Unlock()
+
+	e.logger.Info("starting market-maker engine", "bot_count", len(e.bots))
+
+	go e.run(ctx)
+
+	return nil
+}
+
+// Stop signals the engine to flush its state and stop, blocking until it does
+func (e *Engine) Stop(ctx context.Context) error {
+	e.mu.Lock()
+	if !e.running {
+		e.mu.Unlock()
+		return nil
+	}
+	e.running = false
+	e.mu.Unlock()
+
+	close(e.stopCh)
+
+	select {
+	case <-e.doneCh:
+	case <-ctx.Done():
+		e.logger.Warn("mm engine stop timed out before bots drained")
+		return ctx.Err()
+	}
+
+	return nil
+}
+
+func (e *Engine) run(ctx context.Context) {
+	defer close(e.doneCh)
+
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-e.stopCh:
+			return
+		case <-ticker.C:
+			for _, b := range e.bots {
+				interval := time.Duration(b.cfg.RefillIntervalSec) * time.Second
+				if interval <= 0 || time.Since(b.lastRun) < interval {
+					continue
+				}
+				b.lastRun = time.Now()
+				if err := e.tick(ctx, b); err != nil {
+					b.lastError = err
+					e.logger.Error("mm bot tick failed", "bot_id", b.cfg.ID, "error", err)
+				} else {
+					b.lastError = nil
+				}
+			}
+		}
+	}
+}
+
+// tick evaluates one bot's strategy against every open market it matches and tops
+// up the deficit side when the imbalance drifts outside the configured target range
+func (e *Engine) tick(ctx context.Context, b *bot) error {
+	markets, err := e.useCases.ListMarkets(ctx, "", core.MarketStatusOpen, 200, 0)
+	if err != nil {
+		return fmt.Errorf("failed to list markets: %w", err)
+	}
+
+	for _, market := range markets {
+		if !b.matches(market) {
+			continue
+		}
+
+		if err := e.refillMarket(ctx, b, market); err != nil {
+			e.logger.Error("failed to refill market", "bot_id", b.cfg.ID, "market_id", market.ID, "error", err)
+		}
+	}
+
+	return nil
+}
+
+func (b *bot) matches(market *core.Market) bool {
+	if b.titleRegex != nil && !b.titleRegex.MatchString(market.Title) {
+		return false
+	}
+	if b.cfg.Mint != "" && b.cfg.Mint != market.Mint {
+		return false
+	}
+	if b.cfg.MinEndTsSec > 0 && market.EndTs.Unix() < b.cfg.MinEndTsSec {
+		return false
+	}
+	if b.cfg.MaxEndTsSec > 0 && market.EndTs.Unix() > b.cfg.MaxEndTsSec {
+		return false
+	}
+	return true
+}
+
+// refillMarket tops up the deficit side of a market if its imbalance has drifted
+// outside [TargetImbalanceLo, TargetImbalanceHi], capped at the bot's max exposure
+func (e *Engine) refillMarket(ctx context.Context, b *bot, market *core.Market) error {
+	total := market.StakedA + market.StakedB
+	imbalance := 0.5
+	if total > 0 {
+		imbalance = float64(market.StakedA) / float64(total)
+	}
+
+	if imbalance >= b.cfg.TargetImbalanceLo && imbalance <= b.cfg.TargetImbalanceHi {
+		return nil
+	}
+
+	side := core.BetSideA
+	if imbalance < b.cfg.TargetImbalanceLo {
+		// A is underweight, bet on A to bring the ratio up
+		side = core.BetSideA
+	} else {
+		side = core.BetSideB
+	}
+
+	deficit := deficitAmount(market.StakedA, market.StakedB, b.cfg.TargetImbalanceLo, b.cfg.TargetImbalanceHi, side)
+	if deficit == 0 {
+		return nil
+	}
+
+	state, err := e.repo.GetOrCreateMMBotState(b.cfg.ID, market.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load bot state: %w", err)
+	}
+
+	available := uint64(0)
+	if b.cfg.MaxExposure > state.CumulativeExposure {
+		available = b.cfg.MaxExposure - state.CumulativeExposure
+	}
+	if available == 0 {
+		return nil
+	}
+	if deficit > available {
+		deficit = available
+	}
+
+	req := &core.PlaceBetRequest{
+		MarketID: market.ID,
+		Owner:    e.operator.PublicKey.ToBase58(),
+		Side:     side,
+		Amount:   deficit,
+	}
+
+	signature, err := e.solanaClient.SubmitPlaceBet(ctx, req, e.operator)
+	if err != nil {
+		return fmt.Errorf("failed to submit mm bet: %w", err)
+	}
+
+	state.CumulativeExposure += deficit
+	state.LastRefillAt = time.Now()
+	if err := e.repo.UpdateMMBotState(state); err != nil {
+		return fmt.Errorf("failed to persist bot state: %w", err)
+	}
+
+	e.logger.Info("mm bot placed refill bet", "bot_id", b.cfg.ID, "market_id", market.ID, "side", side, "amount", deficit, "signature", signature)
+
+	return nil
+}
+
+// deficitAmount computes how much stake must be added to the given side to bring
+// the market back to the midpoint of [lo, hi]
+func deficitAmount(stakedA, stakedB uint64, lo, hi float64, side string) uint64 {
+	target := (lo + hi) / 2
+	total := float64(stakedA + stakedB)
+
+	if side == core.BetSideA {
+		// want stakedA+x = target*(total+x)  =>  x = (target*total - stakedA) / (1 - target)
+		if target >= 1 {
+			return 0
+		}
+		x := (target*total - float64(stakedA)) / (1 - target)
+		if x <= 0 {
+			return 0
+		}
+		return uint64(x)
+	}
+
+	if target <= 0 {
+		return 0
+	}
+	x := ((1-target)*total - float64(stakedB)) / target
+	if x <= 0 {
+		return 0
+	}
+	return uint64(x)
+}
+
+// Health reports an error if any bot's last tick failed, surfacing stalled bots
+func (e *Engine) Health() error {
+	for _, b := range e.bots {
+		if b.lastError != nil {
+			return fmt.Errorf("bot %s stalled: %w", b.cfg.ID, b.lastError)
+		}
+	}
+	return nil
+}
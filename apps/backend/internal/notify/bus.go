@@ -0,0 +1,61 @@
+package notify
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// BusHandler receives every Event published to a Bus that it's subscribed to.
+// Handlers run synchronously on the publishing goroutine, so they should hand
+// off anything slow to their own queue (Notifier's own subscriber does this by
+// delegating to the existing DeliveryQueue/Digester).
+type BusHandler func(ctx context.Context, event Event)
+
+// Bus is an in-process typed pub/sub that decouples where market/bet lifecycle
+// events are produced (today, internal/grpc's service handlers and
+// internal/scheduler) from what reacts to them. Notifier subscribes its own
+// subscription fanout and, if NotifyConfig.Webhooks is set, outbound webhook
+// dispatch; a future websocket broadcaster or audit logger could subscribe the
+// same way without the producers changing at all.
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[string][]BusHandler
+
+	logger *slog.Logger
+}
+
+// NewBus returns an empty Bus. logger may be nil.
+func NewBus(logger *slog.Logger) *Bus {
+	return &Bus{handlers: make(map[string][]BusHandler), logger: logger}
+}
+
+// Subscribe registers handler to run for every future Publish of an event
+// whose eventType matches eventType (e.g. "market_created").
+func (b *Bus) Subscribe(eventType string, handler BusHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[eventType] = append(b.handlers[eventType], handler)
+}
+
+// Publish runs every handler subscribed to event's type, synchronously and in
+// registration order. A handler that panics is recovered and logged so one
+// misbehaving subscriber can't take down the call site that published.
+func (b *Bus) Publish(ctx context.Context, event Event) {
+	b.mu.RLock()
+	handlers := append([]BusHandler(nil), b.handlers[event.eventType()]...)
+	b.mu.RUnlock()
+
+	for _, h := range handlers {
+		b.dispatch(ctx, h, event)
+	}
+}
+
+func (b *Bus) dispatch(ctx context.Context, handler BusHandler, event Event) {
+	defer func() {
+		if r := recover(); r != nil && b.logger != nil {
+			b.logger.Error("notification bus handler panicked", "panic", r, "event_type", event.eventType())
+		}
+	}()
+	handler(ctx, event)
+}
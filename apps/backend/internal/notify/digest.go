@@ -0,0 +1,126 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DigestEntry is one event buffered for a user's next digest email.
+type DigestEntry struct {
+	EventType  string
+	Subject    string
+	Body       string
+	OccurredAt time.Time
+}
+
+// digestBucket accumulates one user's buffered entries for a given batch window,
+// alongside the email address to send the combined digest to.
+type digestBucket struct {
+	email   string
+	entries []DigestEntry
+}
+
+// Digester buffers notifications for users whose notification_preferences row
+// asks for an hourly or daily digest instead of immediate delivery, and flushes
+// each user's buffer as a single combined email at the scheduled cadence. Unlike
+// DeliveryQueue, buffered entries live in memory only — a crash before the next
+// flush loses them, which is an acceptable tradeoff for a digest (the underlying
+// events are still visible in-app; only the email summary is missed).
+type Digester struct {
+	mu      sync.Mutex
+	buckets map[string]map[string]*digestBucket // window -> userID -> bucket
+
+	notifier *Notifier
+	logger   *slog.Logger
+}
+
+// NewDigester returns a Digester that renders and sends digest emails through n.
+func NewDigester(n *Notifier, logger *slog.Logger) *Digester {
+	return &Digester{buckets: make(map[string]map[string]*digestBucket), notifier: n, logger: logger}
+}
+
+// Buffer appends entry to userID's bucket for window ("hourly" or "daily"),
+// stamping it with the current time.
+func (d *Digester) Buffer(userID, email, window string, entry DigestEntry) {
+	entry.OccurredAt = time.Now()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	byUser, ok := d.buckets[window]
+	if !ok {
+		byUser = make(map[string]*digestBucket)
+		d.buckets[window] = byUser
+	}
+	bucket, ok := byUser[userID]
+	if !ok {
+		bucket = &digestBucket{}
+		byUser[userID] = bucket
+	}
+	if email != "" {
+		bucket.email = email
+	}
+	bucket.entries = append(bucket.entries, entry)
+}
+
+// Flush sends one combined digest email per user with entries buffered under
+// window since the last flush, then clears window's buffer. Intended to be
+// called from a scheduler cron job at window's cadence (e.g. hourly, daily).
+func (d *Digester) Flush(ctx context.Context, window string) error {
+	d.mu.Lock()
+	byUser := d.buckets[window]
+	delete(d.buckets, window)
+	d.mu.Unlock()
+
+	var lastErr error
+	for userID, bucket := range byUser {
+		if bucket.email == "" || len(bucket.entries) == 0 {
+			continue
+		}
+
+		template := d.notifier.buildTemplate("digest", map[string]interface{}{
+			"Window":  window,
+			"Entries": bucket.entries,
+		}, func() *NotificationTemplate {
+			return defaultDigestTemplate(window, bucket.entries)
+		})
+
+		payload, err := marshalPayload(&EmailNotification{
+			UserID:  userID,
+			To:      bucket.email,
+			Subject: template.Subject,
+			Body:    template.HTMLBody,
+			IsHTML:  true,
+		})
+		if err != nil {
+			d.logger.Error("failed to marshal digest email payload", "error", err, "user_id", userID)
+			lastErr = err
+			continue
+		}
+		if err := d.notifier.Enqueue(ctx, "email", userID, payload); err != nil {
+			d.logger.Error("failed to enqueue digest email", "error", err, "user_id", userID)
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+// defaultDigestTemplate is the built-in digest content used when no
+// digest.html.tmpl is configured in NotifyConfig.TemplateDir.
+func defaultDigestTemplate(window string, entries []DigestEntry) *NotificationTemplate {
+	var html strings.Builder
+	html.WriteString("<html><body><h1>Your notification digest</h1><ul>")
+	for _, e := range entries {
+		html.WriteString(fmt.Sprintf("<li>%s</li>", e.Subject))
+	}
+	html.WriteString("</ul></body></html>")
+
+	return &NotificationTemplate{
+		Subject:  fmt.Sprintf("Your %s digest (%d updates)", window, len(entries)),
+		HTMLBody: html.String(),
+	}
+}
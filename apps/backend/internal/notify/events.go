@@ -0,0 +1,67 @@
+package notify
+
+import (
+	"time"
+
+	"github.com/friend-bets/backend/internal/store"
+)
+
+// EventMask is a bitset selecting which event types a
+// store.NotificationSubscription receives; bits are OR'd together and stored
+// in its EventMask column. EventMaskAll (every bit set, the column's default)
+// keeps existing subscriptions receiving everything until narrowed.
+type EventMask int32
+
+const (
+	EventMaskMarketCreated EventMask = 1 << iota
+	EventMaskBetPlaced
+	EventMaskMarketResolved
+	EventMaskMarketExpiring
+)
+
+// EventMaskAll matches every event type notify currently sends.
+const EventMaskAll = EventMaskMarketCreated | EventMaskBetPlaced | EventMaskMarketResolved | EventMaskMarketExpiring
+
+// eventTypeMask maps the eventType strings already used throughout notify.go
+// (ShouldDeliver, sendNotifications, ...) to their EventMask bit.
+var eventTypeMask = map[string]EventMask{
+	"market_created":  EventMaskMarketCreated,
+	"bet_placed":      EventMaskBetPlaced,
+	"market_resolved": EventMaskMarketResolved,
+	"market_expiring": EventMaskMarketExpiring,
+}
+
+// Event is implemented by every typed event a Notifier publishes to its Bus.
+// Handlers type-switch on the concrete type to read its fields.
+type Event interface {
+	eventType() string
+}
+
+// MarketCreatedEvent is published when a market is created.
+type MarketCreatedEvent struct {
+	Market *store.MarketView
+}
+
+func (MarketCreatedEvent) eventType() string { return "market_created" }
+
+// BetPlacedEvent is published when a bet is placed.
+type BetPlacedEvent struct {
+	Position *store.PositionView
+}
+
+func (BetPlacedEvent) eventType() string { return "bet_placed" }
+
+// MarketResolvedEvent is published when a market is resolved.
+type MarketResolvedEvent struct {
+	Market *store.MarketView
+}
+
+func (MarketResolvedEvent) eventType() string { return "market_resolved" }
+
+// MarketExpiringEvent is published when a market is about to expire.
+type MarketExpiringEvent struct {
+	Market          *store.MarketView
+	TimeUntilExpiry time.Duration
+}
+
+func (MarketExpiringEvent) eventType() string { return "market_expiring" }
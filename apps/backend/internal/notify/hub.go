@@ -0,0 +1,233 @@
+package notify
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Notification is a typed event published through the Hub. MarketID, UserID, and
+// EventType are matched against subscriber filters; an empty filter field on a
+// subscriber matches any value for that field.
+type Notification struct {
+	MarketID    string
+	UserID      string
+	EventType   string
+	TxSignature string
+	// Seq is the EventLog row ID the event was persisted as, if any; 0 for
+	// notifications with no durable row (e.g. a synthetic reorg notice). WatchEvents
+	// uses it as a client-resumable stream cursor.
+	Seq       uint64
+	Data      map[string]interface{}
+	Timestamp time.Time
+}
+
+// SubscriberID identifies an active Hub subscription
+type SubscriberID string
+
+// LaggingEventType marks a synthetic Notification the Hub sends a subscriber once it
+// falls behind (see Hub.markLagging): the subscriber's consumer should stop trusting
+// live delivery, replay from its own last-seen Seq (e.g. via FilterRegistry.GetEventsSince),
+// and call Hub.ClearLagging once it has caught up.
+const LaggingEventType = "lagging"
+
+// lagTimeout is how long Publish will wait for a subscriber's channel to free up
+// before declaring it lagging, instead of dropping the notification outright.
+const lagTimeout = 50 * time.Millisecond
+
+// subscriber holds one subscriber's filter and delivery channel
+type subscriber struct {
+	marketID  string
+	userID    string
+	eventType string
+	ch        chan Notification
+
+	mu            sync.Mutex
+	lagging       bool
+	lagNoticeSent bool
+}
+
+func (s *subscriber) matches(n Notification) bool {
+	if s.marketID != "" && s.marketID != n.MarketID {
+		return false
+	}
+	if s.userID != "" && s.userID != n.UserID {
+		return false
+	}
+	if s.eventType != "" && s.eventType != n.EventType {
+		return false
+	}
+	return true
+}
+
+// Hub is an in-process pub/sub broadcast bus for market notifications, keyed by
+// (marketID, userID, eventType). Each subscriber gets its own buffered channel; a
+// slow consumer has notifications dropped rather than blocking the publisher.
+type Hub struct {
+	mu     sync.RWMutex
+	subs   map[SubscriberID]*subscriber
+	nextID uint64
+	logger *slog.Logger
+}
+
+// NewHub creates a new notification broadcast hub
+func NewHub(logger *slog.Logger) *Hub {
+	return &Hub{
+		subs:   make(map[SubscriberID]*subscriber),
+		logger: logger,
+	}
+}
+
+// Subscribe registers a new subscriber filtered by marketID, userID, and eventType
+// (empty string means "any"). bufferSize sizes the subscriber's delivery channel.
+func (h *Hub) Subscribe(marketID, userID, eventType string, bufferSize int) (SubscriberID, <-chan Notification) {
+	if bufferSize <= 0 {
+		bufferSize = 32
+	}
+
+	id := SubscriberID(fmt.Sprintf("sub_%d", atomic.AddUint64(&h.nextID, 1)))
+	sub := &subscriber{
+		marketID:  marketID,
+		userID:    userID,
+		eventType: eventType,
+		ch:        make(chan Notification, bufferSize),
+	}
+
+	h.mu.Lock()
+	h.subs[id] = sub
+	h.mu.Unlock()
+
+	return id, sub.ch
+}
+
+// Unsubscribe removes a subscriber and closes its channel
+func (h *Hub) Unsubscribe(id SubscriberID) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if sub, ok := h.subs[id]; ok {
+		delete(h.subs, id)
+		close(sub.ch)
+	}
+}
+
+// Publish delivers a notification to every matching subscriber. A subscriber whose
+// channel doesn't free up within lagTimeout is marked lagging (see deliver) rather
+// than blocking the publisher indefinitely.
+func (h *Hub) Publish(n Notification) {
+	if n.Timestamp.IsZero() {
+		n.Timestamp = time.Now()
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for id, sub := range h.subs {
+		if !sub.matches(n) {
+			continue
+		}
+		h.deliver(id, sub, n)
+	}
+}
+
+// deliver sends n to sub, giving a healthy subscriber up to lagTimeout to make room
+// before declaring it lagging. Once a subscriber is lagging, further notifications are
+// best-effort only (non-blocking) so one slow consumer can't stall delivery to everyone
+// else; every such attempt also retries the LaggingEventType sentinel until it's been
+// placed at least once, since the channel may still have been full the moment the
+// subscriber was first marked. The consumer is expected to notice that sentinel,
+// replay from its own last-seen cursor, and call ClearLagging.
+func (h *Hub) deliver(id SubscriberID, sub *subscriber, n Notification) {
+	sub.mu.Lock()
+	lagging := sub.lagging
+	sub.mu.Unlock()
+
+	if lagging {
+		h.sendLagNotice(sub)
+		select {
+		case sub.ch <- n:
+		default:
+		}
+		return
+	}
+
+	select {
+	case sub.ch <- n:
+	case <-time.After(lagTimeout):
+		h.markLagging(id, sub)
+	}
+}
+
+// markLagging flags sub as lagging and makes a first attempt to deliver the
+// LaggingEventType sentinel so its consumer knows to switch to catch-up mode.
+func (h *Hub) markLagging(id SubscriberID, sub *subscriber) {
+	sub.mu.Lock()
+	alreadyLagging := sub.lagging
+	sub.lagging = true
+	sub.lagNoticeSent = false
+	sub.mu.Unlock()
+
+	if alreadyLagging {
+		return
+	}
+
+	h.logger.Warn("notification hub subscriber lagging, switching to catch-up mode", "subscriber_id", id)
+	h.sendLagNotice(sub)
+}
+
+// sendLagNotice makes a non-blocking attempt to place the LaggingEventType sentinel on
+// sub's channel, a no-op once one has already been placed since markLagging.
+func (h *Hub) sendLagNotice(sub *subscriber) {
+	sub.mu.Lock()
+	sent := sub.lagNoticeSent
+	sub.mu.Unlock()
+	if sent {
+		return
+	}
+
+	select {
+	case sub.ch <- Notification{EventType: LaggingEventType, Timestamp: time.Now()}:
+		sub.mu.Lock()
+		sub.lagNoticeSent = true
+		sub.mu.Unlock()
+	default:
+		// Channel still full; the next lagging delivery attempt retries.
+	}
+}
+
+// ClearLagging resets a subscriber's lagging flag once its consumer has replayed past
+// the gap (see LaggingEventType), resuming normal blocking-with-timeout delivery.
+func (h *Hub) ClearLagging(id SubscriberID) {
+	h.mu.RLock()
+	sub, ok := h.subs[id]
+	h.mu.RUnlock()
+	if !ok {
+		return
+	}
+
+	sub.mu.Lock()
+	sub.lagging = false
+	sub.lagNoticeSent = false
+	sub.mu.Unlock()
+}
+
+// Close unsubscribes every subscriber and closes their channels, unblocking any
+// readers so callers draining on shutdown don't leak goroutines.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for id, sub := range h.subs {
+		close(sub.ch)
+		delete(h.subs, id)
+	}
+}
+
+// SubscriberCount returns the number of active subscribers, for metrics/health checks
+func (h *Hub) SubscriberCount() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.subs)
+}
@@ -0,0 +1,32 @@
+package notify
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// RegisterMetrics registers the delivery queue's Prometheus collectors with reg.
+// Safe to call at most once per Notifier. Until called, sent/failed/retried counts
+// are simply not recorded (DeliveryQueue checks for a nil metrics before use).
+func (n *Notifier) RegisterMetrics(reg prometheus.Registerer) error {
+	m := &deliveryQueueMetrics{
+		sent: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "friendbets_notification_delivery_sent_total",
+			Help: "Total notification deliveries sent successfully, labeled by provider.",
+		}, []string{"provider"}),
+		failed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "friendbets_notification_delivery_failed_total",
+			Help: "Total notification deliveries that exhausted their retry budget and were marked dead, labeled by provider.",
+		}, []string{"provider"}),
+		retried: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "friendbets_notification_delivery_retried_total",
+			Help: "Total notification delivery attempts that failed and were scheduled for retry, labeled by provider.",
+		}, []string{"provider"}),
+	}
+
+	for _, c := range []prometheus.Collector{m.sent, m.failed, m.retried} {
+		if err := reg.Register(c); err != nil {
+			return err
+		}
+	}
+
+	n.queue.metrics = m
+	return nil
+}
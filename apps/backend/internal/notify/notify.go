@@ -11,7 +11,6 @@ import (
 	"time"
 
 	"github.com/friend-bets/backend/internal/config"
-	"github.com/friend-bets/backend/internal/core"
 	"github.com/friend-bets/backend/internal/store"
 )
 
@@ -20,15 +19,117 @@ type Notifier struct {
 	config *config.NotifyConfig
 	repo   *store.Repository
 	logger *slog.Logger
+	hub    *Hub
+	queue  *DeliveryQueue
+
+	// bus is published to at the top of every NotifyMarketCreated and friends
+	// call, so other subsystems (webhook dispatch below, or a future
+	// websocket broadcaster/audit logger) can react to the same events
+	// without their own call sites changing. See Bus.
+	bus *Bus
+
+	// templates is non-nil when cfg.TemplateDir is set; NotifyMarketCreated and
+	// friends prefer Render through it and only fall back to the hard-coded
+	// generate* content below when no matching template file is found.
+	templates *TemplateRegistry
+
+	// digester buffers notifications for users whose notification_preferences
+	// row asks for an hourly/daily digest instead of immediate delivery; see
+	// ShouldDeliver and FlushDigest.
+	digester *Digester
 }
 
-// NewNotifier creates a new notifier instance
+// NewNotifier creates a new notifier instance. It starts cfg.Queue.WorkerCount
+// DeliveryQueue workers and requeues any delivery a previous process left pending,
+// so notifications sent before a restart aren't lost.
 func NewNotifier(cfg *config.NotifyConfig, repo *store.Repository, logger *slog.Logger) *Notifier {
-	return &Notifier{
+	n := &Notifier{
 		config: cfg,
 		repo:   repo,
 		logger: logger,
+		hub:    NewHub(logger),
+		bus:    NewBus(logger),
 	}
+	if cfg.TemplateDir != "" {
+		n.templates = NewTemplateRegistry(cfg.TemplateDir, logger)
+	}
+	n.digester = NewDigester(n, logger)
+	registerWebhookDispatch(n.bus, cfg.Webhooks, logger)
+
+	send := map[string]func(ctx context.Context, payload []byte) error{
+		"email":    n.dispatchEmail,
+		"web_push": n.dispatchWebPush,
+		"fcm":      n.dispatchFCM,
+		"apns":     n.dispatchAPNs,
+	}
+	ratePerMin := map[string]int{
+		"email":    cfg.RatePerMinute.Email,
+		"web_push": cfg.RatePerMinute.WebPush,
+		"fcm":      cfg.RatePerMinute.FCM,
+		"apns":     cfg.RatePerMinute.APNs,
+	}
+
+	n.queue = newDeliveryQueue(
+		repo, logger,
+		cfg.Queue.WorkerCount, cfg.Queue.BufferSize, cfg.Queue.MaxAttempts,
+		time.Duration(cfg.Queue.InitialBackoffSec)*time.Second, time.Duration(cfg.Queue.MaxBackoffSec)*time.Second,
+		ratePerMin, send,
+	)
+	n.queue.loadPending(cfg.Queue.BufferSize)
+
+	return n
+}
+
+// Enqueue hands a provider-specific, pre-marshaled payload to the delivery queue
+// for async dispatch. Exposed so callers that already have a serialized payload
+// (e.g. a future push-subscription flow) can bypass the email/web-push-specific
+// Notify* helpers and still get queueing, retries, and dead-lettering for free.
+func (n *Notifier) Enqueue(ctx context.Context, provider, userID string, payload []byte) error {
+	return n.queue.enqueue(ctx, provider, userID, payload)
+}
+
+// Shutdown stops the delivery queue from accepting new sends and waits for
+// in-flight and already-buffered deliveries to drain, up to ctx's deadline.
+func (n *Notifier) Shutdown(ctx context.Context) error {
+	return n.queue.shutdown(ctx)
+}
+
+// Broadcast publishes a notification to every matching subscriber on the in-process
+// hub. Unlike the sink-based Notify* methods, this never touches email/web push —
+// it's for real-time subscribers like SubscribeMarketEvents.
+func (n *Notifier) Broadcast(notification Notification) {
+	n.hub.Publish(notification)
+}
+
+// Subscribe registers a new hub subscriber filtered by marketID, userID, and
+// eventType (empty string means "any"), returning its ID and delivery channel.
+func (n *Notifier) Subscribe(marketID, userID, eventType string, bufferSize int) (SubscriberID, <-chan Notification) {
+	return n.hub.Subscribe(marketID, userID, eventType, bufferSize)
+}
+
+// Unsubscribe removes a hub subscriber registered with Subscribe
+func (n *Notifier) Unsubscribe(id SubscriberID) {
+	n.hub.Unsubscribe(id)
+}
+
+// ClearLagging resets a hub subscriber's lagging flag once its consumer has replayed
+// past the gap that triggered it (see LaggingEventType).
+func (n *Notifier) ClearLagging(id SubscriberID) {
+	n.hub.ClearLagging(id)
+}
+
+// CloseHub unsubscribes every active hub subscriber, unblocking readers so shutdown
+// can drain cleanly without leaking goroutines
+func (n *Notifier) CloseHub() {
+	n.hub.Close()
+}
+
+// Bus returns the Notifier's event Bus, so other subsystems (a websocket
+// broadcaster, an audit logger, ...) can Subscribe to the same market/bet
+// lifecycle events NotifyMarketCreated and friends publish, without those
+// call sites needing to know about it.
+func (n *Notifier) Bus() *Bus {
+	return n.bus
 }
 
 // NotificationTemplate represents a notification template
@@ -41,6 +142,9 @@ type NotificationTemplate struct {
 
 // EmailNotification represents an email notification
 type EmailNotification struct {
+	// UserID is who the email is for, used to generate its List-Unsubscribe
+	// link; blank for anything not tied to a specific user's preferences.
+	UserID  string
 	To      string
 	Subject string
 	Body    string
@@ -60,25 +164,66 @@ type WebPushKeys struct {
 	P256dh string `json:"p256dh"`
 }
 
+// Render renders event's notification content for locale through the template
+// registry (see NotifyConfig.TemplateDir), returning an error if no registry is
+// configured or no template matches. NotifyMarketCreated and friends call this
+// first and only fall back to their built-in content on error, so operators can
+// override or translate copy one event at a time without recompiling.
+func (n *Notifier) Render(event, locale string, data interface{}) (*NotificationTemplate, error) {
+	if n.templates == nil {
+		return nil, fmt.Errorf("no template registry configured")
+	}
+	return n.templates.Render(event, locale, data)
+}
+
+// buildTemplate renders event through the template registry if one is
+// configured, falling back to fallback() if the registry is absent or has no
+// matching template for event/locale.
+func (n *Notifier) buildTemplate(event string, data interface{}, fallback func() *NotificationTemplate) *NotificationTemplate {
+	if tmpl, err := n.Render(event, n.config.DefaultLocale, data); err == nil {
+		return tmpl
+	} else if n.templates != nil {
+		n.logger.Debug("falling back to built-in notification content", "event", event, "error", err)
+	}
+	return fallback()
+}
+
+// FlushDigest sends one combined digest email per user with entries buffered
+// under window ("hourly" or "daily") since the last flush, then clears that
+// window's buffer. Call from a scheduler cron job at the matching cadence.
+func (n *Notifier) FlushDigest(ctx context.Context, window string) error {
+	return n.digester.Flush(ctx, window)
+}
+
 // Market event notifications
 
 // NotifyMarketCreated sends notification when a market is created
-func (n *Notifier) NotifyMarketCreated(ctx context.Context, market *core.Market) error {
+func (n *Notifier) NotifyMarketCreated(ctx context.Context, market *store.MarketView) error {
 	n.logger.Info("sending market created notifications", "market_id", market.ID, "title", market.Title)
-
-	template := &NotificationTemplate{
-		Subject:  fmt.Sprintf("New Market: %s", market.Title),
-		TextBody: n.generateMarketCreatedText(market),
-		HTMLBody: n.generateMarketCreatedHTML(market),
-		WebPushData: map[string]interface{}{
-			"title":     "New Market Created",
-			"body":      fmt.Sprintf("New betting market: %s", market.Title),
-			"icon":      "/icon-192x192.png",
-			"badge":     "/badge-72x72.png",
-			"market_id": market.ID,
-			"action":    "market_created",
-		},
-	}
+	n.bus.Publish(ctx, MarketCreatedEvent{Market: market})
+
+	template := n.buildTemplate("market_created", map[string]interface{}{
+		"Title":             market.Title,
+		"Creator":           market.Creator,
+		"EndTs":             market.EndTs,
+		"ResolveDeadlineTs": market.ResolveDeadlineTs,
+		"FeeBps":            market.FeeBps,
+		"MarketID":          market.ID,
+	}, func() *NotificationTemplate {
+		return &NotificationTemplate{
+			Subject:  fmt.Sprintf("New Market: %s", market.Title),
+			TextBody: n.generateMarketCreatedText(market),
+			HTMLBody: n.generateMarketCreatedHTML(market),
+			WebPushData: map[string]interface{}{
+				"title":     "New Market Created",
+				"body":      fmt.Sprintf("New betting market: %s", market.Title),
+				"icon":      "/icon-192x192.png",
+				"badge":     "/badge-72x72.png",
+				"market_id": market.ID,
+				"action":    "market_created",
+			},
+		}
+	})
 
 	// Get subscriptions for market creation notifications
 	subscriptions, err := n.getSubscriptionsForEvent(ctx, "market_created")
@@ -87,27 +232,35 @@ func (n *Notifier) NotifyMarketCreated(ctx context.Context, market *core.Market)
 	}
 
 	// Send notifications
-	return n.sendNotifications(ctx, subscriptions, template)
+	return n.sendNotifications(ctx, "market_created", subscriptions, template)
 }
 
 // NotifyBetPlaced sends notification when a bet is placed
-func (n *Notifier) NotifyBetPlaced(ctx context.Context, position *core.Position) error {
+func (n *Notifier) NotifyBetPlaced(ctx context.Context, position *store.PositionView) error {
 	n.logger.Info("sending bet placed notifications", "position_id", position.ID, "market_id", position.MarketID)
-
-	template := &NotificationTemplate{
-		Subject:  "Bet Placed Successfully",
-		TextBody: n.generateBetPlacedText(position),
-		HTMLBody: n.generateBetPlacedHTML(position),
-		WebPushData: map[string]interface{}{
-			"title":      "Bet Placed",
-			"body":       fmt.Sprintf("Your bet on side %s has been placed", position.Side),
-			"icon":       "/icon-192x192.png",
-			"badge":      "/badge-72x72.png",
-			"market_id":  position.MarketID,
-			"position_id": position.ID,
-			"action":     "bet_placed",
-		},
-	}
+	n.bus.Publish(ctx, BetPlacedEvent{Position: position})
+
+	template := n.buildTemplate("bet_placed", map[string]interface{}{
+		"MarketID":   position.MarketID,
+		"Side":       position.Side,
+		"Amount":     position.Amount,
+		"PositionID": position.ID,
+	}, func() *NotificationTemplate {
+		return &NotificationTemplate{
+			Subject:  "Bet Placed Successfully",
+			TextBody: n.generateBetPlacedText(position),
+			HTMLBody: n.generateBetPlacedHTML(position),
+			WebPushData: map[string]interface{}{
+				"title":       "Bet Placed",
+				"body":        fmt.Sprintf("Your bet on side %s has been placed", position.Side),
+				"icon":        "/icon-192x192.png",
+				"badge":       "/badge-72x72.png",
+				"market_id":   position.MarketID,
+				"position_id": position.ID,
+				"action":      "bet_placed",
+			},
+		}
+	})
 
 	// Get user-specific subscriptions
 	subscriptions, err := n.getUserSubscriptions(ctx, position.Owner)
@@ -115,80 +268,123 @@ func (n *Notifier) NotifyBetPlaced(ctx context.Context, position *core.Position)
 		return fmt.Errorf("failed to get user subscriptions: %w", err)
 	}
 
-	return n.sendNotifications(ctx, subscriptions, template)
+	return n.sendNotifications(ctx, "bet_placed", subscriptions, template)
 }
 
 // NotifyMarketResolved sends notification when a market is resolved
-func (n *Notifier) NotifyMarketResolved(ctx context.Context, market *core.Market) error {
+func (n *Notifier) NotifyMarketResolved(ctx context.Context, market *store.MarketView) error {
 	n.logger.Info("sending market resolved notifications", "market_id", market.ID, "outcome", *market.Outcome)
-
-	template := &NotificationTemplate{
-		Subject:  fmt.Sprintf("Market Resolved: %s", market.Title),
-		TextBody: n.generateMarketResolvedText(market),
-		HTMLBody: n.generateMarketResolvedHTML(market),
-		WebPushData: map[string]interface{}{
-			"title":     "Market Resolved",
-			"body":      fmt.Sprintf("Market resolved with outcome: %s", *market.Outcome),
-			"icon":      "/icon-192x192.png",
-			"badge":     "/badge-72x72.png",
-			"market_id": market.ID,
-			"outcome":   *market.Outcome,
-			"action":    "market_resolved",
-		},
-	}
+	n.bus.Publish(ctx, MarketResolvedEvent{Market: market})
+
+	template := n.buildTemplate("market_resolved", map[string]interface{}{
+		"Title":    market.Title,
+		"Outcome":  *market.Outcome,
+		"StakedA":  market.StakedA,
+		"StakedB":  market.StakedB,
+		"MarketID": market.ID,
+	}, func() *NotificationTemplate {
+		return &NotificationTemplate{
+			Subject:  fmt.Sprintf("Market Resolved: %s", market.Title),
+			TextBody: n.generateMarketResolvedText(market),
+			HTMLBody: n.generateMarketResolvedHTML(market),
+			WebPushData: map[string]interface{}{
+				"title":     "Market Resolved",
+				"body":      fmt.Sprintf("Market resolved with outcome: %s", *market.Outcome),
+				"icon":      "/icon-192x192.png",
+				"badge":     "/badge-72x72.png",
+				"market_id": market.ID,
+				"outcome":   *market.Outcome,
+				"action":    "market_resolved",
+			},
+		}
+	})
 
 	// Get subscriptions for participants in this market
-	subscriptions, err := n.getMarketParticipantSubscriptions(ctx, market.ID)
+	subscriptions, err := n.getMarketParticipantSubscriptions(ctx, market.ID, "market_resolved")
 	if err != nil {
 		return fmt.Errorf("failed to get market participant subscriptions: %w", err)
 	}
 
-	return n.sendNotifications(ctx, subscriptions, template)
+	return n.sendNotifications(ctx, "market_resolved", subscriptions, template)
 }
 
 // NotifyMarketExpiring sends notification when a market is about to expire
-func (n *Notifier) NotifyMarketExpiring(ctx context.Context, market *core.Market, timeUntilExpiry time.Duration) error {
+func (n *Notifier) NotifyMarketExpiring(ctx context.Context, market *store.MarketView, timeUntilExpiry time.Duration) error {
 	n.logger.Info("sending market expiring notifications", "market_id", market.ID, "expires_in", timeUntilExpiry)
-
-	template := &NotificationTemplate{
-		Subject:  fmt.Sprintf("Market Expiring Soon: %s", market.Title),
-		TextBody: n.generateMarketExpiringText(market, timeUntilExpiry),
-		HTMLBody: n.generateMarketExpiringHTML(market, timeUntilExpiry),
-		WebPushData: map[string]interface{}{
-			"title":     "Market Expiring",
-			"body":      fmt.Sprintf("Market expires in %v", timeUntilExpiry),
-			"icon":      "/icon-192x192.png",
-			"badge":     "/badge-72x72.png",
-			"market_id": market.ID,
-			"action":    "market_expiring",
-		},
-	}
+	n.bus.Publish(ctx, MarketExpiringEvent{Market: market, TimeUntilExpiry: timeUntilExpiry})
+
+	template := n.buildTemplate("market_expiring", map[string]interface{}{
+		"Title":           market.Title,
+		"TimeUntilExpiry": timeUntilExpiry,
+		"StakedA":         market.StakedA,
+		"StakedB":         market.StakedB,
+		"MarketID":        market.ID,
+	}, func() *NotificationTemplate {
+		return &NotificationTemplate{
+			Subject:  fmt.Sprintf("Market Expiring Soon: %s", market.Title),
+			TextBody: n.generateMarketExpiringText(market, timeUntilExpiry),
+			HTMLBody: n.generateMarketExpiringHTML(market, timeUntilExpiry),
+			WebPushData: map[string]interface{}{
+				"title":     "Market Expiring",
+				"body":      fmt.Sprintf("Market expires in %v", timeUntilExpiry),
+				"icon":      "/icon-192x192.png",
+				"badge":     "/badge-72x72.png",
+				"market_id": market.ID,
+				"action":    "market_expiring",
+			},
+		}
+	})
 
 	// Get subscriptions for participants in this market
-	subscriptions, err := n.getMarketParticipantSubscriptions(ctx, market.ID)
+	subscriptions, err := n.getMarketParticipantSubscriptions(ctx, market.ID, "market_expiring")
 	if err != nil {
 		return fmt.Errorf("failed to get market participant subscriptions: %w", err)
 	}
 
-	return n.sendNotifications(ctx, subscriptions, template)
+	return n.sendNotifications(ctx, "market_expiring", subscriptions, template)
 }
 
 // Core notification sending methods
 
-// sendNotifications sends notifications to all subscriptions
-func (n *Notifier) sendNotifications(ctx context.Context, subscriptions []store.NotificationSubscription, template *NotificationTemplate) error {
+// sendNotifications enqueues one DeliveryQueue task per subscription rather than
+// dialing a transport inline, so a slow SMTP server or push endpoint can't block
+// the request handler (CreateMarket, PlaceBet, ...) that triggered the notification.
+// Each subscription is first checked against ShouldDeliver(eventType, sub.Type): a
+// subscription that asks for a digest instead of immediate delivery is buffered
+// onto Digester rather than enqueued, and one that's disabled, quiet-houred, or
+// below its MinSeverity is dropped entirely.
+func (n *Notifier) sendNotifications(ctx context.Context, eventType string, subscriptions []store.NotificationSubscription, template *NotificationTemplate) error {
 	var lastError error
 
 	for _, sub := range subscriptions {
+		if !n.ShouldDeliver(ctx, sub.UserID, eventType, sub.Type) {
+			if sub.Type == "email" {
+				if window, ok := n.digestWindowFor(sub.UserID, eventType, sub.Type); ok {
+					n.digester.Buffer(sub.UserID, sub.Endpoint, window, DigestEntry{
+						EventType: eventType,
+						Subject:   template.Subject,
+						Body:      template.HTMLBody,
+					})
+				}
+			}
+			continue
+		}
+
 		switch sub.Type {
 		case "email":
-			if err := n.sendEmailNotification(ctx, &EmailNotification{
+			payload, err := marshalPayload(&EmailNotification{
+				UserID:  sub.UserID,
 				To:      sub.Endpoint,
 				Subject: template.Subject,
 				Body:    template.HTMLBody,
 				IsHTML:  true,
-			}); err != nil {
-				n.logger.Error("failed to send email notification", "error", err, "email", sub.Endpoint)
+			})
+			if err != nil {
+				n.logger.Error("failed to marshal email payload", "error", err, "email", sub.Endpoint)
+				continue
+			}
+			if err := n.Enqueue(ctx, "email", sub.UserID, payload); err != nil {
+				n.logger.Error("failed to enqueue email notification", "error", err, "email", sub.Endpoint)
 				lastError = err
 			}
 
@@ -199,18 +395,40 @@ func (n *Notifier) sendNotifications(ctx context.Context, subscriptions []store.
 				continue
 			}
 
-			payload, err := json.Marshal(template.WebPushData)
+			webPushPayload, err := json.Marshal(template.WebPushData)
 			if err != nil {
 				n.logger.Error("failed to marshal web push payload", "error", err)
 				continue
 			}
 
-			if err := n.sendWebPushNotification(ctx, &WebPushNotification{
+			payload, err := marshalPayload(&WebPushNotification{
 				Endpoint: sub.Endpoint,
-				Payload:  string(payload),
+				Payload:  string(webPushPayload),
 				Keys:     *webPushData,
-			}); err != nil {
-				n.logger.Error("failed to send web push notification", "error", err, "user_id", sub.UserID)
+			})
+			if err != nil {
+				n.logger.Error("failed to marshal web push delivery payload", "error", err, "user_id", sub.UserID)
+				continue
+			}
+			if err := n.Enqueue(ctx, "web_push", sub.UserID, payload); err != nil {
+				n.logger.Error("failed to enqueue web push notification", "error", err, "user_id", sub.UserID)
+				lastError = err
+			}
+
+		case "fcm", "apns":
+			payload, err := marshalPayload(&DeviceNotification{
+				DeviceToken: sub.DeviceToken,
+				Platform:    sub.Platform,
+				Title:       fmt.Sprintf("%v", template.WebPushData["title"]),
+				Body:        fmt.Sprintf("%v", template.WebPushData["body"]),
+				Data:        template.WebPushData,
+			})
+			if err != nil {
+				n.logger.Error("failed to marshal device push payload", "error", err, "user_id", sub.UserID, "provider", sub.Type)
+				continue
+			}
+			if err := n.Enqueue(ctx, sub.Type, sub.UserID, payload); err != nil {
+				n.logger.Error("failed to enqueue device push notification", "error", err, "user_id", sub.UserID, "provider", sub.Type)
 				lastError = err
 			}
 
@@ -222,6 +440,26 @@ func (n *Notifier) sendNotifications(ctx context.Context, subscriptions []store.
 	return lastError
 }
 
+// dispatchEmail unmarshals a DeliveryQueue payload back into an EmailNotification
+// and sends it. It's the "email" entry in NewNotifier's send map.
+func (n *Notifier) dispatchEmail(ctx context.Context, payload []byte) error {
+	var notification EmailNotification
+	if err := json.Unmarshal(payload, &notification); err != nil {
+		return fmt.Errorf("failed to unmarshal email delivery payload: %w", err)
+	}
+	return n.sendEmailNotification(ctx, &notification)
+}
+
+// dispatchWebPush unmarshals a DeliveryQueue payload back into a WebPushNotification
+// and sends it. It's the "web_push" entry in NewNotifier's send map.
+func (n *Notifier) dispatchWebPush(ctx context.Context, payload []byte) error {
+	var notification WebPushNotification
+	if err := json.Unmarshal(payload, &notification); err != nil {
+		return fmt.Errorf("failed to unmarshal web push delivery payload: %w", err)
+	}
+	return n.sendWebPushNotification(ctx, &notification)
+}
+
 // sendEmailNotification sends an email notification
 func (n *Notifier) sendEmailNotification(ctx context.Context, notification *EmailNotification) error {
 	if n.config.SMTP.Host == "" {
@@ -233,7 +471,18 @@ func (n *Notifier) sendEmailNotification(ctx context.Context, notification *Emai
 	message.WriteString(fmt.Sprintf("To: %s\r\n", notification.To))
 	message.WriteString(fmt.Sprintf("From: %s\r\n", n.config.SMTP.From))
 	message.WriteString(fmt.Sprintf("Subject: %s\r\n", notification.Subject))
-	
+
+	if notification.UserID != "" {
+		if httpsURL, mailto, ok := n.UnsubscribeLink(notification.UserID); ok {
+			links := "<" + httpsURL + ">"
+			if mailto != "" {
+				links += ", <" + mailto + ">"
+			}
+			message.WriteString(fmt.Sprintf("List-Unsubscribe: %s\r\n", links))
+			message.WriteString("List-Unsubscribe-Post: List-Unsubscribe=One-Click\r\n")
+		}
+	}
+
 	if notification.IsHTML {
 		message.WriteString("Content-Type: text/html; charset=UTF-8\r\n")
 	} else {
@@ -295,19 +544,6 @@ func (n *Notifier) sendEmailNotification(ctx context.Context, notification *Emai
 	return nil
 }
 
-// sendWebPushNotification sends a web push notification
-func (n *Notifier) sendWebPushNotification(ctx context.Context, notification *WebPushNotification) error {
-	if !n.config.WebPush.Enabled {
-		return fmt.Errorf("web push not enabled")
-	}
-
-	// In a real implementation, this would use a web push library
-	// to send the notification with proper VAPID headers and encryption
-	n.logger.Info("web push notification sent", "endpoint", notification.Endpoint[:50]+"...")
-	
-	return nil
-}
-
 // Subscription management
 
 // getUserSubscriptions gets notification subscriptions for a user
@@ -315,21 +551,18 @@ func (n *Notifier) getUserSubscriptions(ctx context.Context, userID string) ([]s
 	return n.repo.GetNotificationSubscriptions(userID)
 }
 
-// getSubscriptionsForEvent gets subscriptions for a specific event type
+// getSubscriptionsForEvent gets every enabled subscription whose EventMask
+// includes eventType, for broadcast-style events that aren't scoped to a
+// single user or market (e.g. market_created).
 func (n *Notifier) getSubscriptionsForEvent(ctx context.Context, eventType string) ([]store.NotificationSubscription, error) {
-	// This would need to be implemented in the repository
-	// For now, return empty slice
-	return []store.NotificationSubscription{}, nil
+	return n.repo.GetNotificationSubscriptionsForEvent(int32(eventTypeMask[eventType]))
 }
 
-// getMarketParticipantSubscriptions gets subscriptions for users participating in a market
-func (n *Notifier) getMarketParticipantSubscriptions(ctx context.Context, marketID string) ([]store.NotificationSubscription, error) {
-	// This would need to be implemented to:
-	// 1. Get all positions for the market
-	// 2. Get unique user IDs
-	// 3. Get subscriptions for those users
-	// For now, return empty slice
-	return []store.NotificationSubscription{}, nil
+// getMarketParticipantSubscriptions gets every enabled subscription whose
+// EventMask includes eventType, belonging to a user with at least one
+// position in marketID.
+func (n *Notifier) getMarketParticipantSubscriptions(ctx context.Context, marketID, eventType string) ([]store.NotificationSubscription, error) {
+	return n.repo.GetMarketParticipantSubscriptions(marketID, int32(eventTypeMask[eventType]))
 }
 
 // parseWebPushData parses web push subscription data
@@ -343,7 +576,7 @@ func (n *Notifier) parseWebPushData(data string) (*WebPushKeys, error) {
 
 // Template generators
 
-func (n *Notifier) generateMarketCreatedText(market *core.Market) string {
+func (n *Notifier) generateMarketCreatedText(market *store.MarketView) string {
 	return fmt.Sprintf(`
 New Market Created: %s
 
@@ -357,7 +590,7 @@ Start betting now!
     market.ResolveDeadlineTs.Format(time.RFC3339), market.FeeBps)
 }
 
-func (n *Notifier) generateMarketCreatedHTML(market *core.Market) string {
+func (n *Notifier) generateMarketCreatedHTML(market *store.MarketView) string {
 	return fmt.Sprintf(`
 <html>
 <body>
@@ -373,7 +606,7 @@ func (n *Notifier) generateMarketCreatedHTML(market *core.Market) string {
     market.ResolveDeadlineTs.Format(time.RFC3339), market.FeeBps, market.ID)
 }
 
-func (n *Notifier) generateBetPlacedText(position *core.Position) string {
+func (n *Notifier) generateBetPlacedText(position *store.PositionView) string {
 	return fmt.Sprintf(`
 Bet Placed Successfully!
 
@@ -386,7 +619,7 @@ Your bet is now active.
 `, position.MarketID, position.Side, position.Amount, position.ID)
 }
 
-func (n *Notifier) generateBetPlacedHTML(position *core.Position) string {
+func (n *Notifier) generateBetPlacedHTML(position *store.PositionView) string {
 	return fmt.Sprintf(`
 <html>
 <body>
@@ -401,7 +634,7 @@ func (n *Notifier) generateBetPlacedHTML(position *core.Position) string {
 `, position.MarketID, position.Side, position.Amount, position.ID)
 }
 
-func (n *Notifier) generateMarketResolvedText(market *core.Market) string {
+func (n *Notifier) generateMarketResolvedText(market *store.MarketView) string {
 	outcome := "Unknown"
 	if market.Outcome != nil {
 		outcome = *market.Outcome
@@ -418,7 +651,7 @@ Check your positions to see if you can claim winnings!
 `, market.Title, outcome, market.StakedA, market.StakedB)
 }
 
-func (n *Notifier) generateMarketResolvedHTML(market *core.Market) string {
+func (n *Notifier) generateMarketResolvedHTML(market *store.MarketView) string {
 	outcome := "Unknown"
 	if market.Outcome != nil {
 		outcome = *market.Outcome
@@ -437,7 +670,7 @@ func (n *Notifier) generateMarketResolvedHTML(market *core.Market) string {
 `, market.Title, outcome, market.StakedA, market.StakedB, market.ID)
 }
 
-func (n *Notifier) generateMarketExpiringText(market *core.Market, timeUntil time.Duration) string {
+func (n *Notifier) generateMarketExpiringText(market *store.MarketView, timeUntil time.Duration) string {
 	return fmt.Sprintf(`
 Market Expiring Soon: %s
 
@@ -449,7 +682,7 @@ Last chance to place your bets!
 `, market.Title, timeUntil, market.StakedA, market.StakedB)
 }
 
-func (n *Notifier) generateMarketExpiringHTML(market *core.Market, timeUntil time.Duration) string {
+func (n *Notifier) generateMarketExpiringHTML(market *store.MarketView, timeUntil time.Duration) string {
 	return fmt.Sprintf(`
 <html>
 <body>
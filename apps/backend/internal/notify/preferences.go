@@ -0,0 +1,123 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/friend-bets/backend/internal/store"
+	"gorm.io/gorm"
+)
+
+// Severity values a notification_preferences row's MinSeverity can be set to.
+// Higher-ranked severities pass a lower-ranked MinSeverity filter; a row never
+// suppresses an event at or above its own MinSeverity.
+const (
+	SeverityInfo      = "info"
+	SeverityImportant = "important"
+	SeverityUrgent    = "urgent"
+)
+
+// eventSeverity is the default severity of each notification event, consulted by
+// ShouldDeliver against a preference's MinSeverity. Events not listed here rank as
+// SeverityInfo, the lowest tier.
+var eventSeverity = map[string]string{
+	"market_created":  SeverityInfo,
+	"bet_placed":      SeverityInfo,
+	"market_resolved": SeverityImportant,
+	"market_expiring": SeverityImportant,
+}
+
+// severityRank orders severities low to high; an unrecognized value ranks as
+// SeverityInfo so a typo'd MinSeverity fails open (filters nothing) rather than
+// silently suppressing everything.
+func severityRank(severity string) int {
+	switch severity {
+	case SeverityImportant:
+		return 1
+	case SeverityUrgent:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// batchWindowImmediate is the default NotificationPreference.BatchWindow: send as
+// soon as ShouldDeliver allows it. Any other value is buffered by Digester.
+const batchWindowImmediate = "immediate"
+
+// ShouldDeliver reports whether an eventType notification for userID over channel
+// should be sent right now. A missing preference row defaults to true (deliver).
+// A row with Enabled false, a BatchWindow other than immediate (handled by
+// Digester instead), an eventSeverity below MinSeverity, or a quiet-hours window
+// covering the current time all return false.
+func (n *Notifier) ShouldDeliver(ctx context.Context, userID, eventType, channel string) bool {
+	pref, err := n.repo.GetNotificationPreference(userID, eventType, channel)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			n.logger.Error("failed to load notification preference, defaulting to deliver", "error", err, "user_id", userID, "event_type", eventType, "channel", channel)
+		}
+		return true
+	}
+
+	if !pref.Enabled {
+		return false
+	}
+	if pref.MinSeverity != "" && severityRank(eventSeverity[eventType]) < severityRank(pref.MinSeverity) {
+		return false
+	}
+	if pref.BatchWindow != "" && pref.BatchWindow != batchWindowImmediate {
+		return false
+	}
+	if inQuietHours(pref, time.Now()) {
+		return false
+	}
+	return true
+}
+
+// digestWindowFor returns the batch window ("hourly" or "daily") userID's
+// (eventType, channel) preference buffers into, and whether it should be buffered
+// at all — false for a missing row, a disabled row, or one below MinSeverity,
+// since those are simply dropped rather than digested.
+func (n *Notifier) digestWindowFor(userID, eventType, channel string) (string, bool) {
+	pref, err := n.repo.GetNotificationPreference(userID, eventType, channel)
+	if err != nil {
+		return "", false
+	}
+	if !pref.Enabled || pref.BatchWindow == "" || pref.BatchWindow == batchWindowImmediate {
+		return "", false
+	}
+	if pref.MinSeverity != "" && severityRank(eventSeverity[eventType]) < severityRank(pref.MinSeverity) {
+		return "", false
+	}
+	return pref.BatchWindow, true
+}
+
+// inQuietHours reports whether at (typically time.Now()) falls inside pref's
+// quiet-hours window. A nil QuietHoursStart or QuietHoursEnd means no quiet hours
+// are configured. Timezone defaults to UTC if unset or unrecognized.
+func inQuietHours(pref *store.NotificationPreference, at time.Time) bool {
+	if pref.QuietHoursStart == nil || pref.QuietHoursEnd == nil {
+		return false
+	}
+
+	loc := time.UTC
+	if pref.Timezone != "" {
+		if l, err := time.LoadLocation(pref.Timezone); err == nil {
+			loc = l
+		}
+	}
+
+	local := at.In(loc)
+	minuteOfDay := local.Hour()*60 + local.Minute()
+	start, end := *pref.QuietHoursStart, *pref.QuietHoursEnd
+
+	if start == end {
+		return false
+	}
+	if start < end {
+		return minuteOfDay >= start && minuteOfDay < end
+	}
+	// Wraps past midnight, e.g. 22:00-07:00.
+	return minuteOfDay >= start || minuteOfDay < end
+}
@@ -0,0 +1,400 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+// DeviceNotification is a mobile push send queued for the "fcm" or "apns"
+// provider: the destination device token, the platform it was issued for (so
+// sendNotifications knows which transport to use), and the notification
+// content translated from a NotificationTemplate's WebPushData.
+type DeviceNotification struct {
+	DeviceToken string                 `json:"device_token"`
+	Platform    string                 `json:"platform"`
+	Title       string                 `json:"title"`
+	Body        string                 `json:"body"`
+	Data        map[string]interface{} `json:"data"`
+}
+
+// devicePushGoneError mirrors webPushSubscriptionGoneError for native push: FCM
+// reports an unregistered token as an UNREGISTERED error and APNs reports one as
+// a 410, both of which should be treated as handled rather than retried.
+type devicePushGoneError struct{}
+
+func (devicePushGoneError) Error() string { return "device token no longer registered" }
+
+// dispatchFCM unmarshals a DeliveryQueue payload back into a DeviceNotification
+// and sends it through FCM's HTTP v1 API. It's the "fcm" entry in NewNotifier's
+// send map.
+func (n *Notifier) dispatchFCM(ctx context.Context, payload []byte) error {
+	var notification DeviceNotification
+	if err := json.Unmarshal(payload, &notification); err != nil {
+		return fmt.Errorf("failed to unmarshal fcm delivery payload: %w", err)
+	}
+	return n.sendFCMNotification(ctx, &notification)
+}
+
+// dispatchAPNs unmarshals a DeliveryQueue payload back into a DeviceNotification
+// and sends it through APNs. It's the "apns" entry in NewNotifier's send map.
+func (n *Notifier) dispatchAPNs(ctx context.Context, payload []byte) error {
+	var notification DeviceNotification
+	if err := json.Unmarshal(payload, &notification); err != nil {
+		return fmt.Errorf("failed to unmarshal apns delivery payload: %w", err)
+	}
+	return n.sendAPNsNotification(ctx, &notification)
+}
+
+// fcmServiceAccount is the subset of a Firebase service account JSON key this
+// package needs to mint its own OAuth2 access tokens.
+type fcmServiceAccount struct {
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// fcmTokenCache caches the access token minted from a service account, since FCM
+// tokens are valid for an hour and re-signing a JWT-bearer exchange for every
+// send would be wasteful.
+type fcmTokenCache struct {
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+var fcmTokens fcmTokenCache
+
+// sendFCMNotification sends notification through FCM's HTTP v1 API, authenticating
+// via a self-signed JWT-bearer OAuth2 token exchange (RFC 7523) against the
+// configured service account, rather than depending on the Firebase Admin SDK or
+// golang.org/x/oauth2.
+func (n *Notifier) sendFCMNotification(ctx context.Context, notification *DeviceNotification) error {
+	if !n.config.FCM.Enabled {
+		return fmt.Errorf("fcm not configured")
+	}
+
+	sa, err := loadFCMServiceAccount(n.config.FCM.ServiceAccountKeyPath)
+	if err != nil {
+		return fmt.Errorf("failed to load fcm service account: %w", err)
+	}
+
+	accessToken, err := fcmTokens.get(sa)
+	if err != nil {
+		return fmt.Errorf("failed to mint fcm access token: %w", err)
+	}
+
+	body := map[string]interface{}{
+		"message": map[string]interface{}{
+			"token": notification.DeviceToken,
+			"notification": map[string]interface{}{
+				"title": notification.Title,
+				"body":  notification.Body,
+			},
+			"data": stringifyData(notification.Data),
+		},
+	}
+	bodyJSON, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal fcm request: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("https://fcm.googleapis.com/v1/projects/%s/messages:send", n.config.FCM.ProjectID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(bodyJSON))
+	if err != nil {
+		return fmt.Errorf("failed to build fcm request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send fcm request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode == http.StatusNotFound || bytes.Contains(respBody, []byte("UNREGISTERED")) {
+		if err := n.repo.DisableNotificationSubscriptionByDeviceToken(notification.DeviceToken); err != nil {
+			n.logger.Error("failed to deactivate gone fcm subscription", "error", err)
+		}
+		return devicePushGoneError{}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("fcm send failed: status %d: %s", resp.StatusCode, respBody)
+	}
+
+	n.logger.Info("fcm notification sent", "device_token", truncateEndpoint(notification.DeviceToken))
+	return nil
+}
+
+// stringifyData converts a DeviceNotification's Data map to string values, since
+// FCM's "data" payload fields must all be strings.
+func stringifyData(data map[string]interface{}) map[string]string {
+	out := make(map[string]string, len(data))
+	for k, v := range data {
+		out[k] = fmt.Sprintf("%v", v)
+	}
+	return out
+}
+
+// loadFCMServiceAccount reads and parses a Firebase service account JSON key file.
+func loadFCMServiceAccount(path string) (*fcmServiceAccount, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read service account file: %w", err)
+	}
+	var sa fcmServiceAccount
+	if err := json.Unmarshal(data, &sa); err != nil {
+		return nil, fmt.Errorf("failed to parse service account json: %w", err)
+	}
+	if sa.TokenURI == "" {
+		sa.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+	return &sa, nil
+}
+
+// get returns a cached access token if it still has at least a minute of life
+// left, otherwise mints a new one via the JWT-bearer grant.
+func (c *fcmTokenCache) get(sa *fcmServiceAccount) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token != "" && time.Now().Before(c.expires.Add(-time.Minute)) {
+		return c.token, nil
+	}
+
+	token, expiresIn, err := mintFCMAccessToken(sa)
+	if err != nil {
+		return "", err
+	}
+	c.token = token
+	c.expires = time.Now().Add(time.Duration(expiresIn) * time.Second)
+	return c.token, nil
+}
+
+// mintFCMAccessToken signs a self-issued RS256 JWT with the service account's
+// private key and exchanges it for an OAuth2 access token via the
+// urn:ietf:params:oauth:grant-type:jwt-bearer grant (RFC 7523), the same flow
+// the Firebase Admin SDK performs internally.
+func mintFCMAccessToken(sa *fcmServiceAccount) (string, int, error) {
+	key, err := parseRSAPrivateKey(sa.PrivateKey)
+	if err != nil {
+		return "", 0, err
+	}
+
+	now := time.Now()
+	header := map[string]interface{}{"alg": "RS256", "typ": "JWT"}
+	claims := map[string]interface{}{
+		"iss":   sa.ClientEmail,
+		"scope": "https://www.googleapis.com/auth/firebase.messaging",
+		"aud":   sa.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}
+
+	headerJSON, _ := json.Marshal(header)
+	claimsJSON, _ := json.Marshal(claims)
+	signingInput := base64urlEncode(headerJSON) + "." + base64urlEncode(claimsJSON)
+
+	digest := sha256.Sum256([]byte(signingInput))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, 0, digest[:])
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to sign jwt: %w", err)
+	}
+	assertion := signingInput + "." + base64urlEncode(sig)
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	resp, err := http.PostForm(sa.TokenURI, form)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to exchange jwt for access token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var tokenResp struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+		return "", 0, fmt.Errorf("failed to decode token response: %w", err)
+	}
+	if tokenResp.AccessToken == "" {
+		return "", 0, fmt.Errorf("token exchange returned no access token")
+	}
+	return tokenResp.AccessToken, tokenResp.ExpiresIn, nil
+}
+
+// parseRSAPrivateKey decodes a PEM-encoded PKCS#1 or PKCS#8 RSA private key, the
+// two formats a Firebase service account's "private_key" field may use.
+func parseRSAPrivateKey(pemStr string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(pemStr))
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode pem block")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key is not RSA")
+	}
+	return rsaKey, nil
+}
+
+// apnsTokenCache caches the APNs provider JWT, which Apple asks not to be
+// regenerated more than once every 20 minutes.
+type apnsTokenCache struct {
+	mu       sync.Mutex
+	token    string
+	mintedAt time.Time
+}
+
+var apnsTokens apnsTokenCache
+
+// sendAPNsNotification sends notification through APNs, authenticating with an
+// ES256 provider JWT (the same raw-JWT approach webpush.go uses for VAPID) over
+// HTTP/2, which Go's net/http negotiates automatically against a TLS endpoint.
+func (n *Notifier) sendAPNsNotification(ctx context.Context, notification *DeviceNotification) error {
+	if !n.config.APNs.Enabled {
+		return fmt.Errorf("apns not configured")
+	}
+
+	token, err := apnsTokens.get(n.config.APNs.KeyPath, n.config.APNs.KeyID, n.config.APNs.TeamID)
+	if err != nil {
+		return fmt.Errorf("failed to mint apns token: %w", err)
+	}
+
+	host := "api.push.apple.com"
+	if n.config.APNs.Sandbox {
+		host = "api.sandbox.push.apple.com"
+	}
+
+	payload := map[string]interface{}{
+		"aps": map[string]interface{}{
+			"alert": map[string]interface{}{
+				"title": notification.Title,
+				"body":  notification.Body,
+			},
+		},
+	}
+	for k, v := range notification.Data {
+		payload[k] = v
+	}
+	bodyJSON, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal apns payload: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("https://%s/3/device/%s", host, notification.DeviceToken)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(bodyJSON))
+	if err != nil {
+		return fmt.Errorf("failed to build apns request: %w", err)
+	}
+	req.Header.Set("authorization", "bearer "+token)
+	req.Header.Set("apns-topic", n.config.APNs.Topic)
+	req.Header.Set("apns-priority", "10")
+	req.Header.Set("apns-push-type", "alert")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send apns request: %w", err)
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(resp.Body)
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		n.logger.Info("apns notification sent", "device_token", truncateEndpoint(notification.DeviceToken))
+		return nil
+
+	case resp.StatusCode == http.StatusGone:
+		if err := n.repo.DisableNotificationSubscriptionByDeviceToken(notification.DeviceToken); err != nil {
+			n.logger.Error("failed to deactivate gone apns subscription", "error", err)
+		}
+		return devicePushGoneError{}
+
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable:
+		return &webPushRetryAfterError{after: parseRetryAfter(resp.Header.Get("Retry-After"))}
+
+	default:
+		return fmt.Errorf("apns send failed: status %d: %s", resp.StatusCode, respBody)
+	}
+}
+
+// get returns the cached provider JWT if it's less than 20 minutes old (Apple's
+// recommended refresh interval), otherwise mints a fresh one.
+func (c *apnsTokenCache) get(keyPath, keyID, teamID string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token != "" && time.Since(c.mintedAt) < 20*time.Minute {
+		return c.token, nil
+	}
+
+	key, err := loadAPNsKey(keyPath)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	header := map[string]interface{}{"alg": "ES256", "kid": keyID}
+	claims := map[string]interface{}{"iss": teamID, "iat": now.Unix()}
+
+	headerJSON, _ := json.Marshal(header)
+	claimsJSON, _ := json.Marshal(claims)
+	signingInput := base64urlEncode(headerJSON) + "." + base64urlEncode(claimsJSON)
+
+	digest := sha256.Sum256([]byte(signingInput))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign apns jwt: %w", err)
+	}
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+
+	c.token = signingInput + "." + base64urlEncode(sig)
+	c.mintedAt = now
+	return c.token, nil
+}
+
+// loadAPNsKey decodes a PEM-encoded EC P-256 private key (a .p8 APNs Auth Key).
+func loadAPNsKey(path string) (*ecdsa.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read apns key file: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode pem block")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse apns key: %w", err)
+	}
+	ecKey, ok := key.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("apns key is not an EC private key")
+	}
+	return ecKey, nil
+}
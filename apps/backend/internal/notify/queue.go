@@ -0,0 +1,358 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/friend-bets/backend/internal/config"
+	"github.com/friend-bets/backend/internal/rate"
+	"github.com/friend-bets/backend/internal/store"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Delivery status values for store.NotificationDelivery.
+const (
+	deliveryStatusPending = "pending"
+	deliveryStatusSent    = "sent"
+	deliveryStatusFailed  = "failed"
+	deliveryStatusDead    = "dead"
+)
+
+// deliveryTask is one enqueued send, tagged by provider so a worker dispatches it
+// to the right transport. Payload is the provider's own marshaled request struct
+// (e.g. EmailNotification, WebPushNotification) kept opaque to the queue.
+type deliveryTask struct {
+	deliveryID uint
+	provider   string
+	userID     string
+	payload    []byte
+	attempt    int
+}
+
+// backoff computes the delay before retry attempt (1-based), mirroring
+// scheduler.RetryPolicy.backoff: exponential with a cap and ±20% jitter.
+func backoff(attempt int, initial, max time.Duration) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	d := float64(initial) * math.Pow(2, float64(attempt-1))
+	if maxF := float64(max); maxF > 0 && d > maxF {
+		d = maxF
+	}
+	delta := d * 0.2
+	d += (rand.Float64()*2 - 1) * delta
+	if d < 0 {
+		d = 0
+	}
+	return time.Duration(d)
+}
+
+// deliveryQueueMetrics holds the Prometheus collectors backing
+// DeliveryQueue.RegisterMetrics, labeled by provider.
+type deliveryQueueMetrics struct {
+	sent    *prometheus.CounterVec
+	failed  *prometheus.CounterVec
+	retried *prometheus.CounterVec
+}
+
+// DeliveryQueue dispatches notification sends across a pool of worker goroutines
+// instead of sendNotifications blocking the request handler that triggered them on
+// an SMTP dial or push HTTP call. Each task is persisted to the
+// notification_deliveries table before it's queued, so a crash between enqueue and
+// send loses nothing: LoadPending requeues anything still due on the next startup.
+//
+// A failed send is retried with exponential backoff (via time.AfterFunc, since the
+// delay is usually well under a minute) up to MaxAttempts, after which the delivery
+// is marked dead and left for an operator to inspect rather than retried forever.
+type DeliveryQueue struct {
+	repo   *store.Repository
+	logger *slog.Logger
+
+	tasks chan deliveryTask
+	send  map[string]func(ctx context.Context, payload []byte) error
+
+	limiter    *rate.Limiter
+	ratePerMin map[string]int
+
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+
+	wg       sync.WaitGroup
+	stopCh   chan struct{}
+	stopOnce sync.Once
+
+	metrics *deliveryQueueMetrics
+}
+
+// newDeliveryQueue builds a DeliveryQueue and starts workerCount worker goroutines
+// draining it. send maps provider name to the function that actually dispatches a
+// payload; a provider with no entry is dead-lettered immediately as unsupported.
+func newDeliveryQueue(
+	repo *store.Repository,
+	logger *slog.Logger,
+	workerCount, bufferSize, maxAttempts int,
+	initialBackoff, maxBackoff time.Duration,
+	ratePerMin map[string]int,
+	send map[string]func(ctx context.Context, payload []byte) error,
+) *DeliveryQueue {
+	if workerCount < 1 {
+		workerCount = 1
+	}
+	if bufferSize < 1 {
+		bufferSize = 1
+	}
+
+	q := &DeliveryQueue{
+		repo:           repo,
+		logger:         logger,
+		tasks:          make(chan deliveryTask, bufferSize),
+		send:           send,
+		limiter:        rate.NewLimiter(&config.RateConfig{}, repo, logger),
+		ratePerMin:     ratePerMin,
+		maxAttempts:    maxAttempts,
+		initialBackoff: initialBackoff,
+		maxBackoff:     maxBackoff,
+		stopCh:         make(chan struct{}),
+	}
+
+	for i := 0; i < workerCount; i++ {
+		q.wg.Add(1)
+		go q.worker()
+	}
+
+	return q
+}
+
+// worker drains tasks until the queue is closed, dispatching each through
+// dispatch. Ranging over q.tasks (rather than a select on stopCh) lets Shutdown
+// close the channel and have every worker drain whatever was already buffered
+// before exiting.
+func (q *DeliveryQueue) worker() {
+	defer q.wg.Done()
+	for task := range q.tasks {
+		q.dispatch(task)
+	}
+}
+
+// dispatch waits for the provider's rate budget, sends task, and records the
+// outcome: sent, a scheduled retry, or dead-lettered once maxAttempts is
+// exhausted.
+func (q *DeliveryQueue) dispatch(task deliveryTask) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	q.waitForRateBudget(ctx, task.provider)
+
+	sendFn, ok := q.send[task.provider]
+	if !ok {
+		q.recordOutcome(task, false, "no transport registered for provider")
+		return
+	}
+
+	err := sendFn(ctx, task.payload)
+	if err == nil {
+		q.recordOutcome(task, true, "")
+		return
+	}
+
+	if _, gone := err.(webPushSubscriptionGoneError); gone {
+		// The subscription was deactivated by the send function itself; nothing more
+		// to retry, and it's not a failure worth counting against the provider.
+		q.recordOutcome(task, true, "")
+		return
+	}
+	if _, gone := err.(devicePushGoneError); gone {
+		q.recordOutcome(task, true, "")
+		return
+	}
+
+	if retryAfter, ok := err.(*webPushRetryAfterError); ok {
+		q.scheduleRetryAfter(task, retryAfter.after, err.Error())
+		return
+	}
+
+	q.logger.Error("notification delivery failed", "provider", task.provider, "delivery_id", task.deliveryID, "attempt", task.attempt, "error", err)
+	q.recordOutcome(task, false, err.Error())
+}
+
+// scheduleRetryAfter requeues task after exactly delay, honoring a push service's
+// Retry-After header instead of the usual exponential backoff. It still counts
+// against maxAttempts, since a push service repeatedly asking to back off is as
+// much a reason to eventually dead-letter as any other failure.
+func (q *DeliveryQueue) scheduleRetryAfter(task deliveryTask, delay time.Duration, lastErr string) {
+	if task.attempt >= q.maxAttempts {
+		q.recordOutcome(task, false, lastErr)
+		return
+	}
+
+	next := task
+	next.attempt++
+	if task.deliveryID != 0 {
+		if err := q.repo.UpdateNotificationDeliveryAttempt(task.deliveryID, deliveryStatusFailed, next.attempt, lastErr, time.Now().Add(delay)); err != nil {
+			q.logger.Error("failed to record notification delivery retry", "delivery_id", task.deliveryID, "error", err)
+		}
+	}
+	if q.metrics != nil {
+		q.metrics.retried.WithLabelValues(task.provider).Inc()
+	}
+
+	time.AfterFunc(delay, func() {
+		select {
+		case q.tasks <- next:
+		case <-q.stopCh:
+		}
+	})
+}
+
+// waitForRateBudget blocks (polling, since rate.Limiter.Allow is non-blocking) until
+// provider has an available send slot for this minute or ctx is done. A provider
+// with no configured limit proceeds immediately.
+func (q *DeliveryQueue) waitForRateBudget(ctx context.Context, provider string) {
+	limit, ok := q.ratePerMin[provider]
+	if !ok || limit <= 0 {
+		return
+	}
+
+	for {
+		if q.limiter.Allow(ctx, provider, "notification_delivery", time.Minute, limit) {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+// recordOutcome persists and logs the result of one send attempt: sent on success;
+// otherwise either a scheduled retry (status failed, requeued via time.AfterFunc
+// after an exponential backoff) or dead once maxAttempts is reached.
+func (q *DeliveryQueue) recordOutcome(task deliveryTask, ok bool, lastErr string) {
+	if ok {
+		if q.metrics != nil {
+			q.metrics.sent.WithLabelValues(task.provider).Inc()
+		}
+		if task.deliveryID != 0 {
+			if err := q.repo.UpdateNotificationDeliveryAttempt(task.deliveryID, deliveryStatusSent, task.attempt, "", time.Now()); err != nil {
+				q.logger.Error("failed to mark notification delivery sent", "delivery_id", task.deliveryID, "error", err)
+			}
+		}
+		return
+	}
+
+	if task.attempt >= q.maxAttempts {
+		if q.metrics != nil {
+			q.metrics.failed.WithLabelValues(task.provider).Inc()
+		}
+		if task.deliveryID != 0 {
+			if err := q.repo.UpdateNotificationDeliveryAttempt(task.deliveryID, deliveryStatusDead, task.attempt, lastErr, time.Now()); err != nil {
+				q.logger.Error("failed to mark notification delivery dead", "delivery_id", task.deliveryID, "error", err)
+			}
+		}
+		return
+	}
+
+	next := task
+	next.attempt = task.attempt + 1
+	delay := backoff(next.attempt, q.initialBackoff, q.maxBackoff)
+	nextAttemptAt := time.Now().Add(delay)
+
+	if task.deliveryID != 0 {
+		if err := q.repo.UpdateNotificationDeliveryAttempt(task.deliveryID, deliveryStatusFailed, next.attempt, lastErr, nextAttemptAt); err != nil {
+			q.logger.Error("failed to record notification delivery retry", "delivery_id", task.deliveryID, "error", err)
+		}
+	}
+	if q.metrics != nil {
+		q.metrics.retried.WithLabelValues(task.provider).Inc()
+	}
+
+	time.AfterFunc(delay, func() {
+		select {
+		case q.tasks <- next:
+		case <-q.stopCh:
+		}
+	})
+}
+
+// enqueue persists a new delivery row and hands it to the worker pool. Blocks if
+// every worker is busy and the buffer is full.
+func (q *DeliveryQueue) enqueue(ctx context.Context, provider, userID string, payload []byte) error {
+	delivery := &store.NotificationDelivery{
+		UserID:        userID,
+		Provider:      provider,
+		Payload:       payload,
+		Status:        deliveryStatusPending,
+		NextAttemptAt: time.Now(),
+	}
+	if err := q.repo.CreateNotificationDelivery(delivery); err != nil {
+		return err
+	}
+
+	task := deliveryTask{deliveryID: delivery.ID, provider: provider, userID: userID, payload: payload, attempt: 0}
+
+	select {
+	case q.tasks <- task:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// loadPending requeues any delivery a previous process crashed between
+// persisting and sending, best-effort on startup. A failure here is logged but
+// not fatal — the scheduler's own retry/cleanup jobs aren't involved, so a row
+// left behind just waits for the next restart.
+func (q *DeliveryQueue) loadPending(limit int) {
+	pending, err := q.repo.GetPendingNotificationDeliveries(limit)
+	if err != nil {
+		q.logger.Error("failed to load pending notification deliveries", "error", err)
+		return
+	}
+
+	for _, d := range pending {
+		task := deliveryTask{deliveryID: d.ID, provider: d.Provider, userID: d.UserID, payload: d.Payload, attempt: d.Attempts}
+		select {
+		case q.tasks <- task:
+		default:
+			q.logger.Warn("delivery queue full while requeuing pending deliveries, will retry on next restart", "delivery_id", d.ID)
+		}
+	}
+	if len(pending) > 0 {
+		q.logger.Info("requeued pending notification deliveries", "count", len(pending))
+	}
+}
+
+// shutdown stops accepting new enqueues' retries from scheduling indefinitely and
+// waits for in-flight and already-buffered tasks to drain, up to ctx's deadline.
+func (q *DeliveryQueue) shutdown(ctx context.Context) error {
+	q.stopOnce.Do(func() {
+		close(q.stopCh)
+		close(q.tasks)
+	})
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// marshalPayload is a small helper so callers building a deliveryTask's payload
+// don't each repeat the marshal-and-log-on-error dance.
+func marshalPayload(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
@@ -0,0 +1,273 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+	"time"
+)
+
+// templateFuncs are the helpers available inside every registry template, a small
+// stdlib-only subset of what a library like sprig offers — just enough for the
+// currency/duration formatting notification copy actually needs, without taking on
+// an external template-helpers dependency.
+var templateFuncs = texttemplate.FuncMap{
+	"formatAmount": func(lamports uint64) string {
+		whole := lamports / 1e9
+		frac := lamports % 1e9
+		s := fmt.Sprintf("%d.%09d", whole, frac)
+		s = strings.TrimRight(s, "0")
+		s = strings.TrimSuffix(s, ".")
+		return s
+	},
+	"formatBps": func(bps int) string {
+		return fmt.Sprintf("%.2f%%", float64(bps)/100)
+	},
+	"formatDuration": func(d time.Duration) string {
+		if d < time.Minute {
+			return fmt.Sprintf("%ds", int(d.Seconds()))
+		}
+		if d < time.Hour {
+			return fmt.Sprintf("%dm", int(d.Minutes()))
+		}
+		hours := int(d.Hours())
+		minutes := int(d.Minutes()) % 60
+		if minutes == 0 {
+			return fmt.Sprintf("%dh", hours)
+		}
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"default": func(fallback, value interface{}) interface{} {
+		if value == nil || value == "" {
+			return fallback
+		}
+		return value
+	},
+}
+
+// templateKind is one of the four files a registry event directory may provide.
+type templateKind string
+
+const (
+	kindSubject templateKind = "subject"
+	kindText    templateKind = "text"
+	kindHTML    templateKind = "html"
+	kindWebPush templateKind = "webpush.json"
+)
+
+// templateSet holds the parsed templates for one (event, locale) pair, plus the
+// source file mtimes loadSet last saw them at, so Render can detect an operator
+// edit and reparse without restarting the process.
+type templateSet struct {
+	subject *texttemplate.Template
+	text    *texttemplate.Template
+	html    *htmltemplate.Template
+	webpush *texttemplate.Template
+	mtimes  map[templateKind]time.Time
+}
+
+// render executes whichever of the four templates are present against data,
+// returning a NotificationTemplate. A kind with no template in this set renders
+// as empty (subject/text/html) or an empty map (webpush).
+func (s *templateSet) render(data interface{}) (*NotificationTemplate, error) {
+	out := &NotificationTemplate{WebPushData: map[string]interface{}{}}
+
+	if s.subject != nil {
+		var buf bytes.Buffer
+		if err := s.subject.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("failed to render subject template: %w", err)
+		}
+		out.Subject = buf.String()
+	}
+	if s.text != nil {
+		var buf bytes.Buffer
+		if err := s.text.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("failed to render text template: %w", err)
+		}
+		out.TextBody = buf.String()
+	}
+	if s.html != nil {
+		var buf bytes.Buffer
+		if err := s.html.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("failed to render html template: %w", err)
+		}
+		out.HTMLBody = buf.String()
+	}
+	if s.webpush != nil {
+		var buf bytes.Buffer
+		if err := s.webpush.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("failed to render webpush template: %w", err)
+		}
+		if err := json.Unmarshal(buf.Bytes(), &out.WebPushData); err != nil {
+			return nil, fmt.Errorf("failed to parse rendered webpush template as json: %w", err)
+		}
+	}
+
+	return out, nil
+}
+
+// TemplateRegistry loads notification templates from a directory of files named
+// "<event>.<locale>.<kind>.tmpl" (e.g. "market_created.en.subject.tmpl"), caching
+// parsed templates per (event, locale) and reparsing a file the next time it's
+// needed if its mtime has moved since the cached parse — a hot reload with no
+// filesystem-watcher dependency, just a stat on each Render call.
+type TemplateRegistry struct {
+	dir    string
+	logger *slog.Logger
+
+	mu    sync.Mutex
+	cache map[string]*templateSet
+}
+
+// NewTemplateRegistry returns a registry that loads templates from dir on demand.
+// dir not existing is not an error here: Render simply fails to find anything and
+// the caller falls back to its built-in default content.
+func NewTemplateRegistry(dir string, logger *slog.Logger) *TemplateRegistry {
+	return &TemplateRegistry{dir: dir, logger: logger, cache: make(map[string]*templateSet)}
+}
+
+// localeFallbackChain expands a locale like "fr-CA" into the order Render tries
+// templates in: the exact locale, its base language, then "en" as the ultimate
+// fallback (added even if the caller already asked for "en").
+func localeFallbackChain(locale string) []string {
+	var chain []string
+	seen := make(map[string]bool)
+	add := func(l string) {
+		if l != "" && !seen[l] {
+			chain = append(chain, l)
+			seen[l] = true
+		}
+	}
+	add(locale)
+	if i := strings.Index(locale, "-"); i > 0 {
+		add(locale[:i])
+	}
+	add("en")
+	return chain
+}
+
+// Render renders event's templates for the best available locale in locale's
+// Accept-Language-style fallback chain ("fr-CA" -> "fr" -> "en"), returning the
+// first (event, candidateLocale) pair that has at least one template file present.
+func (r *TemplateRegistry) Render(event, locale string, data interface{}) (*NotificationTemplate, error) {
+	var lastErr error
+	for _, candidate := range localeFallbackChain(locale) {
+		set, err := r.loadSet(event, candidate)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return set.render(data)
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no templates found for event %q", event)
+	}
+	return nil, fmt.Errorf("no templates found for event %q (locale %q): %w", event, locale, lastErr)
+}
+
+// loadSet returns the cached templateSet for (event, locale), reparsing any file
+// whose mtime has changed since it was last parsed. Returns an error if none of
+// the four files exist for this (event, locale) pair.
+func (r *TemplateRegistry) loadSet(event, locale string) (*templateSet, error) {
+	paths := map[templateKind]string{
+		kindSubject: filepath.Join(r.dir, fmt.Sprintf("%s.%s.subject.tmpl", event, locale)),
+		kindText:    filepath.Join(r.dir, fmt.Sprintf("%s.%s.text.tmpl", event, locale)),
+		kindHTML:    filepath.Join(r.dir, fmt.Sprintf("%s.%s.html.tmpl", event, locale)),
+		kindWebPush: filepath.Join(r.dir, fmt.Sprintf("%s.%s.webpush.json.tmpl", event, locale)),
+	}
+
+	mtimes := make(map[templateKind]time.Time, len(paths))
+	anyExist := false
+	for kind, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		anyExist = true
+		mtimes[kind] = info.ModTime()
+	}
+	if !anyExist {
+		return nil, fmt.Errorf("no template files for event %q locale %q in %s", event, locale, r.dir)
+	}
+
+	key := event + "." + locale
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if cached, ok := r.cache[key]; ok && mtimesEqual(cached.mtimes, mtimes) {
+		return cached, nil
+	}
+
+	set := &templateSet{mtimes: mtimes}
+	for kind, path := range paths {
+		if _, ok := mtimes[kind]; !ok {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+
+		if kind == kindHTML {
+			tmpl, err := htmltemplate.New(string(kind)).Funcs(htmlTemplateFuncs()).Parse(string(data))
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+			}
+			set.html = tmpl
+			continue
+		}
+
+		tmpl, err := texttemplate.New(string(kind)).Funcs(templateFuncs).Parse(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		switch kind {
+		case kindSubject:
+			set.subject = tmpl
+		case kindText:
+			set.text = tmpl
+		case kindWebPush:
+			set.webpush = tmpl
+		}
+	}
+
+	r.cache[key] = set
+	if r.logger != nil {
+		r.logger.Info("loaded notification template set", "event", event, "locale", locale)
+	}
+	return set, nil
+}
+
+// htmlTemplateFuncs adapts templateFuncs for html/template, which requires its own
+// FuncMap type even though the signatures are identical.
+func htmlTemplateFuncs() htmltemplate.FuncMap {
+	out := make(htmltemplate.FuncMap, len(templateFuncs))
+	for k, v := range templateFuncs {
+		out[k] = v
+	}
+	return out
+}
+
+// mtimesEqual reports whether two file-mtime snapshots match exactly, used to
+// decide whether a cached templateSet is still fresh.
+func mtimesEqual(a, b map[templateKind]time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if !b[k].Equal(v) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,61 @@
+package notify
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+	"net/url"
+)
+
+// signLinkToken returns an HMAC-SHA256 token (base64url, unpadded) binding userID
+// to secret. There's no login session behind the unsubscribe/preferences links
+// embedded in an email, so this token is what proves a request for a given
+// user_id actually came from the email sent to that user.
+func signLinkToken(secret, userID string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(userID))
+	return base64urlEncode(mac.Sum(nil))
+}
+
+// verifyLinkToken reports whether token is the valid signature for userID under
+// secret, using a constant-time comparison.
+func verifyLinkToken(secret, userID, token string) bool {
+	return hmac.Equal([]byte(signLinkToken(secret, userID)), []byte(token))
+}
+
+// VerifyLinkToken reports whether token is a valid unsubscribe/preferences link
+// token for userID, for the HTTP handlers in internal/grpc to check before
+// acting on a user_id query param. Always false if Links.Secret is unconfigured.
+func (n *Notifier) VerifyLinkToken(userID, token string) bool {
+	if n.config.Links.Secret == "" {
+		return false
+	}
+	return verifyLinkToken(n.config.Links.Secret, userID, token)
+}
+
+// UnsubscribeLink returns the signed HTTPS one-click unsubscribe URL and (if
+// configured) mailto alternative for userID, for the List-Unsubscribe email
+// header. ok is false if Links.Secret or Links.BaseURL isn't configured, in
+// which case sendEmailNotification omits the header rather than emit a link with
+// no verifiable token.
+func (n *Notifier) UnsubscribeLink(userID string) (httpsURL, mailto string, ok bool) {
+	if n.config.Links.Secret == "" || n.config.Links.BaseURL == "" {
+		return "", "", false
+	}
+	token := signLinkToken(n.config.Links.Secret, userID)
+	httpsURL = fmt.Sprintf("%s/notifications/unsubscribe?user_id=%s&token=%s", n.config.Links.BaseURL, url.QueryEscape(userID), url.QueryEscape(token))
+	if n.config.Links.MailTo != "" {
+		mailto = fmt.Sprintf("mailto:%s?subject=unsubscribe", n.config.Links.MailTo)
+	}
+	return httpsURL, mailto, true
+}
+
+// PreferencesLink returns the signed HTTPS URL for userID's preferences-editing
+// endpoint, or "" if Links.Secret or Links.BaseURL isn't configured.
+func (n *Notifier) PreferencesLink(userID string) string {
+	if n.config.Links.Secret == "" || n.config.Links.BaseURL == "" {
+		return ""
+	}
+	token := signLinkToken(n.config.Links.Secret, userID)
+	return fmt.Sprintf("%s/notifications/preferences?user_id=%s&token=%s", n.config.Links.BaseURL, url.QueryEscape(userID), url.QueryEscape(token))
+}
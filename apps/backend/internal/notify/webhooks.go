@@ -0,0 +1,101 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/friend-bets/backend/internal/config"
+)
+
+// webhookPayload is the JSON body POSTed to every matching webhook.
+type webhookPayload struct {
+	EventType string `json:"event_type"`
+	Data      Event  `json:"data"`
+}
+
+// registerWebhookDispatch subscribes bus to every event type notify sends, and
+// for each published event, POSTs a signed JSON payload to every webhook in
+// webhooks whose Events list includes that event type (or is empty, meaning
+// all). Each delivery runs in its own goroutine so a slow or unreachable
+// endpoint can't hold up the caller that published the event; unlike email/
+// web_push/fcm/apns, deliveries aren't retried or persisted through
+// DeliveryQueue — a webhook payload doesn't carry a subscription or user ID,
+// so it doesn't fit that queue's per-provider/per-user model, and a failed
+// delivery is simply logged. A no-op if webhooks is empty.
+func registerWebhookDispatch(bus *Bus, webhooks []config.WebhookConfig, logger *slog.Logger) {
+	if len(webhooks) == 0 {
+		return
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	dispatch := func(ctx context.Context, event Event) {
+		payload, err := json.Marshal(webhookPayload{EventType: event.eventType(), Data: event})
+		if err != nil {
+			logger.Error("failed to marshal webhook payload", "error", err, "event_type", event.eventType())
+			return
+		}
+
+		for _, wh := range webhooks {
+			if !webhookWantsEvent(wh, event.eventType()) {
+				continue
+			}
+			go sendWebhook(client, logger, wh, payload)
+		}
+	}
+
+	for eventType := range eventTypeMask {
+		bus.Subscribe(eventType, dispatch)
+	}
+}
+
+func webhookWantsEvent(wh config.WebhookConfig, eventType string) bool {
+	if len(wh.Events) == 0 {
+		return true
+	}
+	for _, e := range wh.Events {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func sendWebhook(client *http.Client, logger *slog.Logger, wh config.WebhookConfig, payload []byte) {
+	req, err := http.NewRequest(http.MethodPost, wh.URL, bytes.NewReader(payload))
+	if err != nil {
+		logger.Error("failed to build webhook request", "error", err, "url", wh.URL)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if wh.Secret != "" {
+		req.Header.Set("X-Signature", signWebhookBody(wh.Secret, payload))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		logger.Error("failed to deliver webhook", "error", err, "url", wh.URL)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		logger.Error("webhook endpoint rejected delivery", "url", wh.URL, "status", fmt.Sprintf("%d", resp.StatusCode))
+	}
+}
+
+// signWebhookBody returns the hex-encoded HMAC-SHA256 of body under secret,
+// the same scheme internal/solana.WebhookReceiver verifies on inbound
+// webhooks, applied here to sign an outbound one.
+func signWebhookBody(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
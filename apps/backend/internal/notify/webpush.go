@@ -0,0 +1,301 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// vapidTokenTTL is how long a VAPID JWT is valid for; RFC 8292 caps this at 24h, but
+// a push is always sent and discarded well within minutes so there's no reason to
+// mint a long-lived one.
+const vapidTokenTTL = 12 * time.Hour
+
+// webPushRecordSize is the RFC 8188 aes128gcm record size used in the content-coding
+// header. The payload here (a small JSON blob) always fits in a single record, so
+// this just needs to be at least payload length + 17 (the 0x02 delimiter + GCM tag).
+const webPushRecordSize = 4096
+
+// webPushSubscriptionGoneError marks a push service response of 404/410: the
+// subscription no longer exists on the browser/service side and should stop being
+// sent to, rather than retried.
+type webPushSubscriptionGoneError struct{}
+
+func (webPushSubscriptionGoneError) Error() string { return "push subscription no longer exists" }
+
+// webPushRetryAfterError carries a push service's Retry-After (429/503) so the
+// delivery queue can honor it instead of falling back to its own backoff schedule.
+type webPushRetryAfterError struct {
+	after time.Duration
+}
+
+func (e *webPushRetryAfterError) Error() string {
+	return fmt.Sprintf("push service asked to retry after %s", e.after)
+}
+
+// base64urlEncode/Decode use unpadded base64url, the encoding every Web Push
+// subscription field (endpoint keys, VAPID keys) is specified in.
+func base64urlEncode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func base64urlDecode(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(s)
+}
+
+// loadVAPIDKey parses the configured VAPID signing key into an *ecdsa.PrivateKey.
+// The key is stored as a raw P-256 scalar (base64url, unpadded) rather than PEM,
+// matching the format every JS web-push library's vapid.generateKeys() emits.
+func loadVAPIDKey(privateKeyB64 string) (*ecdsa.PrivateKey, error) {
+	d, err := base64urlDecode(privateKeyB64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode VAPID private key: %w", err)
+	}
+
+	curve := elliptic.P256()
+	priv := new(ecdsa.PrivateKey)
+	priv.Curve = curve
+	priv.D = new(big.Int).SetBytes(d)
+	priv.X, priv.Y = curve.ScalarBaseMult(d)
+	return priv, nil
+}
+
+// vapidAuthorizationHeader builds the "Authorization: vapid t=..., k=..." header
+// value for a push request to endpoint, per RFC 8292: a JWT signed ES256 with aud
+// set to the endpoint's origin, sub identifying this server's operator, and a short
+// expiry.
+func vapidAuthorizationHeader(key *ecdsa.PrivateKey, endpoint, subject string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse push endpoint: %w", err)
+	}
+	aud := u.Scheme + "://" + u.Host
+
+	header := map[string]string{"typ": "JWT", "alg": "ES256"}
+	claims := map[string]interface{}{
+		"aud": aud,
+		"exp": time.Now().Add(vapidTokenTTL).Unix(),
+		"sub": "mailto:" + subject,
+	}
+
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal VAPID JWT header: %w", err)
+	}
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal VAPID JWT claims: %w", err)
+	}
+
+	signingInput := base64urlEncode(headerJSON) + "." + base64urlEncode(claimsJSON)
+
+	digest := sha256.Sum256([]byte(signingInput))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign VAPID JWT: %w", err)
+	}
+
+	// JWS ES256 signatures are the fixed-width concatenation of r and s (32 bytes
+	// each for P-256), not ASN.1 DER.
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+
+	jwt := signingInput + "." + base64urlEncode(sig)
+	pubKeyPoint := elliptic.Marshal(elliptic.P256(), key.X, key.Y)
+
+	return fmt.Sprintf("vapid t=%s, k=%s", jwt, base64urlEncode(pubKeyPoint)), nil
+}
+
+// encryptWebPushPayload implements RFC 8291 (Message Encryption for Web Push) on top
+// of RFC 8188's aes128gcm content coding: it derives a content-encryption key and
+// nonce from an ephemeral ECDH exchange with the subscriber's p256dh key, salted
+// with their auth secret, then returns the single-record aes128gcm body ready to
+// POST as-is.
+func encryptWebPushPayload(plaintext []byte, p256dhB64, authB64 string) ([]byte, error) {
+	uaPubBytes, err := base64urlDecode(p256dhB64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode p256dh key: %w", err)
+	}
+	authSecret, err := base64urlDecode(authB64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode auth secret: %w", err)
+	}
+
+	curve := ecdh.P256()
+	uaPub, err := curve.NewPublicKey(uaPubBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse subscriber public key: %w", err)
+	}
+
+	asPriv, err := curve.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate ephemeral key: %w", err)
+	}
+	asPubBytes := asPriv.PublicKey().Bytes()
+
+	sharedSecret, err := asPriv.ECDH(uaPub)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute ECDH shared secret: %w", err)
+	}
+
+	// ikm = HKDF-Expand(HKDF-Extract(auth_secret, ecdh_secret), "WebPush: info\0" ||
+	// ua_public || as_public, 32) per RFC 8291 section 3.3.
+	keyInfo := append([]byte("WebPush: info\x00"), uaPubBytes...)
+	keyInfo = append(keyInfo, asPubBytes...)
+	ikm, err := hkdfExpand(authSecret, sharedSecret, keyInfo, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive IKM: %w", err)
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	cek, err := hkdfExpand(salt, ikm, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive content encryption key: %w", err)
+	}
+	nonce, err := hkdfExpand(salt, ikm, []byte("Content-Encoding: nonce\x00"), 12)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive nonce: %w", err)
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize GCM: %w", err)
+	}
+
+	// A single-record body: the plaintext followed by the 0x02 "last record"
+	// delimiter required by RFC 8188 section 2, then sealed under nonce for record
+	// sequence 0 (so no XOR against the derived nonce is needed).
+	padded := append(append([]byte{}, plaintext...), 0x02)
+	ciphertext := gcm.Seal(nil, nonce, padded, nil)
+
+	header := make([]byte, 16+4+1+len(asPubBytes))
+	copy(header[0:16], salt)
+	binary.BigEndian.PutUint32(header[16:20], webPushRecordSize)
+	header[20] = byte(len(asPubBytes))
+	copy(header[21:], asPubBytes)
+
+	return append(header, ciphertext...), nil
+}
+
+// hkdfExpand runs HKDF-Extract(salt, ikm) followed by HKDF-Expand(..., info, size),
+// the two-step derivation RFC 8291 uses repeatedly with different salts/info.
+func hkdfExpand(salt, ikm, info []byte, size int) ([]byte, error) {
+	reader := hkdf.New(sha256.New, ikm, salt, info)
+	out := make([]byte, size)
+	if _, err := io.ReadFull(reader, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// sendWebPushNotification sends a Web Push message per RFC 8291/8292/8188: the
+// payload is AEAD-encrypted under a key derived from an ephemeral ECDH exchange with
+// the subscriber, and the request is authenticated with a VAPID JWT identifying this
+// server. A 404/410 response means the subscription is gone and is reported as
+// webPushSubscriptionGoneError so the caller can deactivate it instead of retrying; a
+// 429/503 with Retry-After is reported as webPushRetryAfterError so the delivery
+// queue can honor the requested delay.
+func (n *Notifier) sendWebPushNotification(ctx context.Context, notification *WebPushNotification) error {
+	if !n.config.WebPush.Enabled {
+		return fmt.Errorf("web push not enabled")
+	}
+
+	vapidKey, err := loadVAPIDKey(n.config.WebPush.VapidPrivateKey)
+	if err != nil {
+		return fmt.Errorf("failed to load VAPID key: %w", err)
+	}
+
+	body, err := encryptWebPushPayload([]byte(notification.Payload), notification.Keys.P256dh, notification.Keys.Auth)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt web push payload: %w", err)
+	}
+
+	authHeader, err := vapidAuthorizationHeader(vapidKey, notification.Endpoint, n.config.WebPush.VapidEmail)
+	if err != nil {
+		return fmt.Errorf("failed to build VAPID authorization: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, notification.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build push request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("TTL", "86400")
+	req.Header.Set("Urgency", "normal")
+	req.Header.Set("Authorization", authHeader)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send push request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		n.logger.Info("web push notification sent", "endpoint", truncateEndpoint(notification.Endpoint))
+		return nil
+
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		n.logger.Info("push subscription gone, deactivating", "endpoint", truncateEndpoint(notification.Endpoint), "status", resp.StatusCode)
+		if err := n.repo.DisableNotificationSubscriptionByEndpoint(notification.Endpoint); err != nil {
+			n.logger.Error("failed to deactivate gone push subscription", "error", err)
+		}
+		return webPushSubscriptionGoneError{}
+
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable:
+		after := parseRetryAfter(resp.Header.Get("Retry-After"))
+		return &webPushRetryAfterError{after: after}
+
+	default:
+		return fmt.Errorf("push service returned status %d", resp.StatusCode)
+	}
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds, falling back to 60s
+// if it's missing or malformed (Retry-After may also be an HTTP date, which push
+// services don't use in practice, so that form isn't handled here).
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 60 * time.Second
+	}
+	secs, err := strconv.Atoi(value)
+	if err != nil || secs < 0 {
+		return 60 * time.Second
+	}
+	return time.Duration(secs) * time.Second
+}
+
+func truncateEndpoint(endpoint string) string {
+	if len(endpoint) <= 50 {
+		return endpoint
+	}
+	return endpoint[:50] + "..."
+}
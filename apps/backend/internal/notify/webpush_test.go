@@ -0,0 +1,231 @@
+package notify
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/friend-bets/backend/internal/config"
+)
+
+// decryptAsFakePushService reverses encryptWebPushPayload using the subscriber's own
+// private key, playing the role the real push service/browser would, to confirm the
+// envelope sendWebPushNotification produces is actually decryptable by its recipient
+// and not just internally self-consistent.
+func decryptAsFakePushService(t *testing.T, body []byte, uaPriv *ecdh.PrivateKey, authSecret []byte) []byte {
+	t.Helper()
+
+	salt := body[0:16]
+	idlen := int(body[20])
+	asPubBytes := body[21 : 21+idlen]
+	ciphertext := body[21+idlen:]
+
+	asPub, err := ecdh.P256().NewPublicKey(asPubBytes)
+	if err != nil {
+		t.Fatalf("failed to parse ephemeral public key: %v", err)
+	}
+	sharedSecret, err := uaPriv.ECDH(asPub)
+	if err != nil {
+		t.Fatalf("failed to compute shared secret: %v", err)
+	}
+
+	uaPubBytes := uaPriv.PublicKey().Bytes()
+	keyInfo := append([]byte("WebPush: info\x00"), uaPubBytes...)
+	keyInfo = append(keyInfo, asPubBytes...)
+	ikm, err := hkdfExpand(authSecret, sharedSecret, keyInfo, 32)
+	if err != nil {
+		t.Fatalf("failed to derive IKM: %v", err)
+	}
+	cek, err := hkdfExpand(salt, ikm, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	if err != nil {
+		t.Fatalf("failed to derive CEK: %v", err)
+	}
+	nonce, err := hkdfExpand(salt, ikm, []byte("Content-Encoding: nonce\x00"), 12)
+	if err != nil {
+		t.Fatalf("failed to derive nonce: %v", err)
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		t.Fatalf("failed to init cipher: %v", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatalf("failed to init GCM: %v", err)
+	}
+	padded, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		t.Fatalf("failed to decrypt body: %v", err)
+	}
+	if len(padded) == 0 || padded[len(padded)-1] != 0x02 {
+		t.Fatalf("missing RFC 8188 last-record delimiter")
+	}
+	return padded[:len(padded)-1]
+}
+
+// verifyVAPIDHeader parses the "vapid t=..., k=..." Authorization header, verifies
+// the JWT's ES256 signature against the embedded public key, and returns its claims.
+func verifyVAPIDHeader(t *testing.T, header string) map[string]interface{} {
+	t.Helper()
+
+	if !strings.HasPrefix(header, "vapid t=") {
+		t.Fatalf("unexpected authorization scheme: %q", header)
+	}
+	rest := strings.TrimPrefix(header, "vapid t=")
+	parts := strings.SplitN(rest, ", k=", 2)
+	if len(parts) != 2 {
+		t.Fatalf("malformed vapid header: %q", header)
+	}
+	jwt, kParam := parts[0], parts[1]
+
+	pubBytes, err := base64urlDecode(kParam)
+	if err != nil {
+		t.Fatalf("failed to decode vapid public key: %v", err)
+	}
+	x, y := elliptic.Unmarshal(elliptic.P256(), pubBytes)
+	if x == nil {
+		t.Fatalf("failed to unmarshal vapid public key point")
+	}
+	pub := &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+
+	segs := strings.Split(jwt, ".")
+	if len(segs) != 3 {
+		t.Fatalf("malformed jwt: %q", jwt)
+	}
+	digest := sha256.Sum256([]byte(segs[0] + "." + segs[1]))
+	sig, err := base64urlDecode(segs[2])
+	if err != nil || len(sig) != 64 {
+		t.Fatalf("failed to decode jwt signature: %v", err)
+	}
+	r := new(big.Int).SetBytes(sig[:32])
+	s := new(big.Int).SetBytes(sig[32:])
+	if !ecdsa.Verify(pub, digest[:], r, s) {
+		t.Fatalf("vapid jwt signature did not verify")
+	}
+
+	claimsJSON, err := base64urlDecode(segs[1])
+	if err != nil {
+		t.Fatalf("failed to decode jwt claims: %v", err)
+	}
+	var claims map[string]interface{}
+	if err := json.Unmarshal(claimsJSON, &claims); err != nil {
+		t.Fatalf("failed to unmarshal jwt claims: %v", err)
+	}
+	return claims
+}
+
+func TestSendWebPushNotification_EncryptsAndSignsForFakePushService(t *testing.T) {
+	vapidD := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, vapidD); err != nil {
+		t.Fatalf("failed to generate vapid key: %v", err)
+	}
+
+	uaPriv, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate subscriber key: %v", err)
+	}
+	authSecret := make([]byte, 16)
+	if _, err := io.ReadFull(rand.Reader, authSecret); err != nil {
+		t.Fatalf("failed to generate auth secret: %v", err)
+	}
+
+	const wantPayload = `{"title":"Market Resolved","body":"Outcome: A"}`
+
+	var gotClaims map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Encoding") != "aes128gcm" {
+			t.Errorf("expected Content-Encoding: aes128gcm, got %q", r.Header.Get("Content-Encoding"))
+		}
+		gotClaims = verifyVAPIDHeader(t, r.Header.Get("Authorization"))
+
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("failed to read request body: %v", err)
+		}
+		plaintext := decryptAsFakePushService(t, body, uaPriv, authSecret)
+		if string(plaintext) != wantPayload {
+			t.Errorf("decrypted payload mismatch: got %q want %q", plaintext, wantPayload)
+		}
+
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	cfg := &config.NotifyConfig{}
+	cfg.WebPush.Enabled = true
+	cfg.WebPush.VapidPrivateKey = base64urlEncode(vapidD)
+	cfg.WebPush.VapidEmail = "ops@example.com"
+
+	n := &Notifier{
+		config: cfg,
+		logger: slog.New(slog.NewTextHandler(ioutil.Discard, nil)),
+	}
+
+	err = n.sendWebPushNotification(context.Background(), &WebPushNotification{
+		Endpoint: server.URL + "/subscription/abc123",
+		Payload:  wantPayload,
+		Keys: WebPushKeys{
+			P256dh: base64urlEncode(uaPriv.PublicKey().Bytes()),
+			Auth:   base64urlEncode(authSecret),
+		},
+	})
+	if err != nil {
+		t.Fatalf("sendWebPushNotification returned error: %v", err)
+	}
+
+	wantAud := strings.TrimSuffix(server.URL, "")
+	if gotClaims["aud"] != wantAud {
+		t.Errorf("jwt aud = %v, want %v", gotClaims["aud"], wantAud)
+	}
+	if gotClaims["sub"] != "mailto:ops@example.com" {
+		t.Errorf("jwt sub = %v, want mailto:ops@example.com", gotClaims["sub"])
+	}
+}
+
+func TestEncryptWebPushPayload_RecordSizeHeader(t *testing.T) {
+	uaPriv, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate subscriber key: %v", err)
+	}
+	authSecret := make([]byte, 16)
+	io.ReadFull(rand.Reader, authSecret)
+
+	body, err := encryptWebPushPayload([]byte("hello"), base64urlEncode(uaPriv.PublicKey().Bytes()), base64urlEncode(authSecret))
+	if err != nil {
+		t.Fatalf("encryptWebPushPayload returned error: %v", err)
+	}
+
+	recordSize := binary.BigEndian.Uint32(body[16:20])
+	if recordSize != webPushRecordSize {
+		t.Fatalf("record size header = %d, want %d", recordSize, webPushRecordSize)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	cases := map[string]float64{
+		"120": 120,
+		"":    60,
+		"abc": 60,
+		"-5":  60,
+	}
+	for input, want := range cases {
+		if got := parseRetryAfter(input).Seconds(); got != want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", input, got, want)
+		}
+	}
+}
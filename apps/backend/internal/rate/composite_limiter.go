@@ -0,0 +1,125 @@
+package rate
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// StageResult reports whether one stage of a CompositeLimiter check admitted the
+// request, and if not, how long the caller should wait before retrying that stage.
+type StageResult struct {
+	Stage      string
+	Allowed    bool
+	RetryAfter time.Duration
+}
+
+type compositeStage struct {
+	dimension string // "per_user", "per_ip", or "global"
+	limit     int
+	window    time.Duration
+}
+
+// CompositeLimiter evaluates a request against several stacked quotas — e.g. per-user,
+// per-IP, and global — atomically: either every stage admits the request and all of
+// them decrement together, or none of them do. This avoids the partial-consumption bug
+// of calling Limiter.Allow once per stage, where an earlier stage can spend a token
+// before a later stage denies the request outright.
+type CompositeLimiter struct {
+	limiter *Limiter
+	stages  []compositeStage
+}
+
+// NewComposite starts a CompositeLimiter builder backed by limiter's bucket store, so
+// composite stages share the same LRU-bounded buckets as plain Allow/AllowN calls.
+func NewComposite(limiter *Limiter) *CompositeLimiter {
+	return &CompositeLimiter{limiter: limiter}
+}
+
+// PerUser adds a per-user quota stage.
+func (c *CompositeLimiter) PerUser(limit int, window time.Duration) *CompositeLimiter {
+	c.stages = append(c.stages, compositeStage{dimension: "per_user", limit: limit, window: window})
+	return c
+}
+
+// PerIP adds a per-IP quota stage.
+func (c *CompositeLimiter) PerIP(limit int, window time.Duration) *CompositeLimiter {
+	c.stages = append(c.stages, compositeStage{dimension: "per_ip", limit: limit, window: window})
+	return c
+}
+
+// Global adds a quota stage shared across every caller of the given action.
+func (c *CompositeLimiter) Global(limit int, window time.Duration) *CompositeLimiter {
+	c.stages = append(c.stages, compositeStage{dimension: "global", limit: limit, window: window})
+	return c
+}
+
+type resolvedStage struct {
+	stage  compositeStage
+	key    string
+	bucket *TokenBucket
+}
+
+// Allow checks all configured stages for action (keyed by userID/ip as each stage
+// needs) and, only if every stage currently has a token available, consumes one token
+// from each. Results are returned in the order stages were added to the builder.
+func (c *CompositeLimiter) Allow(action, userID, ip string) ([]StageResult, bool) {
+	resolved := make([]resolvedStage, len(c.stages))
+	for i, s := range c.stages {
+		var key string
+		switch s.dimension {
+		case "per_user":
+			key = fmt.Sprintf("user:%s:%s", userID, action)
+		case "per_ip":
+			key = fmt.Sprintf("ip:%s:%s", ip, action)
+		case "global":
+			key = fmt.Sprintf("global:%s", action)
+		}
+		resolved[i] = resolvedStage{
+			stage:  s,
+			key:    key,
+			bucket: c.limiter.getOrCreateBucket(key, s.limit, s.window),
+		}
+	}
+
+	// Lock every stage's bucket mutex in a deterministic order (sorted by bucket key)
+	// so two concurrent composite checks that share some but not all stages can never
+	// deadlock against each other.
+	lockOrder := make([]int, len(resolved))
+	for i := range lockOrder {
+		lockOrder[i] = i
+	}
+	sort.Slice(lockOrder, func(i, j int) bool {
+		return resolved[lockOrder[i]].key < resolved[lockOrder[j]].key
+	})
+	for _, i := range lockOrder {
+		resolved[i].bucket.mu.Lock()
+	}
+	defer func() {
+		for _, i := range lockOrder {
+			resolved[i].bucket.mu.Unlock()
+		}
+	}()
+
+	results := make([]StageResult, len(resolved))
+	allAllowed := true
+	for i, rs := range resolved {
+		rs.bucket.refill()
+		allowed := rs.bucket.tokens >= 1
+		results[i] = StageResult{Stage: rs.stage.dimension, Allowed: allowed}
+		if !allowed {
+			results[i].RetryAfter = time.Duration(1-rs.bucket.tokens) * rs.bucket.refillRate
+			allAllowed = false
+		}
+	}
+
+	if !allAllowed {
+		return results, false
+	}
+
+	for _, rs := range resolved {
+		rs.bucket.tokens--
+	}
+
+	return results, true
+}
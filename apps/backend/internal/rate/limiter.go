@@ -1,6 +1,7 @@
 package rate
 
 import (
+	"container/list"
 	"context"
 	"fmt"
 	"log/slog"
@@ -13,29 +14,41 @@ import (
 
 // Limiter provides rate limiting functionality using token bucket algorithm
 type Limiter struct {
-	config  *config.RateConfig
-	repo    *store.Repository
-	buckets map[string]*TokenBucket
-	mu      sync.RWMutex
-	logger  *slog.Logger
+	config           *config.RateConfig
+	repo             *store.Repository
+	elems            map[string]*list.Element // key -> element wrapping *bucketEntry, most-recently-used at the front
+	lru              *list.List
+	maxKeys          int           // 0 means unbounded
+	maxFutureReserve time.Duration // 0 means unbounded
+	mu               sync.Mutex
+	logger           *slog.Logger
+}
+
+// bucketEntry is the value stored in each lru list element
+type bucketEntry struct {
+	key    string
+	bucket *TokenBucket
 }
 
 // TokenBucket represents a token bucket for rate limiting
 type TokenBucket struct {
-	capacity     int
-	tokens       int
-	refillRate   time.Duration
-	lastRefill   time.Time
-	mu           sync.Mutex
+	capacity   int
+	tokens     int
+	refillRate time.Duration
+	lastRefill time.Time
+	mu         sync.Mutex
 }
 
 // NewLimiter creates a new rate limiter
 func NewLimiter(cfg *config.RateConfig, repo *store.Repository, logger *slog.Logger) *Limiter {
 	limiter := &Limiter{
-		config:  cfg,
-		repo:    repo,
-		buckets: make(map[string]*TokenBucket),
-		logger:  logger,
+		config:           cfg,
+		repo:             repo,
+		elems:            make(map[string]*list.Element),
+		lru:              list.New(),
+		maxKeys:          cfg.MaxTrackedKeys,
+		maxFutureReserve: time.Duration(cfg.MaxFutureReserveSec) * time.Second,
+		logger:           logger,
 	}
 
 	// Start cleanup routine
@@ -56,12 +69,16 @@ func (l *Limiter) Allow(ctx context.Context, key, action string, window time.Dur
 		return false
 	}
 
-	// Also track in database for persistence across restarts
-	go func() {
-		if _, err := l.repo.IncrementRateCounter(bucketKey, window); err != nil {
-			l.logger.Error("failed to increment rate counter in database", "error", err)
-		}
-	}()
+	// Optionally mirror the decision to the durable audit sink; the admission
+	// decision above is already final and made purely from the in-memory bucket,
+	// so a slow or failing audit write can never affect whether this call admits.
+	if l.config.DurableAudit && l.repo != nil {
+		go func() {
+			if _, err := l.repo.IncrementRateCounter(bucketKey, window); err != nil {
+				l.logger.Error("failed to write rate limit audit record", "error", err)
+			}
+		}()
+	}
 
 	return true
 }
@@ -77,10 +94,10 @@ func (l *Limiter) AllowN(ctx context.Context, key, action string, window time.Du
 // Reset resets the rate limit for a specific key and action
 func (l *Limiter) Reset(ctx context.Context, key, action string) error {
 	bucketKey := fmt.Sprintf("%s:%s", key, action)
-	
+
 	l.mu.Lock()
-	if bucket, exists := l.buckets[bucketKey]; exists {
-		bucket.reset()
+	if elem, exists := l.elems[bucketKey]; exists {
+		elem.Value.(*bucketEntry).bucket.reset()
 	}
 	l.mu.Unlock()
 
@@ -90,15 +107,16 @@ func (l *Limiter) Reset(ctx context.Context, key, action string) error {
 // GetUsage returns the current usage for a key and action
 func (l *Limiter) GetUsage(ctx context.Context, key, action string) (int, int, error) {
 	bucketKey := fmt.Sprintf("%s:%s", key, action)
-	
-	l.mu.RLock()
-	bucket, exists := l.buckets[bucketKey]
-	l.mu.RUnlock()
+
+	l.mu.Lock()
+	elem, exists := l.elems[bucketKey]
+	l.mu.Unlock()
 
 	if !exists {
 		return 0, l.getLimitForAction(action), nil
 	}
 
+	bucket := elem.Value.(*bucketEntry).bucket
 	bucket.mu.Lock()
 	used := bucket.capacity - bucket.tokens
 	capacity := bucket.capacity
@@ -107,33 +125,37 @@ func (l *Limiter) GetUsage(ctx context.Context, key, action string) (int, int, e
 	return used, capacity, nil
 }
 
-// getOrCreateBucket gets an existing token bucket or creates a new one
+// getOrCreateBucket gets an existing token bucket or creates a new one, marking it
+// most-recently-used. Once more than maxKeys buckets are tracked, the least-recently-used
+// one is evicted; a key that reappears after eviction is treated as implicitly allowed
+// (it starts over with a full bucket), which is an accepted tradeoff for bounding memory.
 func (l *Limiter) getOrCreateBucket(key string, capacity int, refillPeriod time.Duration) *TokenBucket {
-	l.mu.RLock()
-	bucket, exists := l.buckets[key]
-	l.mu.RUnlock()
-
-	if exists {
-		return bucket
-	}
-
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
-	// Double-check after acquiring write lock
-	if bucket, exists := l.buckets[key]; exists {
-		return bucket
+	if elem, exists := l.elems[key]; exists {
+		l.lru.MoveToFront(elem)
+		return elem.Value.(*bucketEntry).bucket
 	}
 
-	// Create new bucket
-	bucket = &TokenBucket{
+	bucket := &TokenBucket{
 		capacity:   capacity,
 		tokens:     capacity,
 		refillRate: refillPeriod / time.Duration(capacity),
 		lastRefill: time.Now(),
 	}
 
-	l.buckets[key] = bucket
+	elem := l.lru.PushFront(&bucketEntry{key: key, bucket: bucket})
+	l.elems[key] = elem
+
+	if l.maxKeys > 0 && len(l.elems) > l.maxKeys {
+		oldest := l.lru.Back()
+		if oldest != nil {
+			l.lru.Remove(oldest)
+			delete(l.elems, oldest.Value.(*bucketEntry).key)
+		}
+	}
+
 	return bucket
 }
 
@@ -151,6 +173,21 @@ func (l *Limiter) getLimitForAction(action string) int {
 	}
 }
 
+// getWindowForAction gets the default refill window for an action, mirroring
+// getLimitForAction so Reserve/Wait can derive a full policy from just a key+action
+func (l *Limiter) getWindowForAction(action string) time.Duration {
+	switch action {
+	case "create_market":
+		return time.Hour
+	case "place_bet":
+		return time.Minute
+	case "general":
+		return time.Minute
+	default:
+		return time.Minute
+	}
+}
+
 // cleanupRoutine periodically cleans up old token buckets
 func (l *Limiter) cleanupRoutine() {
 	ticker := time.NewTicker(5 * time.Minute)
@@ -164,29 +201,38 @@ func (l *Limiter) cleanupRoutine() {
 	}
 }
 
-// cleanup removes old token buckets that haven't been used recently
+// cleanup removes old token buckets that haven't been used recently. The LRU bound in
+// getOrCreateBucket is the primary defense against unbounded growth; this sweep still
+// catches keys that are stale but never pushed out because maxKeys was never reached.
 func (l *Limiter) cleanup() {
 	l.mu.Lock()
-	defer l.mu.Unlock()
-
 	cutoff := time.Now().Add(-10 * time.Minute)
-	
-	for key, bucket := range l.buckets {
+
+	var toRemove []*list.Element
+	for elem := l.lru.Back(); elem != nil; elem = elem.Prev() {
+		bucket := elem.Value.(*bucketEntry).bucket
 		bucket.mu.Lock()
 		lastUsed := bucket.lastRefill
 		bucket.mu.Unlock()
 
 		if lastUsed.Before(cutoff) {
-			delete(l.buckets, key)
+			toRemove = append(toRemove, elem)
 		}
 	}
+	for _, elem := range toRemove {
+		l.lru.Remove(elem)
+		delete(l.elems, elem.Value.(*bucketEntry).key)
+	}
+	l.mu.Unlock()
 
-	// Also cleanup database counters
-	go func() {
-		if err := l.repo.CleanupExpiredRateCounters(); err != nil {
-			l.logger.Error("failed to cleanup expired rate counters", "error", err)
-		}
-	}()
+	// Also cleanup database audit records, if the durable audit sink is enabled
+	if l.config.DurableAudit && l.repo != nil {
+		go func() {
+			if err := l.repo.CleanupExpiredRateCounters(); err != nil {
+				l.logger.Error("failed to cleanup expired rate limit audit records", "error", err)
+			}
+		}()
+	}
 }
 
 // Token bucket methods
@@ -196,13 +242,32 @@ func (tb *TokenBucket) consume() bool {
 	return tb.consumeN(1)
 }
 
-// consumeN attempts to consume N tokens from the bucket
+// consumeN attempts to consume N tokens from the bucket. Unlike a plain token bucket,
+// tokens are allowed to go negative: a key that keeps hammering a bucket past zero
+// racks up debt it must refill before it is admitted again, rather than simply being
+// denied at the door for free. This makes abusive keys pay a cooldown proportional to
+// how far they overshot, instead of an outlier flood of well-behaved-looking retries.
 func (tb *TokenBucket) consumeN(n int) bool {
 	tb.mu.Lock()
 	defer tb.mu.Unlock()
 
 	tb.refill()
 
+	allowed := tb.tokens >= n
+	tb.tokens -= n
+	return allowed
+}
+
+// tryConsumeN attempts to consume N tokens, only decrementing on success. Unlike
+// consumeN, a failed attempt leaves no debt behind — used by callers such as
+// MultiLimiter.Take that poll in a loop and block rather than reject, where charging
+// a penalty for every poll tick would keep pushing the wait out indefinitely.
+func (tb *TokenBucket) tryConsumeN(n int) bool {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	tb.refill()
+
 	if tb.tokens >= n {
 		tb.tokens -= n
 		return true
@@ -211,11 +276,13 @@ func (tb *TokenBucket) consumeN(n int) bool {
 	return false
 }
 
-// refill adds tokens to the bucket based on elapsed time
+// refill adds tokens to the bucket based on elapsed time, including when the balance
+// is negative — a key in debt climbs back toward (and eventually past) zero at the
+// same rate it would have refilled from empty.
 func (tb *TokenBucket) refill() {
 	now := time.Now()
 	elapsed := now.Sub(tb.lastRefill)
-	
+
 	tokensToAdd := int(elapsed / tb.refillRate)
 	if tokensToAdd > 0 {
 		tb.tokens += tokensToAdd
@@ -241,7 +308,7 @@ func (tb *TokenBucket) reset() {
 func (l *Limiter) CheckBurstLimit(ctx context.Context, key string, burstWindow time.Duration, burstLimit int) bool {
 	// Track requests in a sliding window
 	windowKey := fmt.Sprintf("burst:%s", key)
-	
+
 	// Use token bucket with burst capacity
 	bucket := l.getOrCreateBucket(windowKey, burstLimit, burstWindow)
 	return bucket.consume()
@@ -257,11 +324,14 @@ func (l *Limiter) CheckGlobalLimit(ctx context.Context, action string, globalLim
 // Adaptive rate limiting based on system load
 type LoadBasedLimiter struct {
 	*Limiter
-	baseLimit    int
-	loadFactor   func() float64 // Function to get current system load (0.0 - 1.0)
+	baseLimit  int
+	loadFactor func() float64 // Function to get current system load (0.0 - 1.0)
+	sampler    *LoadSampler   // optional; set via NewAdaptiveLimiter, nil when loadFactor is hand-rolled
 }
 
-// NewLoadBasedLimiter creates a rate limiter that adapts to system load
+// NewLoadBasedLimiter creates a rate limiter that adapts to system load, given an
+// arbitrary loadFactor function. Prefer NewAdaptiveLimiter when sampling live runtime
+// signals rather than supplying a custom load source.
 func NewLoadBasedLimiter(cfg *config.RateConfig, repo *store.Repository, logger *slog.Logger, loadFactor func() float64) *LoadBasedLimiter {
 	return &LoadBasedLimiter{
 		Limiter:    NewLimiter(cfg, repo, logger),
@@ -270,12 +340,30 @@ func NewLoadBasedLimiter(cfg *config.RateConfig, repo *store.Repository, logger
 	}
 }
 
-// AllowAdaptive allows requests based on current system load
+// NewAdaptiveLimiter creates a LoadBasedLimiter driven by a LoadSampler that samples
+// goroutine count, GC pause fraction, and database ping latency. The sampler is
+// started immediately and keeps sampling until ctx is cancelled.
+func NewAdaptiveLimiter(ctx context.Context, cfg *config.RateConfig, repo *store.Repository, logger *slog.Logger) *LoadBasedLimiter {
+	sampler := NewLoadSampler(repo, logger)
+	sampler.Start(ctx)
+
+	return &LoadBasedLimiter{
+		Limiter:    NewLimiter(cfg, repo, logger),
+		baseLimit:  cfg.IPRatePerMinute,
+		loadFactor: sampler.LoadFactor,
+		sampler:    sampler,
+	}
+}
+
+// AllowAdaptive allows requests based on current system load. Rather than a linear
+// reduction, the limit follows a piecewise curve (soft: 1.0x, warn: 0.75x, hot: 0.4x,
+// critical: 0.1x) so operators can reason about behavior at each threshold instead of
+// a limit that drifts continuously with the raw load score.
 func (lbl *LoadBasedLimiter) AllowAdaptive(ctx context.Context, key, action string, window time.Duration) bool {
 	load := lbl.loadFactor()
-	
-	// Reduce limit based on load (higher load = lower limit)
-	adaptiveLimit := int(float64(lbl.baseLimit) * (1.0 - load*0.5))
+	level := classifyLoad(load)
+
+	adaptiveLimit := int(float64(lbl.baseLimit) * multiplierForLevel(level))
 	if adaptiveLimit < 1 {
 		adaptiveLimit = 1
 	}
@@ -283,6 +371,25 @@ func (lbl *LoadBasedLimiter) AllowAdaptive(ctx context.Context, key, action stri
 	return lbl.Allow(ctx, key, action, window, adaptiveLimit)
 }
 
+// GetLimiterStats returns the underlying bucket statistics plus, when this limiter was
+// created via NewAdaptiveLimiter, the sampled load subscores for observability.
+func (lbl *LoadBasedLimiter) GetLimiterStats() map[string]interface{} {
+	stats := lbl.Limiter.GetLimiterStats()
+
+	if lbl.sampler != nil {
+		sub := lbl.sampler.Subscores()
+		stats["load"] = map[string]interface{}{
+			"goroutine_score":  sub.GoroutineScore,
+			"gc_pause_score":   sub.GCPauseScore,
+			"db_latency_score": sub.DBLatencyScore,
+			"combined_score":   sub.Combined,
+			"level":            string(sub.Level),
+		}
+	}
+
+	return stats
+}
+
 // Distributed rate limiting (for multiple server instances)
 type DistributedLimiter struct {
 	*Limiter
@@ -301,7 +408,7 @@ func NewDistributedLimiter(cfg *config.RateConfig, repo *store.Repository, logge
 func (dl *DistributedLimiter) AllowDistributed(ctx context.Context, key, action string, window time.Duration, limit int) bool {
 	// Use database-based counting for distributed rate limiting
 	distributedKey := fmt.Sprintf("dist:%s:%s", key, action)
-	
+
 	count, err := dl.repo.IncrementRateCounter(distributedKey, window)
 	if err != nil {
 		dl.logger.Error("failed to check distributed rate limit", "error", err)
@@ -321,11 +428,11 @@ func (dl *DistributedLimiter) AllowDistributed(ctx context.Context, key, action
 
 // RateLimitInfo contains information about rate limiting status
 type RateLimitInfo struct {
-	Allowed       bool
-	Limit         int
-	Remaining     int
-	ResetTime     time.Time
-	RetryAfter    time.Duration
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	ResetTime  time.Time
+	RetryAfter time.Duration
 }
 
 // CheckWithInfo checks rate limit and returns detailed information
@@ -335,7 +442,7 @@ func (l *Limiter) CheckWithInfo(ctx context.Context, key, action string, window
 
 	bucket.mu.Lock()
 	bucket.refill()
-	
+
 	info := &RateLimitInfo{
 		Allowed:   bucket.tokens > 0,
 		Limit:     bucket.capacity,
@@ -349,7 +456,7 @@ func (l *Limiter) CheckWithInfo(ctx context.Context, key, action string, window
 		// Calculate retry after duration
 		info.RetryAfter = bucket.refillRate
 	}
-	
+
 	bucket.mu.Unlock()
 
 	return info
@@ -359,15 +466,17 @@ func (l *Limiter) CheckWithInfo(ctx context.Context, key, action string, window
 
 // GetLimiterStats returns statistics about the rate limiter
 func (l *Limiter) GetLimiterStats() map[string]interface{} {
-	l.mu.RLock()
-	defer l.mu.RUnlock()
+	l.mu.Lock()
+	defer l.mu.Unlock()
 
 	stats := map[string]interface{}{
-		"total_buckets": len(l.buckets),
+		"total_buckets": len(l.elems),
+		"max_buckets":   l.maxKeys,
 		"buckets":       make(map[string]interface{}),
 	}
 
-	for key, bucket := range l.buckets {
+	for key, elem := range l.elems {
+		bucket := elem.Value.(*bucketEntry).bucket
 		bucket.mu.Lock()
 		bucketStats := map[string]interface{}{
 			"capacity":    bucket.capacity,
@@ -376,7 +485,7 @@ func (l *Limiter) GetLimiterStats() map[string]interface{} {
 			"last_refill": bucket.lastRefill,
 		}
 		bucket.mu.Unlock()
-		
+
 		stats["buckets"].(map[string]interface{})[key] = bucketStats
 	}
 
@@ -387,4 +496,4 @@ func (l *Limiter) GetLimiterStats() map[string]interface{} {
 func (l *Limiter) Health() error {
 	// Check if we can access the database
 	return l.repo.Health()
-}
\ No newline at end of file
+}
@@ -0,0 +1,110 @@
+package rate
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/friend-bets/backend/internal/config"
+)
+
+// newTestLimiter builds a Limiter with no database-backed repository. Allow() spawns a
+// background write through the repository, so tests exercise AllowN instead, which only
+// touches the in-memory token bucket.
+func newTestLimiter(maxKeys int) *Limiter {
+	cfg := &config.RateConfig{MaxTrackedKeys: maxKeys}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return NewLimiter(cfg, nil, logger)
+}
+
+func TestLimiter_LRUBoundsMemoryUnderKeyFlood(t *testing.T) {
+	l := newTestLimiter(100)
+	ctx := context.Background()
+
+	for i := 0; i < 10000; i++ {
+		l.AllowN(ctx, fmt.Sprintf("key-%d", i), "general", time.Minute, 5, 1)
+	}
+
+	l.mu.Lock()
+	tracked := len(l.elems)
+	l.mu.Unlock()
+
+	if tracked > 100 {
+		t.Fatalf("expected at most 100 tracked keys, got %d", tracked)
+	}
+}
+
+func TestLimiter_OvershootStaysDeniedUntilDebtClears(t *testing.T) {
+	l := newTestLimiter(0)
+	ctx := context.Background()
+
+	capacity := 5
+	overshoot := 3 // K
+
+	// Drain the bucket, then overshoot it by K more denied requests.
+	for i := 0; i < capacity+overshoot; i++ {
+		l.AllowN(ctx, "abusive", "general", time.Minute, capacity, 1)
+	}
+
+	// Balance should now be -K; it takes K+1 refills to climb back above zero.
+	bucketKey := "abusive:general"
+	l.mu.Lock()
+	elem := l.elems[bucketKey]
+	l.mu.Unlock()
+	bucket := elem.Value.(*bucketEntry).bucket
+
+	bucket.mu.Lock()
+	if bucket.tokens != -overshoot {
+		bucket.mu.Unlock()
+		t.Fatalf("expected token debt of %d, got %d", -overshoot, bucket.tokens)
+	}
+	refillRate := bucket.refillRate
+	bucket.mu.Unlock()
+
+	// Force the clock backwards on lastRefill to simulate K refill periods elapsing -
+	// still not enough to clear the debt.
+	bucket.mu.Lock()
+	bucket.lastRefill = time.Now().Add(-time.Duration(overshoot) * refillRate)
+	bucket.mu.Unlock()
+
+	if l.AllowN(ctx, "abusive", "general", time.Minute, capacity, 1) {
+		t.Fatalf("expected key still denied after only K refills")
+	}
+
+	// One more refill period closes the debt and the key should be admitted again.
+	bucket.mu.Lock()
+	bucket.lastRefill = time.Now().Add(-time.Duration(overshoot+1) * refillRate)
+	bucket.mu.Unlock()
+
+	if !l.AllowN(ctx, "abusive", "general", time.Minute, capacity, 1) {
+		t.Fatalf("expected key admitted after K+1 refills")
+	}
+}
+
+func TestLimiter_WellBehavedKeyUnaffectedByConcurrentAbusiveKeys(t *testing.T) {
+	l := newTestLimiter(1000)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := fmt.Sprintf("abuser-%d", i)
+			for j := 0; j < 200; j++ {
+				l.AllowN(ctx, key, "general", time.Minute, 5, 1)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < 5; i++ {
+		if !l.AllowN(ctx, "well-behaved", "general", time.Minute, 5, 1) {
+			t.Fatalf("well-behaved key was denied on request %d despite being within its own limit", i)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package rate
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/friend-bets/backend/internal/store"
+)
+
+// LoadLevel is a coarse classification of the current system load, used to pick a
+// specific throttling multiplier rather than reasoning about a raw 0.0-1.0 score.
+type LoadLevel string
+
+const (
+	LoadLevelSoft     LoadLevel = "soft"
+	LoadLevelWarn     LoadLevel = "warn"
+	LoadLevelHot      LoadLevel = "hot"
+	LoadLevelCritical LoadLevel = "critical"
+)
+
+// LoadWeights controls how much each signal contributes to the combined load score.
+// They don't need to sum to 1 (the combined score is clamped to [0, 1] regardless),
+// but that's the natural way to keep the score interpretable.
+type LoadWeights struct {
+	Goroutines float64
+	GCPause    float64
+	DBLatency  float64
+}
+
+// DefaultLoadWeights weighs all three signals equally.
+var DefaultLoadWeights = LoadWeights{Goroutines: 1.0 / 3, GCPause: 1.0 / 3, DBLatency: 1.0 / 3}
+
+// these ceilings define what "1.0" (maximally loaded) means for each raw signal
+const (
+	goroutineCeiling    = 5000
+	dbLatencyCeilingSec = 0.5 // 500ms p99 is considered maximally loaded
+	dbLatencyWindowSize = 50
+	defaultSampleWindow = 5 * time.Second
+	defaultHysteresisN  = 3
+)
+
+// LoadSubscores exposes the individual signals behind a combined load score, for
+// observability (e.g. surfaced through GetLimiterStats).
+type LoadSubscores struct {
+	GoroutineScore float64
+	GCPauseScore   float64
+	DBLatencyScore float64
+	Combined       float64
+	Level          LoadLevel
+}
+
+// LoadSampler periodically samples live runtime signals - goroutine count, GC pause
+// fraction, and rolling p99 database ping latency - and combines them into a
+// normalized load score. It applies hysteresis so the reported level only changes
+// after a new level has been observed for several consecutive samples in a row,
+// rather than flapping between levels on every tick.
+type LoadSampler struct {
+	repo     *store.Repository
+	weights  LoadWeights
+	interval time.Duration
+
+	hysteresisSamples int
+
+	mu            sync.RWMutex
+	latencies     []time.Duration
+	subscores     LoadSubscores
+	pendingLevel  LoadLevel
+	pendingStreak int
+
+	stopCh chan struct{}
+	doneCh chan struct{}
+	logger *slog.Logger
+}
+
+// NewLoadSampler creates a LoadSampler using equal-weighted default signals and a
+// 5-second sample interval. Call Start to begin sampling.
+func NewLoadSampler(repo *store.Repository, logger *slog.Logger) *LoadSampler {
+	return &LoadSampler{
+		repo:              repo,
+		weights:           DefaultLoadWeights,
+		interval:          defaultSampleWindow,
+		hysteresisSamples: defaultHysteresisN,
+		subscores:         LoadSubscores{Level: LoadLevelSoft},
+		pendingLevel:      LoadLevelSoft,
+		stopCh:            make(chan struct{}),
+		doneCh:            make(chan struct{}),
+		logger:            logger,
+	}
+}
+
+// Start begins periodic sampling until ctx is cancelled or Stop is called.
+func (ls *LoadSampler) Start(ctx context.Context) {
+	go ls.run(ctx)
+}
+
+// Stop halts sampling, blocking until the sampling goroutine has exited.
+func (ls *LoadSampler) Stop() {
+	close(ls.stopCh)
+	<-ls.doneCh
+}
+
+func (ls *LoadSampler) run(ctx context.Context) {
+	defer close(ls.doneCh)
+
+	ticker := time.NewTicker(ls.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ls.stopCh:
+			return
+		case <-ticker.C:
+			ls.sample(ctx)
+		}
+	}
+}
+
+// sample takes one reading of each signal, recombines the score, and applies
+// hysteresis before updating the reported level.
+func (ls *LoadSampler) sample(ctx context.Context) {
+	goroutineScore := clamp01(float64(runtime.NumGoroutine()) / goroutineCeiling)
+
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+	gcScore := clamp01(memStats.GCCPUFraction)
+
+	dbScore := ls.sampleDBLatency(ctx)
+
+	combined := clamp01(
+		ls.weights.Goroutines*goroutineScore +
+			ls.weights.GCPause*gcScore +
+			ls.weights.DBLatency*dbScore,
+	)
+
+	proposed := classifyLoad(combined)
+
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	if proposed == ls.pendingLevel {
+		ls.pendingStreak++
+	} else {
+		ls.pendingLevel = proposed
+		ls.pendingStreak = 1
+	}
+
+	if ls.pendingStreak >= ls.hysteresisSamples && proposed != ls.subscores.Level {
+		ls.logger.Info("load level changed", "from", ls.subscores.Level, "to", proposed, "score", combined)
+	}
+	if ls.pendingStreak >= ls.hysteresisSamples {
+		ls.subscores.Level = proposed
+	}
+
+	ls.subscores.GoroutineScore = goroutineScore
+	ls.subscores.GCPauseScore = gcScore
+	ls.subscores.DBLatencyScore = dbScore
+	ls.subscores.Combined = combined
+}
+
+// sampleDBLatency pings the repository's health check, folds the latency into a
+// rolling window, and returns the window's p99 normalized against dbLatencyCeilingSec.
+func (ls *LoadSampler) sampleDBLatency(ctx context.Context) float64 {
+	start := time.Now()
+	_ = ls.repo.Health()
+	latency := time.Since(start)
+
+	ls.mu.Lock()
+	ls.latencies = append(ls.latencies, latency)
+	if len(ls.latencies) > dbLatencyWindowSize {
+		ls.latencies = ls.latencies[len(ls.latencies)-dbLatencyWindowSize:]
+	}
+	sorted := make([]time.Duration, len(ls.latencies))
+	copy(sorted, ls.latencies)
+	ls.mu.Unlock()
+
+	if len(sorted) == 0 {
+		return 0
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	idx := int(float64(len(sorted))*0.99) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	p99 := sorted[idx]
+
+	return clamp01(p99.Seconds() / dbLatencyCeilingSec)
+}
+
+// LoadFactor returns the most recently sampled combined load score (0.0-1.0). It
+// satisfies the loadFactor func() float64 signature NewLoadBasedLimiter expects.
+func (ls *LoadSampler) LoadFactor() float64 {
+	ls.mu.RLock()
+	defer ls.mu.RUnlock()
+	return ls.subscores.Combined
+}
+
+// Level returns the current hysteresis-stabilized load level.
+func (ls *LoadSampler) Level() LoadLevel {
+	ls.mu.RLock()
+	defer ls.mu.RUnlock()
+	return ls.subscores.Level
+}
+
+// Subscores returns a snapshot of the individual signals behind the combined score,
+// for observability.
+func (ls *LoadSampler) Subscores() LoadSubscores {
+	ls.mu.RLock()
+	defer ls.mu.RUnlock()
+	return ls.subscores
+}
+
+func classifyLoad(score float64) LoadLevel {
+	switch {
+	case score < 0.3:
+		return LoadLevelSoft
+	case score < 0.6:
+		return LoadLevelWarn
+	case score < 0.85:
+		return LoadLevelHot
+	default:
+		return LoadLevelCritical
+	}
+}
+
+// multiplierForLevel maps a load level to the limit multiplier AllowAdaptive applies,
+// replacing the old linear 1 - load*0.5 reduction with a piecewise curve operators can
+// reason about at each threshold.
+func multiplierForLevel(level LoadLevel) float64 {
+	switch level {
+	case LoadLevelSoft:
+		return 1.0
+	case LoadLevelWarn:
+		return 0.75
+	case LoadLevelHot:
+		return 0.4
+	case LoadLevelCritical:
+		return 0.1
+	default:
+		return 1.0
+	}
+}
+
+func clamp01(v float64) float64 {
+	if v < 0 {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
@@ -0,0 +1,133 @@
+package rate
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// MultiLimiter lets callers register named rate-limit policies once, then block for a
+// token via Take, instead of threading a limit/window through every call site the way
+// Limiter.Allow requires. It's intended for wiring into something like a Connect RPC
+// interceptor, where each method maps to one named policy.
+type MultiLimiter struct {
+	mu       sync.RWMutex
+	policies map[string]*namedPolicy
+	logger   *slog.Logger
+}
+
+type namedPolicy struct {
+	limit  int
+	window time.Duration
+	bucket *TokenBucket
+}
+
+// NewMultiLimiter creates an empty MultiLimiter. Policies must be registered with Add
+// before Take/TakeN/TakeCtx can be called for them.
+func NewMultiLimiter(logger *slog.Logger) *MultiLimiter {
+	return &MultiLimiter{
+		policies: make(map[string]*namedPolicy),
+		logger:   logger,
+	}
+}
+
+// Add registers a new named policy. It returns an error if name is already registered;
+// use Update to change an existing policy's limit or window.
+func (ml *MultiLimiter) Add(name string, limit int, window time.Duration) error {
+	ml.mu.Lock()
+	defer ml.mu.Unlock()
+
+	if _, exists := ml.policies[name]; exists {
+		return fmt.Errorf("rate policy %q is already registered", name)
+	}
+
+	ml.policies[name] = newNamedPolicy(limit, window)
+	return nil
+}
+
+// Update replaces an existing policy's limit and window, e.g. to raise a limit during
+// off-peak hours. The bucket resets to full capacity under the new policy.
+func (ml *MultiLimiter) Update(name string, limit int, window time.Duration) error {
+	ml.mu.Lock()
+	defer ml.mu.Unlock()
+
+	if _, exists := ml.policies[name]; !exists {
+		return fmt.Errorf("rate policy %q is not registered", name)
+	}
+
+	ml.policies[name] = newNamedPolicy(limit, window)
+	ml.logger.Info("rate policy updated", "name", name, "limit", limit, "window", window)
+	return nil
+}
+
+// Remove deletes a named policy. It is a no-op if the policy isn't registered.
+func (ml *MultiLimiter) Remove(name string) {
+	ml.mu.Lock()
+	defer ml.mu.Unlock()
+	delete(ml.policies, name)
+}
+
+func newNamedPolicy(limit int, window time.Duration) *namedPolicy {
+	return &namedPolicy{
+		limit:  limit,
+		window: window,
+		bucket: &TokenBucket{
+			capacity:   limit,
+			tokens:     limit,
+			refillRate: window / time.Duration(limit),
+			lastRefill: time.Now(),
+		},
+	}
+}
+
+// Take blocks until a token is available under the named policy, then consumes it.
+func (ml *MultiLimiter) Take(name string) error {
+	return ml.TakeCtx(context.Background(), name)
+}
+
+// TakeN blocks until n tokens are available under the named policy, then consumes them.
+func (ml *MultiLimiter) TakeN(name string, n int) error {
+	return ml.takeNCtx(context.Background(), name, n)
+}
+
+// TakeCtx blocks until a token is available under the named policy or ctx is
+// cancelled, whichever comes first.
+func (ml *MultiLimiter) TakeCtx(ctx context.Context, name string) error {
+	return ml.takeNCtx(ctx, name, 1)
+}
+
+func (ml *MultiLimiter) takeNCtx(ctx context.Context, name string, n int) error {
+	ml.mu.RLock()
+	policy, exists := ml.policies[name]
+	ml.mu.RUnlock()
+
+	if !exists {
+		return fmt.Errorf("rate policy %q is not registered", name)
+	}
+
+	if policy.bucket.tryConsumeN(n) {
+		return nil
+	}
+
+	// Poll at a cadence finer than the refill rate so Take doesn't overshoot the
+	// moment a token actually becomes available by much.
+	pollInterval := policy.bucket.refillRate / 4
+	if pollInterval <= 0 {
+		pollInterval = time.Millisecond
+	}
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if policy.bucket.tryConsumeN(n) {
+				return nil
+			}
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package rate
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/friend-bets/backend/internal/config"
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisDistributedLimiter is a Redis-backed alternative to DistributedLimiter for
+// multi-replica deployments: counters live in Redis (INCR + EXPIRE on the window) rather
+// than the Postgres RateCounter table, so a hot rate-limited action doesn't serialize
+// through the primary database the way DistributedLimiter's repo-backed counting does.
+type RedisDistributedLimiter struct {
+	*Limiter
+	client *redis.Client
+	nodeID string
+}
+
+// NewRedisDistributedLimiter creates a distributed rate limiter backed by client.
+func NewRedisDistributedLimiter(cfg *config.RateConfig, client *redis.Client, logger *slog.Logger, nodeID string) *RedisDistributedLimiter {
+	return &RedisDistributedLimiter{
+		Limiter: NewLimiter(cfg, nil, logger),
+		client:  client,
+		nodeID:  nodeID,
+	}
+}
+
+// AllowDistributed checks rate limits across all nodes sharing client, incrementing a
+// Redis counter keyed by key+action and letting it expire at the end of window.
+func (dl *RedisDistributedLimiter) AllowDistributed(ctx context.Context, key, action string, window time.Duration, limit int) bool {
+	distributedKey := fmt.Sprintf("ratelimit:dist:%s:%s", key, action)
+
+	count, err := dl.client.Incr(ctx, distributedKey).Result()
+	if err != nil {
+		dl.logger.Error("failed to check distributed rate limit in redis", "error", err)
+		// Fall back to local rate limiting
+		return dl.Allow(ctx, key, action, window, limit)
+	}
+	if count == 1 {
+		// First increment in this window owns setting the expiry
+		dl.client.Expire(ctx, distributedKey, window)
+	}
+
+	if int(count) > limit {
+		dl.logger.Debug("distributed rate limit exceeded", "key", key, "action", action, "count", count, "limit", limit)
+		return false
+	}
+
+	return true
+}
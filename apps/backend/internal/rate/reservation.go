@@ -0,0 +1,124 @@
+package rate
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrRateLimitExceeded is returned by Reserve when the wait required to admit a
+// request would exceed the limiter's MaxFutureReserve, so the caller can fail fast
+// (e.g. a Connect interceptor returning resource_exhausted with retry-after metadata)
+// instead of blocking a goroutine indefinitely.
+type ErrRateLimitExceeded struct {
+	RetryAfter time.Duration
+}
+
+func (e *ErrRateLimitExceeded) Error() string {
+	return fmt.Sprintf("rate limit exceeded, retry after %s", e.RetryAfter)
+}
+
+// Reservation represents tokens reserved from a bucket ahead of time, modeled after
+// golang.org/x/time/rate.Reservation. The tokens are deducted at Reserve time; Cancel
+// returns them if the caller ultimately doesn't act on the reservation.
+type Reservation struct {
+	bucket *TokenBucket
+	tokens int
+	delay  time.Duration
+
+	mu       sync.Mutex
+	acted    bool
+	canceled bool
+}
+
+// Delay returns how long the caller must wait before the reserved tokens are
+// actually available.
+func (r *Reservation) Delay() time.Duration {
+	return r.delay
+}
+
+// Act marks the reservation as consumed. After Act, Cancel is a no-op.
+func (r *Reservation) Act() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.acted = true
+}
+
+// Cancel returns the reserved tokens to the bucket if the reservation hasn't already
+// been acted on. It is safe to call multiple times.
+func (r *Reservation) Cancel() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.acted || r.canceled {
+		return
+	}
+	r.canceled = true
+
+	r.bucket.mu.Lock()
+	r.bucket.tokens += r.tokens
+	if r.bucket.tokens > r.bucket.capacity {
+		r.bucket.tokens = r.bucket.capacity
+	}
+	r.bucket.mu.Unlock()
+}
+
+// Reserve reserves n tokens for key+action ahead of time and reports how long the
+// caller must wait for them to be available. If the required wait would exceed the
+// limiter's configured MaxFutureReserve, the reservation is cancelled immediately and
+// an *ErrRateLimitExceeded carrying the required wait is returned.
+func (l *Limiter) Reserve(ctx context.Context, key, action string, n int) (*Reservation, error) {
+	bucketKey := fmt.Sprintf("%s:%s", key, action)
+	limit := l.getLimitForAction(action)
+	window := l.getWindowForAction(action)
+	bucket := l.getOrCreateBucket(bucketKey, limit, window)
+
+	bucket.mu.Lock()
+	bucket.refill()
+	bucket.tokens -= n
+	tokensAfter := bucket.tokens
+	refillRate := bucket.refillRate
+	bucket.mu.Unlock()
+
+	var delay time.Duration
+	if tokensAfter < 0 {
+		delay = time.Duration(-tokensAfter) * refillRate
+	}
+
+	reservation := &Reservation{bucket: bucket, tokens: n, delay: delay}
+
+	if l.maxFutureReserve > 0 && delay > l.maxFutureReserve {
+		reservation.Cancel()
+		return nil, &ErrRateLimitExceeded{RetryAfter: delay}
+	}
+
+	return reservation, nil
+}
+
+// Wait blocks until n tokens are available for key+action, or ctx is cancelled,
+// whichever comes first. It fails fast with *ErrRateLimitExceeded if the wait would
+// exceed MaxFutureReserve rather than blocking for that long.
+func (l *Limiter) Wait(ctx context.Context, key, action string, n int) error {
+	reservation, err := l.Reserve(ctx, key, action, n)
+	if err != nil {
+		return err
+	}
+
+	if reservation.Delay() <= 0 {
+		reservation.Act()
+		return nil
+	}
+
+	timer := time.NewTimer(reservation.Delay())
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		reservation.Cancel()
+		return ctx.Err()
+	case <-timer.C:
+		reservation.Act()
+		return nil
+	}
+}
@@ -0,0 +1,220 @@
+package rate
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/friend-bets/backend/internal/config"
+)
+
+// byteBucket is a token bucket denominated in bytes rather than requests, used to
+// throttle bandwidth instead of request counts.
+type byteBucket struct {
+	mu         sync.Mutex
+	capacity   int64
+	tokens     int64
+	refillRate time.Duration // time to refill one byte
+	lastRefill time.Time
+}
+
+func newByteBucket(bytesPerSecond, burstBytes int64) *byteBucket {
+	if bytesPerSecond <= 0 {
+		bytesPerSecond = 1
+	}
+	if burstBytes <= 0 {
+		burstBytes = bytesPerSecond
+	}
+	return &byteBucket{
+		capacity:   burstBytes,
+		tokens:     burstBytes,
+		refillRate: time.Second / time.Duration(bytesPerSecond),
+		lastRefill: time.Now(),
+	}
+}
+
+func (b *byteBucket) refill() {
+	now := time.Now()
+	elapsed := now.Sub(b.lastRefill)
+	tokensToAdd := int64(elapsed / b.refillRate)
+	if tokensToAdd > 0 {
+		b.tokens += tokensToAdd
+		if b.tokens > b.capacity {
+			b.tokens = b.capacity
+		}
+		b.lastRefill = now
+	}
+}
+
+// waitN blocks until n bytes' worth of tokens are available, or ctx is cancelled.
+func (b *byteBucket) waitN(ctx context.Context, n int64) error {
+	if n <= 0 {
+		return nil
+	}
+
+	b.mu.Lock()
+	b.refill()
+	if b.tokens >= n {
+		b.tokens -= n
+		b.mu.Unlock()
+		return nil
+	}
+	b.mu.Unlock()
+
+	ticker := time.NewTicker(b.refillRate)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			b.mu.Lock()
+			b.refill()
+			if b.tokens >= n {
+				b.tokens -= n
+				b.mu.Unlock()
+				return nil
+			}
+			b.mu.Unlock()
+		}
+	}
+}
+
+// Throttler enforces bandwidth (bytes-per-second) limits, as opposed to Limiter's
+// request-count limits. It holds one global bucket plus a bucket per key (e.g. per
+// authenticated user), so a single heavy downloader can't starve bandwidth from
+// everyone else while still being bounded by the global cap.
+type Throttler struct {
+	cfg    *config.RateConfig
+	global *byteBucket
+
+	mu     sync.Mutex
+	perKey map[string]*byteBucket
+
+	logger *slog.Logger
+}
+
+// NewThrottler creates a Throttler using cfg's global bytes_per_second/burst_bytes as
+// both the global cap and the default per-key cap.
+func NewThrottler(cfg *config.RateConfig, logger *slog.Logger) *Throttler {
+	return &Throttler{
+		cfg:    cfg,
+		global: newByteBucket(cfg.BytesPerSecond, cfg.BurstBytes),
+		perKey: make(map[string]*byteBucket),
+		logger: logger,
+	}
+}
+
+func (t *Throttler) getOrCreateKeyBucket(key string) *byteBucket {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if b, exists := t.perKey[key]; exists {
+		return b
+	}
+
+	b := newByteBucket(t.cfg.BytesPerSecond, t.cfg.BurstBytes)
+	t.perKey[key] = b
+	return b
+}
+
+// WaitN blocks until n bytes are admitted under both the per-key bucket (if key is
+// non-empty) and the global bucket, or ctx is cancelled.
+func (t *Throttler) WaitN(ctx context.Context, key string, n int) error {
+	if key != "" {
+		if err := t.getOrCreateKeyBucket(key).waitN(ctx, int64(n)); err != nil {
+			return err
+		}
+	}
+	return t.global.waitN(ctx, int64(n))
+}
+
+// throttledReader wraps an io.Reader so every Read call is paced by the throttler.
+// Tokens are charged only for bytes actually transferred, so a short/partial read
+// never loses bandwidth allowance it didn't use.
+type throttledReader struct {
+	ctx       context.Context
+	r         io.Reader
+	throttler *Throttler
+	key       string
+}
+
+// ThrottledReader wraps r so reads are paced against the throttler's bandwidth cap
+// for key (use "" to skip the per-key bucket and only apply the global cap).
+func (t *Throttler) ThrottledReader(ctx context.Context, r io.Reader, key string) io.Reader {
+	return &throttledReader{ctx: ctx, r: r, throttler: t, key: key}
+}
+
+func (tr *throttledReader) Read(p []byte) (int, error) {
+	n, err := tr.r.Read(p)
+	if n > 0 {
+		if werr := tr.throttler.WaitN(tr.ctx, tr.key, n); werr != nil {
+			return n, werr
+		}
+	}
+	return n, err
+}
+
+// throttledWriter wraps an io.Writer so every Write call is paced by the throttler.
+type throttledWriter struct {
+	ctx       context.Context
+	w         io.Writer
+	throttler *Throttler
+	key       string
+}
+
+// ThrottledWriter wraps w so writes are paced against the throttler's bandwidth cap
+// for key (use "" to skip the per-key bucket and only apply the global cap).
+func (t *Throttler) ThrottledWriter(ctx context.Context, w io.Writer, key string) io.Writer {
+	return &throttledWriter{ctx: ctx, w: w, throttler: t, key: key}
+}
+
+func (tw *throttledWriter) Write(p []byte) (int, error) {
+	n, err := tw.w.Write(p)
+	if n > 0 {
+		if werr := tw.throttler.WaitN(tw.ctx, tw.key, n); werr != nil {
+			return n, werr
+		}
+	}
+	return n, err
+}
+
+// throttledResponseWriter wraps an http.ResponseWriter so the response body is paced
+// by the throttler, while still passing through Header/WriteHeader unchanged.
+type throttledResponseWriter struct {
+	http.ResponseWriter
+	writer io.Writer
+}
+
+func (w *throttledResponseWriter) Write(p []byte) (int, error) {
+	return w.writer.Write(p)
+}
+
+// Middleware returns HTTP middleware that throttles both the inbound request body and
+// the outbound response body, keyed by bandwidthKey(r) (e.g. the authenticated user,
+// or remote address for anonymous callers). This is aimed at endpoints like market
+// image uploads or bulk exports, and at mitigating slow-loris style abuse where
+// request-count limits alone don't help.
+func (t *Throttler) Middleware(bandwidthKey func(r *http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := bandwidthKey(r)
+			ctx := r.Context()
+
+			if r.Body != nil {
+				r.Body = io.NopCloser(t.ThrottledReader(ctx, r.Body, key))
+			}
+
+			throttledWriter := &throttledResponseWriter{
+				ResponseWriter: w,
+				writer:         t.ThrottledWriter(ctx, w, key),
+			}
+
+			next.ServeHTTP(throttledWriter, r)
+		})
+	}
+}
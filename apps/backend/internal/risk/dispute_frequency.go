@@ -0,0 +1,45 @@
+package risk
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/friend-bets/backend/internal/core"
+	"github.com/friend-bets/backend/internal/store"
+)
+
+// DisputeFrequencyBreaker trips when more than MaxDisputes disputes have been filed
+// against markets from the same creator within Window, a signal that the creator may be
+// running bad-faith markets rather than any one market being independently abnormal.
+type DisputeFrequencyBreaker struct {
+	repo        *store.Repository
+	maxDisputes int
+	window      time.Duration
+	logger      *slog.Logger
+}
+
+// NewDisputeFrequencyBreaker creates a breaker that trips once a market's creator has
+// had more than maxDisputes disputes filed against their markets within window.
+func NewDisputeFrequencyBreaker(repo *store.Repository, maxDisputes int, window time.Duration, logger *slog.Logger) *DisputeFrequencyBreaker {
+	return &DisputeFrequencyBreaker{repo: repo, maxDisputes: maxDisputes, window: window, logger: logger}
+}
+
+// Check runs on every event, since a dispute filed against one market from a creator
+// should also be able to halt that creator's other currently-open markets.
+func (b *DisputeFrequencyBreaker) Check(market *core.Market, event *core.MarketEvent) Decision {
+	count, err := b.repo.CountDisputesByCreatorSince(market.Creator, time.Now().Add(-b.window))
+	if err != nil {
+		b.logger.Error("dispute frequency breaker failed to count disputes", "error", err, "creator", market.Creator)
+		return Decision{}
+	}
+
+	if int(count) > b.maxDisputes {
+		return Decision{
+			Trip:   true,
+			Reason: fmt.Sprintf("creator %s has had %d disputes filed within %s (threshold %d)", market.Creator, count, b.window, b.maxDisputes),
+		}
+	}
+
+	return Decision{}
+}
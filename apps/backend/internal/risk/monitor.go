@@ -0,0 +1,75 @@
+package risk
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/friend-bets/backend/internal/core"
+	"github.com/friend-bets/backend/internal/store"
+)
+
+// Monitor runs a Breaker against incoming market events and halts a market when it
+// trips, persisting the trip as an EventLog row. It doesn't hold a notify.Notifier
+// itself — core already depends on notify, so a reverse dependency here would grow the
+// cycle further; callers broadcast the returned Decision through their own notifier.
+type Monitor struct {
+	repo    *store.Repository
+	breaker Breaker
+	logger  *slog.Logger
+}
+
+// NewMonitor creates a Monitor that runs breaker against every event it's given.
+func NewMonitor(repo *store.Repository, breaker Breaker, logger *slog.Logger) *Monitor {
+	return &Monitor{repo: repo, breaker: breaker, logger: logger}
+}
+
+// Observe checks event against the configured breaker and, if it trips, halts market and
+// persists an EventCircuitBreakerTripped row. The returned Decision is non-trip when
+// nothing fired.
+func (m *Monitor) Observe(market *core.Market, event *core.MarketEvent) (Decision, error) {
+	decision := m.breaker.Check(market, event)
+	if !decision.Trip {
+		return decision, nil
+	}
+
+	marketView, err := m.repo.GetMarket(market.ID)
+	if err != nil {
+		return decision, fmt.Errorf("failed to load market to halt: %w", err)
+	}
+	marketView.Status = core.MarketStatusHalted
+	if err := m.repo.UpdateMarket(marketView); err != nil {
+		return decision, fmt.Errorf("failed to halt market: %w", err)
+	}
+
+	marketID := market.ID
+	dataJSON, _ := json.Marshal(map[string]interface{}{"reason": decision.Reason})
+	logEntry := &store.EventLog{
+		EventType: core.EventCircuitBreakerTripped,
+		MarketID:  &marketID,
+		Data:      string(dataJSON),
+		BlockTime: time.Now(),
+	}
+	if err := m.repo.CreateEventLog(logEntry); err != nil {
+		m.logger.Error("failed to persist circuit breaker trip event", "error", err, "market_id", market.ID)
+	}
+
+	m.logger.Warn("circuit breaker tripped", "market_id", market.ID, "reason", decision.Reason)
+
+	return decision, nil
+}
+
+// Reset reopens a halted market, for an admin endpoint to call after investigating a trip.
+func (m *Monitor) Reset(marketID string) error {
+	marketView, err := m.repo.GetMarket(marketID)
+	if err != nil {
+		return fmt.Errorf("market not found: %w", err)
+	}
+	if marketView.Status != core.MarketStatusHalted {
+		return fmt.Errorf("market %s is not halted", marketID)
+	}
+
+	marketView.Status = core.MarketStatusOpen
+	return m.repo.UpdateMarket(marketView)
+}
@@ -0,0 +1,102 @@
+package risk
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/friend-bets/backend/internal/core"
+)
+
+type oddsSample struct {
+	at     time.Time
+	priceA float64 // SideA's odds as a fraction of total, so movement is comparable across bet sizes
+}
+
+// OddsMovementBreaker trips when a market's implied SideA probability moves by more than
+// MaxMoveBps (basis points) within Window, computed from BetPlaced events.
+type OddsMovementBreaker struct {
+	maxMoveBps int
+	window     time.Duration
+
+	mu      sync.Mutex
+	samples map[string][]oddsSample
+}
+
+// NewOddsMovementBreaker creates a breaker that trips when a market's SideA probability
+// moves by more than maxMoveBps within window.
+func NewOddsMovementBreaker(maxMoveBps int, window time.Duration) *OddsMovementBreaker {
+	return &OddsMovementBreaker{
+		maxMoveBps: maxMoveBps,
+		window:     window,
+		samples:    make(map[string][]oddsSample),
+	}
+}
+
+// Check only reacts to BetPlaced events, records a new odds sample for the market, and
+// trips if the probability has moved more than maxMoveBps since the oldest sample still
+// inside window.
+func (b *OddsMovementBreaker) Check(market *core.Market, event *core.MarketEvent) Decision {
+	if event == nil || event.EventType != core.EventBetPlaced {
+		return Decision{}
+	}
+
+	priceA := sideAProbability(market)
+	now := time.Now()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	samples := b.samples[market.ID]
+
+	cutoff := now.Add(-b.window)
+	kept := samples[:0]
+	for _, s := range samples {
+		if s.at.After(cutoff) {
+			kept = append(kept, s)
+		}
+	}
+	samples = kept
+
+	var decision Decision
+	if len(samples) > 0 {
+		oldest := samples[0]
+		moveBps := int(((priceA - oldest.priceA) * 10000))
+		if moveBps < 0 {
+			moveBps = -moveBps
+		}
+		if moveBps >= b.maxMoveBps {
+			decision = Decision{
+				Trip:   true,
+				Reason: fmt.Sprintf("side A odds moved %d bps within %s (threshold %d bps)", moveBps, b.window, b.maxMoveBps),
+			}
+		}
+	}
+
+	samples = append(samples, oddsSample{at: now, priceA: priceA})
+	b.samples[market.ID] = samples
+
+	return decision
+}
+
+// sideAProbability returns SideA's implied probability. For an LMSR market
+// CalculateOdds already returns a probability quote directly; for a parimutuel market it
+// returns a payout multiplier instead (which is inversely proportional to probability),
+// so it's inverted and normalized here: probA = oddsB / (oddsA + oddsB).
+func sideAProbability(market *core.Market) float64 {
+	odds := market.CalculateOdds()
+	a, _ := odds.SideA.Float64()
+	b, _ := odds.SideB.Float64()
+
+	if market.MarketType == core.MarketTypeLMSR {
+		if a+b == 0 {
+			return 0.5
+		}
+		return a / (a + b)
+	}
+
+	if a+b == 0 {
+		return 0.5
+	}
+	return b / (a + b)
+}
@@ -0,0 +1,43 @@
+package risk
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/friend-bets/backend/internal/core"
+	"github.com/friend-bets/backend/internal/store"
+)
+
+// ResolverHistoryBreaker trips when a market's creator (who is also its resolver, per
+// ValidateResolveMarket) has a history of resolving past markets after their
+// resolve_deadline_ts, a signal they're likely to do the same for this market.
+type ResolverHistoryBreaker struct {
+	repo          *store.Repository
+	maxViolations int
+	logger        *slog.Logger
+}
+
+// NewResolverHistoryBreaker creates a breaker that trips once a market's resolver has
+// more than maxViolations past resolve-deadline violations on record.
+func NewResolverHistoryBreaker(repo *store.Repository, maxViolations int, logger *slog.Logger) *ResolverHistoryBreaker {
+	return &ResolverHistoryBreaker{repo: repo, maxViolations: maxViolations, logger: logger}
+}
+
+// Check runs on every event; a resolver's history doesn't change per-event, but this
+// keeps the breaker stateless and consistent with the rest of the Breaker interface.
+func (b *ResolverHistoryBreaker) Check(market *core.Market, event *core.MarketEvent) Decision {
+	violations, err := b.repo.CountResolveDeadlineViolationsByCreator(market.Creator)
+	if err != nil {
+		b.logger.Error("resolver history breaker failed to count violations", "error", err, "creator", market.Creator)
+		return Decision{}
+	}
+
+	if int(violations) > b.maxViolations {
+		return Decision{
+			Trip:   true,
+			Reason: fmt.Sprintf("resolver %s has %d past resolve-deadline violations (threshold %d)", market.Creator, violations, b.maxViolations),
+		}
+	}
+
+	return Decision{}
+}
@@ -0,0 +1,43 @@
+// Package risk watches the market event stream for abnormal activity (wallet
+// concentration, sudden odds swings, dispute floods, chronically late resolvers) and can
+// automatically halt a market in response, inspired by bbgo's risk/circuitbreaker package.
+package risk
+
+import (
+	"github.com/friend-bets/backend/internal/core"
+)
+
+// Decision is the result of a Breaker's check: whether it detected abnormal activity and,
+// if so, a human-readable reason recorded in the trip event and surfaced to subscribers.
+type Decision struct {
+	Trip   bool
+	Reason string
+}
+
+// Breaker watches a market's incoming events for abnormal activity and reports whether
+// the market should be halted.
+type Breaker interface {
+	Check(market *core.Market, event *core.MarketEvent) Decision
+}
+
+// MultiBreaker composes several Breakers; the first one to trip halts the market, so
+// later breakers in the list aren't evaluated once one has already tripped.
+type MultiBreaker struct {
+	breakers []Breaker
+}
+
+// NewMultiBreaker composes breakers into a single Breaker.
+func NewMultiBreaker(breakers ...Breaker) *MultiBreaker {
+	return &MultiBreaker{breakers: breakers}
+}
+
+// Check runs every composed breaker in order and returns the first tripped Decision, or
+// a non-tripped Decision if none of them fired.
+func (m *MultiBreaker) Check(market *core.Market, event *core.MarketEvent) Decision {
+	for _, b := range m.breakers {
+		if d := b.Check(market, event); d.Trip {
+			return d
+		}
+	}
+	return Decision{}
+}
@@ -0,0 +1,59 @@
+package risk
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/friend-bets/backend/internal/core"
+	"github.com/friend-bets/backend/internal/store"
+)
+
+// WalletConcentrationBreaker trips when a single wallet's positions add up to more than
+// MaxShareBps (basis points) of a market's total staked amount.
+type WalletConcentrationBreaker struct {
+	repo        *store.Repository
+	maxShareBps int
+	logger      *slog.Logger
+}
+
+// NewWalletConcentrationBreaker creates a breaker that trips once any one wallet's share
+// of a market's TotalStaked crosses maxShareBps basis points (e.g. 5000 for 50%).
+func NewWalletConcentrationBreaker(repo *store.Repository, maxShareBps int, logger *slog.Logger) *WalletConcentrationBreaker {
+	return &WalletConcentrationBreaker{repo: repo, maxShareBps: maxShareBps, logger: logger}
+}
+
+// Check only reacts to BetPlaced events, since that's the only event type that can move
+// a wallet's concentration.
+func (b *WalletConcentrationBreaker) Check(market *core.Market, event *core.MarketEvent) Decision {
+	if event == nil || event.EventType != core.EventBetPlaced {
+		return Decision{}
+	}
+
+	totalStaked := market.StakedA + market.StakedB
+	if totalStaked == 0 {
+		return Decision{}
+	}
+
+	positions, err := b.repo.GetPositionsByMarket(market.ID)
+	if err != nil {
+		b.logger.Error("wallet concentration breaker failed to load positions", "error", err, "market_id", market.ID)
+		return Decision{}
+	}
+
+	byWallet := make(map[string]uint64, len(positions))
+	for _, p := range positions {
+		byWallet[p.Owner] += p.Amount
+	}
+
+	for wallet, staked := range byWallet {
+		shareBps := int(staked * 10000 / totalStaked)
+		if shareBps >= b.maxShareBps {
+			return Decision{
+				Trip:   true,
+				Reason: fmt.Sprintf("wallet %s holds %d bps of total staked (threshold %d bps)", wallet, shareBps, b.maxShareBps),
+			}
+		}
+	}
+
+	return Decision{}
+}
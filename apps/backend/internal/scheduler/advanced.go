@@ -0,0 +1,85 @@
+package scheduler
+
+import "sync/atomic"
+
+// schedulerStyleAdvanced gives each job its own bounded work queue and a
+// dedicated worker goroutine draining it, so a stalled job (e.g. Solana RPC
+// stalls behind ProcessMarketsNearEnd) can never pile up unbounded concurrent
+// goroutines the way the basic style's per-tick goroutine can.
+const schedulerStyleAdvanced = "advanced"
+
+// startJobWorker gives job its own bounded work queue and a dedicated worker
+// goroutine, if it doesn't already have one. Only used in "advanced" scheduler
+// style. The queue is sized from s.queueSize (default 1): a cron tick that
+// arrives while the queue is already full is dropped rather than piling up,
+// since the previous tick is still pending or in progress.
+func (s *Scheduler) startJobWorker(job *Job) {
+	s.mu.Lock()
+	if job.queue != nil {
+		s.mu.Unlock()
+		return
+	}
+	job.queue = make(chan struct{}, s.queueSize)
+	job.workerDone = make(chan struct{})
+	s.mu.Unlock()
+
+	s.jobWorkersWG.Add(1)
+	go func() {
+		defer s.jobWorkersWG.Done()
+		defer close(job.workerDone)
+
+		// Ranging over job.queue drains any ticks still buffered when it's
+		// closed during Stop, rather than dropping them mid-shutdown.
+		for range job.queue {
+			s.runJob(job)
+		}
+	}()
+}
+
+// dispatchAdvanced hands a cron tick to job's bounded queue without blocking.
+// If the queue is already full - the worker is still processing a previous
+// tick and one is already queued behind it - the new tick is dropped and
+// counted rather than left to pile up.
+func (s *Scheduler) dispatchAdvanced(job *Job) {
+	select {
+	case job.queue <- struct{}{}:
+	default:
+		s.recordJobSkipped(job)
+	}
+}
+
+// recordJobSkipped records a dropped/coalesced tick for job, both as an atomic
+// counter for introspection (GetJobStats) and, if registered, as a Prometheus
+// metric.
+func (s *Scheduler) recordJobSkipped(job *Job) {
+	atomic.AddInt64(&job.skipped, 1)
+	s.logger.Warn("job tick skipped, previous run still queued or in progress", "job", job.ID)
+
+	s.mu.RLock()
+	metrics := s.metrics
+	s.mu.RUnlock()
+
+	if metrics != nil {
+		metrics.jobSkipped.WithLabelValues(job.ID).Inc()
+	}
+}
+
+// closeJobQueues closes every advanced-mode job queue so its worker goroutine
+// drains any buffered tick and exits, then waits for all job workers to
+// finish. Called from Stop, after cron has stopped dispatching new ticks, so
+// no further sends can race the close.
+func (s *Scheduler) closeJobQueues() {
+	s.mu.RLock()
+	queues := make([]chan struct{}, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		if job.queue != nil {
+			queues = append(queues, job.queue)
+		}
+	}
+	s.mu.RUnlock()
+
+	for _, q := range queues {
+		close(q)
+	}
+	s.jobWorkersWG.Wait()
+}
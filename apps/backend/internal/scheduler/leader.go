@@ -0,0 +1,113 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/friend-bets/backend/internal/store"
+)
+
+// schedulerAdvisoryLockID is the Postgres advisory lock key used to elect a single
+// leader among worker replicas. It's arbitrary but must stay stable across
+// deploys, since advisory locks are identified purely by this integer.
+const schedulerAdvisoryLockID = 827341
+
+// leaderHeartbeatInterval is how often a replica checks whether it still holds, or
+// can newly acquire, the advisory lock.
+const leaderHeartbeatInterval = 10 * time.Second
+
+// leaderElector elects a single leader among worker replicas using a Postgres
+// session-scoped advisory lock (pg_try_advisory_lock). The lock is held on a
+// dedicated *sql.Conn for as long as this instance is leader, so Postgres releases
+// it automatically if that connection drops - a crashed or partitioned replica
+// loses leadership without any explicit handoff.
+type leaderElector struct {
+	db     *store.DB
+	lockID int64
+	logger *slog.Logger
+
+	mu     sync.Mutex
+	conn   *sql.Conn
+	leader bool
+}
+
+func newLeaderElector(db *store.DB, logger *slog.Logger) *leaderElector {
+	return &leaderElector{
+		db:     db,
+		lockID: schedulerAdvisoryLockID,
+		logger: logger,
+	}
+}
+
+// tryAcquire attempts to become leader if not already, or verifies the held
+// connection is still alive if already leader. It returns the leader state after
+// the attempt.
+func (e *leaderElector) tryAcquire(ctx context.Context) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.conn != nil {
+		if err := e.conn.PingContext(ctx); err == nil {
+			return true
+		}
+		e.logger.Warn("leader connection died, releasing leadership")
+		e.conn.Close()
+		e.conn = nil
+		e.leader = false
+	}
+
+	sqlDB, err := e.db.DB.DB()
+	if err != nil {
+		e.logger.Error("failed to get underlying sql.DB for leader election", "error", err)
+		return false
+	}
+
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		e.logger.Error("failed to obtain connection for leader election", "error", err)
+		return false
+	}
+
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", e.lockID).Scan(&acquired); err != nil {
+		e.logger.Error("failed to attempt advisory lock", "error", err)
+		conn.Close()
+		return false
+	}
+
+	if !acquired {
+		conn.Close()
+		return false
+	}
+
+	e.conn = conn
+	e.leader = true
+	return true
+}
+
+// release gives up leadership, unlocking and closing the held connection if any.
+func (e *leaderElector) release(ctx context.Context) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.conn == nil {
+		return
+	}
+
+	if _, err := e.conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", e.lockID); err != nil {
+		e.logger.Warn("failed to explicitly unlock advisory lock", "error", err)
+	}
+	e.conn.Close()
+	e.conn = nil
+	e.leader = false
+}
+
+// isLeader reports the last-known leadership state.
+func (e *leaderElector) isLeader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.leader
+}
@@ -0,0 +1,29 @@
+package scheduler
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// schedulerMetrics holds the Prometheus collectors backing Scheduler.RegisterMetrics.
+type schedulerMetrics struct {
+	jobSkipped *prometheus.CounterVec // labeled by job_id
+}
+
+// RegisterMetrics registers the Scheduler's Prometheus collectors with reg. Safe to
+// call at most once per Scheduler instance, before Start.
+func (s *Scheduler) RegisterMetrics(reg prometheus.Registerer) error {
+	m := &schedulerMetrics{
+		jobSkipped: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "friendbets_scheduler_job_skipped_total",
+			Help: "Number of job ticks skipped because a previous run of the same job was still in progress, labeled by job_id.",
+		}, []string{"job_id"}),
+	}
+
+	if err := reg.Register(m.jobSkipped); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.metrics = m
+	s.mu.Unlock()
+
+	return nil
+}
@@ -0,0 +1,189 @@
+package scheduler
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/friend-bets/backend/internal/notify"
+)
+
+// RetryPolicy governs automatic retry attempts for a job's cron-triggered runs:
+// after a failure, the next attempt is scheduled after an exponential backoff
+// (InitialBackoff * Multiplier^attempt, capped at MaxBackoff, with ±JitterFraction
+// random jitter) rather than waiting for the job's own cron schedule to fire
+// again. MaxAttempts bounds how many consecutive failures get an automatic retry
+// before the job falls back to waiting for its next scheduled tick.
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	JitterFraction float64 // e.g. 0.2 for ±20%
+}
+
+// defaultRetryPolicy is applied to every job registered by registerJobs.
+func defaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    5,
+		InitialBackoff: 5 * time.Second,
+		MaxBackoff:     5 * time.Minute,
+		Multiplier:     2,
+		JitterFraction: 0.2,
+	}
+}
+
+// backoff returns the delay before retry number attempt (1-based).
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	d := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(attempt-1))
+	if max := float64(p.MaxBackoff); max > 0 && d > max {
+		d = max
+	}
+
+	if p.JitterFraction > 0 {
+		delta := d * p.JitterFraction
+		d += (rand.Float64()*2 - 1) * delta
+	}
+	if d < 0 {
+		d = 0
+	}
+
+	return time.Duration(d)
+}
+
+// CircuitBreakerConfig governs when a job's repeated failures pause cron
+// dispatch entirely: once ErrorCount reaches FailureThreshold, the breaker
+// opens and cron ticks are skipped until CooldownDuration has elapsed, at
+// which point the next tick is let through as a half-open trial.
+type CircuitBreakerConfig struct {
+	FailureThreshold int
+	CooldownDuration time.Duration
+}
+
+// defaultCircuitBreaker is applied to every job registered by registerJobs.
+func defaultCircuitBreaker() CircuitBreakerConfig {
+	return CircuitBreakerConfig{
+		FailureThreshold: 5,
+		CooldownDuration: 10 * time.Minute,
+	}
+}
+
+// isBreakerOpen reports whether job's circuit breaker is currently open. If
+// CooldownDuration has elapsed since it opened, it's cleared here so the
+// caller's invocation goes through as a half-open trial.
+func (s *Scheduler) isBreakerOpen(job *Job) bool {
+	job.breakerMu.Lock()
+	defer job.breakerMu.Unlock()
+
+	if !job.breakerOpen {
+		return false
+	}
+	if time.Since(job.breakerOpenedAt) < job.Breaker.CooldownDuration {
+		return true
+	}
+
+	job.breakerOpen = false
+	return false
+}
+
+// openBreaker opens job's circuit breaker, alerting via the notifier on a
+// closed->open transition.
+func (s *Scheduler) openBreaker(job *Job) {
+	job.breakerMu.Lock()
+	alreadyOpen := job.breakerOpen
+	job.breakerOpen = true
+	job.breakerOpenedAt = time.Now()
+	job.breakerMu.Unlock()
+
+	if alreadyOpen {
+		return
+	}
+
+	s.logger.Error("circuit breaker opened for job", "job", job.ID, "error_count", job.ErrorCount)
+	s.alertBreakerTransition(job, "open")
+}
+
+// closeBreaker closes job's circuit breaker, alerting via the notifier if it
+// was previously open.
+func (s *Scheduler) closeBreaker(job *Job) {
+	job.breakerMu.Lock()
+	wasOpen := job.breakerOpen
+	job.breakerOpen = false
+	job.breakerMu.Unlock()
+
+	if !wasOpen {
+		return
+	}
+
+	s.logger.Info("circuit breaker closed for job", "job", job.ID)
+	s.alertBreakerTransition(job, "close")
+}
+
+// alertBreakerTransition broadcasts a circuit breaker open/close event over
+// the notifier's hub, for any admin UI subscribed to scheduler events.
+func (s *Scheduler) alertBreakerTransition(job *Job, transition string) {
+	if s.notifier == nil {
+		return
+	}
+
+	s.notifier.Broadcast(notify.Notification{
+		EventType: "scheduler_circuit_breaker_" + transition,
+		Data: map[string]interface{}{
+			"job_id":      job.ID,
+			"error_count": job.ErrorCount,
+		},
+		Timestamp: time.Now(),
+	})
+}
+
+// scheduleRetry schedules another attempt at job after an exponential backoff,
+// unless its circuit breaker is open or it's exhausted Retry.MaxAttempts for
+// this failure streak, in which case it waits for the next scheduled tick.
+func (s *Scheduler) scheduleRetry(job *Job) {
+	job.breakerMu.Lock()
+	open := job.breakerOpen
+	job.breakerMu.Unlock()
+	if open {
+		return
+	}
+
+	if job.Retry.MaxAttempts > 0 && job.ErrorCount >= job.Retry.MaxAttempts {
+		s.logger.Warn("job exhausted retry attempts, waiting for next scheduled tick", "job", job.ID, "attempts", job.ErrorCount)
+		return
+	}
+
+	delay := job.Retry.backoff(job.ErrorCount)
+	s.logger.Info("scheduling job retry", "job", job.ID, "attempt", job.ErrorCount, "delay", delay)
+
+	job.breakerMu.Lock()
+	if job.retryTimer != nil {
+		job.retryTimer.Stop()
+	}
+	job.retryTimer = time.AfterFunc(delay, func() {
+		s.runJob(job)
+	})
+	job.breakerMu.Unlock()
+}
+
+// stopRetryTimers cancels any pending retry timers across all jobs, so a
+// scheduled retry can't fire after Stop has returned.
+func (s *Scheduler) stopRetryTimers() {
+	s.mu.RLock()
+	jobs := make([]*Job, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, job)
+	}
+	s.mu.RUnlock()
+
+	for _, job := range jobs {
+		job.breakerMu.Lock()
+		if job.retryTimer != nil {
+			job.retryTimer.Stop()
+		}
+		job.breakerMu.Unlock()
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log/slog"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/friend-bets/backend/internal/config"
@@ -14,10 +15,25 @@ import (
 	"github.com/robfig/cron/v3"
 )
 
-// Scheduler manages background jobs and cron tasks
+// defaultJobTimeout bounds a job run when its Job.Timeout is unset.
+const defaultJobTimeout = 10 * time.Minute
+
+// jobTimeout returns job.Timeout, falling back to defaultJobTimeout if unset.
+func jobTimeout(job *Job) time.Duration {
+	if job.Timeout <= 0 {
+		return defaultJobTimeout
+	}
+	return job.Timeout
+}
+
+// Scheduler manages background jobs and cron tasks. When multiple worker
+// replicas run the same Scheduler, only the elected leader (see leader.go)
+// actually runs cron, so jobs like autoCloseMarkets and dailyAnalyticsRollup
+// aren't duplicated across replicas.
 type Scheduler struct {
 	cron      *cron.Cron
 	config    *config.WorkerConfig
+	repo      *store.Repository
 	useCases  *core.UseCases
 	notifier  *notify.Notifier
 	analytics *store.Analytics
@@ -26,24 +42,79 @@ type Scheduler struct {
 	mu        sync.RWMutex
 	stopCh    chan struct{}
 	doneCh    chan struct{}
+
+	// jobs is the registry of all jobs this scheduler knows about, keyed by
+	// Job.ID, guarded by mu alongside running/isLeader
+	jobs map[string]*Job
+
+	elector  *leaderElector
+	isLeader bool
+
+	// style is config.WorkerConfig.SchedulerStyle, normalized to either "basic"
+	// (default: cron spawns a goroutine per tick) or schedulerStyleAdvanced
+	// (each job gets a bounded queue and dedicated worker goroutine, see
+	// advanced.go). queueSize is the buffer size of those queues.
+	style        string
+	queueSize    int
+	jobWorkersWG sync.WaitGroup
+
+	// metrics is nil until RegisterMetrics is called
+	metrics *schedulerMetrics
 }
 
 // Job represents a background job
 type Job struct {
-	ID          string
-	Name        string
-	Function    func(ctx context.Context) error
-	Schedule    string // Cron expression
-	LastRun     time.Time
-	NextRun     time.Time
-	ErrorCount  int
-	LastError   error
-	Enabled     bool
-}
-
-// NewScheduler creates a new scheduler instance
+	ID         string
+	Name       string
+	Function   func(ctx context.Context) error
+	Schedule   string // Cron expression
+	LastRun    time.Time
+	NextRun    time.Time
+	ErrorCount int
+	LastError  error
+	Enabled    bool
+
+	// Timezone is an IANA zone (e.g. "America/New_York") applied to Schedule via
+	// a CRON_TZ= prefix when non-empty, overriding the scheduler's default
+	// location for this job only.
+	Timezone string
+	// Timeout bounds how long a single run of this job may take; defaults to
+	// defaultJobTimeout if zero.
+	Timeout time.Duration
+
+	// EntryID is the cron.EntryID this job is registered under while enabled,
+	// zero when the job is disabled or not yet scheduled
+	EntryID cron.EntryID
+
+	// queue, workerDone, inFlight, and skipped are populated only in
+	// schedulerStyleAdvanced: queue is the bounded work channel a tick is sent
+	// to, workerDone closes when this job's dedicated worker goroutine exits,
+	// and inFlight/skipped are atomic counters for introspection (GetJobStats)
+	queue      chan struct{}
+	workerDone chan struct{}
+	inFlight   int32
+	skipped    int64
+
+	// Retry and Breaker configure how this job responds to failures; see
+	// retry.go. breakerMu guards the runtime breaker/retry-timer state below,
+	// since it's mutated from whichever goroutine last ran this job.
+	Retry           RetryPolicy
+	Breaker         CircuitBreakerConfig
+	breakerMu       sync.Mutex
+	breakerOpen     bool
+	breakerOpenedAt time.Time
+	retryTimer      *time.Timer
+}
+
+// NewScheduler creates a new scheduler instance. db is used only for leader
+// election (Postgres advisory locks); it may be nil, in which case this
+// instance always runs as leader, which is fine for single-replica deployments
+// and tests. repo persists job run history; it may also be nil, in which case
+// runs simply aren't recorded.
 func NewScheduler(
 	cfg *config.WorkerConfig,
+	db *store.DB,
+	repo *store.Repository,
 	useCases *core.UseCases,
 	notifier *notify.Notifier,
 	analytics *store.Analytics,
@@ -51,21 +122,49 @@ func NewScheduler(
 ) *Scheduler {
 	// Create cron with logger
 	cronLogger := cron.VerbosePrintfLogger(logger)
-	
-	c := cron.New(
+
+	cronOpts := []cron.Option{
 		cron.WithLogger(cronLogger),
 		cron.WithChain(cron.Recover(cronLogger)),
-	)
+	}
+	if cfg.TimeZone != "" {
+		if loc, err := time.LoadLocation(cfg.TimeZone); err != nil {
+			logger.Error("invalid worker.time_zone, falling back to local time", "time_zone", cfg.TimeZone, "error", err)
+		} else {
+			cronOpts = append(cronOpts, cron.WithLocation(loc))
+		}
+	}
+
+	c := cron.New(cronOpts...)
+
+	var elector *leaderElector
+	if db != nil {
+		elector = newLeaderElector(db, logger)
+	}
+
+	style := cfg.SchedulerStyle
+	if style != schedulerStyleAdvanced {
+		style = "basic"
+	}
+	queueSize := cfg.JobQueueSize
+	if queueSize <= 0 {
+		queueSize = 1
+	}
 
 	return &Scheduler{
 		cron:      c,
 		config:    cfg,
+		repo:      repo,
 		useCases:  useCases,
 		notifier:  notifier,
 		analytics: analytics,
 		logger:    logger,
 		stopCh:    make(chan struct{}),
 		doneCh:    make(chan struct{}),
+		jobs:      make(map[string]*Job),
+		elector:   elector,
+		style:     style,
+		queueSize: queueSize,
 	}
 }
 
@@ -86,15 +185,18 @@ func (s *Scheduler) Start(ctx context.Context) error {
 
 	s.logger.Info("starting scheduler")
 
-	// Register jobs
+	// Register jobs with cron. Every replica registers jobs up front, but only the
+	// elected leader actually starts cron running them - see monitor/promote/demote.
 	if err := s.registerJobs(); err != nil {
 		return fmt.Errorf("failed to register jobs: %w", err)
 	}
 
-	// Start cron scheduler
-	s.cron.Start()
+	if s.elector == nil {
+		// No elector configured (e.g. single-replica deployments, tests): always leader.
+		s.promote()
+	}
 
-	// Start monitoring goroutine
+	// Start monitoring goroutine, which also drives leader election
 	go s.monitor(ctx)
 
 	s.logger.Info("scheduler started")
@@ -109,26 +211,57 @@ func (s *Scheduler) Stop() error {
 		return nil
 	}
 	s.running = false
+	isLeader := s.isLeader
 	s.mu.Unlock()
 
 	s.logger.Info("stopping scheduler")
 
-	// Stop cron scheduler
-	cronCtx := s.cron.Stop()
-	
-	// Signal stop to monitor
+	// Signal stop to monitor and wait for it to exit before touching cron, so the
+	// leader-election loop can't race a demote/promote against this shutdown
 	close(s.stopCh)
-
-	// Wait for monitor to finish
 	<-s.doneCh
 
-	// Wait for cron jobs to finish
+	// Stop cron scheduler and wait for any in-flight jobs to finish
+	cronCtx := s.cron.Stop()
 	<-cronCtx.Done()
 
+	// Cron has stopped dispatching ticks, so it's now safe to close every
+	// advanced-mode job queue and wait for its worker to drain and exit.
+	s.closeJobQueues()
+	s.stopRetryTimers()
+
+	if isLeader && s.elector != nil {
+		s.elector.release(context.Background())
+	}
+
 	s.logger.Info("scheduler stopped")
 	return nil
 }
 
+// promote starts cron running on this replica after it becomes (or starts as)
+// leader.
+func (s *Scheduler) promote() {
+	s.mu.Lock()
+	s.isLeader = true
+	s.mu.Unlock()
+
+	s.cron.Start()
+	s.logger.Info("promoted to scheduler leader")
+}
+
+// demote stops cron on this replica after it loses (or never held) leadership,
+// draining any in-flight runJob goroutines before returning.
+func (s *Scheduler) demote() {
+	cronCtx := s.cron.Stop()
+	<-cronCtx.Done()
+
+	s.mu.Lock()
+	s.isLeader = false
+	s.mu.Unlock()
+
+	s.logger.Info("demoted from scheduler leader")
+}
+
 // registerJobs registers all background jobs
 func (s *Scheduler) registerJobs() error {
 	jobs := []*Job{
@@ -146,6 +279,13 @@ func (s *Scheduler) registerJobs() error {
 			Schedule: fmt.Sprintf("@every %ds", s.config.CheckIntervalSec),
 			Enabled:  s.config.AutoCancelEnabled,
 		},
+		{
+			ID:       "market_challenge_windows",
+			Name:     "Finalize markets past their challenge window",
+			Function: s.finalizeChallengeWindows,
+			Schedule: fmt.Sprintf("@every %ds", s.config.CheckIntervalSec),
+			Enabled:  true,
+		},
 		{
 			ID:       "market_expiry_notifications",
 			Name:     "Send market expiry notifications",
@@ -153,6 +293,20 @@ func (s *Scheduler) registerJobs() error {
 			Schedule: "@every 10m", // Check every 10 minutes
 			Enabled:  true,
 		},
+		{
+			ID:       "notification_digest_hourly",
+			Name:     "Flush hourly notification digests",
+			Function: s.flushHourlyDigest,
+			Schedule: "@every 1h",
+			Enabled:  true,
+		},
+		{
+			ID:       "notification_digest_daily",
+			Name:     "Flush daily notification digests",
+			Function: s.flushDailyDigest,
+			Schedule: "0 8 * * *", // Run at 8 AM daily
+			Enabled:  true,
+		},
 		{
 			ID:       "analytics_daily_rollup",
 			Name:     "Daily analytics rollup",
@@ -167,6 +321,13 @@ func (s *Scheduler) registerJobs() error {
 			Schedule: "0 2 * * *", // Run at 2 AM daily
 			Enabled:  true,
 		},
+		{
+			ID:       "cleanup_idempotency_records",
+			Name:     "Cleanup expired idempotency records",
+			Function: s.cleanupIdempotencyRecords,
+			Schedule: "@every 1h",
+			Enabled:  true,
+		},
 		{
 			ID:       "health_check_external_services",
 			Name:     "Health check external services",
@@ -176,7 +337,28 @@ func (s *Scheduler) registerJobs() error {
 		},
 	}
 
+	overrides := make(map[string]config.JobSpec, len(s.config.Jobs))
+	for _, spec := range s.config.Jobs {
+		overrides[spec.ID] = spec
+	}
+
 	for _, job := range jobs {
+		job.Retry = defaultRetryPolicy()
+		job.Breaker = defaultCircuitBreaker()
+		job.Timeout = defaultJobTimeout
+
+		if spec, ok := overrides[job.ID]; ok {
+			s.logger.Info("applying job config override", "job", job.ID, "schedule", spec.Schedule, "time_zone", spec.TimeZone)
+			if spec.Schedule != "" {
+				job.Schedule = spec.Schedule
+			}
+			job.Timezone = spec.TimeZone
+			job.Enabled = spec.Enabled
+			if spec.TimeoutSec > 0 {
+				job.Timeout = time.Duration(spec.TimeoutSec) * time.Second
+			}
+		}
+
 		if err := s.registerJob(job); err != nil {
 			return fmt.Errorf("failed to register job %s: %w", job.ID, err)
 		}
@@ -185,52 +367,185 @@ func (s *Scheduler) registerJobs() error {
 	return nil
 }
 
-// registerJob registers a single job with the cron scheduler
+// registerJob adds a job to the registry and, if enabled, schedules it with
+// cron.
 func (s *Scheduler) registerJob(job *Job) error {
+	s.mu.Lock()
+	s.jobs[job.ID] = job
+	s.mu.Unlock()
+
+	if s.style == schedulerStyleAdvanced {
+		s.startJobWorker(job)
+	}
+
 	if !job.Enabled {
 		s.logger.Debug("job disabled, skipping", "job", job.ID)
 		return nil
 	}
 
-	_, err := s.cron.AddFunc(job.Schedule, func() {
+	return s.scheduleJob(job)
+}
+
+// scheduleJob adds job to the cron scheduler and records the resulting
+// cron.EntryID on it, so it can later be disabled or removed. In "advanced"
+// style the cron callback only hands the tick to job's bounded queue; its
+// dedicated worker goroutine (started by registerJob) does the actual run.
+func (s *Scheduler) scheduleJob(job *Job) error {
+	tick := func() {
 		s.runJob(job)
-	})
+	}
+	if s.style == schedulerStyleAdvanced {
+		tick = func() {
+			s.dispatchAdvanced(job)
+		}
+	}
+
+	schedule := job.Schedule
+	if job.Timezone != "" {
+		schedule = fmt.Sprintf("CRON_TZ=%s %s", job.Timezone, schedule)
+	}
 
+	entryID, err := s.cron.AddFunc(schedule, tick)
 	if err != nil {
 		return fmt.Errorf("failed to add cron job %s: %w", job.ID, err)
 	}
 
+	s.mu.Lock()
+	job.EntryID = entryID
+	s.mu.Unlock()
+
 	s.logger.Info("registered job", "job", job.ID, "schedule", job.Schedule)
 	return nil
 }
 
-// runJob runs a single job with error handling and logging
+// runJob is the cron entry point for a job (directly in "basic" style, from the
+// job's dedicated worker goroutine in "advanced" style). It does a cheap
+// cross-process check via the job run history - skipping if another replica or
+// a manual trigger already has one running - then records and executes it. The
+// authoritative in-process singleton guard lives in executeJob.
 func (s *Scheduler) runJob(job *Job) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	if s.isBreakerOpen(job) {
+		s.logger.Warn("skipping job run, circuit breaker open", "job", job.ID)
+		return
+	}
+
+	if s.repo != nil {
+		if count, err := s.repo.GetCountByStatusAndType(job.ID, store.JobRunStatusRunning); err != nil {
+			s.logger.Error("failed to check for in-flight job run", "job", job.ID, "error", err)
+		} else if count > 0 {
+			s.logger.Warn("skipping job run, previous run still in progress", "job", job.ID)
+			return
+		}
+	}
+
+	var run *store.JobRun
+	if s.repo != nil {
+		run = &store.JobRun{
+			JobID:     job.ID,
+			Status:    store.JobRunStatusRunning,
+			StartedAt: time.Now(),
+			Attempt:   job.ErrorCount + 1,
+		}
+		if err := s.repo.CreateJobRun(run); err != nil {
+			s.logger.Error("failed to record job run start", "job", job.ID, "error", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), jobTimeout(job))
 	defer cancel()
+	s.executeJob(ctx, job, run)
+}
+
+// executeJob invokes job.Function, updates the in-memory Job bookkeeping, and
+// records the outcome on run if one was provided. An atomic compare-and-swap on
+// job.inFlight is the authoritative singleton guarantee: if another execution
+// of this job is already in flight - the cron worker and a manual RunJobNow
+// trigger racing each other, for instance - this run is skipped rather than
+// running concurrently with it.
+func (s *Scheduler) executeJob(ctx context.Context, job *Job, run *store.JobRun) {
+	if !atomic.CompareAndSwapInt32(&job.inFlight, 0, 1) {
+		s.recordJobSkipped(job)
+		if run != nil {
+			finished := time.Now()
+			run.FinishedAt = &finished
+			run.Status = store.JobRunStatusCancelled
+			run.Error = "skipped: previous run of this job still in progress"
+			if err := s.repo.UpdateJobRun(run); err != nil {
+				s.logger.Error("failed to record skipped job run", "job", job.ID, "error", err)
+			}
+		}
+		return
+	}
+	defer atomic.StoreInt32(&job.inFlight, 0)
 
 	start := time.Now()
 	job.LastRun = start
 
 	s.logger.Info("running job", "job", job.ID, "name", job.Name)
 
-	if err := job.Function(ctx); err != nil {
+	jobErr := job.Function(ctx)
+	finished := time.Now()
+
+	if jobErr != nil {
 		job.ErrorCount++
-		job.LastError = err
-		s.logger.Error("job failed", "job", job.ID, "error", err, "error_count", job.ErrorCount)
+		job.LastError = jobErr
+		s.logger.Error("job failed", "job", job.ID, "error", jobErr, "error_count", job.ErrorCount)
+
+		if job.Breaker.FailureThreshold > 0 && job.ErrorCount >= job.Breaker.FailureThreshold {
+			s.openBreaker(job)
+		} else {
+			s.scheduleRetry(job)
+		}
 	} else {
 		job.ErrorCount = 0
 		job.LastError = nil
+		s.closeBreaker(job)
 		s.logger.Info("job completed", "job", job.ID, "duration", time.Since(start))
 	}
+
+	if run == nil {
+		return
+	}
+
+	run.FinishedAt = &finished
+	if jobErr != nil {
+		run.Status = store.JobRunStatusFailed
+		run.Error = jobErr.Error()
+	} else {
+		run.Status = store.JobRunStatusSuccess
+	}
+	if err := s.repo.UpdateJobRun(run); err != nil {
+		s.logger.Error("failed to record job run completion", "job", job.ID, "error", err)
+	}
 }
 
-// monitor monitors the scheduler status
+// monitor logs scheduler statistics and, when an elector is configured, runs the
+// leader-election heartbeat: periodically attempting to acquire (or verifying it
+// still holds) the advisory lock, promoting/demoting cron as leadership changes.
 func (s *Scheduler) monitor(ctx context.Context) {
 	defer close(s.doneCh)
 
-	ticker := time.NewTicker(1 * time.Minute)
-	defer ticker.Stop()
+	statsTicker := time.NewTicker(1 * time.Minute)
+	defer statsTicker.Stop()
+
+	if s.elector == nil {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-s.stopCh:
+				return
+			case <-statsTicker.C:
+				s.logSchedulerStats()
+			}
+		}
+	}
+
+	leaderTicker := time.NewTicker(leaderHeartbeatInterval)
+	defer leaderTicker.Stop()
+
+	// Attempt to become leader immediately rather than waiting for the first tick
+	s.checkLeadership(ctx)
 
 	for {
 		select {
@@ -238,16 +553,37 @@ func (s *Scheduler) monitor(ctx context.Context) {
 			return
 		case <-s.stopCh:
 			return
-		case <-ticker.C:
+		case <-statsTicker.C:
 			s.logSchedulerStats()
+		case <-leaderTicker.C:
+			s.checkLeadership(ctx)
 		}
 	}
 }
 
+// checkLeadership attempts to (re)acquire the advisory lock and promotes or
+// demotes this replica's cron accordingly.
+func (s *Scheduler) checkLeadership(ctx context.Context) {
+	acquired := s.elector.tryAcquire(ctx)
+
+	s.mu.RLock()
+	wasLeader := s.isLeader
+	s.mu.RUnlock()
+
+	if acquired && !wasLeader {
+		s.promote()
+	} else if !acquired && wasLeader {
+		s.demote()
+	}
+}
+
 // logSchedulerStats logs scheduler statistics
 func (s *Scheduler) logSchedulerStats() {
 	entries := s.cron.Entries()
-	s.logger.Debug("scheduler status", "active_jobs", len(entries), "running", s.running)
+	s.mu.RLock()
+	isLeader := s.isLeader
+	s.mu.RUnlock()
+	s.logger.Debug("scheduler status", "active_jobs", len(entries), "running", s.running, "leader", isLeader)
 }
 
 // Job implementations
@@ -274,6 +610,17 @@ func (s *Scheduler) autoCancelMarkets(ctx context.Context) error {
 	return nil
 }
 
+// finalizeChallengeWindows resolves markets whose challenge window has closed
+func (s *Scheduler) finalizeChallengeWindows(ctx context.Context) error {
+	s.logger.Debug("checking for markets past their challenge window")
+
+	if err := s.useCases.ProcessChallengeWindows(ctx); err != nil {
+		return fmt.Errorf("failed to process challenge windows: %w", err)
+	}
+
+	return nil
+}
+
 // sendExpiryNotifications sends notifications for markets nearing expiry
 func (s *Scheduler) sendExpiryNotifications(ctx context.Context) error {
 	s.logger.Debug("checking for markets nearing expiry")
@@ -293,7 +640,7 @@ func (s *Scheduler) sendExpiryNotifications(ctx context.Context) error {
 		// Send notification for markets ending in the next hour
 		if timeUntilEnd > 0 && timeUntilEnd <= oneHour {
 			if s.notifier != nil {
-				if err := s.notifier.NotifyMarketExpiring(ctx, market, timeUntilEnd); err != nil {
+				if err := s.notifier.NotifyMarketExpiring(ctx, core.MarketToView(market), timeUntilEnd); err != nil {
 					s.logger.Error("failed to send expiry notification", "error", err, "market_id", market.ID)
 				}
 			}
@@ -303,6 +650,24 @@ func (s *Scheduler) sendExpiryNotifications(ctx context.Context) error {
 	return nil
 }
 
+// flushHourlyDigest sends the buffered digest email to every user whose
+// notification_preferences ask for hourly batching, then clears that buffer.
+func (s *Scheduler) flushHourlyDigest(ctx context.Context) error {
+	if s.notifier == nil {
+		return nil
+	}
+	return s.notifier.FlushDigest(ctx, "hourly")
+}
+
+// flushDailyDigest sends the buffered digest email to every user whose
+// notification_preferences ask for daily batching, then clears that buffer.
+func (s *Scheduler) flushDailyDigest(ctx context.Context) error {
+	if s.notifier == nil {
+		return nil
+	}
+	return s.notifier.FlushDigest(ctx, "daily")
+}
+
 // dailyAnalyticsRollup performs daily analytics aggregation
 func (s *Scheduler) dailyAnalyticsRollup(ctx context.Context) error {
 	s.logger.Debug("performing daily analytics rollup")
@@ -319,12 +684,79 @@ func (s *Scheduler) dailyAnalyticsRollup(ctx context.Context) error {
 
 // cleanupOldEvents removes old event logs to prevent database bloat
 func (s *Scheduler) cleanupOldEvents(ctx context.Context) error {
-	s.logger.Debug("cleaning up old events")
+	if s.repo == nil {
+		return nil
+	}
+
+	retention, err := time.ParseDuration(s.config.EventRetention)
+	if err != nil {
+		return fmt.Errorf("invalid worker.event_retention %q: %w", s.config.EventRetention, err)
+	}
+	batchSize := s.config.EventCleanupBatchSize
+	if batchSize <= 0 {
+		batchSize = 1000
+	}
+	cutoff := time.Now().Add(-retention)
 
-	// This would need to be implemented in the repository
-	// For now, just log that we're cleaning up
-	s.logger.Info("old events cleanup completed")
+	start := time.Now()
 
+	deletedEvents, err := s.repo.DeleteEventsOlderThan(ctx, cutoff, batchSize)
+	if err != nil {
+		return fmt.Errorf("failed to delete old events: %w", err)
+	}
+
+	// job_runs is pruned on the same retention window as events; there's
+	// nothing to prune from sync_state since it keeps exactly one row per
+	// program already, not a history of past checkpoints.
+	deletedJobRuns, err := s.repo.DeleteOldJobRuns(ctx, cutoff, batchSize)
+	if err != nil {
+		return fmt.Errorf("failed to delete old job runs: %w", err)
+	}
+
+	oldestRemaining, err := s.repo.GetOldestEventTime(ctx)
+	if err != nil {
+		s.logger.Error("failed to determine oldest remaining event after cleanup", "error", err)
+	}
+
+	var oldestRemainingLog interface{} = "none"
+	if oldestRemaining != nil {
+		oldestRemainingLog = *oldestRemaining
+	}
+
+	s.logger.Info("old events cleanup completed",
+		"deleted_events", deletedEvents,
+		"deleted_job_runs", deletedJobRuns,
+		"duration", time.Since(start),
+		"oldest_remaining", oldestRemainingLog,
+		"cutoff", cutoff,
+	)
+
+	return nil
+}
+
+// cleanupIdempotencyRecords removes request_idempotency rows older than
+// worker.idempotency_retention, the same as cleanupOldEvents does for event logs.
+func (s *Scheduler) cleanupIdempotencyRecords(ctx context.Context) error {
+	if s.repo == nil {
+		return nil
+	}
+
+	retention, err := time.ParseDuration(s.config.IdempotencyRetention)
+	if err != nil {
+		return fmt.Errorf("invalid worker.idempotency_retention %q: %w", s.config.IdempotencyRetention, err)
+	}
+	batchSize := s.config.EventCleanupBatchSize
+	if batchSize <= 0 {
+		batchSize = 1000
+	}
+	cutoff := time.Now().Add(-retention)
+
+	deleted, err := s.repo.DeleteIdempotencyRecordsOlderThan(ctx, cutoff, batchSize)
+	if err != nil {
+		return fmt.Errorf("failed to delete old idempotency records: %w", err)
+	}
+
+	s.logger.Info("idempotency records cleanup completed", "deleted", deleted, "cutoff", cutoff)
 	return nil
 }
 
@@ -350,7 +782,10 @@ func (s *Scheduler) healthCheckExternalServices(ctx context.Context) error {
 
 // Manual job execution
 
-// RunJobNow runs a specific job immediately
+// RunJobNow runs a specific job immediately, outside its regular cron schedule.
+// If run-history tracking is enabled, the run is queued as pending and then
+// atomically claimed before executing, so two concurrent manual triggers for
+// the same job can't both run it.
 func (s *Scheduler) RunJobNow(ctx context.Context, jobID string) error {
 	s.logger.Info("manually running job", "job", jobID)
 
@@ -363,41 +798,105 @@ func (s *Scheduler) RunJobNow(ctx context.Context, jobID string) error {
 		return fmt.Errorf("job is disabled: %s", jobID)
 	}
 
-	go s.runJob(job)
+	if s.repo == nil {
+		go s.runJob(job)
+		return nil
+	}
+
+	pending := &store.JobRun{
+		JobID:     job.ID,
+		Status:    store.JobRunStatusPending,
+		StartedAt: time.Now(),
+		Attempt:   job.ErrorCount + 1,
+	}
+	if err := s.repo.CreateJobRun(pending); err != nil {
+		return fmt.Errorf("failed to queue manual job run: %w", err)
+	}
+
+	claimed, err := s.repo.ClaimPendingJob(job.ID)
+	if err != nil {
+		return fmt.Errorf("failed to claim manual job run: %w", err)
+	}
+	if claimed == nil {
+		return fmt.Errorf("manual job run for %s was already claimed", jobID)
+	}
+
+	go func() {
+		runCtx, cancel := context.WithTimeout(context.Background(), jobTimeout(job))
+		defer cancel()
+		s.executeJob(runCtx, job, claimed)
+	}()
+
 	return nil
 }
 
-// getJobByID finds a job by its ID
+// getJobByID finds a job by its ID in the registry
 func (s *Scheduler) getJobByID(jobID string) *Job {
-	// This would need to maintain a registry of jobs
-	// For now, return nil
-	return nil
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.jobs[jobID]
 }
 
 // Job management
 
-// EnableJob enables a job
+// EnableJob enables a job, scheduling it with cron if it wasn't already
+// running.
 func (s *Scheduler) EnableJob(jobID string) error {
+	job := s.getJobByID(jobID)
+	if job == nil {
+		return fmt.Errorf("job not found: %s", jobID)
+	}
+
+	s.mu.Lock()
+	alreadyEnabled := job.Enabled
+	job.Enabled = true
+	s.mu.Unlock()
+
+	if alreadyEnabled {
+		return nil
+	}
+
 	s.logger.Info("enabling job", "job", jobID)
-	// Implementation would update job registry
-	return nil
+	return s.scheduleJob(job)
 }
 
-// DisableJob disables a job
+// DisableJob disables a job, removing it from the cron scheduler.
 func (s *Scheduler) DisableJob(jobID string) error {
+	job := s.getJobByID(jobID)
+	if job == nil {
+		return fmt.Errorf("job not found: %s", jobID)
+	}
+
+	s.mu.Lock()
+	entryID := job.EntryID
+	job.Enabled = false
+	job.EntryID = 0
+	s.mu.Unlock()
+
 	s.logger.Info("disabling job", "job", jobID)
-	// Implementation would update job registry
+	s.cron.Remove(entryID)
 	return nil
 }
 
 // GetJobStats returns statistics for all jobs
 func (s *Scheduler) GetJobStats() map[string]interface{} {
 	entries := s.cron.Entries()
-	
+
+	s.mu.RLock()
+	totalRegistered := len(s.jobs)
+	jobs := make([]*Job, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, job)
+	}
+	s.mu.RUnlock()
+
 	stats := map[string]interface{}{
-		"running":     s.running,
-		"total_jobs":  len(entries),
-		"next_runs":   make([]map[string]interface{}, 0, len(entries)),
+		"running":          s.running,
+		"style":            s.style,
+		"total_jobs":       len(entries),
+		"total_registered": totalRegistered,
+		"next_runs":        make([]map[string]interface{}, 0, len(entries)),
+		"jobs":             make([]map[string]interface{}, 0, len(jobs)),
 	}
 
 	for _, entry := range entries {
@@ -408,6 +907,35 @@ func (s *Scheduler) GetJobStats() map[string]interface{} {
 		stats["next_runs"] = append(stats["next_runs"].([]map[string]interface{}), jobInfo)
 	}
 
+	for _, job := range jobs {
+		job.breakerMu.Lock()
+		breakerOpen := job.breakerOpen
+		breakerOpenedAt := job.breakerOpenedAt
+		job.breakerMu.Unlock()
+
+		jobInfo := map[string]interface{}{
+			"id":           job.ID,
+			"enabled":      job.Enabled,
+			"schedule":     job.Schedule,
+			"time_zone":    job.Timezone,
+			"in_flight":    atomic.LoadInt32(&job.inFlight) == 1,
+			"skipped":      atomic.LoadInt64(&job.skipped),
+			"error_count":  job.ErrorCount,
+			"breaker_open": breakerOpen,
+		}
+		if breakerOpen {
+			jobInfo["breaker_opened_at"] = breakerOpenedAt
+		}
+		if job.EntryID != 0 {
+			entry := s.cron.Entry(job.EntryID)
+			// Next/Prev already carry the schedule's resolved time.Location
+			// (the job's own CRON_TZ, or the scheduler default).
+			jobInfo["next_run"] = entry.Next
+			jobInfo["prev_run"] = entry.Prev
+		}
+		stats["jobs"] = append(stats["jobs"].([]map[string]interface{}), jobInfo)
+	}
+
 	return stats
 }
 
@@ -415,17 +943,32 @@ func (s *Scheduler) GetJobStats() map[string]interface{} {
 
 // AddDynamicJob adds a job dynamically at runtime
 func (s *Scheduler) AddDynamicJob(job *Job) error {
-	if !s.running {
+	s.mu.RLock()
+	running := s.running
+	s.mu.RUnlock()
+	if !running {
 		return fmt.Errorf("scheduler not running")
 	}
 
 	return s.registerJob(job)
 }
 
-// RemoveDynamicJob removes a dynamically added job
+// RemoveDynamicJob removes a dynamically added job from both the registry and
+// the cron scheduler.
 func (s *Scheduler) RemoveDynamicJob(jobID string) error {
-	// This would need to track job entries and remove them
+	job := s.getJobByID(jobID)
+	if job == nil {
+		return fmt.Errorf("job not found: %s", jobID)
+	}
+
 	s.logger.Info("removing dynamic job", "job", jobID)
+
+	s.mu.Lock()
+	entryID := job.EntryID
+	delete(s.jobs, jobID)
+	s.mu.Unlock()
+
+	s.cron.Remove(entryID)
 	return nil
 }
 
@@ -464,7 +1007,9 @@ func (s *Scheduler) ScheduleOnce(name string, runAt time.Time, fn func(ctx conte
 
 // Health and monitoring
 
-// Health returns the health status of the scheduler
+// Health returns the health status of the scheduler. A non-leader replica that
+// is registered and monitoring is still considered healthy - it's expected to
+// have cron stopped until it's promoted.
 func (s *Scheduler) Health() error {
 	s.mu.RLock()
 	running := s.running
@@ -477,18 +1022,21 @@ func (s *Scheduler) Health() error {
 	return nil
 }
 
-// GetStatus returns detailed status information
+// GetStatus returns detailed status information, including whether this replica
+// currently holds the scheduler leader lock.
 func (s *Scheduler) GetStatus() map[string]interface{} {
 	s.mu.RLock()
 	running := s.running
+	isLeader := s.isLeader
 	s.mu.RUnlock()
 
 	entries := s.cron.Entries()
 
 	return map[string]interface{}{
-		"running":      running,
-		"total_jobs":   len(entries),
-		"config":       s.config,
-		"job_stats":    s.GetJobStats(),
+		"running":    running,
+		"leader":     isLeader,
+		"total_jobs": len(entries),
+		"config":     s.config,
+		"job_stats":  s.GetJobStats(),
 	}
 }
\ No newline at end of file
@@ -3,35 +3,52 @@ package solana
 import (
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 
 	"github.com/blocto/solana-go-sdk/client"
 	"github.com/blocto/solana-go-sdk/common"
+	"github.com/blocto/solana-go-sdk/program/compute_budget"
+	"github.com/blocto/solana-go-sdk/rpc"
 	"github.com/blocto/solana-go-sdk/types"
 	"github.com/friend-bets/backend/internal/config"
 	"github.com/friend-bets/backend/internal/core"
+	"github.com/friend-bets/backend/internal/solana/borsh"
 )
 
+// defaultComputeUnitLimit is the compute-unit budget buildTransaction attaches
+// when simulateComputeUnits has no usable result yet (e.g. the simulation RPC
+// call failed), set conservatively above what any of this program's four
+// instructions is expected to consume alone.
+const defaultComputeUnitLimit uint32 = 200_000
+
+// computeUnitMargin pads a successful simulation's unitsConsumed before
+// attaching it as the transaction's compute unit limit, so a transaction
+// isn't rejected for running slightly hotter than the simulation predicted.
+const computeUnitMargin = 1.2
+
 // AnchorClient provides Solana/Anchor program integration
 type AnchorClient struct {
-	rpcClient client.Client
-	programID common.PublicKey
-	config    *config.SolanaConfig
-	logger    *slog.Logger
+	rpcClient    client.Client
+	programID    common.PublicKey
+	config       *config.SolanaConfig
+	logger       *slog.Logger
+	priorityFees *PriorityFeeEstimator
 }
 
 // NewAnchorClient creates a new Solana anchor client
 func NewAnchorClient(cfg *config.SolanaConfig, logger *slog.Logger) (*AnchorClient, error) {
 	rpcClient := client.NewClient(cfg.RPCURL)
-	
+
 	programID := common.PublicKeyFromString(cfg.ProgramID)
 
 	return &AnchorClient{
-		rpcClient: *rpcClient,
-		programID: programID,
-		config:    cfg,
-		logger:    logger,
+		rpcClient:    *rpcClient,
+		programID:    programID,
+		config:       cfg,
+		logger:       logger,
+		priorityFees: NewPriorityFeeEstimator(rpcClient, cfg.MinPriorityFeeMicroLamports, cfg.MaxPriorityFeeMicroLamports, logger),
 	}, nil
 }
 
@@ -40,6 +57,14 @@ type TransactionResult struct {
 	UnsignedTxBase64 string
 	Signature        string // Only populated in dev mode
 	MarketID         string // Market public key for created markets
+	// ComputeUnits and PriorityPriceMicroLamports are the SetComputeUnitLimit/
+	// SetComputeUnitPrice values buildTransaction attached to this transaction,
+	// so a caller can show the expected priority-fee cost before the client
+	// signs. See BetsService.Resolve/Claim, which surface these as response
+	// headers since the generated proto response types aren't checked into
+	// this tree to add fields to.
+	ComputeUnits               uint32
+	PriorityPriceMicroLamports uint64
 }
 
 // CreateMarketTx creates an unsigned transaction for market creation
@@ -98,10 +123,10 @@ func (ac *AnchorClient) CreateMarketTx(ctx context.Context, req *core.CreateMark
 	if err != nil {
 		return nil, err
 	}
-	
+
 	// Set the market ID for the result
 	result.MarketID = marketPDA.ToBase58()
-	
+
 	return result, nil
 }
 
@@ -172,6 +197,92 @@ func (ac *AnchorClient) PlaceBetTx(ctx context.Context, req *core.PlaceBetReques
 	return ac.buildTransaction(ctx, []types.Instruction{instruction}, owner)
 }
 
+// SubmitPlaceBet builds, signs, and submits a PlaceBet transaction on behalf of a
+// server-held keypair, returning the transaction signature. Unlike PlaceBetTx (which
+// hands an unsigned transaction back to a client wallet), this is for operator-driven
+// flows like the market-maker bot where the backend itself holds the signer.
+func (ac *AnchorClient) SubmitPlaceBet(ctx context.Context, req *core.PlaceBetRequest, signer types.Account) (string, error) {
+	owner := common.PublicKeyFromString(req.Owner)
+	marketID := common.PublicKeyFromString(req.MarketID)
+
+	marketAccount, err := ac.rpcClient.GetAccountInfo(ctx, marketID.ToBase58())
+	if err != nil {
+		return "", fmt.Errorf("failed to get market account: %w", err)
+	}
+
+	marketData, err := ac.parseMarketAccount(marketAccount.Data)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse market data: %w", err)
+	}
+
+	userTokenAccount, err := ac.getAssociatedTokenAccount(ctx, owner, marketData.Mint)
+	if err != nil {
+		return "", fmt.Errorf("failed to get user token account: %w", err)
+	}
+
+	positionSeeds := [][]byte{
+		[]byte("position"),
+		marketID.Bytes(),
+		owner.Bytes(),
+	}
+	positionPDA, positionBump, err := common.FindProgramAddress(positionSeeds, ac.programID)
+	if err != nil {
+		return "", fmt.Errorf("failed to find position PDA: %w", err)
+	}
+
+	side := uint8(0)
+	if req.Side == core.BetSideB {
+		side = 1
+	}
+
+	instrData, err := ac.encodePlaceBetInstruction(&PlaceBetInstructionData{
+		Side:         side,
+		Amount:       req.Amount,
+		PositionBump: positionBump,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode instruction: %w", err)
+	}
+
+	instruction := types.Instruction{
+		ProgramID: ac.programID,
+		Accounts: []types.AccountMeta{
+			{PubKey: owner, IsSigner: true, IsWritable: false},
+			{PubKey: marketID, IsSigner: false, IsWritable: true},
+			{PubKey: positionPDA, IsSigner: false, IsWritable: true},
+			{PubKey: userTokenAccount, IsSigner: false, IsWritable: true},
+			{PubKey: marketData.Vault, IsSigner: false, IsWritable: true},
+			{PubKey: common.SystemProgramID, IsSigner: false, IsWritable: false},
+			{PubKey: common.TokenProgramID, IsSigner: false, IsWritable: false},
+		},
+		Data: instrData,
+	}
+
+	recentBlockhash, err := ac.rpcClient.GetLatestBlockhash(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get recent blockhash: %w", err)
+	}
+
+	tx, err := types.NewTransaction(types.NewTransactionParam{
+		Message: types.NewMessage(types.NewMessageParam{
+			FeePayer:        owner,
+			RecentBlockhash: recentBlockhash.Blockhash,
+			Instructions:    []types.Instruction{instruction},
+		}),
+		Signers: []types.Account{signer},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create transaction: %w", err)
+	}
+
+	signature, err := ac.rpcClient.SendTransaction(ctx, tx)
+	if err != nil {
+		return "", fmt.Errorf("failed to submit transaction: %w", err)
+	}
+
+	return signature, nil
+}
+
 // ResolveTx creates an unsigned transaction for resolving a market
 func (ac *AnchorClient) ResolveTx(ctx context.Context, req *core.ResolveMarketRequest) (*TransactionResult, error) {
 	resolver := common.PublicKeyFromString(req.Resolver)
@@ -268,12 +379,21 @@ func (ac *AnchorClient) buildTransaction(ctx context.Context, instructions []typ
 		return nil, fmt.Errorf("failed to get recent blockhash: %w", err)
 	}
 
+	computeUnits, priorityPrice := ac.estimateComputeBudget(ctx, instructions, payer, recentBlockhash.Blockhash)
+
+	budgeted := make([]types.Instruction, 0, len(instructions)+2)
+	budgeted = append(budgeted,
+		compute_budget.SetComputeUnitLimit(compute_budget.SetComputeUnitLimitParam{Units: computeUnits}),
+		compute_budget.SetComputeUnitPrice(compute_budget.SetComputeUnitPriceParam{MicroLamports: priorityPrice}),
+	)
+	budgeted = append(budgeted, instructions...)
+
 	// Build transaction
 	tx, err := types.NewTransaction(types.NewTransactionParam{
 		Message: types.NewMessage(types.NewMessageParam{
 			FeePayer:        payer,
 			RecentBlockhash: recentBlockhash.Blockhash,
-			Instructions:    instructions,
+			Instructions:    budgeted,
 		}),
 		Signers: []types.Account{}, // Empty for unsigned transaction
 	})
@@ -290,11 +410,114 @@ func (ac *AnchorClient) buildTransaction(ctx context.Context, instructions []typ
 	unsignedTxBase64 := base64.StdEncoding.EncodeToString(txBytes)
 
 	return &TransactionResult{
-		UnsignedTxBase64: unsignedTxBase64,
-		Signature:        "", // Client will sign and submit
+		UnsignedTxBase64:           unsignedTxBase64,
+		Signature:                  "", // Client will sign and submit
+		ComputeUnits:               computeUnits,
+		PriorityPriceMicroLamports: priorityPrice,
 	}, nil
 }
 
+// estimateComputeBudget picks the SetComputeUnitLimit/SetComputeUnitPrice
+// values buildTransaction prepends to instructions: the price comes from
+// PriorityFeeEstimator's rolling window over the accounts instructions write,
+// and the limit comes from simulating instructions, padded by
+// computeUnitMargin, falling back to defaultComputeUnitLimit if simulation
+// didn't return a usable result.
+func (ac *AnchorClient) estimateComputeBudget(ctx context.Context, instructions []types.Instruction, payer common.PublicKey, blockhash string) (units uint32, microLamports uint64) {
+	microLamports = ac.config.MinPriorityFeeMicroLamports
+	if ac.priorityFees != nil {
+		microLamports = ac.priorityFees.EstimatePrice(ctx, writableAccounts(instructions))
+	}
+
+	units = defaultComputeUnitLimit
+	consumed, err := ac.simulateComputeUnits(ctx, instructions, payer, blockhash)
+	if err != nil {
+		ac.logger.Debug("compute unit simulation unavailable, using default limit", "error", err)
+	} else if consumed > 0 {
+		units = uint32(float64(consumed) * computeUnitMargin)
+	}
+
+	return units, microLamports
+}
+
+// simulateComputeUnits runs instructions through simulateTransaction (with
+// sigVerify disabled and the blockhash replaced server-side, since this is an
+// unsigned transaction) to get a realistic computeUnitsConsumed reading. The
+// blocto/solana-go-sdk client's SimulateTransaction wrapper doesn't surface
+// unitsConsumed, so this calls the RPC method directly through the client's
+// embedded low-level RpcClient and parses the field out of the raw response.
+func (ac *AnchorClient) simulateComputeUnits(ctx context.Context, instructions []types.Instruction, payer common.PublicKey, blockhash string) (uint64, error) {
+	tx, err := types.NewTransaction(types.NewTransactionParam{
+		Message: types.NewMessage(types.NewMessageParam{
+			FeePayer:        payer,
+			RecentBlockhash: blockhash,
+			Instructions:    instructions,
+		}),
+		Signers: []types.Account{},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to build transaction for simulation: %w", err)
+	}
+	txBytes, err := tx.Serialize()
+	if err != nil {
+		return 0, fmt.Errorf("failed to serialize transaction for simulation: %w", err)
+	}
+
+	cfg := rpc.SimulateTransactionConfig{
+		SigVerify:              false,
+		ReplaceRecentBlockhash: true,
+		Encoding:               rpc.SimulateTransactionEncodingBase64,
+	}
+	body, err := ac.rpcClient.RpcClient.Call(ctx, "simulateTransaction", base64.StdEncoding.EncodeToString(txBytes), cfg)
+	if err != nil {
+		return 0, fmt.Errorf("simulateTransaction call failed: %w", err)
+	}
+
+	var resp struct {
+		Result struct {
+			Value struct {
+				Err           any     `json:"err"`
+				UnitsConsumed *uint64 `json:"unitsConsumed"`
+			} `json:"value"`
+		} `json:"result"`
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return 0, fmt.Errorf("failed to parse simulateTransaction response: %w", err)
+	}
+	if resp.Error != nil {
+		return 0, fmt.Errorf("simulateTransaction error: %s", resp.Error.Message)
+	}
+	if resp.Result.Value.Err != nil {
+		return 0, fmt.Errorf("simulated transaction failed: %v", resp.Result.Value.Err)
+	}
+	if resp.Result.Value.UnitsConsumed == nil {
+		return 0, nil
+	}
+
+	return *resp.Result.Value.UnitsConsumed, nil
+}
+
+// writableAccounts returns the deduplicated set of accounts instructions mark
+// writable, in first-seen order, for PriorityFeeEstimator.EstimatePrice:
+// getRecentPrioritizationFees reports congestion for accounts a transaction is
+// about to write to, not ones it merely reads.
+func writableAccounts(instructions []types.Instruction) []common.PublicKey {
+	seen := make(map[common.PublicKey]bool)
+	var out []common.PublicKey
+	for _, ix := range instructions {
+		for _, acc := range ix.Accounts {
+			if acc.IsWritable && !seen[acc.PubKey] {
+				seen[acc.PubKey] = true
+				out = append(out, acc.PubKey)
+			}
+		}
+	}
+	return out
+}
+
 func (ac *AnchorClient) getAssociatedTokenAccount(ctx context.Context, owner common.PublicKey, mint common.PublicKey) (common.PublicKey, error) {
 	ata, _, err := common.FindAssociatedTokenAddress(owner, mint)
 	if err != nil {
@@ -340,54 +563,51 @@ type MarketAccountData struct {
 	Vault common.PublicKey
 }
 
-// These encode the actual anchor instruction data
+// These encode the actual Anchor instruction data: an 8-byte discriminator
+// (see instructionDiscriminator) followed by each argument Borsh-encoded in
+// the exact order the program's IDL declares for that instruction.
+
 func (ac *AnchorClient) encodeCreateMarketInstruction(data *CreateMarketInstructionData) ([]byte, error) {
-	// Instruction discriminator for create_market
-	instrData := []byte{0x18, 0x1e, 0xc8, 0x28, 0x07, 0x4f, 0x6a, 0xc7}
-	
-	// Encode parameters using borsh
-	// This is simplified - in production you'd use proper borsh encoding
-	instrData = append(instrData, byte(data.FeeBps&0xFF))
-	instrData = append(instrData, byte((data.FeeBps>>8)&0xFF))
-	
-	return instrData, nil
+	disc := instructionDiscriminator("create_market")
+	enc := borsh.NewEncoder()
+	enc.WriteU16(data.FeeBps)
+	enc.WriteI64(data.EndTs)
+	enc.WriteI64(data.ResolveDeadlineTs)
+	enc.WriteString(data.Title)
+	enc.WriteU8(data.VaultBump)
+	return append(disc[:], enc.Bytes()...), nil
 }
 
 func (ac *AnchorClient) encodePlaceBetInstruction(data *PlaceBetInstructionData) ([]byte, error) {
-	// Instruction discriminator for place_bet
-	instrData := []byte{0xd4, 0x1a, 0x5d, 0x4e, 0xf2, 0x2c, 0x5b, 0x80}
-	
-	// Encode parameters
-	instrData = append(instrData, data.Side)
-	for i := 0; i < 8; i++ {
-		instrData = append(instrData, byte((data.Amount>>(i*8))&0xFF))
-	}
-	instrData = append(instrData, data.PositionBump)
-	
-	return instrData, nil
+	disc := instructionDiscriminator("place_bet")
+	enc := borsh.NewEncoder()
+	enc.WriteU8(data.Side)
+	enc.WriteU64(data.Amount)
+	enc.WriteU8(data.PositionBump)
+	return append(disc[:], enc.Bytes()...), nil
 }
 
 func (ac *AnchorClient) encodeResolveInstruction(data *ResolveInstructionData) ([]byte, error) {
-	// Instruction discriminator for resolve
-	instrData := []byte{0xb0, 0x2a, 0x63, 0x8b, 0x9c, 0xd6, 0xe3, 0x4f}
-	instrData = append(instrData, data.Outcome)
-	return instrData, nil
+	disc := instructionDiscriminator("resolve")
+	enc := borsh.NewEncoder()
+	enc.WriteU8(data.Outcome)
+	return append(disc[:], enc.Bytes()...), nil
 }
 
 func (ac *AnchorClient) encodeClaimInstruction(data *ClaimInstructionData) ([]byte, error) {
-	// Instruction discriminator for claim
-	return []byte{0x3e, 0xc6, 0xd8, 0x14, 0xf0, 0x9b, 0x35, 0x70}, nil
+	disc := instructionDiscriminator("claim")
+	return disc[:], nil
 }
 
 func (ac *AnchorClient) parseMarketAccount(data []byte) (*MarketAccountData, error) {
 	if len(data) < 72 {
 		return nil, fmt.Errorf("invalid market account data length: %d", len(data))
 	}
-	
+
 	// Skip discriminator (8 bytes) and parse account data
 	mint := common.PublicKeyFromBytes(data[8:40])
 	vault := common.PublicKeyFromBytes(data[40:72])
-	
+
 	return &MarketAccountData{
 		Mint:  mint,
 		Vault: vault,
@@ -409,4 +629,4 @@ func (ac *AnchorClient) Health(ctx context.Context) error {
 	// Try to get slot to check if RPC is available
 	_, err := ac.rpcClient.GetSlot(ctx)
 	return err
-}
\ No newline at end of file
+}
@@ -0,0 +1,127 @@
+package solana
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// anchorVector is one entry of a testdata/anchor_vectors/*.json file: an
+// instruction's inputs and the exact Borsh-encoded instruction data the Go
+// encoder must produce for it.
+//
+// There's no on-chain program or TS Anchor client checked into this repo to
+// generate these from, so expected_hex was computed by an independent
+// reference implementation of the same sha256-discriminator-plus-Borsh
+// scheme Anchor itself uses (see instructionDiscriminator and
+// internal/solana/borsh), not dumped from a canonical client. That makes this
+// a regression/consistency test against the scheme's own spec rather than a
+// cross-client conformance check; if this repo ever gains a real TS client or
+// IDL fixture, these vectors should be regenerated from it instead.
+type anchorVector struct {
+	Instruction string          `json:"instruction"`
+	Name        string          `json:"name"`
+	Inputs      json.RawMessage `json:"inputs"`
+	ExpectedHex string          `json:"expected_hex"`
+}
+
+func TestAnchorEncodersConformance(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "anchor_vectors", "*.json"))
+	if err != nil {
+		t.Fatalf("failed to list vector files: %v", err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no vector files found under testdata/anchor_vectors")
+	}
+
+	ac := &AnchorClient{}
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", file, err)
+		}
+
+		var vectors []anchorVector
+		if err := json.Unmarshal(data, &vectors); err != nil {
+			t.Fatalf("failed to parse %s: %v", file, err)
+		}
+
+		for _, v := range vectors {
+			t.Run(v.Instruction+"/"+v.Name, func(t *testing.T) {
+				got, err := encodeVector(ac, v)
+				if err != nil {
+					t.Fatalf("failed to encode vector: %v", err)
+				}
+
+				gotHex := hex.EncodeToString(got)
+				if gotHex != v.ExpectedHex {
+					t.Errorf("encoded instruction data mismatch\n got:      %s\n expected: %s", gotHex, v.ExpectedHex)
+				}
+			})
+		}
+	}
+}
+
+// encodeVector decodes v.Inputs into the struct encodeCreateMarketInstruction
+// and friends expect, then invokes the matching encoder.
+func encodeVector(ac *AnchorClient, v anchorVector) ([]byte, error) {
+	switch v.Instruction {
+	case "create_market":
+		var inputs struct {
+			FeeBps            uint16 `json:"fee_bps"`
+			EndTs             int64  `json:"end_ts"`
+			ResolveDeadlineTs int64  `json:"resolve_deadline_ts"`
+			Title             string `json:"title"`
+			VaultBump         uint8  `json:"vault_bump"`
+		}
+		if err := json.Unmarshal(v.Inputs, &inputs); err != nil {
+			return nil, err
+		}
+		return ac.encodeCreateMarketInstruction(&CreateMarketInstructionData{
+			FeeBps:            inputs.FeeBps,
+			EndTs:             inputs.EndTs,
+			ResolveDeadlineTs: inputs.ResolveDeadlineTs,
+			Title:             inputs.Title,
+			VaultBump:         inputs.VaultBump,
+		})
+
+	case "place_bet":
+		var inputs struct {
+			Side         uint8  `json:"side"`
+			Amount       uint64 `json:"amount"`
+			PositionBump uint8  `json:"position_bump"`
+		}
+		if err := json.Unmarshal(v.Inputs, &inputs); err != nil {
+			return nil, err
+		}
+		return ac.encodePlaceBetInstruction(&PlaceBetInstructionData{
+			Side:         inputs.Side,
+			Amount:       inputs.Amount,
+			PositionBump: inputs.PositionBump,
+		})
+
+	case "resolve":
+		var inputs struct {
+			Outcome uint8 `json:"outcome"`
+		}
+		if err := json.Unmarshal(v.Inputs, &inputs); err != nil {
+			return nil, err
+		}
+		return ac.encodeResolveInstruction(&ResolveInstructionData{Outcome: inputs.Outcome})
+
+	case "claim":
+		return ac.encodeClaimInstruction(&ClaimInstructionData{})
+
+	default:
+		return nil, errUnknownInstruction(v.Instruction)
+	}
+}
+
+type errUnknownInstruction string
+
+func (e errUnknownInstruction) Error() string {
+	return "unknown instruction in vector file: " + string(e)
+}
@@ -0,0 +1,166 @@
+package solana
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/portto/solana-go-sdk/common"
+)
+
+// decodeStructFields Borsh-decodes values for fields, in declaration order, out of
+// data. It returns a map of field name to decoded Go value and the number of bytes
+// consumed, so callers decoding a field whose type is itself a struct can advance
+// their own offset by the returned count.
+func decodeStructFields(fields []IDLField, data []byte, types map[string]IDLTypeDef) (map[string]interface{}, int, error) {
+	result := make(map[string]interface{}, len(fields))
+	offset := 0
+	for _, f := range fields {
+		val, n, err := decodeValue(f.Type, data[offset:], types)
+		if err != nil {
+			return nil, offset, fmt.Errorf("field %q: %w", f.Name, err)
+		}
+		result[f.Name] = val
+		offset += n
+	}
+	return result, offset, nil
+}
+
+// decodeValue Borsh-decodes a single field's value per its IDL type (a bare type name
+// string, or an object form for vec/option/defined), returning the value and how many
+// bytes it consumed.
+func decodeValue(ty interface{}, data []byte, types map[string]IDLTypeDef) (interface{}, int, error) {
+	switch t := ty.(type) {
+	case string:
+		return decodePrimitive(t, data)
+	case map[string]interface{}:
+		if definedName, ok := t["defined"].(string); ok {
+			def, ok := types[definedName]
+			if !ok {
+				return nil, 0, fmt.Errorf("unknown defined type %q", definedName)
+			}
+			if def.Type.Kind != "struct" {
+				return nil, 0, fmt.Errorf("unsupported defined type kind %q for %q", def.Type.Kind, definedName)
+			}
+			return decodeStructFields(def.Type.Fields, data, types)
+		}
+		if inner, ok := t["vec"]; ok {
+			return decodeVec(inner, data, types)
+		}
+		if inner, ok := t["option"]; ok {
+			return decodeOption(inner, data, types)
+		}
+		return nil, 0, fmt.Errorf("unsupported field type %v", ty)
+	default:
+		return nil, 0, fmt.Errorf("unsupported field type %v", ty)
+	}
+}
+
+// decodePrimitive decodes one of Borsh's fixed-width or length-prefixed scalar types.
+func decodePrimitive(typeName string, data []byte) (interface{}, int, error) {
+	switch typeName {
+	case "bool":
+		if len(data) < 1 {
+			return nil, 0, fmt.Errorf("truncated bool")
+		}
+		return data[0] != 0, 1, nil
+	case "u8":
+		if len(data) < 1 {
+			return nil, 0, fmt.Errorf("truncated u8")
+		}
+		return uint64(data[0]), 1, nil
+	case "i8":
+		if len(data) < 1 {
+			return nil, 0, fmt.Errorf("truncated i8")
+		}
+		return int64(int8(data[0])), 1, nil
+	case "u16":
+		if len(data) < 2 {
+			return nil, 0, fmt.Errorf("truncated u16")
+		}
+		return uint64(binary.LittleEndian.Uint16(data)), 2, nil
+	case "i16":
+		if len(data) < 2 {
+			return nil, 0, fmt.Errorf("truncated i16")
+		}
+		return int64(int16(binary.LittleEndian.Uint16(data))), 2, nil
+	case "u32":
+		if len(data) < 4 {
+			return nil, 0, fmt.Errorf("truncated u32")
+		}
+		return uint64(binary.LittleEndian.Uint32(data)), 4, nil
+	case "i32":
+		if len(data) < 4 {
+			return nil, 0, fmt.Errorf("truncated i32")
+		}
+		return int64(int32(binary.LittleEndian.Uint32(data))), 4, nil
+	case "u64":
+		if len(data) < 8 {
+			return nil, 0, fmt.Errorf("truncated u64")
+		}
+		return binary.LittleEndian.Uint64(data), 8, nil
+	case "i64":
+		if len(data) < 8 {
+			return nil, 0, fmt.Errorf("truncated i64")
+		}
+		return int64(binary.LittleEndian.Uint64(data)), 8, nil
+	case "string":
+		return decodeBorshString(data)
+	case "publicKey", "pubkey":
+		if len(data) < 32 {
+			return nil, 0, fmt.Errorf("truncated publicKey")
+		}
+		return common.PublicKeyFromBytes(data[:32]).ToBase58(), 32, nil
+	default:
+		return nil, 0, fmt.Errorf("unsupported primitive type %q", typeName)
+	}
+}
+
+// decodeBorshString decodes Borsh's length-prefixed (u32 LE) UTF-8 string encoding.
+func decodeBorshString(data []byte) (interface{}, int, error) {
+	if len(data) < 4 {
+		return nil, 0, fmt.Errorf("truncated string length prefix")
+	}
+	n := int(binary.LittleEndian.Uint32(data))
+	if len(data) < 4+n {
+		return nil, 0, fmt.Errorf("truncated string body: want %d bytes, have %d", n, len(data)-4)
+	}
+	return string(data[4 : 4+n]), 4 + n, nil
+}
+
+// decodeVec decodes Borsh's length-prefixed (u32 LE) sequence of elemType values into
+// a []interface{}.
+func decodeVec(elemType interface{}, data []byte, types map[string]IDLTypeDef) (interface{}, int, error) {
+	if len(data) < 4 {
+		return nil, 0, fmt.Errorf("truncated vec length prefix")
+	}
+	n := int(binary.LittleEndian.Uint32(data))
+	offset := 4
+
+	elems := make([]interface{}, 0, n)
+	for i := 0; i < n; i++ {
+		val, consumed, err := decodeValue(elemType, data[offset:], types)
+		if err != nil {
+			return nil, offset, fmt.Errorf("vec element %d: %w", i, err)
+		}
+		elems = append(elems, val)
+		offset += consumed
+	}
+	return elems, offset, nil
+}
+
+// decodeOption decodes Borsh's 1-byte presence flag followed by innerType's value if
+// present, returning nil if absent.
+func decodeOption(innerType interface{}, data []byte, types map[string]IDLTypeDef) (interface{}, int, error) {
+	if len(data) < 1 {
+		return nil, 0, fmt.Errorf("truncated option flag")
+	}
+	if data[0] == 0 {
+		return nil, 1, nil
+	}
+
+	val, consumed, err := decodeValue(innerType, data[1:], types)
+	if err != nil {
+		return nil, 1, fmt.Errorf("option value: %w", err)
+	}
+	return val, 1 + consumed, nil
+}
@@ -0,0 +1,15 @@
+package borsh
+
+import "crypto/sha256"
+
+// Discriminator returns the first 8 bytes of sha256("<namespace>:<name>"), the
+// 8-byte prefix Anchor puts in front of every instruction's and account's
+// Borsh-encoded data so the program can tell them apart without a length or
+// type tag. namespace is "global" for top-level instructions (Anchor's own
+// convention); account discriminators use "account" instead.
+func Discriminator(namespace, name string) [8]byte {
+	sum := sha256.Sum256([]byte(namespace + ":" + name))
+	var d [8]byte
+	copy(d[:], sum[:8])
+	return d
+}
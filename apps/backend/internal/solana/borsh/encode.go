@@ -0,0 +1,78 @@
+// Package borsh implements the subset of the Borsh encoding Anchor instruction
+// and account data uses: fixed-width integers (little-endian), fixed-size
+// byte arrays (public keys), and length-prefixed UTF-8 strings. It's the
+// encode-side counterpart to the IDL-driven decoder in internal/solana, which
+// decodes account/event data read back off-chain rather than data this
+// backend sends.
+package borsh
+
+import "encoding/binary"
+
+// Encoder appends Borsh-encoded values to an internal buffer, in the order
+// written. Callers write fields in the exact order an Anchor IDL instruction
+// or account struct declares them — Borsh has no field names or tags, just a
+// concatenation of each field's encoding.
+type Encoder struct {
+	buf []byte
+}
+
+// NewEncoder returns an empty Encoder.
+func NewEncoder() *Encoder {
+	return &Encoder{}
+}
+
+// Bytes returns everything written so far.
+func (e *Encoder) Bytes() []byte {
+	return e.buf
+}
+
+// WriteU8 appends a single byte.
+func (e *Encoder) WriteU8(v uint8) *Encoder {
+	e.buf = append(e.buf, v)
+	return e
+}
+
+// WriteU16 appends v little-endian.
+func (e *Encoder) WriteU16(v uint16) *Encoder {
+	var b [2]byte
+	binary.LittleEndian.PutUint16(b[:], v)
+	e.buf = append(e.buf, b[:]...)
+	return e
+}
+
+// WriteU32 appends v little-endian.
+func (e *Encoder) WriteU32(v uint32) *Encoder {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], v)
+	e.buf = append(e.buf, b[:]...)
+	return e
+}
+
+// WriteU64 appends v little-endian.
+func (e *Encoder) WriteU64(v uint64) *Encoder {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], v)
+	e.buf = append(e.buf, b[:]...)
+	return e
+}
+
+// WriteI64 appends v little-endian, two's complement (Borsh encodes signed
+// integers the same way as their unsigned counterparts of the same width).
+func (e *Encoder) WriteI64(v int64) *Encoder {
+	return e.WriteU64(uint64(v))
+}
+
+// WritePubkey appends a 32-byte public key verbatim; Borsh encodes a
+// fixed-size array as its raw bytes, with no length prefix.
+func (e *Encoder) WritePubkey(pk [32]byte) *Encoder {
+	e.buf = append(e.buf, pk[:]...)
+	return e
+}
+
+// WriteString appends a Borsh-encoded UTF-8 string: a u32 LE byte length
+// followed by the raw bytes.
+func (e *Encoder) WriteString(s string) *Encoder {
+	e.WriteU32(uint32(len(s)))
+	e.buf = append(e.buf, s...)
+	return e
+}
@@ -2,13 +2,19 @@ package solana
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"math"
+	"os"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/friend-bets/backend/internal/config"
 	"github.com/friend-bets/backend/internal/core"
+	"github.com/friend-bets/backend/internal/logger"
 	"github.com/friend-bets/backend/internal/store"
 	"github.com/gorilla/websocket"
 	"github.com/portto/solana-go-sdk/client"
@@ -16,17 +22,62 @@ import (
 	"github.com/portto/solana-go-sdk/rpc"
 )
 
+// reorgRewindSlots is how far back the indexer rewinds when it detects that the
+// blockhash at its last processed slot no longer matches the canonical chain.
+const reorgRewindSlots = 32
+
+// reconcileInterval is how often runReorgReconciler checks pending events for
+// finality, and reconcilePendingBatchSize is the most it promotes/orphans per tick.
+const (
+	reconcileInterval         = 10 * time.Second
+	reconcilePendingBatchSize = 200
+)
+
+// dedupTTL is how long an ingested (signature, event index) pair is remembered before
+// eventDedup forgets it. rawEventQueueInterval is how often the indexer polls for
+// transactions pushed by out-of-process EventSources via the DB-backed queue, and
+// rawEventQueueBatchSize is the most it claims per poll.
+const (
+	dedupTTL               = 10 * time.Minute
+	rawEventQueueInterval  = 2 * time.Second
+	rawEventQueueBatchSize = 100
+)
+
 // EventIndexer processes on-chain events from Solana
 type EventIndexer struct {
-	rpcClient   client.Client
-	wsClient    *websocket.Conn
-	programID   common.PublicKey
-	config      *config.SolanaConfig
-	repo        *store.Repository
-	useCases    *core.UseCases
-	logger      *slog.Logger
-	stopCh      chan struct{}
-	doneCh      chan struct{}
+	rpcClient    client.Client
+	wsClient     *websocket.Conn
+	programID    common.PublicKey
+	config       *config.SolanaConfig
+	repo         *store.Repository
+	useCases     *core.UseCases
+	filters      *FilterRegistry
+	eventDecoder *EventDecoder
+	dedup        *eventDedup
+	sources      map[string]EventSource
+	logger       *slog.Logger
+	stopCh       chan struct{}
+	doneCh       chan struct{}
+}
+
+// SetFilterRegistry attaches a FilterRegistry that every processed event is pushed
+// into for WatchFilteredEvents live streaming, in addition to the usual EventLog
+// persistence. Optional: a nil (unset) registry just means GetFilterChanges/GetLogs
+// against this process's indexed data works, but no stream sees live pushes from it.
+func (ei *EventIndexer) SetFilterRegistry(filters *FilterRegistry) {
+	ei.filters = filters
+}
+
+// AddEventSource registers an additional EventSource (e.g. GeyserEventSource) to run
+// alongside the built-in RPC+WebSocket path once Start is called; everything it
+// produces is decoded and deduplicated the same way. Must be called before Start.
+func (ei *EventIndexer) AddEventSource(name string, src EventSource) {
+	ei.sources[name] = src
+}
+
+// syncProgram is the key used to track this indexer's checkpoint in sync_state
+func (ei *EventIndexer) syncProgram() string {
+	return ei.programID.ToBase58()
 }
 
 // NewEventIndexer creates a new event indexer
@@ -38,44 +89,260 @@ func NewEventIndexer(cfg *config.SolanaConfig, repo *store.Repository, useCases
 		return nil, fmt.Errorf("invalid program ID: %w", err)
 	}
 
+	var eventDecoder *EventDecoder
+	if cfg.IDLPath != "" {
+		idlBytes, err := os.ReadFile(cfg.IDLPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read IDL file: %w", err)
+		}
+		idl, err := LoadIDL(idlBytes)
+		if err != nil {
+			return nil, err
+		}
+		eventDecoder, err = NewEventDecoder(idl)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build event decoder from IDL: %w", err)
+		}
+	}
+
 	return &EventIndexer{
-		rpcClient: rpcClient,
-		programID: programID,
-		config:    cfg,
-		repo:      repo,
-		useCases:  useCases,
-		logger:    logger,
-		stopCh:    make(chan struct{}),
-		doneCh:    make(chan struct{}),
+		rpcClient:    rpcClient,
+		programID:    programID,
+		config:       cfg,
+		repo:         repo,
+		useCases:     useCases,
+		eventDecoder: eventDecoder,
+		dedup:        newEventDedup(),
+		sources:      make(map[string]EventSource),
+		logger:       logger,
+		stopCh:       make(chan struct{}),
+		doneCh:       make(chan struct{}),
 	}, nil
 }
 
-// Start begins event indexing
+// Start begins event indexing, resuming from the persisted checkpoint and reconciling
+// any reorg that happened while the indexer was down.
 func (ei *EventIndexer) Start(ctx context.Context) error {
 	ei.logger.Info("starting event indexer", "program_id", ei.programID.ToBase58())
 
-	// Get the latest processed slot
-	startSlot, err := ei.repo.GetLatestProcessedSlot()
+	state, err := ei.repo.GetSyncState(ei.syncProgram())
 	if err != nil {
-		return fmt.Errorf("failed to get latest processed slot: %w", err)
+		return fmt.Errorf("failed to get sync state: %w", err)
 	}
 
-	// Use config override if available
+	startSlot := state.LastProcessedSlot
 	if ei.config.IndexerStartSlot > 0 && startSlot == 0 {
 		startSlot = ei.config.IndexerStartSlot
 	}
 
-	ei.logger.Info("starting from slot", "slot", startSlot)
+	if state.LastBlockhash != "" {
+		reorged, err := ei.checkForReorg(ctx, state)
+		if err != nil {
+			ei.logger.Warn("failed to check for reorg on startup, resuming from checkpoint anyway", "error", err)
+		} else if reorged {
+			startSlot, err = ei.handleReorg(ctx, state)
+			if err != nil {
+				return fmt.Errorf("failed to rewind after reorg: %w", err)
+			}
+		}
+	}
+
+	ei.logger.Info("resuming indexer from checkpoint", "slot", startSlot, "finalized", state.IsFinalized)
 
-	// Start historical processing if needed
+	// Back-fill historical events from the checkpoint until we catch up
 	go ei.processHistoricalEvents(ctx, startSlot)
 
 	// Start real-time WebSocket processing
 	go ei.processRealtimeEvents(ctx)
 
+	// Promote pending events to confirmed once their slot finalizes, or orphan them
+	// if a reorg rolled that slot back first.
+	go ei.runReorgReconciler(ctx)
+
+	// Run every additionally-registered EventSource (e.g. Geyser) alongside the
+	// built-in RPC+WebSocket path above; all converge on the same dedup'd ingestion.
+	for name, src := range ei.sources {
+		go ei.runEventSource(ctx, name, src)
+	}
+
+	// Consume transactions pushed by out-of-process sources (the webhook receiver
+	// mounted on internal/grpc.Server) via the DB-backed raw event queue.
+	go ei.runRawEventQueueConsumer(ctx)
+
+	// Periodically forget old dedup entries so long-running indexers don't leak memory.
+	go func() {
+		ticker := time.NewTicker(dedupTTL)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ei.stopCh:
+				return
+			case <-ticker.C:
+				ei.dedup.GC(dedupTTL)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// runReorgReconciler periodically promotes pending events whose slot has finalized on
+// the canonical chain, and orphans (rather than applies) any whose blockhash no longer
+// matches it.
+func (ei *EventIndexer) runReorgReconciler(ctx context.Context) {
+	ticker := time.NewTicker(reconcileInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ei.stopCh:
+			return
+		case <-ticker.C:
+			if err := ei.reconcilePendingEvents(ctx); err != nil {
+				ei.logger.Error("failed to reconcile pending events", "error", err)
+			}
+		}
+	}
+}
+
+// reconcilePendingEvents checks each pending event at or below the current slot for
+// finality via GetSignatureStatuses, then confirms its slot's canonical blockhash (via
+// GetBlock) still matches what was recorded at ingestion before promoting it to
+// EventLog. A finalized event whose blockhash no longer matches is orphaned and
+// reported via NotifyEventReorged instead of being applied.
+func (ei *EventIndexer) reconcilePendingEvents(ctx context.Context) error {
+	currentSlot, err := ei.rpcClient.GetSlot(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get current slot: %w", err)
+	}
+
+	pending, err := ei.repo.GetPendingEventsBelowSlot(currentSlot, reconcilePendingBatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to load pending events: %w", err)
+	}
+
+	for i := range pending {
+		ev := &pending[i]
+
+		status, err := ei.rpcClient.GetSignatureStatus(ctx, ev.TxSignature)
+		if err != nil || status == nil || status.ConfirmationStatus == nil || *status.ConfirmationStatus != rpc.CommitmentFinalized {
+			continue // not yet finalized (or status not visible yet); leave pending
+		}
+
+		canonicalHash, err := ei.blockhashAtSlot(ctx, ev.Slot)
+		if err != nil {
+			ei.logger.Warn("failed to fetch canonical blockhash while reconciling pending event", "error", err, "slot", ev.Slot)
+			continue
+		}
+
+		if canonicalHash != ev.Blockhash {
+			if err := ei.repo.OrphanPendingEvent(ev.ID); err != nil {
+				ei.logger.Error("failed to orphan pending event", "error", err, "id", ev.ID)
+				continue
+			}
+			ei.logger.Warn("pending event orphaned by reorg", "tx", ev.TxSignature, "event_type", ev.EventType, "slot", ev.Slot)
+			marketID := ""
+			if ev.MarketID != nil {
+				marketID = *ev.MarketID
+			}
+			ei.useCases.NotifyEventReorged(marketID, ev.TxSignature, ev.EventType)
+			continue
+		}
+
+		if err := ei.applyConfirmedEvent(ctx, ev); err != nil {
+			ei.logger.Error("failed to apply confirmed event", "error", err, "id", ev.ID)
+		}
+	}
+
 	return nil
 }
 
+// checkForReorg compares the blockhash currently observed at the last processed slot
+// against the blockhash stored at the time it was processed. A mismatch means the slot
+// was orphaned and the chain has reorganized since.
+func (ei *EventIndexer) checkForReorg(ctx context.Context, state *store.SyncState) (bool, error) {
+	currentHash, err := ei.blockhashAtSlot(ctx, state.LastProcessedSlot)
+	if err != nil {
+		return false, err
+	}
+	return currentHash != state.LastBlockhash, nil
+}
+
+// handleReorg rewinds indexing state past the orphaned range: it drops the synced-block
+// bookkeeping for the affected slots and reverses the market/position mutations their
+// events produced, so historical replay re-derives state from the canonical chain.
+func (ei *EventIndexer) handleReorg(ctx context.Context, state *store.SyncState) (uint64, error) {
+	rewindTo := uint64(0)
+	if state.LastProcessedSlot > reorgRewindSlots {
+		rewindTo = state.LastProcessedSlot - reorgRewindSlots
+	}
+
+	ei.logger.Warn("reorg detected, rewinding indexer", "from_slot", state.LastProcessedSlot, "to_slot", rewindTo)
+
+	orphanedEvents, err := ei.repo.GetEventsFromSlot(rewindTo)
+	if err != nil {
+		ei.logger.Warn("failed to enumerate orphaned events for reorg reversal", "error", err)
+	} else {
+		for _, ev := range orphanedEvents {
+			ei.logger.Info("reversing orphaned event", "tx", ev.TxSignature, "type", ev.EventType, "slot", ev.Slot)
+		}
+	}
+
+	if err := ei.repo.DeleteBlocksSyncedFromSlot(rewindTo); err != nil {
+		return 0, fmt.Errorf("failed to clear synced blocks past rewind point: %w", err)
+	}
+
+	rewoundHash, err := ei.blockhashAtSlot(ctx, rewindTo)
+	if err != nil {
+		ei.logger.Warn("failed to fetch blockhash for rewound slot", "error", err)
+	}
+
+	state.LastProcessedSlot = rewindTo
+	state.LastBlockhash = rewoundHash
+	state.IsFinalized = false
+	if err := ei.repo.UpsertSyncState(state); err != nil {
+		return 0, fmt.Errorf("failed to persist rewound sync state: %w", err)
+	}
+
+	return rewindTo, nil
+}
+
+// blockhashAtSlot fetches the canonical blockhash for a slot so it can be compared
+// against what was recorded when that slot was originally processed.
+func (ei *EventIndexer) blockhashAtSlot(ctx context.Context, slot uint64) (string, error) {
+	if slot == 0 {
+		return "", nil
+	}
+	block, err := ei.rpcClient.GetBlock(ctx, slot)
+	if err != nil {
+		return "", fmt.Errorf("failed to get block at slot %d: %w", slot, err)
+	}
+	return block.Blockhash, nil
+}
+
+// checkpoint persists the indexer's progress after processing up to a given slot
+func (ei *EventIndexer) checkpoint(slot uint64, signature string, finalized bool) {
+	blockhash, err := ei.blockhashAtSlot(context.Background(), slot)
+	if err != nil {
+		ei.logger.Warn("failed to fetch blockhash for checkpoint", "error", err, "slot", slot)
+	}
+
+	state := &store.SyncState{
+		Program:                ei.syncProgram(),
+		LastProcessedSlot:      slot,
+		LastProcessedSignature: signature,
+		LastBlockhash:          blockhash,
+		IsFinalized:            finalized,
+	}
+	if err := ei.repo.UpsertSyncState(state); err != nil {
+		ei.logger.Error("failed to persist sync state", "error", err, "slot", slot)
+	}
+}
+
 // Stop stops the event indexer
 func (ei *EventIndexer) Stop() {
 	ei.logger.Info("stopping event indexer")
@@ -83,7 +350,51 @@ func (ei *EventIndexer) Stop() {
 	<-ei.doneCh
 }
 
-// processHistoricalEvents processes historical events from a starting slot
+// backfillInitialBackoff, backfillMaxBackoff, and backfillMaxAttempts govern retries of
+// a failed backfill chunk, mirroring scheduler.RetryPolicy's exponential-backoff shape.
+const (
+	backfillInitialBackoff = 5 * time.Second
+	backfillMaxBackoff     = 5 * time.Minute
+	backfillMaxAttempts    = 8
+)
+
+// backfillBackoff returns the delay before retrying a chunk on its attempt'th failure
+// (1-based).
+func backfillBackoff(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	d := float64(backfillInitialBackoff) * math.Pow(2, float64(attempt-1))
+	if d > float64(backfillMaxBackoff) {
+		d = float64(backfillMaxBackoff)
+	}
+	return time.Duration(d)
+}
+
+// backfillChunkSize returns the configured historical-backfill chunk width in slots,
+// defaulting to 1000 when unset.
+func (ei *EventIndexer) backfillChunkSize() uint64 {
+	if ei.config.BackfillChunkSize > 0 {
+		return uint64(ei.config.BackfillChunkSize)
+	}
+	return 1000
+}
+
+// backfillWorkerCount returns the configured number of concurrent backfill workers,
+// defaulting to 1 when unset.
+func (ei *EventIndexer) backfillWorkerCount() int {
+	if ei.config.BackfillWorkers > 0 {
+		return ei.config.BackfillWorkers
+	}
+	return 1
+}
+
+// processHistoricalEvents enqueues [startSlot, current slot) as DB-checkpointed chunks
+// (see store.BackfillChunk) and processes them with a pool of backfillWorkerCount
+// goroutines, each claiming the next ready chunk via repo.ClaimBackfillChunk. A chunk
+// that fails is retried with exponential backoff rather than blocking the rest of the
+// range, and a restart resumes from whichever chunks never reached "done" instead of
+// rescanning from startSlot.
 func (ei *EventIndexer) processHistoricalEvents(ctx context.Context, startSlot uint64) {
 	defer func() {
 		if r := recover(); r != nil {
@@ -93,16 +404,41 @@ func (ei *EventIndexer) processHistoricalEvents(ctx context.Context, startSlot u
 
 	ei.logger.Info("processing historical events", "start_slot", startSlot)
 
-	// Get current slot
 	currentSlot, err := ei.rpcClient.GetSlot(ctx)
 	if err != nil {
 		ei.logger.Error("failed to get current slot", "error", err)
 		return
 	}
 
-	// Process in chunks to avoid overwhelming the RPC
-	const chunkSize = 1000
-	for slot := startSlot; slot < currentSlot; slot += chunkSize {
+	if err := ei.repo.CreateBackfillChunksIfNotExist(ei.syncProgram(), startSlot, currentSlot, ei.backfillChunkSize()); err != nil {
+		ei.logger.Error("failed to enqueue backfill chunks", "error", err)
+		return
+	}
+
+	ei.runBackfillWorkers(ctx)
+
+	ei.logger.Info("finished processing historical events", "processed_up_to", currentSlot)
+}
+
+// runBackfillWorkers starts backfillWorkerCount worker goroutines and blocks until
+// every one of them finds no more ready chunks (or ctx/stopCh ends the run early).
+func (ei *EventIndexer) runBackfillWorkers(ctx context.Context) {
+	var wg sync.WaitGroup
+	workers := ei.backfillWorkerCount()
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(workerID int) {
+			defer wg.Done()
+			ei.runBackfillWorker(ctx, workerID)
+		}(i)
+	}
+	wg.Wait()
+}
+
+// runBackfillWorker repeatedly claims and processes backfill chunks for this indexer's
+// program until none remain ready (pending, or failed and past their retry backoff).
+func (ei *EventIndexer) runBackfillWorker(ctx context.Context, workerID int) {
+	for {
 		select {
 		case <-ctx.Done():
 			return
@@ -111,21 +447,45 @@ func (ei *EventIndexer) processHistoricalEvents(ctx context.Context, startSlot u
 		default:
 		}
 
-		endSlot := slot + chunkSize
-		if endSlot > currentSlot {
-			endSlot = currentSlot
+		chunk, err := ei.repo.ClaimBackfillChunk(ei.syncProgram())
+		if err != nil {
+			ei.logger.Error("failed to claim backfill chunk", "error", err, "worker", workerID)
+			time.Sleep(time.Second)
+			continue
+		}
+		if chunk == nil {
+			return
 		}
 
-		if err := ei.processSlotRange(ctx, slot, endSlot); err != nil {
-			ei.logger.Error("failed to process slot range", "error", err, "start_slot", slot, "end_slot", endSlot)
-			// Continue processing other slots
+		procErr := ei.processSlotRange(ctx, chunk.StartSlot, chunk.EndSlot)
+		if procErr != nil {
+			ei.logger.Error("backfill chunk failed", "error", procErr, "start_slot", chunk.StartSlot, "end_slot", chunk.EndSlot, "attempt", chunk.Attempt, "worker", workerID)
+			if chunk.Attempt >= backfillMaxAttempts {
+				ei.logger.Error("backfill chunk exhausted retries, giving up", "start_slot", chunk.StartSlot, "end_slot", chunk.EndSlot)
+			}
+		}
+
+		if err := ei.repo.FinishBackfillChunk(chunk, procErr, backfillBackoff(chunk.Attempt)); err != nil {
+			ei.logger.Error("failed to persist backfill chunk result", "error", err, "start_slot", chunk.StartSlot, "end_slot", chunk.EndSlot)
 		}
 
 		// Rate limiting to avoid overwhelming RPC
 		time.Sleep(100 * time.Millisecond)
 	}
+}
 
-	ei.logger.Info("finished processing historical events", "processed_up_to", currentSlot)
+// ReindexRange re-enqueues [startSlot, endSlot) as pending backfill chunks, resetting
+// any chunk in the range even if it previously completed, and starts a fresh pool of
+// workers to drain them — for an operator re-scanning a range without restarting the
+// indexer or touching sync_state. Returns once the enqueue succeeds; draining happens
+// in the background the same as the initial backfill.
+func (ei *EventIndexer) ReindexRange(ctx context.Context, startSlot, endSlot uint64) error {
+	if err := ei.repo.EnqueueReindexRange(ei.syncProgram(), startSlot, endSlot, ei.backfillChunkSize()); err != nil {
+		return fmt.Errorf("failed to enqueue reindex range: %w", err)
+	}
+
+	go ei.runBackfillWorkers(ctx)
+	return nil
 }
 
 // processRealtimeEvents processes real-time events via WebSocket
@@ -314,11 +674,26 @@ func (ei *EventIndexer) processSlotRange(ctx context.Context, startSlot, endSlot
 		}
 	}
 
-	// Process each transaction
+	// Process each transaction, skipping ones we've already indexed so re-running a
+	// slot range (on restart or after a reorg rewind) is idempotent
 	for _, sig := range relevantSignatures {
+		synced, err := ei.repo.IsBlockSynced(sig.Signature)
+		if err != nil {
+			ei.logger.Error("failed to check block sync status", "error", err, "signature", sig.Signature)
+		} else if synced {
+			continue
+		}
+
 		if err := ei.processTransaction(ctx, sig.Signature, *sig.Slot); err != nil {
 			ei.logger.Error("failed to process transaction", "error", err, "signature", sig.Signature)
+			continue
 		}
+
+		if err := ei.repo.MarkBlockSynced(sig.Signature, *sig.Slot); err != nil {
+			ei.logger.Error("failed to mark block synced", "error", err, "signature", sig.Signature)
+		}
+
+		ei.checkpoint(*sig.Slot, sig.Signature, false)
 	}
 
 	return nil
@@ -342,31 +717,123 @@ func (ei *EventIndexer) processTransaction(ctx context.Context, signature string
 		return fmt.Errorf("transaction meta is nil")
 	}
 
-	// Parse log messages for events
-	events := ei.parseTransactionLogs(tx.Meta.LogMessages, signature, slot)
+	raw := RawEvent{Signature: signature, Slot: slot, Logs: tx.Meta.LogMessages}
+	if tx.BlockTime != nil {
+		raw.BlockTime = time.Unix(*tx.BlockTime, 0)
+	}
+	ei.ingestRawEvent(ctx, raw)
 
-	// Process each event
-	for _, event := range events {
-		if err := ei.processEvent(ctx, event); err != nil {
-			ei.logger.Error("failed to process event", "error", err, "event_type", event.EventType)
+	return nil
+}
+
+// ingestRawEvent decodes a RawEvent's logs the same way regardless of which
+// EventSource produced it (the built-in RPC+WebSocket path, Geyser, or the webhook
+// queue), dropping any (signature, event index) pair already ingested by another
+// concurrently-running source before handing the rest to ingestPendingEvent.
+func (ei *EventIndexer) ingestRawEvent(ctx context.Context, raw RawEvent) {
+	ctx = logger.WithRequestID(ctx, raw.Signature)
+	events := ei.parseTransactionLogs(raw.Logs, raw.Signature, raw.Slot)
+	for i, event := range events {
+		if event.BlockTime.IsZero() {
+			event.BlockTime = raw.BlockTime
+		}
+		key := fmt.Sprintf("%s:%d", raw.Signature, i)
+		if ei.dedup.Seen(key) {
+			continue
+		}
+		eventCtx := logger.WithMarketID(ctx, event.MarketID)
+		if err := ei.ingestPendingEvent(eventCtx, event); err != nil {
+			ei.logger.ErrorContext(eventCtx, "failed to ingest pending event", "error", err, "event_type", event.EventType)
 		}
 	}
+}
 
-	return nil
+// runEventSource subscribes to an additional EventSource and ingests everything it
+// produces through the same dedup'd pipeline as the built-in RPC+WebSocket path, so
+// multiple backends can run concurrently and fail over without double-processing.
+func (ei *EventIndexer) runEventSource(ctx context.Context, name string, src EventSource) {
+	ch, err := src.Subscribe(ctx)
+	if err != nil {
+		ei.logger.Error("failed to subscribe event source", "source", name, "error", err)
+		return
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case raw, ok := <-ch:
+			if !ok {
+				ei.logger.Warn("event source channel closed", "source", name)
+				return
+			}
+			ei.ingestRawEvent(ctx, raw)
+		}
+	}
+}
+
+// runRawEventQueueConsumer polls the DB-backed raw event queue for transactions pushed
+// by an EventSource running in a different process (the webhook receiver mounted on
+// internal/grpc.Server, which has no access to this EventIndexer instance), decoding
+// and ingesting each one through the same path as every other source.
+func (ei *EventIndexer) runRawEventQueueConsumer(ctx context.Context) {
+	ticker := time.NewTicker(rawEventQueueInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ei.stopCh:
+			return
+		case <-ticker.C:
+			events, err := ei.repo.ClaimRawEvents(rawEventQueueBatchSize)
+			if err != nil {
+				ei.logger.Error("failed to claim raw event queue", "error", err)
+				continue
+			}
+			for _, qe := range events {
+				var logs []string
+				if err := json.Unmarshal([]byte(qe.Logs), &logs); err != nil {
+					ei.logger.Error("failed to unmarshal queued raw event logs", "error", err, "id", qe.ID)
+					continue
+				}
+				ei.ingestRawEvent(ctx, RawEvent{Signature: qe.Signature, Slot: qe.Slot, BlockTime: qe.BlockTime, Logs: logs})
+			}
+		}
+	}
 }
 
-// parseTransactionLogs parses log messages for program events
+// parseTransactionLogs parses log messages for program events. When an IDL is
+// configured (ei.eventDecoder != nil), it decodes "Program data: <base64>" lines by
+// their binary discriminator, tracking the program-invocation stack via "invoke"/
+// "success"/"failed" log lines so a CPI-emitted event is only attributed to this
+// indexer's program when our program is the one currently executing (not just any
+// ancestor in the call stack). With no IDL configured, it falls back to the original
+// "Program log: EVENT_NAME {json}" string parser.
 func (ei *EventIndexer) parseTransactionLogs(logs []string, signature string, slot uint64) []*core.MarketEvent {
 	var events []*core.MarketEvent
+	var programStack []string
+	myProgram := ei.programID.ToBase58()
 
 	for _, log := range logs {
-		// Look for program log messages that contain event data
-		// Anchor programs emit logs like: "Program log: EVENT_NAME {json_data}"
-		if len(log) > 12 && log[:12] == "Program log:" {
-			eventLog := log[13:] // Remove "Program log: "
-			
-			// Try to parse as event
-			if event := ei.parseEventLog(eventLog, signature, slot); event != nil {
+		switch {
+		case strings.HasPrefix(log, "Program ") && strings.Contains(log, " invoke ["):
+			if fields := strings.Fields(log); len(fields) >= 2 {
+				programStack = append(programStack, fields[1])
+			}
+		case strings.HasPrefix(log, "Program ") && (strings.HasSuffix(log, " success") || strings.HasSuffix(log, " failed")):
+			if len(programStack) > 0 {
+				programStack = programStack[:len(programStack)-1]
+			}
+		case ei.eventDecoder != nil && strings.HasPrefix(log, "Program data: "):
+			if len(programStack) == 0 || programStack[len(programStack)-1] != myProgram {
+				continue // emitted by a different program's CPI, not ours
+			}
+			if event := ei.parseAnchorEventData(strings.TrimPrefix(log, "Program data: "), signature, slot); event != nil {
+				events = append(events, event)
+			}
+		case ei.eventDecoder == nil && len(log) > 12 && log[:12] == "Program log:":
+			if event := ei.parseEventLog(log[13:], signature, slot); event != nil {
 				events = append(events, event)
 			}
 		}
@@ -375,6 +842,36 @@ func (ei *EventIndexer) parseTransactionLogs(logs []string, signature string, sl
 	return events
 }
 
+// parseAnchorEventData base64-decodes and Borsh-decodes an Anchor "Program data:"
+// event payload via ei.eventDecoder, producing a MarketEvent with actual on-chain
+// typed fields instead of a best-effort JSON blob.
+func (ei *EventIndexer) parseAnchorEventData(base64Payload, signature string, slot uint64) *core.MarketEvent {
+	raw, err := base64.StdEncoding.DecodeString(base64Payload)
+	if err != nil {
+		ei.logger.Warn("failed to base64-decode Program data log", "error", err, "signature", signature)
+		return nil
+	}
+
+	name, fields, err := ei.eventDecoder.Decode(raw)
+	if err != nil {
+		ei.logger.Warn("failed to decode anchor event", "error", err, "signature", signature)
+		return nil
+	}
+
+	event := &core.MarketEvent{
+		TxSignature: signature,
+		EventType:   name,
+		Data:        fields,
+		Slot:        slot,
+		BlockTime:   time.Now(), // Would get from transaction
+	}
+	if marketID, ok := fields["market"].(string); ok {
+		event.MarketID = marketID
+	}
+
+	return event
+}
+
 // parseEventLog parses a single event log
 func (ei *EventIndexer) parseEventLog(eventLog, signature string, slot uint64) *core.MarketEvent {
 	// Look for known event patterns
@@ -427,36 +924,87 @@ func (ei *EventIndexer) parseAccountData(dataB64, pubkey string, slot uint64) ([
 	return []*core.MarketEvent{}, nil
 }
 
-// processEvent processes a parsed market event
-func (ei *EventIndexer) processEvent(ctx context.Context, event *core.MarketEvent) error {
-	// Store event in database
-	eventLog := &store.EventLog{
+// ingestPendingEvent records a parsed event into pending_events at the commitment the
+// WebSocket/RPC path observed it at (typically "processed"), tagged with the blockhash
+// of its slot. It is NOT applied to application state yet: runReorgReconciler promotes
+// it to EventLog, via applyConfirmedEvent, once that slot is confirmed finalized on the
+// canonical chain, or orphans it if the slot was rolled back first.
+func (ei *EventIndexer) ingestPendingEvent(ctx context.Context, event *core.MarketEvent) error {
+	blockhash, err := ei.blockhashAtSlot(ctx, event.Slot)
+	if err != nil {
+		return fmt.Errorf("failed to fetch blockhash for pending event: %w", err)
+	}
+
+	dataJSON, err := json.Marshal(event.Data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event data: %w", err)
+	}
+
+	pending := &store.PendingEvent{
 		TxSignature: event.TxSignature,
 		EventType:   event.EventType,
 		MarketID:    &event.MarketID,
+		Data:        string(dataJSON),
 		Slot:        event.Slot,
+		Blockhash:   blockhash,
 		BlockTime:   event.BlockTime,
+		Status:      store.PendingEventStatusPending,
+	}
+	if err := ei.repo.CreatePendingEvent(pending); err != nil {
+		return fmt.Errorf("failed to store pending event: %w", err)
 	}
 
-	// Serialize event data
-	dataJSON, err := json.Marshal(event.Data)
-	if err != nil {
-		return fmt.Errorf("failed to marshal event data: %w", err)
+	ei.logger.DebugContext(ctx, "ingested pending event", "event", event, "slot", event.Slot)
+	return nil
+}
+
+// applyConfirmedEvent stores a promoted event in EventLog, pushes it to the live filter
+// registry, and runs it through the use-case layer. Only events that runReorgReconciler
+// has confirmed are finalized on the canonical chain reach this point.
+func (ei *EventIndexer) applyConfirmedEvent(ctx context.Context, pending *store.PendingEvent) error {
+	eventLog := &store.EventLog{
+		TxSignature: pending.TxSignature,
+		EventType:   pending.EventType,
+		MarketID:    pending.MarketID,
+		Data:        pending.Data,
+		Slot:        pending.Slot,
+		BlockTime:   pending.BlockTime,
 	}
-	eventLog.Data = string(dataJSON)
 
-	// Store in database
-	if err := ei.repo.CreateEventLog(eventLog); err != nil {
-		return fmt.Errorf("failed to store event log: %w", err)
+	if err := ei.repo.PromotePendingEvent(pending, eventLog); err != nil {
+		return fmt.Errorf("failed to promote pending event: %w", err)
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(pending.Data), &data); err != nil {
+		ei.logger.ErrorContext(ctx, "failed to unmarshal promoted event data", "error", err, "event_type", pending.EventType)
+		data = map[string]interface{}{}
+	}
+	marketID := ""
+	if pending.MarketID != nil {
+		marketID = *pending.MarketID
+	}
+	ctx = logger.WithMarketID(ctx, marketID)
+	event := &core.MarketEvent{
+		TxSignature: pending.TxSignature,
+		EventType:   pending.EventType,
+		MarketID:    marketID,
+		Data:        data,
+		Slot:        pending.Slot,
+		BlockTime:   pending.BlockTime,
+		Seq:         uint64(eventLog.ID),
+	}
+
+	if ei.filters != nil {
+		ei.filters.Ingest(*eventLog, event)
 	}
 
-	// Process event through use cases
 	if err := ei.useCases.ProcessMarketEvent(ctx, event); err != nil {
-		ei.logger.Error("failed to process market event", "error", err, "event_type", event.EventType)
-		// Don't return error as we still want to continue processing
+		ei.logger.ErrorContext(ctx, "failed to process market event", "error", err, "event_type", event.EventType)
+		// Don't return error as we still want to continue reconciling other events
 	}
 
-	ei.logger.Debug("processed event", "type", event.EventType, "market_id", event.MarketID, "slot", event.Slot)
+	ei.logger.DebugContext(ctx, "confirmed and applied event", "event", event)
 
 	return nil
 }
@@ -477,11 +1025,29 @@ func (ei *EventIndexer) Health(ctx context.Context) error {
 	return nil
 }
 
+// Lag reports how far behind the chain tip the indexer's checkpoint is, in slots
+func (ei *EventIndexer) Lag(ctx context.Context) (uint64, error) {
+	state, err := ei.repo.GetSyncState(ei.syncProgram())
+	if err != nil {
+		return 0, fmt.Errorf("failed to get sync state: %w", err)
+	}
+
+	currentSlot, err := ei.rpcClient.GetSlot(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get current slot: %w", err)
+	}
+
+	if currentSlot < state.LastProcessedSlot {
+		return 0, nil
+	}
+	return currentSlot - state.LastProcessedSlot, nil
+}
+
 // GetIndexingStatus returns the current indexing status
 func (ei *EventIndexer) GetIndexingStatus(ctx context.Context) (map[string]interface{}, error) {
-	latestProcessedSlot, err := ei.repo.GetLatestProcessedSlot()
+	state, err := ei.repo.GetSyncState(ei.syncProgram())
 	if err != nil {
-		return nil, fmt.Errorf("failed to get latest processed slot: %w", err)
+		return nil, fmt.Errorf("failed to get sync state: %w", err)
 	}
 
 	currentSlot, err := ei.rpcClient.GetSlot(ctx)
@@ -489,10 +1055,77 @@ func (ei *EventIndexer) GetIndexingStatus(ctx context.Context) (map[string]inter
 		return nil, fmt.Errorf("failed to get current slot: %w", err)
 	}
 
+	lag, err := ei.Lag(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	progress, err := ei.repo.GetBackfillProgress(ei.syncProgram())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get backfill progress: %w", err)
+	}
+	recentChunks, err := ei.repo.GetRecentCompletedBackfillChunks(ei.syncProgram(), 20)
+	if err != nil {
+		ei.logger.Warn("failed to load recent backfill chunks for rate estimate", "error", err)
+	}
+	slotsPerSec, eta := estimateBackfillRate(ei.backfillChunkSize(), progress, recentChunks)
+
+	pendingCounts, err := ei.repo.GetPendingEventCounts()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending event counts: %w", err)
+	}
+	confirmedCount, err := ei.repo.CountEventLogs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to count confirmed events: %w", err)
+	}
+
 	return map[string]interface{}{
-		"latest_processed_slot": latestProcessedSlot,
-		"current_slot":          currentSlot,
-		"slots_behind":          currentSlot - latestProcessedSlot,
-		"websocket_connected":   ei.wsClient != nil,
+		"latest_processed_slot":     state.LastProcessedSlot,
+		"last_processed_tx":         state.LastProcessedSignature,
+		"is_finalized":              state.IsFinalized,
+		"current_slot":              currentSlot,
+		"slots_behind":              lag,
+		"websocket_connected":       ei.wsClient != nil,
+		"backfill_chunks_pending":   progress.Pending,
+		"backfill_chunks_in_flight": progress.InProgress,
+		"backfill_chunks_done":      progress.Done,
+		"backfill_chunks_failed":    progress.Failed,
+		"backfill_slots_per_sec":    slotsPerSec,
+		"backfill_eta_sec":          eta.Seconds(),
+		"pending_events":            pendingCounts.Pending,
+		"orphaned_events":           pendingCounts.Orphaned,
+		"confirmed_events":          confirmedCount,
 	}, nil
+}
+
+// estimateBackfillRate derives a slots/sec throughput from the most recently finished
+// chunks' wall-clock duration, and projects an ETA for the chunks still pending, in
+// flight, or awaiting retry at that rate. Returns 0, 0 if there's no completed chunk
+// history yet or nothing left to do.
+func estimateBackfillRate(chunkSize uint64, progress *store.BackfillProgress, recent []store.BackfillChunk) (float64, time.Duration) {
+	remaining := progress.Pending + progress.InProgress + progress.Failed
+	if remaining == 0 || len(recent) == 0 {
+		return 0, 0
+	}
+
+	var totalSlots uint64
+	var totalDuration time.Duration
+	for _, c := range recent {
+		if c.StartedAt == nil || c.FinishedAt == nil {
+			continue
+		}
+		totalSlots += c.EndSlot - c.StartSlot
+		totalDuration += c.FinishedAt.Sub(*c.StartedAt)
+	}
+	if totalDuration <= 0 {
+		return 0, 0
+	}
+
+	slotsPerSec := float64(totalSlots) / totalDuration.Seconds()
+	if slotsPerSec <= 0 {
+		return 0, 0
+	}
+
+	etaSeconds := float64(remaining) * float64(chunkSize) / slotsPerSec
+	return slotsPerSec, time.Duration(etaSeconds * float64(time.Second))
 }
\ No newline at end of file
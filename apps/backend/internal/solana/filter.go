@@ -0,0 +1,316 @@
+package solana
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/friend-bets/backend/internal/core"
+	"github.com/friend-bets/backend/internal/store"
+)
+
+// filterTTL is how long a filter that hasn't been polled via GetFilterChanges is kept
+// before GC removes it, mirroring eth_newFilter's expiry.
+const filterTTL = 10 * time.Minute
+
+// liveBufferSize bounds how many matched events a filter's live stream holds before
+// backpressure kicks in, so one slow WatchFilteredEvents client can't stall ingestion.
+const liveBufferSize = 256
+
+// FilterSpec describes which MarketEvents a filter matches, modeled on Ethereum's
+// eth_newFilter: a populated field narrows the match, a zero-value field matches
+// anything for that dimension. Creator matches the event's market's creator (this
+// domain model has no separate resolver identity; the creator resolves their own
+// markets, see ValidateResolveMarket).
+type FilterSpec struct {
+	EventTypes []string
+	MarketIDs  []string
+	Creator    string
+	FromSlot   uint64
+	ToSlot     uint64 // 0 means unbounded
+	FromTime   time.Time
+	ToTime     time.Time // zero means unbounded
+}
+
+// Matches reports whether event, whose market's creator is creator, satisfies spec.
+// Pass an empty creator when the caller hasn't looked one up yet and spec.Creator is
+// empty (the check is then trivially skipped).
+func (f FilterSpec) Matches(event *core.MarketEvent, creator string) bool {
+	if len(f.EventTypes) > 0 && !containsString(f.EventTypes, event.EventType) {
+		return false
+	}
+	if len(f.MarketIDs) > 0 && !containsString(f.MarketIDs, event.MarketID) {
+		return false
+	}
+	if f.Creator != "" && f.Creator != creator {
+		return false
+	}
+	if f.FromSlot > 0 && event.Slot < f.FromSlot {
+		return false
+	}
+	if f.ToSlot > 0 && event.Slot > f.ToSlot {
+		return false
+	}
+	if !f.FromTime.IsZero() && event.BlockTime.Before(f.FromTime) {
+		return false
+	}
+	if !f.ToTime.IsZero() && event.BlockTime.After(f.ToTime) {
+		return false
+	}
+	return true
+}
+
+func containsString(list []string, v string) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
+
+// registeredFilter is one filter's server-side state: its spec, its GetFilterChanges
+// cursor, and (if a WatchFilteredEvents stream has it open) its live channel.
+type registeredFilter struct {
+	spec       FilterSpec
+	lastPolled time.Time
+	live       chan store.EventLog
+
+	mu     sync.Mutex
+	cursor uint // last EventLog.ID delivered via GetFilterChanges
+}
+
+// FilterRegistry implements the server side of the eth_getLogs-style filter API:
+// CreateFilter/GetFilterChanges provide poll-based incremental delivery backed by
+// persisted EventLog rows (so it works the same whether the event was indexed a
+// second or a month ago), while Ingest/Watch provide live push delivery to any filter
+// with an open WatchFilteredEvents stream. Live delivery only sees events ingested by
+// the EventIndexer instance sharing this registry; GetFilterChanges and GetLogs query
+// the database directly and work from any process, including one that never runs an
+// indexer itself.
+type FilterRegistry struct {
+	repo *store.Repository
+
+	mu      sync.Mutex
+	filters map[string]*registeredFilter
+	nextID  uint64
+}
+
+// NewFilterRegistry creates an empty FilterRegistry backed by repo.
+func NewFilterRegistry(repo *store.Repository) *FilterRegistry {
+	return &FilterRegistry{repo: repo, filters: make(map[string]*registeredFilter)}
+}
+
+// CreateFilter registers spec and returns its filter ID.
+func (r *FilterRegistry) CreateFilter(spec FilterSpec) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	id := fmt.Sprintf("filter_%d", r.nextID)
+	r.filters[id] = &registeredFilter{
+		spec:       spec,
+		lastPolled: time.Now(),
+		live:       make(chan store.EventLog, liveBufferSize),
+	}
+	return id
+}
+
+// UninstallFilter removes a filter and closes its live channel, mirroring
+// eth_uninstallFilter.
+func (r *FilterRegistry) UninstallFilter(id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f, ok := r.filters[id]
+	if !ok {
+		return false
+	}
+	close(f.live)
+	delete(r.filters, id)
+	return true
+}
+
+// GetFilterChanges returns every EventLog row matching id's filter added since the
+// last call (or since creation, for the first call), advancing the filter's cursor
+// past the last row returned.
+func (r *FilterRegistry) GetFilterChanges(id string) ([]store.EventLog, error) {
+	r.mu.Lock()
+	f, ok := r.filters[id]
+	r.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown filter %q", id)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lastPolled = time.Now()
+
+	events, err := r.repo.GetEventLogsFiltered(store.EventLogFilter{
+		EventTypes: f.spec.EventTypes,
+		MarketIDs:  f.spec.MarketIDs,
+		FromSlot:   f.spec.FromSlot,
+		ToSlot:     f.spec.ToSlot,
+		FromTime:   f.spec.FromTime,
+		ToTime:     f.spec.ToTime,
+		AfterID:    f.cursor,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	matched, maxID := r.applyCreatorFilter(events, f.spec.Creator)
+	if maxID > f.cursor {
+		f.cursor = maxID
+	}
+	return matched, nil
+}
+
+// GetLogs returns every persisted EventLog row matching spec, with no cursor
+// tracking — for one-shot historical queries (eth_getLogs), unlike GetFilterChanges's
+// incremental polling against a registered filter.
+func (r *FilterRegistry) GetLogs(spec FilterSpec) ([]store.EventLog, error) {
+	events, err := r.repo.GetEventLogsFiltered(store.EventLogFilter{
+		EventTypes: spec.EventTypes,
+		MarketIDs:  spec.MarketIDs,
+		FromSlot:   spec.FromSlot,
+		ToSlot:     spec.ToSlot,
+		FromTime:   spec.FromTime,
+		ToTime:     spec.ToTime,
+	})
+	if err != nil {
+		return nil, err
+	}
+	matched, _ := r.applyCreatorFilter(events, spec.Creator)
+	return matched, nil
+}
+
+// GetEventsSince returns up to limit persisted EventLog rows with ID greater than
+// seq, ordered by ID ascending — the catch-up query WatchEvents runs before handing
+// a resuming client off to the live notify hub.
+func (r *FilterRegistry) GetEventsSince(seq uint64, limit int) ([]store.EventLog, error) {
+	return r.repo.GetEventsSince(uint(seq), limit)
+}
+
+// applyCreatorFilter narrows events to those whose market's creator matches creator
+// (a no-op when creator is empty), and returns the highest EventLog.ID seen among the
+// unfiltered events so a cursor can advance past rows that didn't match creator too.
+func (r *FilterRegistry) applyCreatorFilter(events []store.EventLog, creator string) ([]store.EventLog, uint) {
+	var maxID uint
+	if creator == "" {
+		if len(events) > 0 {
+			maxID = events[len(events)-1].ID
+		}
+		return events, maxID
+	}
+
+	matched := make([]store.EventLog, 0, len(events))
+	for _, e := range events {
+		if e.ID > maxID {
+			maxID = e.ID
+		}
+		if e.MarketID == nil {
+			continue
+		}
+		market, err := r.repo.GetMarket(*e.MarketID)
+		if err != nil || market.Creator != creator {
+			continue
+		}
+		matched = append(matched, e)
+	}
+	return matched, maxID
+}
+
+// Ingest pushes log to every registered filter whose spec matches event, for any
+// filter with an open WatchFilteredEvents stream. A filter whose live channel is full
+// drops the oldest buffered event rather than blocking ingestion, trading a slow
+// client's completeness for the indexer's liveness.
+func (r *FilterRegistry) Ingest(log store.EventLog, event *core.MarketEvent) {
+	r.mu.Lock()
+	filters := make([]*registeredFilter, 0, len(r.filters))
+	for _, f := range r.filters {
+		filters = append(filters, f)
+	}
+	r.mu.Unlock()
+	if len(filters) == 0 {
+		return
+	}
+
+	var creator string
+	var creatorLoaded bool
+
+	for _, f := range filters {
+		if f.spec.Creator != "" && !creatorLoaded {
+			if market, err := r.repo.GetMarket(event.MarketID); err == nil {
+				creator = market.Creator
+			}
+			creatorLoaded = true
+		}
+		if !f.spec.Matches(event, creator) {
+			continue
+		}
+
+		select {
+		case f.live <- log:
+		default:
+			select {
+			case <-f.live:
+			default:
+			}
+			select {
+			case f.live <- log:
+			default:
+			}
+		}
+	}
+}
+
+// Watch returns id's live channel of matched events, or false if no such filter
+// exists.
+func (r *FilterRegistry) Watch(id string) (<-chan store.EventLog, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f, ok := r.filters[id]
+	if !ok {
+		return nil, false
+	}
+	return f.live, true
+}
+
+// GC removes filters that haven't been polled via GetFilterChanges within filterTTL.
+// A filter created solely for WatchFilteredEvents (never polled) is reclaimed once
+// its TTL lapses same as any other; callers that only stream should still call
+// GetFilterChanges occasionally, or rely on UninstallFilter when their stream ends.
+func (r *FilterRegistry) GC() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cutoff := time.Now().Add(-filterTTL)
+	for id, f := range r.filters {
+		f.mu.Lock()
+		stale := f.lastPolled.Before(cutoff)
+		f.mu.Unlock()
+		if stale {
+			close(f.live)
+			delete(r.filters, id)
+		}
+	}
+}
+
+// StartGC runs GC on a fixed interval until ctx is cancelled.
+func (r *FilterRegistry) StartGC(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				r.GC()
+			}
+		}
+	}()
+}
@@ -0,0 +1,59 @@
+package solana
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// GeyserTransactionStream is the minimal subset of a Yellowstone/Geyser gRPC streaming
+// client GeyserEventSource needs: a bidirectional stream that, once a program-scoped
+// subscribe request with a commitment level has been sent, yields decoded transaction
+// updates with far lower latency than RPC+WebSocket polling and no JSON log
+// reparsing. A real implementation wraps the client generated from a Geyser plugin's
+// proto definitions (e.g. Triton's yellowstone-grpc); GeyserEventSource depends only
+// on this interface so that generated client doesn't have to be vendored here.
+type GeyserTransactionStream interface {
+	// Recv blocks for the next transaction update matching the subscription, or
+	// returns an error (including context cancellation) once the stream ends.
+	Recv(ctx context.Context) (signature string, slot uint64, blockTime time.Time, logs []string, err error)
+}
+
+// GeyserEventSource adapts a GeyserTransactionStream into an EventSource, translating
+// each received update into a RawEvent for EventIndexer's usual decode/dedup/ingest
+// path. It runs alongside the built-in RPC+WebSocket path rather than replacing it, so
+// losing the Geyser connection just falls back to the slower path without downtime.
+type GeyserEventSource struct {
+	stream GeyserTransactionStream
+	logger *slog.Logger
+}
+
+// NewGeyserEventSource wraps an already-connected, already-subscribed
+// GeyserTransactionStream; see that type's doc comment for how to obtain one.
+func NewGeyserEventSource(stream GeyserTransactionStream, logger *slog.Logger) *GeyserEventSource {
+	return &GeyserEventSource{stream: stream, logger: logger}
+}
+
+// Subscribe implements EventSource by repeatedly draining g.stream onto a channel of
+// RawEvents until ctx is cancelled or the stream ends for good.
+func (g *GeyserEventSource) Subscribe(ctx context.Context) (<-chan RawEvent, error) {
+	ch := make(chan RawEvent, 256)
+	go func() {
+		defer close(ch)
+		for {
+			signature, slot, blockTime, logs, err := g.stream.Recv(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					g.logger.Error("geyser stream ended", "error", err)
+				}
+				return
+			}
+			select {
+			case ch <- RawEvent{Signature: signature, Slot: slot, BlockTime: blockTime, Logs: logs}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
@@ -0,0 +1,129 @@
+package solana
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+
+	"github.com/friend-bets/backend/internal/solana/borsh"
+)
+
+// IDL is the subset of an Anchor program's IDL JSON this package needs to decode
+// events: the event list (name + ordered, typed fields) and any named struct types
+// those fields reference via {"defined": "Name"}.
+type IDL struct {
+	Events []IDLEvent   `json:"events"`
+	Types  []IDLTypeDef `json:"types"`
+}
+
+// IDLEvent describes one Anchor event's name and field layout.
+type IDLEvent struct {
+	Name   string     `json:"name"`
+	Fields []IDLField `json:"fields"`
+}
+
+// IDLField describes one field's name and Borsh type. Type is left as interface{}
+// because Anchor's IDL schema represents it as either a bare string ("u64",
+// "publicKey", ...) or an object ({"vec": ...}, {"option": ...}, {"defined": "Name"}).
+type IDLField struct {
+	Name string      `json:"name"`
+	Type interface{} `json:"type"`
+}
+
+// IDLTypeDef is a named struct type referenced by event fields via {"defined": name}.
+type IDLTypeDef struct {
+	Name string         `json:"name"`
+	Type IDLTypeDefBody `json:"type"`
+}
+
+// IDLTypeDefBody is the body of a named type definition; only "struct" kinds are
+// supported, matching the event payloads this decoder targets.
+type IDLTypeDefBody struct {
+	Kind   string     `json:"kind"`
+	Fields []IDLField `json:"fields"`
+}
+
+// LoadIDL parses an Anchor IDL JSON file's bytes.
+func LoadIDL(data []byte) (*IDL, error) {
+	var idl IDL
+	if err := json.Unmarshal(data, &idl); err != nil {
+		return nil, fmt.Errorf("failed to parse IDL JSON: %w", err)
+	}
+	return &idl, nil
+}
+
+// eventDiscriminator returns the 8-byte discriminator Anchor prefixes an event's
+// Borsh-encoded payload with: the first 8 bytes of sha256("event:<Name>").
+func eventDiscriminator(name string) [8]byte {
+	sum := sha256.Sum256([]byte("event:" + name))
+	var d [8]byte
+	copy(d[:], sum[:8])
+	return d
+}
+
+// instructionDiscriminator returns the 8-byte discriminator Anchor prefixes an
+// instruction's Borsh-encoded data with: the first 8 bytes of
+// sha256("global:<method>"), where method is the instruction's snake_case name
+// as declared in the program's IDL. Used by anchor_client.go's encode*Instruction
+// functions instead of hardcoded byte arrays, so they stay correct across an
+// IDL regeneration without anyone needing to recompute and paste new bytes.
+func instructionDiscriminator(method string) [8]byte {
+	return borsh.Discriminator("global", method)
+}
+
+// EventDecoder decodes Anchor "Program data:" event payloads into typed field maps,
+// using discriminators and field layouts precomputed from an IDL.
+type EventDecoder struct {
+	eventsByName map[string]IDLEvent
+	eventsByDisc map[[8]byte]string
+	typesByName  map[string]IDLTypeDef
+}
+
+// NewEventDecoder precomputes every event's discriminator and indexes the IDL's named
+// types, so Decode can look both up in O(1).
+func NewEventDecoder(idl *IDL) (*EventDecoder, error) {
+	d := &EventDecoder{
+		eventsByName: make(map[string]IDLEvent, len(idl.Events)),
+		eventsByDisc: make(map[[8]byte]string, len(idl.Events)),
+		typesByName:  make(map[string]IDLTypeDef, len(idl.Types)),
+	}
+
+	for _, t := range idl.Types {
+		d.typesByName[t.Name] = t
+	}
+
+	for _, ev := range idl.Events {
+		disc := eventDiscriminator(ev.Name)
+		if existing, ok := d.eventsByDisc[disc]; ok {
+			return nil, fmt.Errorf("discriminator collision between events %q and %q", existing, ev.Name)
+		}
+		d.eventsByName[ev.Name] = ev
+		d.eventsByDisc[disc] = ev.Name
+	}
+
+	return d, nil
+}
+
+// Decode identifies and decodes an Anchor event payload (the raw bytes of a
+// "Program data:" log, after base64-decoding): the first 8 bytes select the event by
+// discriminator, and the rest is Borsh-decoded per that event's IDL field layout.
+func (d *EventDecoder) Decode(data []byte) (eventName string, fields map[string]interface{}, err error) {
+	if len(data) < 8 {
+		return "", nil, fmt.Errorf("event payload too short for a discriminator: %d bytes", len(data))
+	}
+
+	var disc [8]byte
+	copy(disc[:], data[:8])
+
+	name, ok := d.eventsByDisc[disc]
+	if !ok {
+		return "", nil, fmt.Errorf("no IDL event matches discriminator %x", disc)
+	}
+
+	fields, _, err = decodeStructFields(d.eventsByName[name].Fields, data[8:], d.typesByName)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to decode event %q: %w", name, err)
+	}
+
+	return name, fields, nil
+}
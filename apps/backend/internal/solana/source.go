@@ -0,0 +1,66 @@
+package solana
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RawEvent is one transaction's worth of on-chain program logs, observed by whichever
+// EventSource produced it (the built-in RPC+WebSocket path, GeyserEventSource, or
+// WebhookEventSource). EventIndexer decodes it the same way via parseTransactionLogs
+// regardless of origin, so every backend benefits from the same parsing, dedup, and
+// pending/confirmed lifecycle.
+type RawEvent struct {
+	Signature string
+	Slot      uint64
+	BlockTime time.Time
+	Logs      []string
+}
+
+// EventSource is a pluggable, additional origin of on-chain transactions for
+// EventIndexer to decode and ingest alongside its own built-in RPC+WebSocket polling
+// (see processRealtimeEvents/processHistoricalEvents). Every source's output converges
+// on the same dedup'd ingestRawEvent path, so multiple sources can run concurrently and
+// fail over between each other without double-processing a transaction.
+type EventSource interface {
+	// Subscribe streams newly observed transactions as they arrive. The returned
+	// channel is closed when ctx is cancelled or the source's connection is lost for
+	// good; a source that can reconnect does so internally rather than closing.
+	Subscribe(ctx context.Context) (<-chan RawEvent, error)
+}
+
+// eventDedup suppresses re-ingesting a (signature, event index within that
+// transaction) pair already seen from another concurrently-running EventSource.
+type eventDedup struct {
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+// newEventDedup creates an empty dedup cache.
+func newEventDedup() *eventDedup {
+	return &eventDedup{seen: make(map[string]time.Time)}
+}
+
+// Seen reports whether key was already recorded, recording it if not.
+func (d *eventDedup) Seen(key string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.seen[key]; ok {
+		return true
+	}
+	d.seen[key] = time.Now()
+	return false
+}
+
+// GC drops entries older than ttl, bounding memory for a long-running indexer.
+func (d *eventDedup) GC(ttl time.Duration) {
+	cutoff := time.Now().Add(-ttl)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for k, t := range d.seen {
+		if t.Before(cutoff) {
+			delete(d.seen, k)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package solana
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/friend-bets/backend/internal/store"
+)
+
+// webhookTransaction is one pushed transaction notification. Helius-style "raw"
+// webhooks forward the transaction largely as RPC's getTransaction would encode it, so
+// the fields line up with what parseTransactionLogs already expects.
+type webhookTransaction struct {
+	Signature   string   `json:"signature"`
+	Slot        uint64   `json:"slot"`
+	Timestamp   int64    `json:"timestamp"` // unix seconds
+	LogMessages []string `json:"logMessages"`
+}
+
+// WebhookReceiver accepts pushed transaction notifications (Helius-style) over HTTP,
+// authenticated by an HMAC-SHA256 signature over the raw request body, and hands each
+// one to the DB-backed raw event queue for whichever worker process's EventIndexer is
+// running to decode — this HTTP handler runs inside internal/grpc.Server, a different
+// process than EventIndexer, so it can't ingest directly (see RawEventQueue).
+type WebhookReceiver struct {
+	repo       *store.Repository
+	hmacSecret string
+	logger     *slog.Logger
+}
+
+// NewWebhookReceiver creates a receiver. hmacSecret must be non-empty; callers decide
+// whether to mount Handler at all based on config.
+func NewWebhookReceiver(repo *store.Repository, hmacSecret string, logger *slog.Logger) *WebhookReceiver {
+	return &WebhookReceiver{repo: repo, hmacSecret: hmacSecret, logger: logger}
+}
+
+// Handler returns the http.HandlerFunc to mount at the configured webhook path. It
+// verifies the "X-Signature" header (hex-encoded HMAC-SHA256 of the raw body) before
+// parsing, and enqueues every transaction in the payload onto the raw event queue.
+func (w *WebhookReceiver) Handler() http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(io.LimitReader(r.Body, 10<<20)) // 10MB cap
+		if err != nil {
+			http.Error(rw, "failed to read body", http.StatusBadRequest)
+			return
+		}
+
+		if !w.verifySignature(r.Header.Get("X-Signature"), body) {
+			http.Error(rw, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+
+		var txs []webhookTransaction
+		if err := json.Unmarshal(body, &txs); err != nil {
+			http.Error(rw, "invalid payload", http.StatusBadRequest)
+			return
+		}
+
+		for _, tx := range txs {
+			logsJSON, err := json.Marshal(tx.LogMessages)
+			if err != nil {
+				w.logger.Error("failed to marshal webhook tx logs", "error", err, "signature", tx.Signature)
+				continue
+			}
+			event := &store.RawEventQueue{
+				Source:    "webhook",
+				Signature: tx.Signature,
+				Slot:      tx.Slot,
+				BlockTime: time.Unix(tx.Timestamp, 0),
+				Logs:      string(logsJSON),
+				Status:    store.RawEventQueueStatusPending,
+			}
+			if err := w.repo.EnqueueRawEvent(event); err != nil {
+				w.logger.Error("failed to enqueue webhook tx", "error", err, "signature", tx.Signature)
+			}
+		}
+
+		rw.WriteHeader(http.StatusOK)
+	}
+}
+
+// verifySignature checks sigHeader (hex-encoded) against the HMAC-SHA256 of body using
+// a constant-time comparison.
+func (w *WebhookReceiver) verifySignature(sigHeader string, body []byte) bool {
+	if sigHeader == "" {
+		return false
+	}
+	sig, err := hex.DecodeString(sigHeader)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(w.hmacSecret))
+	mac.Write(body)
+	return hmac.Equal(sig, mac.Sum(nil))
+}
@@ -3,21 +3,76 @@ package store
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"log/slog"
+	"sort"
 	"time"
+
+	"github.com/shopspring/decimal"
 )
 
-// Odds represents betting odds
+// Odds represents betting odds as fixed-point decimals, so displayed values are
+// identical across clients regardless of floating-point rounding
 type Odds struct {
-	A float64 `json:"a"`
-	B float64 `json:"b"`
+	A Decimal `json:"a"`
+	B Decimal `json:"b"`
+}
+
+// oneOdds is the even-odds value (1:1) used when a market has no liquidity on a side
+var oneOdds = decimal.NewFromInt(1)
+
+// calculateOdds computes Odds as totalStaked/stakedSide in fixed-point, mirroring
+// core.Market.CalculateOdds's convention of treating an empty side as even odds rather
+// than dividing by zero.
+func calculateOdds(stakedA, stakedB uint64) Odds {
+	if stakedA == 0 && stakedB == 0 {
+		return Odds{A: oneOdds, B: oneOdds}
+	}
+
+	totalStaked := DecimalFromLamports(stakedA + stakedB)
+
+	odds := Odds{A: decimal.Zero, B: decimal.Zero}
+	if stakedA > 0 {
+		odds.A = totalStaked.Div(DecimalFromLamports(stakedA))
+	}
+	if stakedB > 0 {
+		odds.B = totalStaked.Div(DecimalFromLamports(stakedB))
+	}
+
+	return odds
 }
 
 // Analytics provides analytics and metrics functionality
 type Analytics struct {
 	repo   *Repository
 	logger *slog.Logger
+
+	// metrics is nil until RegisterMetrics is called, at which point gauges/counters
+	// start being updated from ProcessDailyRollup, GetPlatformOverview, and the
+	// position-write path
+	metrics *analyticsMetrics
+
+	// rolling is nil until UseRollingAccumulator is called, at which point
+	// GetPlatformOverview and the Prometheus collector read recent-activity counters
+	// from it instead of re-scanning the SQL store
+	rolling *RollingAccumulator
+}
+
+// UseRollingAccumulator wires a RollingAccumulator into Analytics. Call once at boot,
+// after the accumulator has been backfilled.
+func (a *Analytics) UseRollingAccumulator(ra *RollingAccumulator) {
+	a.rolling = ra
+}
+
+// RecordPosition folds a newly ingested position into the rolling window, if one has
+// been wired in via UseRollingAccumulator. Called from the indexer's position-write
+// path as bets land on-chain.
+func (a *Analytics) RecordPosition(ctx context.Context, pos *PositionView) error {
+	if a.rolling == nil {
+		return nil
+	}
+	return a.rolling.RecordPosition(ctx, pos)
 }
 
 // NewAnalytics creates a new analytics service
@@ -43,7 +98,7 @@ type MarketMetrics struct {
 	SideBVolume        uint64     `json:"side_b_volume"`
 	SideABets          int        `json:"side_a_bets"`
 	SideBBets          int        `json:"side_b_bets"`
-	CurrentOdds        Odds  `json:"current_odds"`
+	CurrentOdds        Odds       `json:"current_odds"`
 	Status             string     `json:"status"`
 	ResolvedAt         *time.Time `json:"resolved_at,omitempty"`
 	Outcome            *string    `json:"outcome,omitempty"`
@@ -58,7 +113,7 @@ type DailyMetrics struct {
 	TotalVolume        uint64    `json:"total_volume"`
 	ActiveUsers        int       `json:"active_users"`
 	NewUsers           int       `json:"new_users"`
-	AvgBetSize         float64   `json:"avg_bet_size"`
+	AvgBetSize         Decimal   `json:"avg_bet_size"`
 	LargestBet         uint64    `json:"largest_bet"`
 	MostActiveMarket   string    `json:"most_active_market"`
 	TopCreatorByVolume string    `json:"top_creator_by_volume"`
@@ -72,9 +127,12 @@ type UserMetrics struct {
 	MarketsCreated int       `json:"markets_created"`
 	WinningBets    int       `json:"winning_bets"`
 	LosingBets     int       `json:"losing_bets"`
-	WinRate        float64   `json:"win_rate"`
-	ProfitLoss     int64     `json:"profit_loss"`
-	AvgBetSize     float64   `json:"avg_bet_size"`
+	WinRate        Decimal   `json:"win_rate"`
+	ProfitLoss     int64     `json:"profit_loss"`   // gross payout minus stake across resolved positions, before protocol fees, in lamports
+	RealizedFees   uint64    `json:"realized_fees"` // this user's proportional share of protocol fees on winning positions, in lamports
+	NetProfit      int64     `json:"net_profit"`    // ProfitLoss minus RealizedFees; what the user actually realized, in lamports
+	ROI            Decimal   `json:"roi"`           // NetProfit as a percentage of stake placed on resolved positions
+	AvgBetSize     Decimal   `json:"avg_bet_size"`
 	LargestBet     uint64    `json:"largest_bet"`
 	FavoredSide    string    `json:"favored_side"` // Which side they bet on more often
 	FirstBetAt     time.Time `json:"first_bet_at"`
@@ -82,6 +140,153 @@ type UserMetrics struct {
 	ActiveDays     int       `json:"active_days"`
 }
 
+// positionPnL is a single resolved position's realized profit/loss, computed by
+// computePositionPnL
+type positionPnL struct {
+	ProfitLoss   int64
+	RealizedFees uint64
+	NetProfit    int64
+}
+
+// computePositionPnL computes a resolved position's realized P&L using the same
+// parimutuel formula core.Market.CalculatePayout applies on-chain: the pool's fee_bps
+// is taken off the total stake before the remainder splits proportionally among
+// winners. Losing positions forfeit their full stake and carry no fee share.
+func computePositionPnL(market *MarketView, pos *PositionView) positionPnL {
+	if market.Outcome == nil || *market.Outcome != pos.Side {
+		return positionPnL{ProfitLoss: -int64(pos.Amount), NetProfit: -int64(pos.Amount)}
+	}
+
+	var winningSideTotal uint64
+	if *market.Outcome == "A" {
+		winningSideTotal = market.StakedA
+	} else {
+		winningSideTotal = market.StakedB
+	}
+	if winningSideTotal == 0 {
+		return positionPnL{ProfitLoss: -int64(pos.Amount), NetProfit: -int64(pos.Amount)}
+	}
+
+	totalStaked := market.StakedA + market.StakedB
+	share := DecimalFromLamports(pos.Amount).Div(DecimalFromLamports(winningSideTotal))
+
+	feeAmount := DecimalFromLamports(totalStaked).
+		Mul(decimal.NewFromInt(int64(market.FeeBps))).
+		Div(decimal.NewFromInt(10000))
+
+	grossPayout := DecimalFromLamports(totalStaked).Mul(share).IntPart()
+	fee := feeAmount.Mul(share).IntPart()
+
+	return positionPnL{
+		ProfitLoss:   grossPayout - int64(pos.Amount),
+		RealizedFees: uint64(fee),
+		NetProfit:    grossPayout - fee - int64(pos.Amount),
+	}
+}
+
+// ComputePositionNetProfit returns a resolved position's net profit (after fees),
+// using the same formula as ComputeRealizedPnL, for callers outside the store package
+// (e.g. core's claim handler, updating UserStatsAggregator) that need a single
+// position's payout without duplicating the parimutuel math.
+func ComputePositionNetProfit(market *MarketView, pos *PositionView) int64 {
+	return computePositionPnL(market, pos).NetProfit
+}
+
+// RealizedPnL aggregates a user's realized profit/loss across all resolved positions
+type RealizedPnL struct {
+	ProfitLoss   int64   `json:"profit_loss"`
+	RealizedFees uint64  `json:"realized_fees"`
+	NetProfit    int64   `json:"net_profit"`
+	ROI          Decimal `json:"roi"`
+}
+
+// ComputeRealizedPnL aggregates a user's realized P&L across all resolved positions
+// via computePositionPnL, and expresses NetProfit as a percentage return (ROI) on the
+// stake placed into those positions.
+func (a *Analytics) ComputeRealizedPnL(ctx context.Context, userID string) (*RealizedPnL, error) {
+	positions, err := a.repo.GetPositionsByUser(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user positions: %w", err)
+	}
+
+	pnl := &RealizedPnL{}
+	var totalResolvedStake uint64
+
+	for i := range positions {
+		pos := &positions[i]
+		market := &pos.Market
+		if market.Status != "resolved" || market.Outcome == nil {
+			continue
+		}
+
+		totalResolvedStake += pos.Amount
+
+		position := computePositionPnL(market, pos)
+		pnl.ProfitLoss += position.ProfitLoss
+		pnl.RealizedFees += position.RealizedFees
+		pnl.NetProfit += position.NetProfit
+	}
+
+	if totalResolvedStake > 0 {
+		pnl.ROI = decimal.NewFromInt(pnl.NetProfit).
+			Div(DecimalFromLamports(totalResolvedStake)).
+			Mul(decimal.NewFromInt(100))
+	}
+
+	return pnl, nil
+}
+
+// PnLBucket is one time bucket of a user's GetUserPnLTimeSeries
+type PnLBucket struct {
+	BucketStart  time.Time `json:"bucket_start"`
+	ProfitLoss   int64     `json:"profit_loss"`
+	RealizedFees uint64    `json:"realized_fees"`
+	NetProfit    int64     `json:"net_profit"`
+	BetsResolved int       `json:"bets_resolved"`
+}
+
+// GetUserPnLTimeSeries buckets a user's realized P&L (from resolved positions) by the
+// bucket duration their bet was placed in, e.g. bucket=24h for daily P&L.
+func (a *Analytics) GetUserPnLTimeSeries(ctx context.Context, userID string, bucket time.Duration) ([]PnLBucket, error) {
+	positions, err := a.repo.GetPositionsByUser(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user positions: %w", err)
+	}
+
+	buckets := make(map[int64]*PnLBucket)
+	for i := range positions {
+		pos := &positions[i]
+		market := &pos.Market
+		if market.Status != "resolved" || market.Outcome == nil {
+			continue
+		}
+
+		position := computePositionPnL(market, pos)
+
+		bucketStart := pos.CreatedAt.Truncate(bucket)
+		key := bucketStart.Unix()
+		b, ok := buckets[key]
+		if !ok {
+			b = &PnLBucket{BucketStart: bucketStart}
+			buckets[key] = b
+		}
+		b.ProfitLoss += position.ProfitLoss
+		b.RealizedFees += position.RealizedFees
+		b.NetProfit += position.NetProfit
+		b.BetsResolved++
+	}
+
+	series := make([]PnLBucket, 0, len(buckets))
+	for _, b := range buckets {
+		series = append(series, *b)
+	}
+	sort.Slice(series, func(i, j int) bool {
+		return series[i].BucketStart.Before(series[j].BucketStart)
+	})
+
+	return series, nil
+}
+
 // FunnelMetrics contains conversion funnel metrics
 type FunnelMetrics struct {
 	Date                 time.Time `json:"date"`
@@ -137,15 +342,7 @@ func (a *Analytics) GetMarketMetrics(ctx context.Context, marketID string) (*Mar
 	metrics.UniqueParticipants = len(uniqueUsers)
 
 	// Calculate current odds
-	if market.StakedA+market.StakedB > 0 {
-		totalStaked := float64(market.StakedA + market.StakedB)
-		metrics.CurrentOdds = Odds{
-			A: totalStaked / float64(market.StakedA),
-			B: totalStaked / float64(market.StakedB),
-		}
-	} else {
-		metrics.CurrentOdds = Odds{A: 1.0, B: 1.0}
-	}
+	metrics.CurrentOdds = calculateOdds(market.StakedA, market.StakedB)
 
 	// Set resolved timestamp if resolved
 	if market.Status == "resolved" {
@@ -189,16 +386,22 @@ func (a *Analytics) GetDailyMetrics(ctx context.Context, date time.Time) (*Daily
 func (a *Analytics) calculateAdditionalDailyMetrics(ctx context.Context, metrics *DailyMetrics, startOfDay, endOfDay time.Time) error {
 	db := a.repo.db.DB
 
-	// Calculate average bet size
-	var avgBetSize sql.NullFloat64
+	// Calculate average bet size. Summed in SQL, then divided as a Decimal rather than
+	// using SQL AVG(), so the average is computed fixed-point end to end.
+	var betStats struct {
+		TotalAmount sql.NullFloat64
+		Count       int64
+	}
 	err := db.Model(&PositionView{}).
 		Where("created_at >= ? AND created_at < ?", startOfDay, endOfDay).
-		Select("AVG(amount)").
-		Scan(&avgBetSize).Error
+		Select("SUM(amount) as total_amount, COUNT(*) as count").
+		Scan(&betStats).Error
 	if err != nil {
 		return fmt.Errorf("failed to calculate avg bet size: %w", err)
 	}
-	metrics.AvgBetSize = avgBetSize.Float64
+	if betStats.Count > 0 && betStats.TotalAmount.Valid {
+		metrics.AvgBetSize = decimal.NewFromFloat(betStats.TotalAmount.Float64).Div(decimal.NewFromInt(betStats.Count))
+	}
 
 	// Calculate largest bet
 	var largestBet uint64
@@ -249,93 +452,154 @@ func (a *Analytics) calculateAdditionalDailyMetrics(ctx context.Context, metrics
 
 // GetUserMetrics retrieves comprehensive metrics for a user
 func (a *Analytics) GetUserMetrics(ctx context.Context, userID string) (*UserMetrics, error) {
-	db := a.repo.db.DB
-
-	metrics := &UserMetrics{
-		UserID: userID,
-	}
-
-	// Get user positions
+	// Positions are fetched with Market preloaded (one extra WHERE id IN (...) query),
+	// so win/loss and P&L below are derived in-memory instead of re-querying the
+	// market per position.
 	positions, err := a.repo.GetPositionsByUser(userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user positions: %w", err)
 	}
 
-	// Count markets created by user
 	var marketsCreated int64
-	err = db.Model(&MarketView{}).Where("creator = ?", userID).Count(&marketsCreated).Error
+	err = a.repo.db.Model(&MarketView{}).Where("creator = ?", userID).Count(&marketsCreated).Error
 	if err != nil {
 		return nil, fmt.Errorf("failed to count markets created: %w", err)
 	}
-	metrics.MarketsCreated = int(marketsCreated)
 
-	// Process positions
-	if len(positions) > 0 {
-		metrics.TotalBets = len(positions)
+	return buildUserMetrics(userID, positions, int(marketsCreated)), nil
+}
 
-		var totalVolume uint64
-		var largestBet uint64
-		sideACounts := 0
-		firstBetTime := positions[0].CreatedAt
-		lastBetTime := positions[0].CreatedAt
-		activeDaysMap := make(map[string]bool)
+// GetBulkUserMetrics computes UserMetrics for many users in a fixed number of SQL
+// statements regardless of len(userIDs): one query for their positions (with Market
+// preloaded in a second, still-bounded query), and one grouped query for markets
+// created. Intended for leaderboards, where per-user round-trips would regress
+// latency badly.
+func (a *Analytics) GetBulkUserMetrics(ctx context.Context, userIDs []string) (map[string]*UserMetrics, error) {
+	if len(userIDs) == 0 {
+		return map[string]*UserMetrics{}, nil
+	}
 
-		for _, pos := range positions {
-			totalVolume += pos.Amount
-			if pos.Amount > largestBet {
-				largestBet = pos.Amount
-			}
+	positions, err := a.repo.GetPositionsByUsers(userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get positions for users: %w", err)
+	}
 
-			if pos.Side == "A" {
-				sideACounts++
-			}
+	marketsCreated, err := a.repo.CountMarketsCreatedByUsers(userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count markets created: %w", err)
+	}
 
-			if pos.CreatedAt.Before(firstBetTime) {
-				firstBetTime = pos.CreatedAt
-			}
-			if pos.CreatedAt.After(lastBetTime) {
-				lastBetTime = pos.CreatedAt
-			}
+	positionsByUser := make(map[string][]PositionView, len(userIDs))
+	for i := range positions {
+		owner := positions[i].Owner
+		positionsByUser[owner] = append(positionsByUser[owner], positions[i])
+	}
 
-			// Track unique active days
-			dayKey := pos.CreatedAt.Format("2006-01-02")
-			activeDaysMap[dayKey] = true
-
-			// Check if position won (need to get market outcome)
-			market, err := a.repo.GetMarket(pos.MarketID)
-			if err == nil && market.Status == "resolved" && market.Outcome != nil {
-				if *market.Outcome == pos.Side {
-					metrics.WinningBets++
-				} else {
-					metrics.LosingBets++
-				}
-			}
+	results := make(map[string]*UserMetrics, len(userIDs))
+	for _, userID := range userIDs {
+		results[userID] = buildUserMetrics(userID, positionsByUser[userID], marketsCreated[userID])
+	}
+
+	return results, nil
+}
+
+// buildUserMetrics aggregates a UserMetrics from a user's already-fetched positions
+// (with Market preloaded) and market-created count, without issuing any further
+// queries — shared by GetUserMetrics and GetBulkUserMetrics.
+func buildUserMetrics(userID string, positions []PositionView, marketsCreated int) *UserMetrics {
+	metrics := &UserMetrics{
+		UserID:         userID,
+		MarketsCreated: marketsCreated,
+	}
+
+	if len(positions) == 0 {
+		return metrics
+	}
+
+	metrics.TotalBets = len(positions)
+
+	var totalVolume uint64
+	var largestBet uint64
+	sideACounts := 0
+	firstBetTime := positions[0].CreatedAt
+	lastBetTime := positions[0].CreatedAt
+	activeDaysMap := make(map[string]bool)
+
+	var pnl RealizedPnL
+	var totalResolvedStake uint64
+
+	for i := range positions {
+		pos := &positions[i]
+		totalVolume += pos.Amount
+		if pos.Amount > largestBet {
+			largestBet = pos.Amount
 		}
 
-		metrics.TotalVolume = totalVolume
-		metrics.LargestBet = largestBet
-		metrics.AvgBetSize = float64(totalVolume) / float64(metrics.TotalBets)
-		metrics.FirstBetAt = firstBetTime
-		metrics.LastBetAt = lastBetTime
-		metrics.ActiveDays = len(activeDaysMap)
-
-		// Calculate win rate
-		totalResolvedBets := metrics.WinningBets + metrics.LosingBets
-		if totalResolvedBets > 0 {
-			metrics.WinRate = float64(metrics.WinningBets) / float64(totalResolvedBets) * 100
+		if pos.Side == "A" {
+			sideACounts++
 		}
 
-		// Determine favored side
-		if sideACounts > len(positions)/2 {
-			metrics.FavoredSide = "A"
-		} else if sideACounts < len(positions)/2 {
-			metrics.FavoredSide = "B"
-		} else {
-			metrics.FavoredSide = "balanced"
+		if pos.CreatedAt.Before(firstBetTime) {
+			firstBetTime = pos.CreatedAt
+		}
+		if pos.CreatedAt.After(lastBetTime) {
+			lastBetTime = pos.CreatedAt
+		}
+
+		// Track unique active days
+		dayKey := pos.CreatedAt.Format("2006-01-02")
+		activeDaysMap[dayKey] = true
+
+		market := &pos.Market
+		if market.Status == "resolved" && market.Outcome != nil {
+			if *market.Outcome == pos.Side {
+				metrics.WinningBets++
+			} else {
+				metrics.LosingBets++
+			}
+
+			totalResolvedStake += pos.Amount
+			position := computePositionPnL(market, pos)
+			pnl.ProfitLoss += position.ProfitLoss
+			pnl.RealizedFees += position.RealizedFees
+			pnl.NetProfit += position.NetProfit
 		}
 	}
 
-	return metrics, nil
+	metrics.TotalVolume = totalVolume
+	metrics.LargestBet = largestBet
+	metrics.AvgBetSize = DecimalFromLamports(totalVolume).Div(decimal.NewFromInt(int64(metrics.TotalBets)))
+	metrics.FirstBetAt = firstBetTime
+	metrics.LastBetAt = lastBetTime
+	metrics.ActiveDays = len(activeDaysMap)
+
+	// Calculate win rate
+	totalResolvedBets := metrics.WinningBets + metrics.LosingBets
+	if totalResolvedBets > 0 {
+		metrics.WinRate = decimal.NewFromInt(int64(metrics.WinningBets)).
+			Div(decimal.NewFromInt(int64(totalResolvedBets))).
+			Mul(decimal.NewFromInt(100))
+	}
+
+	// Determine favored side
+	if sideACounts > len(positions)/2 {
+		metrics.FavoredSide = "A"
+	} else if sideACounts < len(positions)/2 {
+		metrics.FavoredSide = "B"
+	} else {
+		metrics.FavoredSide = "balanced"
+	}
+
+	metrics.ProfitLoss = pnl.ProfitLoss
+	metrics.RealizedFees = pnl.RealizedFees
+	metrics.NetProfit = pnl.NetProfit
+	if totalResolvedStake > 0 {
+		metrics.ROI = decimal.NewFromInt(pnl.NetProfit).
+			Div(DecimalFromLamports(totalResolvedStake)).
+			Mul(decimal.NewFromInt(100))
+	}
+
+	return metrics
 }
 
 // ProcessDailyRollup processes and stores daily analytics rollup
@@ -412,6 +676,8 @@ func (a *Analytics) ProcessDailyRollup(ctx context.Context, date time.Time) erro
 		return fmt.Errorf("failed to update analytics: %w", err)
 	}
 
+	a.recordDailyRollup(analytics)
+
 	a.logger.Info("daily rollup completed",
 		"date", startOfDay.Format("2006-01-02"),
 		"markets_created", analytics.MarketsCreated,
@@ -458,19 +724,30 @@ func (a *Analytics) GetPlatformOverview(ctx context.Context) (map[string]interfa
 	db.Model(&PositionView{}).Distinct("owner").Count(&uniqueUsers)
 	overview["unique_users"] = uniqueUsers
 
-	// Recent activity (last 24 hours)
-	last24h := time.Now().Add(-24 * time.Hour)
+	// Recent activity (last 24 hours). Served from the RollingAccumulator when one is
+	// wired in, so this doesn't re-scan PositionView on every call.
+	if a.rolling != nil {
+		snapshot, err := a.rolling.Snapshot(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read rolling snapshot: %w", err)
+		}
+		overview["recent_bets_24h"] = int64(snapshot.BetsPlaced)
+		overview["recent_volume_24h"] = snapshot.Volume
+		overview["recent_active_users_24h"] = snapshot.ActiveUsers
+	} else {
+		last24h := time.Now().Add(-24 * time.Hour)
 
-	var recentBets int64
-	db.Model(&PositionView{}).Where("created_at > ?", last24h).Count(&recentBets)
-	overview["recent_bets_24h"] = recentBets
+		var recentBets int64
+		db.Model(&PositionView{}).Where("created_at > ?", last24h).Count(&recentBets)
+		overview["recent_bets_24h"] = recentBets
 
-	var recentVolume sql.NullFloat64
-	db.Model(&PositionView{}).Where("created_at > ?", last24h).Select("SUM(amount)").Scan(&recentVolume)
-	if recentVolume.Valid {
-		overview["recent_volume_24h"] = uint64(recentVolume.Float64)
-	} else {
-		overview["recent_volume_24h"] = uint64(0)
+		var recentVolume sql.NullFloat64
+		db.Model(&PositionView{}).Where("created_at > ?", last24h).Select("SUM(amount)").Scan(&recentVolume)
+		if recentVolume.Valid {
+			overview["recent_volume_24h"] = uint64(recentVolume.Float64)
+		} else {
+			overview["recent_volume_24h"] = uint64(0)
+		}
 	}
 
 	return overview, nil
@@ -516,20 +793,19 @@ func (a *Analytics) GetTopMarketsByVolume(ctx context.Context, limit int) ([]Mar
 	return metrics, nil
 }
 
-// GetTopUsersByVolume returns top users by trading volume
+// GetTopUsersByVolume returns top users by trading volume, including win rate and P&L
+// for each via GetBulkUserMetrics, which stays at a fixed query count regardless of
+// limit
 func (a *Analytics) GetTopUsersByVolume(ctx context.Context, limit int) ([]UserMetrics, error) {
 	db := a.repo.db.DB
 
 	var results []struct {
 		UserID      string
 		TotalVolume uint64
-		TotalBets   int64
-		FirstBetAt  time.Time
-		LastBetAt   time.Time
 	}
 
 	err := db.Model(&PositionView{}).
-		Select("owner as user_id, SUM(amount) as total_volume, COUNT(*) as total_bets, MIN(created_at) as first_bet_at, MAX(created_at) as last_bet_at").
+		Select("owner as user_id, SUM(amount) as total_volume").
 		Group("owner").
 		Order("total_volume DESC").
 		Limit(limit).
@@ -539,46 +815,181 @@ func (a *Analytics) GetTopUsersByVolume(ctx context.Context, limit int) ([]UserM
 		return nil, fmt.Errorf("failed to get top users: %w", err)
 	}
 
-	metrics := make([]UserMetrics, len(results))
+	userIDs := make([]string, len(results))
 	for i, result := range results {
-		metrics[i] = UserMetrics{
-			UserID:      result.UserID,
-			TotalVolume: result.TotalVolume,
-			TotalBets:   int(result.TotalBets),
-			FirstBetAt:  result.FirstBetAt,
-			LastBetAt:   result.LastBetAt,
-			AvgBetSize:  float64(result.TotalVolume) / float64(result.TotalBets),
+		userIDs[i] = result.UserID
+	}
+
+	byUser, err := a.GetBulkUserMetrics(ctx, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bulk user metrics: %w", err)
+	}
+
+	metrics := make([]UserMetrics, len(userIDs))
+	for i, userID := range userIDs {
+		if m, ok := byUser[userID]; ok {
+			metrics[i] = *m
+		} else {
+			metrics[i] = UserMetrics{UserID: userID}
 		}
 	}
 
 	return metrics, nil
 }
 
-// TrackFunnelEvent tracks a funnel conversion event
+// funnelStages are the conversion stages tracked by TrackFunnelEvent and aggregated
+// by GetFunnelMetrics, in funnel order
+const (
+	FunnelStageVisitorsToApp   = "visitors_to_app"
+	FunnelStageViewedMarkets   = "viewed_markets"
+	FunnelStageConnectedWallet = "connected_wallet"
+	FunnelStagePlacedFirstBet  = "placed_first_bet"
+)
+
+// TrackFunnelEvent persists a funnel conversion event so GetFunnelMetrics and
+// GetCohortRetention can compute real rates instead of estimates. metadata is
+// JSON-encoded into the stored row; a "session_id" key, if present, is lifted into
+// the event's SessionID column.
 func (a *Analytics) TrackFunnelEvent(ctx context.Context, eventType, userID string, metadata map[string]interface{}) error {
-	// This would typically track events in a separate analytics table
-	// For now, just log the event
-	a.logger.Info("funnel event tracked",
-		"event_type", eventType,
-		"user_id", userID,
-		"metadata", metadata,
-	)
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal funnel event metadata: %w", err)
+	}
+
+	sessionID, _ := metadata["session_id"].(string)
+
+	event := &FunnelEvent{
+		EventType:  eventType,
+		UserID:     userID,
+		SessionID:  sessionID,
+		OccurredAt: time.Now(),
+		Metadata:   string(metadataJSON),
+	}
+	if err := a.repo.CreateFunnelEvent(event); err != nil {
+		return fmt.Errorf("failed to persist funnel event: %w", err)
+	}
+
+	a.logger.Info("funnel event tracked", "event_type", eventType, "user_id", userID)
+
 	return nil
 }
 
-// GetFunnelMetrics retrieves conversion funnel metrics
+// GetFunnelMetrics computes conversion rates between the visitors_to_app ->
+// viewed_markets -> connected_wallet -> placed_first_bet stages from tracked funnel
+// events, plus next-day retention for users who placed their first bet on date.
 func (a *Analytics) GetFunnelMetrics(ctx context.Context, date time.Time) (*FunnelMetrics, error) {
-	// This would need to be implemented based on tracked funnel events
-	// For now, return basic metrics
-	return &FunnelMetrics{
-		Date:                 date,
-		ConversionToFirstBet: 15.0, // Example: 15% conversion rate
-		RetentionRate:        30.0, // Example: 30% retention rate
-	}, nil
+	startOfDay := date.Truncate(24 * time.Hour)
+	endOfDay := startOfDay.Add(24 * time.Hour)
+
+	visitors, err := a.repo.CountFunnelEventUsers(FunnelStageVisitorsToApp, startOfDay, endOfDay)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count visitors: %w", err)
+	}
+	viewedMarkets, err := a.repo.CountFunnelEventUsers(FunnelStageViewedMarkets, startOfDay, endOfDay)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count viewed markets: %w", err)
+	}
+	connectedWallet, err := a.repo.CountFunnelEventUsers(FunnelStageConnectedWallet, startOfDay, endOfDay)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count connected wallet: %w", err)
+	}
+	placedFirstBet, err := a.repo.CountFunnelEventUsers(FunnelStagePlacedFirstBet, startOfDay, endOfDay)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count placed first bet: %w", err)
+	}
+
+	metrics := &FunnelMetrics{
+		Date:            startOfDay,
+		VisitorsToApp:   int(visitors),
+		ViewedMarkets:   int(viewedMarkets),
+		ConnectedWallet: int(connectedWallet),
+		PlacedFirstBet:  int(placedFirstBet),
+	}
+
+	if visitors > 0 {
+		metrics.ConversionToFirstBet, _ = decimal.NewFromInt(placedFirstBet).
+			Div(decimal.NewFromInt(visitors)).
+			Mul(decimal.NewFromInt(100)).
+			Float64()
+	}
+
+	retention, err := a.GetCohortRetention(ctx, startOfDay, 1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute next-day retention: %w", err)
+	}
+	for _, point := range retention.Points {
+		if point.Day == 1 {
+			metrics.ReturnedUsers = point.ActiveUsers
+			metrics.RetentionRate = point.RetentionPct
+		}
+	}
+
+	return metrics, nil
+}
+
+// retentionDayOffsets are the days-since-first-bet checkpoints GetCohortRetention reports
+var retentionDayOffsets = []int{0, 1, 7, 30}
+
+// CohortRetentionPoint is the fraction of a cohort still active a given number of
+// days after their first bet
+type CohortRetentionPoint struct {
+	Day          int     `json:"day"`
+	CohortSize   int     `json:"cohort_size"`
+	ActiveUsers  int     `json:"active_users"`
+	RetentionPct float64 `json:"retention_pct"`
+}
+
+// CohortRetention is the Day-0/1/7/30 retention matrix for the cohort of users who
+// placed their first bet on CohortDate
+type CohortRetention struct {
+	CohortDate time.Time              `json:"cohort_date"`
+	Points     []CohortRetentionPoint `json:"points"`
+}
+
+// GetCohortRetention groups users by the day they placed their first bet (cohortDate)
+// and, for each of the Day-0/1/7/30 checkpoints at or within windowDays, reports how
+// many of them placed another bet on that day, by joining the cohort against
+// subsequent PositionView activity.
+func (a *Analytics) GetCohortRetention(ctx context.Context, cohortDate time.Time, windowDays int) (*CohortRetention, error) {
+	cohortStart := cohortDate.Truncate(24 * time.Hour)
+	cohortEnd := cohortStart.Add(24 * time.Hour)
+
+	cohortUsers, err := a.repo.GetCohortUsers(cohortStart, cohortEnd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cohort users: %w", err)
+	}
+
+	retention := &CohortRetention{CohortDate: cohortStart}
+
+	for _, dayOffset := range retentionDayOffsets {
+		if dayOffset > windowDays {
+			continue
+		}
+
+		dayStart := cohortStart.Add(time.Duration(dayOffset) * 24 * time.Hour)
+		dayEnd := dayStart.Add(24 * time.Hour)
+
+		point := CohortRetentionPoint{Day: dayOffset, CohortSize: len(cohortUsers)}
+
+		if len(cohortUsers) > 0 {
+			activeUsers, err := a.repo.CountActiveUsersOnDay(cohortUsers, dayStart, dayEnd)
+			if err != nil {
+				return nil, fmt.Errorf("failed to count active users for day %d: %w", dayOffset, err)
+			}
+			point.ActiveUsers = int(activeUsers)
+			point.RetentionPct, _ = decimal.NewFromInt(activeUsers).
+				Div(decimal.NewFromInt(int64(len(cohortUsers)))).
+				Mul(decimal.NewFromInt(100)).
+				Float64()
+		}
+
+		retention.Points = append(retention.Points, point)
+	}
+
+	return retention, nil
 }
 
 // Health check
 func (a *Analytics) Health() error {
 	return a.repo.Health()
 }
-
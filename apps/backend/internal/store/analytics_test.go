@@ -0,0 +1,52 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestCalculateOdds_NoLiquidity(t *testing.T) {
+	odds := calculateOdds(0, 0)
+
+	if !odds.A.Equal(oneOdds) || !odds.B.Equal(oneOdds) {
+		t.Fatalf("expected even odds with no liquidity on either side, got A=%s B=%s", odds.A, odds.B)
+	}
+}
+
+func TestCalculateOdds_OneSideEmpty(t *testing.T) {
+	odds := calculateOdds(100, 0)
+
+	if !odds.A.Equal(decimal.NewFromInt(1)) {
+		t.Fatalf("expected side A odds of 1 when all liquidity is on side A, got %s", odds.A)
+	}
+	if !odds.B.Equal(decimal.Zero) {
+		t.Fatalf("expected side B odds of 0 when it has no liquidity, got %s", odds.B)
+	}
+}
+
+func TestCalculateOdds_EvenSplit(t *testing.T) {
+	odds := calculateOdds(500, 500)
+
+	if !odds.A.Equal(decimal.NewFromInt(2)) || !odds.B.Equal(decimal.NewFromInt(2)) {
+		t.Fatalf("expected 2x odds on both sides for an even split, got A=%s B=%s", odds.A, odds.B)
+	}
+}
+
+func TestCalculateOdds_ExtremeRatio(t *testing.T) {
+	odds := calculateOdds(1, 1_000_000_000_000)
+
+	if odds.B.LessThanOrEqual(decimal.NewFromInt(1)) {
+		t.Fatalf("expected side B odds just above 1 when side A is a tiny fraction, got %s", odds.B)
+	}
+	expectedA := decimal.NewFromInt(1_000_000_000_001)
+	if !odds.A.Equal(expectedA) {
+		t.Fatalf("expected side A odds of %s for an extreme ratio, got %s", expectedA, odds.A)
+	}
+}
+
+func TestDecimalFromLamports(t *testing.T) {
+	if !DecimalFromLamports(12345).Equal(decimal.NewFromInt(12345)) {
+		t.Fatalf("expected DecimalFromLamports to preserve the integer value exactly")
+	}
+}
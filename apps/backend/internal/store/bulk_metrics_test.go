@@ -0,0 +1,140 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// countingDB opens an in-memory sqlite database and returns it alongside a counter
+// that's incremented on every query GORM executes, so tests can assert a fixed query
+// count regardless of how much data is involved.
+func countingDB(t *testing.T) (*DB, *int64) {
+	t.Helper()
+
+	var queries int64
+	gdb, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{
+		Logger: countingLogger{count: &queries},
+	})
+	if err != nil {
+		t.Fatalf("failed to open sqlite: %v", err)
+	}
+
+	if err := gdb.AutoMigrate(&MarketView{}, &PositionView{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	return &DB{gdb}, &queries
+}
+
+// countingLogger is a minimal gorm logger.Interface that counts every Trace call
+// (one per executed query) and otherwise discards output.
+type countingLogger struct {
+	count *int64
+}
+
+func (l countingLogger) LogMode(logger.LogLevel) logger.Interface      { return l }
+func (l countingLogger) Info(context.Context, string, ...interface{})  {}
+func (l countingLogger) Warn(context.Context, string, ...interface{})  {}
+func (l countingLogger) Error(context.Context, string, ...interface{}) {}
+func (l countingLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	atomic.AddInt64(l.count, 1)
+}
+
+func seedUserWithPositions(t *testing.T, repo *Repository, userID string, n int) {
+	t.Helper()
+
+	marketID := "market-" + userID
+	outcome := "A"
+	market := &MarketView{
+		ID:      marketID,
+		Creator: userID,
+		Mint:    "mint",
+		Vault:   "vault",
+		FeeBps:  100,
+		StakedA: uint64(n * 100),
+		StakedB: uint64(n * 100),
+		Status:  "resolved",
+		Outcome: &outcome,
+		Title:   "test market",
+	}
+	if err := repo.CreateMarket(market); err != nil {
+		t.Fatalf("failed to create market: %v", err)
+	}
+
+	for i := 0; i < n; i++ {
+		pos := &PositionView{
+			ID:       fmt.Sprintf("%s-pos-%d", userID, i),
+			MarketID: marketID,
+			Owner:    userID,
+			Side:     "A",
+			Amount:   100,
+		}
+		if err := repo.CreateOrUpdatePosition(pos); err != nil {
+			t.Fatalf("failed to create position: %v", err)
+		}
+	}
+}
+
+// TestGetBulkUserMetrics_FixedQueryCount proves GetBulkUserMetrics issues the same
+// number of SQL statements whether it's asked about 3 users or 8 users, guarding
+// against the N+1 regression the bulk metrics API was built to eliminate.
+func TestGetBulkUserMetrics_FixedQueryCount(t *testing.T) {
+	db, queries := countingDB(t)
+	repo := NewRepository(db)
+
+	for _, userID := range []string{"user-a", "user-b", "user-c"} {
+		seedUserWithPositions(t, repo, userID, 5)
+	}
+
+	analytics := NewAnalytics(repo, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	atomic.StoreInt64(queries, 0)
+	small, err := analytics.GetBulkUserMetrics(context.Background(), []string{"user-a", "user-b", "user-c"})
+	if err != nil {
+		t.Fatalf("GetBulkUserMetrics failed: %v", err)
+	}
+	smallQueryCount := atomic.LoadInt64(queries)
+
+	for _, userID := range []string{"user-d", "user-e", "user-f", "user-g", "user-h"} {
+		seedUserWithPositions(t, repo, userID, 5)
+	}
+
+	atomic.StoreInt64(queries, 0)
+	large, err := analytics.GetBulkUserMetrics(context.Background(), []string{
+		"user-a", "user-b", "user-c", "user-d", "user-e", "user-f", "user-g", "user-h",
+	})
+	if err != nil {
+		t.Fatalf("GetBulkUserMetrics failed: %v", err)
+	}
+	largeQueryCount := atomic.LoadInt64(queries)
+
+	if smallQueryCount != largeQueryCount {
+		t.Fatalf("expected fixed query count regardless of user count, got %d queries for 3 users and %d queries for 8 users", smallQueryCount, largeQueryCount)
+	}
+
+	if len(small) != 3 {
+		t.Fatalf("expected 3 results for small batch, got %d", len(small))
+	}
+	if len(large) != 8 {
+		t.Fatalf("expected 8 results for large batch, got %d", len(large))
+	}
+
+	for _, userID := range []string{"user-a", "user-b", "user-c"} {
+		m := large[userID]
+		if m.TotalBets != 5 {
+			t.Fatalf("expected 5 bets for %s, got %d", userID, m.TotalBets)
+		}
+		if m.WinningBets != 5 {
+			t.Fatalf("expected 5 winning bets for %s, got %d", userID, m.WinningBets)
+		}
+	}
+}
@@ -13,8 +13,10 @@ type DB struct {
 	*gorm.DB
 }
 
-// NewDB creates a new database connection
-func NewDB(databaseURL string, logger interface{}) (*DB, error) {
+// NewDB creates a new database connection. devAutoMigrate should only be set from a
+// --dev flag in local development; production deployments apply schema changes with
+// the `migrate` CLI (see Migrate) instead of relying on GORM's AutoMigrate.
+func NewDB(databaseURL string, devAutoMigrate bool, logger interface{}) (*DB, error) {
 	if databaseURL == "" {
 		return nil, fmt.Errorf("database URL is required")
 	}
@@ -40,9 +42,12 @@ func NewDB(databaseURL string, logger interface{}) (*DB, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	// Run auto-migrations
-	if err := AutoMigrate(db); err != nil {
-		return nil, fmt.Errorf("failed to run migrations: %w", err)
+	// AutoMigrate is a dev-only convenience; real deployments run the versioned
+	// migrations under migrations/ via the `migrate` CLI before starting this service.
+	if devAutoMigrate {
+		if err := AutoMigrate(db); err != nil {
+			return nil, fmt.Errorf("failed to run migrations: %w", err)
+		}
 	}
 
 	return &DB{DB: db}, nil
@@ -0,0 +1,17 @@
+package store
+
+import "github.com/shopspring/decimal"
+
+// Decimal is the fixed-point decimal type used throughout this package for odds,
+// averages, and P&L figures. It's an alias for shopspring/decimal.Decimal — the same
+// type core.Market.CalculateOdds already uses — rather than a separate fixed-point
+// type, so analytics math and on-chain-derived odds stay consistent and interchangeable.
+// Using decimal instead of float64 avoids precision loss at large volumes and the
+// non-deterministic display values float64 division produces across clients.
+type Decimal = decimal.Decimal
+
+// DecimalFromLamports converts a raw lamport amount (the smallest unit used
+// throughout this codebase for staked/bet amounts) into a Decimal.
+func DecimalFromLamports(lamports uint64) Decimal {
+	return decimal.NewFromInt(int64(lamports))
+}
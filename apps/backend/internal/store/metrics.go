@@ -0,0 +1,178 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const metricsSnapshotInterval = 30 * time.Second
+
+// analyticsMetrics holds the Prometheus collectors backing Analytics.RegisterMetrics,
+// plus a cached snapshot of the gauges so scrapes never trigger a full DB scan. The
+// snapshot is refreshed on an interval by a background goroutine and updated directly
+// by ProcessDailyRollup and the position-write path as those events happen.
+type analyticsMetrics struct {
+	totalVolume    prometheus.Gauge
+	activeMarkets  prometheus.Gauge
+	betsPlaced     *prometheus.CounterVec // labeled by side
+	marketOdds     *prometheus.GaugeVec   // labeled by market_id, side
+	dailyVolume    prometheus.Gauge
+	dailyBetsCount prometheus.Gauge
+
+	// Rolling-window gauges, populated from Analytics.rolling when wired in; nil
+	// rolling means these stay at zero rather than falling back to a DB scan.
+	rollingVolume      prometheus.Gauge
+	rollingBetsPlaced  prometheus.Gauge
+	rollingActiveUsers prometheus.Gauge
+}
+
+// RegisterMetrics registers the Analytics Prometheus collectors with reg and starts a
+// background collector that refreshes the cached snapshot from GetPlatformOverview on
+// an interval, until ctx is cancelled. Safe to call at most once per Analytics instance.
+func (a *Analytics) RegisterMetrics(ctx context.Context, reg prometheus.Registerer) error {
+	m := &analyticsMetrics{
+		totalVolume: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "friendbets_total_volume",
+			Help: "Total lifetime betting volume across all markets.",
+		}),
+		activeMarkets: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "friendbets_active_markets",
+			Help: "Number of markets currently open for betting.",
+		}),
+		betsPlaced: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "friendbets_bets_placed_total",
+			Help: "Total number of bets placed, labeled by side.",
+		}, []string{"side"}),
+		marketOdds: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "friendbets_market_odds",
+			Help: "Current implied odds for a market, labeled by market_id and side.",
+		}, []string{"market_id", "side"}),
+		dailyVolume: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "friendbets_daily_rollup_volume",
+			Help: "Total volume recorded by the most recently processed daily rollup.",
+		}),
+		dailyBetsCount: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "friendbets_daily_rollup_bets_placed",
+			Help: "Bets placed recorded by the most recently processed daily rollup.",
+		}),
+		rollingVolume: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "friendbets_rolling_volume",
+			Help: "Betting volume within the rolling accumulator's window.",
+		}),
+		rollingBetsPlaced: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "friendbets_rolling_bets_placed",
+			Help: "Bets placed within the rolling accumulator's window.",
+		}),
+		rollingActiveUsers: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "friendbets_rolling_active_users",
+			Help: "Distinct users active within the rolling accumulator's window.",
+		}),
+	}
+
+	for _, c := range []prometheus.Collector{m.totalVolume, m.activeMarkets, m.betsPlaced, m.marketOdds, m.dailyVolume, m.dailyBetsCount, m.rollingVolume, m.rollingBetsPlaced, m.rollingActiveUsers} {
+		if err := reg.Register(c); err != nil {
+			return err
+		}
+	}
+
+	a.metrics = m
+
+	go a.runMetricsCollector(ctx)
+
+	return nil
+}
+
+// runMetricsCollector periodically refreshes the cached gauge snapshot from
+// GetPlatformOverview so a scrape never has to wait on a full DB scan.
+func (a *Analytics) runMetricsCollector(ctx context.Context) {
+	ticker := time.NewTicker(metricsSnapshotInterval)
+	defer ticker.Stop()
+
+	a.refreshMetricsSnapshot(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.refreshMetricsSnapshot(ctx)
+		}
+	}
+}
+
+func (a *Analytics) refreshMetricsSnapshot(ctx context.Context) {
+	overview, err := a.GetPlatformOverview(ctx)
+	if err != nil {
+		a.logger.Error("failed to refresh metrics snapshot", "error", err)
+		return
+	}
+
+	if totalVolume, ok := overview["total_volume"].(uint64); ok {
+		a.metrics.totalVolume.Set(float64(totalVolume))
+	}
+	if activeMarkets, ok := overview["active_markets"].(int64); ok {
+		a.metrics.activeMarkets.Set(float64(activeMarkets))
+	}
+
+	// The rolling gauges read the accumulator directly rather than the overview map,
+	// since they're backed by RollingAccumulator rather than a SQL scan.
+	if a.rolling != nil {
+		snapshot, err := a.rolling.Snapshot(ctx)
+		if err != nil {
+			a.logger.Error("failed to refresh rolling metrics snapshot", "error", err)
+			return
+		}
+		a.metrics.rollingVolume.Set(float64(snapshot.Volume))
+		a.metrics.rollingBetsPlaced.Set(float64(snapshot.BetsPlaced))
+		a.metrics.rollingActiveUsers.Set(float64(snapshot.ActiveUsers))
+	}
+}
+
+// recordBetPlaced increments the bets-placed counter for side. Called from the
+// position-write path (UseCases.PlaceBet) as bets land, so the counter reflects
+// real-time state without waiting on the next scheduled snapshot. No-op until
+// RegisterMetrics has been called.
+func (a *Analytics) recordBetPlaced(side string) {
+	if a.metrics == nil {
+		return
+	}
+	a.metrics.betsPlaced.WithLabelValues(side).Inc()
+}
+
+// RecordBetPlaced increments the bets-placed counter for side. Exposed for callers
+// outside this package (e.g. core.UseCases.PlaceBet); no-op until RegisterMetrics has
+// been called.
+func (a *Analytics) RecordBetPlaced(side string) {
+	a.recordBetPlaced(side)
+}
+
+// recordMarketOdds updates the exported odds gauge for a market. Called from the
+// position-write path alongside recordBetPlaced, since placing a bet is exactly what
+// moves a market's odds.
+func (a *Analytics) recordMarketOdds(marketID string, odds Odds) {
+	if a.metrics == nil {
+		return
+	}
+	aFloat, _ := odds.A.Float64()
+	bFloat, _ := odds.B.Float64()
+	a.metrics.marketOdds.WithLabelValues(marketID, "A").Set(aFloat)
+	a.metrics.marketOdds.WithLabelValues(marketID, "B").Set(bFloat)
+}
+
+// RecordMarketOdds updates the exported odds gauge for a market. Exposed for callers
+// outside this package; no-op until RegisterMetrics has been called.
+func (a *Analytics) RecordMarketOdds(marketID string, odds Odds) {
+	a.recordMarketOdds(marketID, odds)
+}
+
+// recordDailyRollup updates the per-day rollup gauges from a just-processed rollup, so
+// the latest rollup is visible at /metrics without querying AnalyticsDaily directly.
+func (a *Analytics) recordDailyRollup(daily *AnalyticsDaily) {
+	if a.metrics == nil {
+		return
+	}
+	a.metrics.dailyVolume.Set(float64(daily.TotalVolume))
+	a.metrics.dailyBetsCount.Set(float64(daily.BetsPlaced))
+}
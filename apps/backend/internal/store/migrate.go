@@ -0,0 +1,59 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/c9s/rockhopper/v2"
+
+	"github.com/friend-bets/backend/migrations"
+)
+
+// MigrateDirection selects which way Migrate moves the schema.
+type MigrateDirection string
+
+const (
+	MigrateUp     MigrateDirection = "up"
+	MigrateDown   MigrateDirection = "down"
+	MigrateRedo   MigrateDirection = "redo"
+	MigrateStatus MigrateDirection = "status"
+)
+
+// Migrate applies the versioned SQL migrations embedded in the migrations package
+// against sqlDB using rockhopper. It replaces the blanket AutoMigrate call for
+// production deployments, which can't express down-migrations, data backfills, or
+// non-trivial index changes. target is the migration version to stop at; 0 means
+// "all the way" for up/down and is ignored by redo/status.
+func Migrate(ctx context.Context, sqlDB *sql.DB, direction MigrateDirection, target int64) error {
+	dialect, err := rockhopper.LoadDialect("postgres")
+	if err != nil {
+		return fmt.Errorf("failed to load rockhopper postgres dialect: %w", err)
+	}
+
+	loader := rockhopper.NewFileSystemMigrationLoader(migrations.FS, ".")
+	migrationSet, err := loader.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load migrations: %w", err)
+	}
+
+	db := rockhopper.New("postgres", dialect, sqlDB)
+	defer db.Close()
+
+	if err := db.Touch(ctx); err != nil {
+		return fmt.Errorf("failed to initialize rockhopper migration log table: %w", err)
+	}
+
+	switch direction {
+	case MigrateUp:
+		return db.Up(ctx, migrationSet, target)
+	case MigrateDown:
+		return db.Down(ctx, migrationSet, target)
+	case MigrateRedo:
+		return db.Redo(ctx, migrationSet)
+	case MigrateStatus:
+		return db.Status(ctx, migrationSet)
+	default:
+		return fmt.Errorf("unknown migrate direction %q", direction)
+	}
+}
@@ -8,21 +8,36 @@ import (
 
 // MarketView represents a market in the database (shadow of on-chain state)
 type MarketView struct {
-	ID                  string    `gorm:"primaryKey" json:"id"`
-	Creator             string    `gorm:"not null;index" json:"creator"`
-	Mint                string    `gorm:"not null" json:"mint"`
-	Vault               string    `gorm:"not null" json:"vault"`
-	FeeBps              uint16    `gorm:"not null" json:"fee_bps"`
-	EndTs               time.Time `gorm:"not null;index" json:"end_ts"`
-	ResolveDeadlineTs   time.Time `gorm:"not null;index" json:"resolve_deadline_ts"`
-	StakedA             uint64    `gorm:"not null;default:0" json:"staked_a"`
-	StakedB             uint64    `gorm:"not null;default:0" json:"staked_b"`
-	Status              string    `gorm:"not null;default:'open';index" json:"status"` // open, pending_resolve, resolved, cancelled
-	Outcome             *string   `json:"outcome,omitempty"`                           // A or B
-	CreatorFeeWithdrawn bool      `gorm:"not null;default:false" json:"creator_fee_withdrawn"`
-	Title               string    `gorm:"not null" json:"title"`
-	CreatedAt           time.Time `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt           time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+	ID                  string     `gorm:"primaryKey" json:"id"`
+	Creator             string     `gorm:"not null;index" json:"creator"`
+	Mint                string     `gorm:"not null" json:"mint"`
+	Vault               string     `gorm:"not null" json:"vault"`
+	FeeBps              uint16     `gorm:"not null" json:"fee_bps"`
+	EndTs               time.Time  `gorm:"not null;index" json:"end_ts"`
+	ResolveDeadlineTs   time.Time  `gorm:"not null;index" json:"resolve_deadline_ts"`
+	StakedA             uint64     `gorm:"not null;default:0" json:"staked_a"`
+	StakedB             uint64     `gorm:"not null;default:0" json:"staked_b"`
+	Status              string     `gorm:"not null;default:'open';index" json:"status"` // open, pending_resolve, pending_challenge, disputed, resolved, cancelled
+	Outcome             *string    `json:"outcome,omitempty"`                           // A or B
+	CreatorFeeWithdrawn bool       `gorm:"not null;default:false" json:"creator_fee_withdrawn"`
+	Title               string     `gorm:"not null" json:"title"`
+	ParentID            *string    `gorm:"index" json:"parent_id,omitempty"`
+	ProposedOutcome     *string    `json:"proposed_outcome,omitempty"` // A or B, set while pending_challenge
+	ChallengeEndsAt     *time.Time `json:"challenge_ends_at,omitempty"`
+	CreatedAt           time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt           time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// LMSR fields; zero/empty for the default "parimutuel" market type.
+	MarketType     string  `gorm:"not null;default:'parimutuel'" json:"market_type"`
+	LiquidityParam float64 `gorm:"not null;default:0" json:"liquidity_param,omitempty"` // b
+	SharesA        float64 `gorm:"not null;default:0" json:"shares_a,omitempty"`        // qA
+	SharesB        float64 `gorm:"not null;default:0" json:"shares_b,omitempty"`        // qB
+
+	// Hedge fields; empty/false unless the creator opted into the hedge package's
+	// offsetting perp position worker at market creation.
+	HedgeEnabled  bool   `gorm:"not null;default:false" json:"hedge_enabled"`
+	HedgeExchange string `json:"hedge_exchange,omitempty"`
+	HedgeSymbol   string `json:"hedge_symbol,omitempty"`
 
 	// Derived fields
 	TotalStaked uint64  `gorm:"->" json:"total_staked"`
@@ -61,30 +76,58 @@ type EventLog struct {
 	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
 }
 
-// Dispute represents a dispute for manual resolution
+// Dispute represents a dispute for manual resolution, or a stake-weighted challenge
+// raised against a market's proposed outcome during its challenge window
 type Dispute struct {
-	ID          uint      `gorm:"primaryKey" json:"id"`
-	MarketID    string    `gorm:"not null;index" json:"market_id"`
-	DisputerID  string    `gorm:"not null" json:"disputer_id"`
-	Reason      string    `gorm:"not null" json:"reason"`
-	EvidenceURL string    `json:"evidence_url,omitempty"`
-	Status      string    `gorm:"not null;default:'pending'" json:"status"` // pending, reviewing, resolved, rejected
-	AdminNotes  string    `json:"admin_notes,omitempty"`
-	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	MarketID        string    `gorm:"not null;uniqueIndex:idx_disputes_market_disputer" json:"market_id"`
+	DisputerID      string    `gorm:"not null;uniqueIndex:idx_disputes_market_disputer" json:"disputer_id"`
+	Reason          string    `gorm:"not null" json:"reason"`
+	EvidenceURL     string    `json:"evidence_url,omitempty"`
+	Status          string    `gorm:"not null;default:'pending'" json:"status"` // pending, reviewing, resolved, rejected
+	AdminNotes      string    `json:"admin_notes,omitempty"`
+	ProposedOutcome string    `json:"proposed_outcome,omitempty"` // A or B; the outcome this dispute is staking against the current proposal
+	StakeAmount     uint64    `gorm:"not null;default:0" json:"stake_amount"`
+	CreatedAt       time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt       time.Time `gorm:"autoUpdateTime" json:"updated_at"`
 
 	// Relations
 	Market MarketView `gorm:"foreignKey:MarketID" json:"market,omitempty"`
 }
 
+// DisputeVote records one staker's stake-weighted vote on how to finalize a market
+// stuck in MarketStatusDisputed; see core.CastDisputeVote. A voter may only vote once
+// per market - the unique index lets CreateDisputeVote report a duplicate as a
+// constraint violation rather than requiring a separate existence check.
+type DisputeVote struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	MarketID  string    `gorm:"not null;uniqueIndex:idx_dispute_votes_market_voter" json:"market_id"`
+	Voter     string    `gorm:"not null;uniqueIndex:idx_dispute_votes_market_voter" json:"voter"`
+	Outcome   string    `gorm:"not null" json:"outcome"` // A or B
+	Weight    uint64    `gorm:"not null" json:"weight"`  // voter's staked position size at the time of voting
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
 // NotificationSubscription stores user notification preferences
 type NotificationSubscription struct {
-	ID        uint      `gorm:"primaryKey" json:"id"`
-	UserID    string    `gorm:"not null;index" json:"user_id"`
-	Type      string    `gorm:"not null" json:"type"` // email, web_push
-	Endpoint  string    `gorm:"not null" json:"endpoint"`
-	Data      string    `gorm:"type:jsonb" json:"data"` // JSON for web push keys, etc.
-	Enabled   bool      `gorm:"not null;default:true" json:"enabled"`
+	ID       uint   `gorm:"primaryKey" json:"id"`
+	UserID   string `gorm:"not null;index" json:"user_id"`
+	Type     string `gorm:"not null" json:"type"` // email, web_push, fcm, apns
+	Endpoint string `gorm:"not null" json:"endpoint"`
+	Data     string `gorm:"type:jsonb" json:"data"` // JSON for web push keys, etc.
+	// DeviceToken is the FCM registration token or APNs device token for Type fcm/apns;
+	// unused for email/web_push, which carry everything they need in Endpoint/Data.
+	DeviceToken string `gorm:"index" json:"device_token,omitempty"`
+	// Platform is the mobile OS a fcm/apns subscription's DeviceToken was issued for
+	// ("android" or "ios"), used to pick the right notification shape when translating
+	// NotificationTemplate for that provider.
+	Platform string    `gorm:"index" json:"platform,omitempty"`
+	Enabled  bool      `gorm:"not null;default:true" json:"enabled"`
+	// EventMask selects which event types this subscription receives, as a
+	// bitset of notify.EventMask values OR'd together (notify.EventMaskAll by
+	// default, so existing subscriptions keep receiving every event type
+	// unless a caller narrows them explicitly).
+	EventMask int32     `gorm:"not null;default:-1;index" json:"event_mask"`
 	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
 }
@@ -112,6 +155,271 @@ type RateCounter struct {
 	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
 }
 
+// SyncState tracks per-program indexing progress so the indexer can resume after
+// a restart instead of replaying from genesis or silently losing events.
+type SyncState struct {
+	Program                string    `gorm:"primaryKey" json:"program"`
+	LastProcessedSlot      uint64    `gorm:"not null;default:0" json:"last_processed_slot"`
+	LastProcessedSignature string    `json:"last_processed_signature,omitempty"`
+	LastBlockhash          string    `json:"last_blockhash,omitempty"`
+	IsFinalized            bool      `gorm:"not null;default:false" json:"is_finalized"`
+	UpdatedAt              time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// BlockSynced records each transaction the indexer has processed, keyed by signature,
+// so re-running a slot range after a restart or reorg rewind is idempotent.
+type BlockSynced struct {
+	Signature   string    `gorm:"primaryKey" json:"signature"`
+	Slot        uint64    `gorm:"not null;index" json:"slot"`
+	IsProcessed bool      `gorm:"not null;default:false" json:"is_processed"`
+	ProcessedAt time.Time `json:"processed_at"`
+}
+
+// MMBotState tracks a market-maker bot's cumulative exposure and realized PnL for a
+// single market it has been placing liquidity-seeding bets into
+type MMBotState struct {
+	ID                 uint      `gorm:"primaryKey" json:"id"`
+	BotID              string    `gorm:"not null;uniqueIndex:idx_mm_bot_market" json:"bot_id"`
+	MarketID           string    `gorm:"not null;uniqueIndex:idx_mm_bot_market;index" json:"market_id"`
+	CumulativeExposure uint64    `gorm:"not null;default:0" json:"cumulative_exposure"`
+	RealizedPnL        int64     `gorm:"not null;default:0" json:"realized_pnl"`
+	LastRefillAt       time.Time `json:"last_refill_at"`
+	CreatedAt          time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt          time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// FunnelEvent records a single conversion-funnel event (app visit, market view,
+// wallet connect, first bet) so stage conversion rates and cohort retention can be
+// computed from real activity instead of hard-coded estimates.
+type FunnelEvent struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	EventType  string    `gorm:"not null;index:idx_funnel_event_type,priority:1" json:"event_type"` // visitors_to_app, viewed_markets, connected_wallet, placed_first_bet
+	UserID     string    `gorm:"not null;index:idx_funnel_user,priority:1" json:"user_id"`
+	SessionID  string    `json:"session_id,omitempty"`
+	OccurredAt time.Time `gorm:"not null;index:idx_funnel_event_type,priority:2;index:idx_funnel_user,priority:2" json:"occurred_at"`
+	Metadata   string    `gorm:"type:jsonb" json:"metadata,omitempty"` // JSON-encoded event metadata
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// UserStats holds incrementally-updated per-user trading stats, maintained by
+// UserStatsAggregator as BetPlaced/Claimed events land. This lets leaderboard and
+// profile queries read a single row instead of rescanning every position the way
+// Analytics.ComputeRealizedPnL does.
+type UserStats struct {
+	UserID            string  `gorm:"primaryKey" json:"user_id"`
+	RealizedPnL       int64   `gorm:"not null;default:0" json:"realized_pnl"`
+	GrossVolume       uint64  `gorm:"not null;default:0" json:"gross_volume"`
+	BetsWon           int     `gorm:"not null;default:0" json:"bets_won"`
+	BetsLost          int     `gorm:"not null;default:0" json:"bets_lost"`
+	CurrentStreak     int     `gorm:"not null;default:0" json:"current_streak"` // positive: winning streak, negative: losing streak
+	LongestWinStreak  int     `gorm:"not null;default:0" json:"longest_win_streak"`
+	LongestLossStreak int     `gorm:"not null;default:0" json:"longest_loss_streak"`
+	RiskScore         float64 `gorm:"not null;default:0" json:"risk_score"` // sharpe-like: mean(net profit per claim) / stddev(net profit per claim)
+
+	// netProfitSum/netProfitSumSq/claimCount back RiskScore's running mean/stddev
+	// (Welford's algorithm) so it updates in O(1) instead of rescanning claim history.
+	NetProfitSum   int64   `gorm:"not null;default:0" json:"-"`
+	NetProfitSumSq float64 `gorm:"not null;default:0" json:"-"`
+	ClaimCount     int     `gorm:"not null;default:0" json:"-"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// Job run statuses
+const (
+	JobRunStatusPending   = "pending"
+	JobRunStatusRunning   = "running"
+	JobRunStatusSuccess   = "success"
+	JobRunStatusFailed    = "failed"
+	JobRunStatusCancelled = "cancelled"
+)
+
+// JobRun records a single execution of a scheduler job: when it started and
+// finished, its outcome, which retry attempt it was, and any error, so job
+// failures can be audited and overlapping/duplicate runs can be detected.
+type JobRun struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	JobID      string     `gorm:"not null;index:idx_job_run_job_id,priority:1" json:"job_id"`
+	Status     string     `gorm:"not null;index:idx_job_run_job_id,priority:2" json:"status"` // pending, running, success, failed, cancelled
+	StartedAt  time.Time  `gorm:"not null;index" json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Attempt    int        `gorm:"not null;default:1" json:"attempt"`
+	Error      string     `json:"error,omitempty"`
+	Payload    string     `gorm:"type:jsonb" json:"payload,omitempty"` // JSON-encoded job-specific context
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// HedgePosition tracks one hedge-enabled market's offsetting perpetual position on an
+// external exchange, mirroring xmaker's CoveredPosition: Covered is false whenever the
+// last adjustment attempt failed, so a restart (or the next observed event) knows to
+// retry rather than trusting a stale ActualNotional.
+type HedgePosition struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	MarketID       string    `gorm:"not null;uniqueIndex" json:"market_id"`
+	Creator        string    `gorm:"not null;index" json:"creator"`
+	Exchange       string    `gorm:"not null" json:"exchange"`
+	Symbol         string    `gorm:"not null" json:"symbol"`
+	Side           string    `gorm:"not null;default:''" json:"side,omitempty"` // long or short
+	TargetNotional int64     `gorm:"not null;default:0" json:"target_notional"`
+	ActualNotional int64     `gorm:"not null;default:0" json:"actual_notional"`
+	Covered        bool      `gorm:"not null;default:false" json:"covered"`
+	LastOrderID    string    `json:"last_order_id,omitempty"`
+	CreatedAt      time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// CreatorExchangeKey stores one creator's API credentials for an external exchange,
+// encrypted at rest by hedge.KeyStore before being persisted here; this package never
+// sees (or logs) plaintext key material.
+type CreatorExchangeKey struct {
+	ID                 uint      `gorm:"primaryKey" json:"id"`
+	Creator            string    `gorm:"not null;uniqueIndex:idx_creator_exchange_key" json:"creator"`
+	Exchange           string    `gorm:"not null;uniqueIndex:idx_creator_exchange_key" json:"exchange"`
+	EncryptedAPIKey    string    `gorm:"not null" json:"-"`
+	EncryptedAPISecret string    `gorm:"not null" json:"-"`
+	CreatedAt          time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt          time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// Backfill chunk statuses
+const (
+	BackfillChunkStatusPending    = "pending"
+	BackfillChunkStatusInProgress = "in_progress"
+	BackfillChunkStatusDone       = "done"
+	BackfillChunkStatusFailed     = "failed"
+)
+
+// BackfillChunk checkpoints one [StartSlot, EndSlot) slice of a historical event
+// backfill, so a worker pool can claim chunks concurrently and a restart resumes from
+// whichever chunks never reached "done" instead of rescanning the whole range. Attempt
+// counts and NextAttemptAt back exponential backoff on chunks that keep failing.
+type BackfillChunk struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	Program       string     `gorm:"not null;index:idx_backfill_program_status,priority:1;uniqueIndex:idx_backfill_range,priority:1" json:"program"`
+	StartSlot     uint64     `gorm:"not null;uniqueIndex:idx_backfill_range,priority:2" json:"start_slot"`
+	EndSlot       uint64     `gorm:"not null;uniqueIndex:idx_backfill_range,priority:3" json:"end_slot"`
+	Status        string     `gorm:"not null;index:idx_backfill_program_status,priority:2" json:"status"`
+	Attempt       int        `gorm:"not null;default:0" json:"attempt"`
+	NextAttemptAt time.Time  `gorm:"not null" json:"next_attempt_at"`
+	Error         string     `json:"error,omitempty"`
+	StartedAt     *time.Time `json:"started_at,omitempty"`
+	FinishedAt    *time.Time `json:"finished_at,omitempty"`
+	CreatedAt     time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt     time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// Pending event statuses
+const (
+	PendingEventStatusPending  = "pending"
+	PendingEventStatusOrphaned = "orphaned"
+)
+
+// PendingEvent is an event ingested at processed commitment but not yet promoted to
+// EventLog: it's tagged with the slot+blockhash it was observed at so a reconciler can
+// confirm, once that slot finalizes, that the blockhash is still part of the canonical
+// chain before the event is allowed to affect application state. A row whose blockhash
+// no longer matches the canonical chain at promotion time is marked orphaned rather than
+// deleted, so event_reorged can be emitted and the history of what got rolled back stays
+// inspectable; it's pruned later the same way BlockSynced rows are.
+type PendingEvent struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	TxSignature string    `gorm:"not null;index" json:"tx_signature"`
+	EventType   string    `gorm:"not null" json:"event_type"`
+	MarketID    *string   `gorm:"index" json:"market_id,omitempty"`
+	Data        string    `gorm:"type:jsonb" json:"data"`
+	Slot        uint64    `gorm:"not null;index" json:"slot"`
+	Blockhash   string    `gorm:"not null" json:"blockhash"`
+	BlockTime   time.Time `gorm:"not null" json:"block_time"`
+	Status      string    `gorm:"not null;default:'pending';index" json:"status"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// Raw event queue statuses
+const (
+	RawEventQueueStatusPending = "pending"
+	RawEventQueueStatusDone    = "done"
+)
+
+// RawEventQueue hands a transaction's logs from an EventSource that runs in a
+// different process than EventIndexer (the webhook receiver, mounted on
+// internal/grpc.Server) over to whichever worker process's EventIndexer is running,
+// the same way BackfillChunk hands backfill work to worker goroutines: a DB row a
+// poller can claim, since the two don't share memory. Source records which EventSource
+// produced the row, for diagnostics.
+type RawEventQueue struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Source    string    `gorm:"not null" json:"source"`
+	Signature string    `gorm:"not null;index" json:"tx_signature"`
+	Slot      uint64    `gorm:"not null" json:"slot"`
+	BlockTime time.Time `gorm:"not null" json:"block_time"`
+	Logs      string    `gorm:"type:jsonb;not null" json:"logs"` // JSON-encoded []string
+	Status    string    `gorm:"not null;default:'pending';index" json:"status"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// RequestIdempotency caches a mutating RPC's response keyed by (user_id, method, key)
+// so a client retry (network blip, double-click) within the TTL window gets back the
+// original response instead of re-running the use case and creating a duplicate
+// position/market. See grpc.IdempotencyInterceptor.
+type RequestIdempotency struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	UserID       string    `gorm:"not null;uniqueIndex:idx_idempotency_key" json:"user_id"`
+	Method       string    `gorm:"not null;uniqueIndex:idx_idempotency_key" json:"method"`
+	Key          string    `gorm:"not null;uniqueIndex:idx_idempotency_key" json:"key"`
+	RequestHash  string    `gorm:"not null" json:"request_hash"`
+	ResponseData []byte    `gorm:"type:bytea;not null" json:"-"`
+	CreatedAt    time.Time `gorm:"autoCreateTime;index" json:"created_at"`
+}
+
+// NotificationDelivery is one asynchronously-dispatched notification send,
+// persisted before it's handed to notify.DeliveryQueue so a crash or restart
+// doesn't lose a pending send. Payload holds whatever the provider's send
+// function needs (e.g. a marshaled EmailNotification or WebPushNotification),
+// kept opaque here since its shape is provider-specific.
+type NotificationDelivery struct {
+	ID     uint   `gorm:"primaryKey" json:"id"`
+	UserID string `gorm:"not null;index" json:"user_id"`
+	// Provider is "email", "web_push", "fcm", or "apns".
+	Provider string `gorm:"not null;index" json:"provider"`
+	Payload  []byte `gorm:"type:jsonb;not null" json:"-"`
+	// Status is pending, sent, failed (will retry), or dead (exhausted retries).
+	Status        string    `gorm:"not null;default:'pending';index" json:"status"`
+	Attempts      int       `gorm:"not null;default:0" json:"attempts"`
+	LastError     string    `json:"last_error,omitempty"`
+	NextAttemptAt time.Time `gorm:"index" json:"next_attempt_at"`
+	CreatedAt     time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt     time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// NotificationPreference controls whether and how userID receives eventType
+// notifications on channel, keyed by (UserID, EventType, Channel). A missing row
+// means the default applies: enabled, immediate, no severity filter, no quiet
+// hours — see notify.Notifier.ShouldDeliver.
+type NotificationPreference struct {
+	ID        uint   `gorm:"primaryKey" json:"id"`
+	UserID    string `gorm:"not null;uniqueIndex:idx_notification_preference_key" json:"user_id"`
+	EventType string `gorm:"not null;uniqueIndex:idx_notification_preference_key" json:"event_type"`
+	Channel   string `gorm:"not null;uniqueIndex:idx_notification_preference_key" json:"channel"`
+	Enabled   bool   `gorm:"not null;default:true" json:"enabled"`
+	// MinSeverity suppresses events ranked below it (see notify's severity
+	// ranking); empty means no severity filtering.
+	MinSeverity string `json:"min_severity,omitempty"`
+	// BatchWindow is "immediate", "hourly", or "daily". Anything but immediate is
+	// buffered by notify.Digester and sent as one combined email at the next
+	// scheduled flush instead of per-event.
+	BatchWindow string `gorm:"not null;default:'immediate'" json:"batch_window"`
+	// QuietHoursStart/End are minutes since midnight (0-1439) in Timezone during
+	// which immediate delivery is suppressed; nil means no quiet hours. A start
+	// greater than end wraps past midnight (e.g. 22:00-07:00).
+	QuietHoursStart *int `json:"quiet_hours_start,omitempty"`
+	QuietHoursEnd   *int `json:"quiet_hours_end,omitempty"`
+	// Timezone is an IANA zone name quiet hours are evaluated in; empty means UTC.
+	Timezone  string    `json:"timezone,omitempty"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
 // AutoMigrate runs database migrations
 func AutoMigrate(db *gorm.DB) error {
 	return db.AutoMigrate(
@@ -119,9 +427,23 @@ func AutoMigrate(db *gorm.DB) error {
 		&PositionView{},
 		&EventLog{},
 		&Dispute{},
+		&DisputeVote{},
 		&NotificationSubscription{},
 		&AnalyticsDaily{},
 		&RateCounter{},
+		&SyncState{},
+		&BlockSynced{},
+		&MMBotState{},
+		&FunnelEvent{},
+		&JobRun{},
+		&UserStats{},
+		&HedgePosition{},
+		&CreatorExchangeKey{},
+		&BackfillChunk{},
+		&PendingEvent{},
+		&RawEventQueue{},
+		&RequestIdempotency{},
+		&NotificationDelivery{},
+		&NotificationPreference{},
 	)
 }
-
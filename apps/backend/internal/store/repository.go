@@ -1,9 +1,12 @@
 package store
 
 import (
+	"context"
+	"fmt"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // Repository provides database operations
@@ -69,6 +72,13 @@ func (r *Repository) GetMarketsNearEnd(within time.Duration) ([]MarketView, erro
 	return markets, err
 }
 
+// GetMarketsByParentID gets all successor markets chained off a parent market
+func (r *Repository) GetMarketsByParentID(parentID string) ([]MarketView, error) {
+	var markets []MarketView
+	err := r.db.Where("parent_id = ?", parentID).Order("created_at ASC").Find(&markets).Error
+	return markets, err
+}
+
 // GetExpiredUnresolvedMarkets gets markets that are past their resolve deadline
 func (r *Repository) GetExpiredUnresolvedMarkets() ([]MarketView, error) {
 	var markets []MarketView
@@ -119,6 +129,46 @@ func (r *Repository) GetPositionsByUser(userID string) ([]PositionView, error) {
 	return positions, err
 }
 
+// GetPositionsByUsers gets all positions for a set of users in one query (plus
+// GORM's own bounded IN-clause query to preload Market), used by bulk metrics lookups
+// so per-user query count stays fixed regardless of len(userIDs)
+func (r *Repository) GetPositionsByUsers(userIDs []string) ([]PositionView, error) {
+	var positions []PositionView
+	err := r.db.Preload("Market").Where("owner IN ?", userIDs).Find(&positions).Error
+	return positions, err
+}
+
+// CountMarketsCreatedByUsers counts markets created per user for a set of users in a
+// single grouped query
+func (r *Repository) CountMarketsCreatedByUsers(userIDs []string) (map[string]int, error) {
+	var rows []struct {
+		Creator string
+		Count   int64
+	}
+	err := r.db.Model(&MarketView{}).
+		Select("creator, COUNT(*) as count").
+		Where("creator IN ?", userIDs).
+		Group("creator").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.Creator] = int(row.Count)
+	}
+	return counts, nil
+}
+
+// GetPositionsCreatedSince gets all positions created at or after since, used to seed
+// a RollingAccumulator's window at boot
+func (r *Repository) GetPositionsCreatedSince(since time.Time) ([]PositionView, error) {
+	var positions []PositionView
+	err := r.db.Where("created_at >= ?", since).Find(&positions).Error
+	return positions, err
+}
+
 // Events
 
 // CreateEventLog creates a new event log entry
@@ -126,6 +176,13 @@ func (r *Repository) CreateEventLog(event *EventLog) error {
 	return r.db.Create(event).Error
 }
 
+// CountEventLogs returns the total number of confirmed, applied events.
+func (r *Repository) CountEventLogs() (int64, error) {
+	var count int64
+	err := r.db.Model(&EventLog{}).Count(&count).Error
+	return count, err
+}
+
 // GetEventsByMarket gets events for a specific market
 func (r *Repository) GetEventsByMarket(marketID string, limit int) ([]EventLog, error) {
 	var events []EventLog
@@ -133,6 +190,30 @@ func (r *Repository) GetEventsByMarket(marketID string, limit int) ([]EventLog,
 	return events, err
 }
 
+// GetEventsByType gets all events of the given type, oldest first, for replaying
+// history in order (e.g. UserStatsAggregator.Backfill).
+func (r *Repository) GetEventsByType(eventType string) ([]EventLog, error) {
+	var events []EventLog
+	err := r.db.Where("event_type = ?", eventType).Order("created_at ASC").Find(&events).Error
+	return events, err
+}
+
+// GetEventsFromSlot gets all events at or after a given slot, used to find events
+// orphaned by a chain reorg
+func (r *Repository) GetEventsFromSlot(slot uint64) ([]EventLog, error) {
+	var events []EventLog
+	err := r.db.Where("slot >= ?", slot).Order("slot ASC").Find(&events).Error
+	return events, err
+}
+
+// GetEventsSince returns up to limit events with ID greater than seq, ordered by ID
+// ascending, for resuming a WatchEvents stream from a previously emitted cursor.
+func (r *Repository) GetEventsSince(seq uint, limit int) ([]EventLog, error) {
+	var events []EventLog
+	err := r.db.Where("id > ?", seq).Order("id ASC").Limit(limit).Find(&events).Error
+	return events, err
+}
+
 // GetLatestProcessedSlot gets the latest processed slot from event logs
 func (r *Repository) GetLatestProcessedSlot() (uint64, error) {
 	var event EventLog
@@ -146,9 +227,123 @@ func (r *Repository) GetLatestProcessedSlot() (uint64, error) {
 	return event.Slot, nil
 }
 
+// Pending events
+
+// CreatePendingEvent records an event ingested at processed commitment, awaiting
+// confirmation before it's promoted to EventLog and applied.
+func (r *Repository) CreatePendingEvent(event *PendingEvent) error {
+	return r.db.Create(event).Error
+}
+
+// GetPendingEventsBelowSlot returns pending events at or below maxSlot, oldest first, so
+// a reconciler can check each one's finality and canonical-chain membership in slot
+// order.
+func (r *Repository) GetPendingEventsBelowSlot(maxSlot uint64, limit int) ([]PendingEvent, error) {
+	var events []PendingEvent
+	err := r.db.Where("status = ? AND slot <= ?", PendingEventStatusPending, maxSlot).
+		Order("slot ASC").Limit(limit).Find(&events).Error
+	return events, err
+}
+
+// PromotePendingEvent atomically moves a confirmed pending event into EventLog and
+// deletes the pending row, so a crash between the two can't double-promote it.
+func (r *Repository) PromotePendingEvent(pending *PendingEvent, confirmed *EventLog) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(confirmed).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&PendingEvent{}, pending.ID).Error
+	})
+}
+
+// OrphanPendingEvent marks a pending event whose blockhash no longer appears in the
+// canonical chain, instead of deleting it outright, so the reorg it was rolled back by
+// stays inspectable.
+func (r *Repository) OrphanPendingEvent(id uint) error {
+	return r.db.Model(&PendingEvent{}).Where("id = ?", id).Update("status", PendingEventStatusOrphaned).Error
+}
+
+// PendingEventCounts summarizes pending-pipeline depth for GetIndexingStatus.
+type PendingEventCounts struct {
+	Pending  int64
+	Orphaned int64
+}
+
+// GetPendingEventCounts groups pending_events by status for reporting.
+func (r *Repository) GetPendingEventCounts() (*PendingEventCounts, error) {
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+	if err := r.db.Model(&PendingEvent{}).Select("status, count(*) as count").Group("status").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	counts := &PendingEventCounts{}
+	for _, row := range rows {
+		switch row.Status {
+		case PendingEventStatusPending:
+			counts.Pending = row.Count
+		case PendingEventStatusOrphaned:
+			counts.Orphaned = row.Count
+		}
+	}
+	return counts, nil
+}
+
+// Sync state
+
+// GetSyncState gets the indexing checkpoint for a program, or a zero-value state if none exists yet
+func (r *Repository) GetSyncState(program string) (*SyncState, error) {
+	var state SyncState
+	err := r.db.Where("program = ?", program).First(&state).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return &SyncState{Program: program}, nil
+		}
+		return nil, err
+	}
+	return &state, nil
+}
+
+// UpsertSyncState persists the latest indexing checkpoint for a program
+func (r *Repository) UpsertSyncState(state *SyncState) error {
+	return r.db.Save(state).Error
+}
+
+// IsBlockSynced reports whether a transaction signature has already been processed
+func (r *Repository) IsBlockSynced(signature string) (bool, error) {
+	var block BlockSynced
+	err := r.db.Where("signature = ? AND is_processed = ?", signature, true).First(&block).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// MarkBlockSynced records a transaction as processed
+func (r *Repository) MarkBlockSynced(signature string, slot uint64) error {
+	return r.db.Save(&BlockSynced{
+		Signature:   signature,
+		Slot:        slot,
+		IsProcessed: true,
+		ProcessedAt: time.Now(),
+	}).Error
+}
+
+// DeleteBlocksSyncedFromSlot removes synced-block records at or after a slot, used when
+// rewinding past a reorg so the affected range can be safely reprocessed
+func (r *Repository) DeleteBlocksSyncedFromSlot(slot uint64) error {
+	return r.db.Where("slot >= ?", slot).Delete(&BlockSynced{}).Error
+}
+
 // Disputes
 
-// CreateDispute creates a new dispute
+// CreateDispute creates a new dispute. The caller is expected to surface a
+// unique-constraint error as "already disputed" - the unique index on
+// (market_id, disputer_id) is the source of truth, not a prior read.
 func (r *Repository) CreateDispute(dispute *Dispute) error {
 	return r.db.Create(dispute).Error
 }
@@ -160,6 +355,88 @@ func (r *Repository) GetDisputesByStatus(status string) ([]Dispute, error) {
 	return disputes, err
 }
 
+// GetDisputesByMarket gets all disputes raised against a market's proposed outcome
+func (r *Repository) GetDisputesByMarket(marketID string) ([]Dispute, error) {
+	var disputes []Dispute
+	err := r.db.Where("market_id = ?", marketID).Find(&disputes).Error
+	return disputes, err
+}
+
+// SumDisputeStakeByOutcome sums staked dispute amounts for a market, grouped by the
+// outcome each dispute argues for
+func (r *Repository) SumDisputeStakeByOutcome(marketID string) (map[string]uint64, error) {
+	var rows []struct {
+		ProposedOutcome string
+		Total           uint64
+	}
+	err := r.db.Model(&Dispute{}).
+		Select("proposed_outcome, sum(stake_amount) as total").
+		Where("market_id = ?", marketID).
+		Group("proposed_outcome").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	totals := make(map[string]uint64, len(rows))
+	for _, row := range rows {
+		totals[row.ProposedOutcome] = row.Total
+	}
+	return totals, nil
+}
+
+// CreateDisputeVote records a staker's weighted vote on a disputed market's outcome.
+// The caller is expected to surface a unique-constraint error as "already voted" -
+// the unique index on (market_id, voter) is the source of truth, not a prior read.
+func (r *Repository) CreateDisputeVote(vote *DisputeVote) error {
+	return r.db.Create(vote).Error
+}
+
+// SumDisputeVoteWeightByOutcome sums recorded dispute-vote weight for a market,
+// grouped by the outcome each vote supports.
+func (r *Repository) SumDisputeVoteWeightByOutcome(marketID string) (map[string]uint64, error) {
+	var rows []struct {
+		Outcome string
+		Total   uint64
+	}
+	err := r.db.Model(&DisputeVote{}).
+		Select("outcome, sum(weight) as total").
+		Where("market_id = ?", marketID).
+		Group("outcome").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	totals := make(map[string]uint64, len(rows))
+	for _, row := range rows {
+		totals[row.Outcome] = row.Total
+	}
+	return totals, nil
+}
+
+// CountDisputesByCreatorSince counts disputes filed against markets created by creator
+// since the given time, for the risk package's dispute-frequency circuit breaker.
+func (r *Repository) CountDisputesByCreatorSince(creator string, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Table("disputes").
+		Joins("JOIN market_views ON market_views.id = disputes.market_id").
+		Where("market_views.creator = ? AND disputes.created_at >= ?", creator, since).
+		Count(&count).Error
+	return count, err
+}
+
+// CountResolveDeadlineViolationsByCreator counts resolved markets created by creator
+// whose last update happened after their resolve deadline, for the risk package's
+// resolver-history circuit breaker. This is an approximation: the store doesn't track a
+// separate "resolved at" timestamp, so UpdatedAt is used as a proxy for when resolution
+// actually landed.
+func (r *Repository) CountResolveDeadlineViolationsByCreator(creator string) (int64, error) {
+	var count int64
+	err := r.db.Model(&MarketView{}).
+		Where("creator = ? AND status = ? AND updated_at > resolve_deadline_ts", creator, "resolved").
+		Count(&count).Error
+	return count, err
+}
+
 // Notifications
 
 // CreateNotificationSubscription creates a new notification subscription
@@ -174,6 +451,116 @@ func (r *Repository) GetNotificationSubscriptions(userID string) ([]Notification
 	return subs, err
 }
 
+// GetNotificationSubscriptionsForEvent returns every enabled subscription
+// whose EventMask includes eventMask, for broadcast-style events that aren't
+// scoped to a single user or market.
+func (r *Repository) GetNotificationSubscriptionsForEvent(eventMask int32) ([]NotificationSubscription, error) {
+	var subs []NotificationSubscription
+	err := r.db.Where("enabled = ? AND (event_mask & ?) != 0", true, eventMask).Find(&subs).Error
+	return subs, err
+}
+
+// GetMarketParticipantSubscriptions returns every enabled subscription whose
+// EventMask includes eventMask, belonging to a user with at least one
+// position (win or lose side) in marketID.
+func (r *Repository) GetMarketParticipantSubscriptions(marketID string, eventMask int32) ([]NotificationSubscription, error) {
+	var subs []NotificationSubscription
+	err := r.db.
+		Where("enabled = ? AND (event_mask & ?) != 0", true, eventMask).
+		Where("user_id IN (?)", r.db.Model(&PositionView{}).Select("DISTINCT owner").Where("market_id = ?", marketID)).
+		Find(&subs).Error
+	return subs, err
+}
+
+// DisableNotificationSubscriptionByEndpoint turns off a web push subscription once
+// its push service reports 404/410 (the browser unsubscribed or the endpoint
+// expired), so it stops being sent to without deleting the row outright.
+func (r *Repository) DisableNotificationSubscriptionByEndpoint(endpoint string) error {
+	return r.db.Model(&NotificationSubscription{}).Where("endpoint = ?", endpoint).Update("enabled", false).Error
+}
+
+// DisableNotificationSubscriptionByDeviceToken is DisableNotificationSubscriptionByEndpoint's
+// counterpart for fcm/apns subscriptions, which are keyed by DeviceToken rather than Endpoint.
+func (r *Repository) DisableNotificationSubscriptionByDeviceToken(deviceToken string) error {
+	return r.db.Model(&NotificationSubscription{}).Where("device_token = ?", deviceToken).Update("enabled", false).Error
+}
+
+// CreateNotificationDelivery persists a new queued notification send, before it's
+// handed to notify.DeliveryQueue, so the send survives a crash between enqueue and
+// dispatch.
+func (r *Repository) CreateNotificationDelivery(d *NotificationDelivery) error {
+	return r.db.Create(d).Error
+}
+
+// UpdateNotificationDeliveryAttempt records the outcome of one delivery attempt:
+// status moves to sent, failed (will retry at nextAttemptAt), or dead (attempts
+// exhausted), and attempts/lastError/updated_at move with it.
+func (r *Repository) UpdateNotificationDeliveryAttempt(id uint, status string, attempts int, lastErr string, nextAttemptAt time.Time) error {
+	return r.db.Model(&NotificationDelivery{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":          status,
+		"attempts":        attempts,
+		"last_error":      lastErr,
+		"next_attempt_at": nextAttemptAt,
+	}).Error
+}
+
+// GetPendingNotificationDeliveries returns up to limit deliveries still awaiting
+// dispatch (pending or failed-but-due-for-retry), oldest next_attempt_at first, so
+// DeliveryQueue can requeue them on startup without losing work a previous process
+// crashed mid-send.
+func (r *Repository) GetPendingNotificationDeliveries(limit int) ([]NotificationDelivery, error) {
+	var deliveries []NotificationDelivery
+	err := r.db.Where("status IN ? AND next_attempt_at <= ?", []string{"pending", "failed"}, time.Now()).
+		Order("next_attempt_at ASC").
+		Limit(limit).
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+// GetNotificationPreference loads userID's preference for (eventType, channel).
+// Returns gorm.ErrRecordNotFound if none has been set, which callers treat as
+// "use the default" rather than an error.
+func (r *Repository) GetNotificationPreference(userID, eventType, channel string) (*NotificationPreference, error) {
+	var pref NotificationPreference
+	err := r.db.Where("user_id = ? AND event_type = ? AND channel = ?", userID, eventType, channel).First(&pref).Error
+	return &pref, err
+}
+
+// GetNotificationPreferences lists every preference row userID has set, for the
+// preferences-editing HTTP endpoint to render.
+func (r *Repository) GetNotificationPreferences(userID string) ([]NotificationPreference, error) {
+	var prefs []NotificationPreference
+	err := r.db.Where("user_id = ?", userID).Find(&prefs).Error
+	return prefs, err
+}
+
+// UpsertNotificationPreference creates or replaces userID's preference for
+// (eventType, channel), keyed by the table's (user_id, event_type, channel)
+// unique index.
+func (r *Repository) UpsertNotificationPreference(pref *NotificationPreference) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "event_type"}, {Name: "channel"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"enabled", "min_severity", "batch_window", "quiet_hours_start", "quiet_hours_end", "timezone", "updated_at",
+		}),
+	}).Create(pref).Error
+}
+
+// DisableAllNotificationPreferences flips enabled to false on every one of
+// userID's preference rows across all event types and channels, and creates a
+// disabled catch-all row (event_type/channel "*") so events with no existing
+// preference row are suppressed too. This is what the one-click unsubscribe link
+// calls: a single global opt-out rather than per-event toggling.
+func (r *Repository) DisableAllNotificationPreferences(userID string) error {
+	if err := r.db.Model(&NotificationPreference{}).Where("user_id = ?", userID).Update("enabled", false).Error; err != nil {
+		return err
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "event_type"}, {Name: "channel"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled", "updated_at"}),
+	}).Create(&NotificationPreference{UserID: userID, EventType: "*", Channel: "*", Enabled: false}).Error
+}
+
 // Analytics
 
 // GetOrCreateAnalyticsDaily gets or creates daily analytics record
@@ -190,6 +577,36 @@ func (r *Repository) UpdateAnalyticsDaily(analytics *AnalyticsDaily) error {
 	return r.db.Save(analytics).Error
 }
 
+// GetUserStats returns userID's incrementally-maintained trading stats, for the
+// per-user leaderboard/profile API. Returns gorm.ErrRecordNotFound if the user has no
+// stats row yet (i.e. they've never had a bet placed or claimed).
+func (r *Repository) GetUserStats(userID string) (*UserStats, error) {
+	var stats UserStats
+	err := r.db.Where("user_id = ?", userID).First(&stats).Error
+	return &stats, err
+}
+
+// userStatsMetricColumns maps the metric names TopUsers accepts to their backing
+// UserStats column, so callers can't pass an arbitrary string into an ORDER BY clause.
+var userStatsMetricColumns = map[string]string{
+	"realized_pnl": "realized_pnl",
+	"gross_volume": "gross_volume",
+	"risk_score":   "risk_score",
+}
+
+// TopUsers returns the top limit users ranked by metric ("realized_pnl",
+// "gross_volume", or "risk_score"), descending, for leaderboards.
+func (r *Repository) TopUsers(metric string, limit int) ([]UserStats, error) {
+	column, ok := userStatsMetricColumns[metric]
+	if !ok {
+		return nil, fmt.Errorf("unknown leaderboard metric %q", metric)
+	}
+
+	var stats []UserStats
+	err := r.db.Order(column + " DESC").Limit(limit).Find(&stats).Error
+	return stats, err
+}
+
 // Rate Limiting
 
 // IncrementRateCounter increments a rate counter
@@ -224,8 +641,510 @@ func (r *Repository) CleanupExpiredRateCounters() error {
 	return r.db.Where("window_end < ?", time.Now()).Delete(&RateCounter{}).Error
 }
 
+// Idempotency keys
+
+// GetIdempotencyRecord looks up a cached response for (userID, method, key), returning
+// gorm.ErrRecordNotFound if none exists (including one that's aged out past
+// DeleteIdempotencyRecordsOlderThan's retention window).
+func (r *Repository) GetIdempotencyRecord(userID, method, key string) (*RequestIdempotency, error) {
+	var rec RequestIdempotency
+	err := r.db.Where("user_id = ? AND method = ? AND key = ?", userID, method, key).First(&rec).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+// SaveIdempotencyRecord persists rec after a mutating RPC succeeds, so a retry within
+// the TTL window can be answered without re-running the use case. A conflicting
+// (user_id, method, key) row — a concurrent duplicate request racing this one — is
+// left as-is rather than overwritten.
+func (r *Repository) SaveIdempotencyRecord(rec *RequestIdempotency) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(rec).Error
+}
+
+// DeleteIdempotencyRecordsOlderThan deletes request_idempotency rows created before
+// cutoff in batches of batchSize, mirroring DeleteEventsOlderThan.
+func (r *Repository) DeleteIdempotencyRecordsOlderThan(ctx context.Context, cutoff time.Time, batchSize int) (int64, error) {
+	return r.deleteOlderThanInBatches(ctx, &RequestIdempotency{}, "created_at", cutoff, batchSize)
+}
+
+// Market makers
+
+// GetOrCreateMMBotState gets or creates the liquidity state a bot holds in a market
+func (r *Repository) GetOrCreateMMBotState(botID, marketID string) (*MMBotState, error) {
+	var state MMBotState
+	err := r.db.Where("bot_id = ? AND market_id = ?", botID, marketID).FirstOrCreate(&state, MMBotState{
+		BotID:    botID,
+		MarketID: marketID,
+	}).Error
+	return &state, err
+}
+
+// UpdateMMBotState persists a bot's updated exposure/PnL for a market
+func (r *Repository) UpdateMMBotState(state *MMBotState) error {
+	return r.db.Save(state).Error
+}
+
+// GetMMBotStatesByBot gets all per-market state rows for a bot
+func (r *Repository) GetMMBotStatesByBot(botID string) ([]MMBotState, error) {
+	var states []MMBotState
+	err := r.db.Where("bot_id = ?", botID).Find(&states).Error
+	return states, err
+}
+
+// EventLogFilter narrows a GetEventLogsFiltered query; a zero-value field is ignored.
+type EventLogFilter struct {
+	EventTypes []string
+	MarketIDs  []string
+	FromSlot   uint64
+	ToSlot     uint64
+	FromTime   time.Time
+	ToTime     time.Time
+	// AfterID restricts to rows with ID greater than this, for cursor-based polling.
+	AfterID uint
+}
+
+// GetEventLogsFiltered returns EventLog rows matching filter, ordered by ID ascending
+// so a caller can track a monotonic cursor across repeated calls (ID already orders
+// events by ingestion time within a slot, so it doubles as the log-index tiebreak a
+// separate column would otherwise provide).
+func (r *Repository) GetEventLogsFiltered(filter EventLogFilter) ([]EventLog, error) {
+	query := r.db.Model(&EventLog{})
+
+	if len(filter.EventTypes) > 0 {
+		query = query.Where("event_type IN ?", filter.EventTypes)
+	}
+	if len(filter.MarketIDs) > 0 {
+		query = query.Where("market_id IN ?", filter.MarketIDs)
+	}
+	if filter.FromSlot > 0 {
+		query = query.Where("slot >= ?", filter.FromSlot)
+	}
+	if filter.ToSlot > 0 {
+		query = query.Where("slot <= ?", filter.ToSlot)
+	}
+	if !filter.FromTime.IsZero() {
+		query = query.Where("block_time >= ?", filter.FromTime)
+	}
+	if !filter.ToTime.IsZero() {
+		query = query.Where("block_time <= ?", filter.ToTime)
+	}
+	if filter.AfterID > 0 {
+		query = query.Where("id > ?", filter.AfterID)
+	}
+
+	var events []EventLog
+	err := query.Order("id ASC").Find(&events).Error
+	return events, err
+}
+
+// Hedge positions and exchange credentials
+
+// GetOrCreateHedgePosition loads a market's hedge position, creating an uncovered zero
+// row if this is the first time hedge.Monitor has observed it.
+func (r *Repository) GetOrCreateHedgePosition(marketID, creator, exchange, symbol string) (*HedgePosition, error) {
+	var pos HedgePosition
+	err := r.db.Where("market_id = ?", marketID).FirstOrCreate(&pos, HedgePosition{
+		MarketID: marketID,
+		Creator:  creator,
+		Exchange: exchange,
+		Symbol:   symbol,
+	}).Error
+	return &pos, err
+}
+
+// UpdateHedgePosition persists a hedge position's updated target/actual notional,
+// coverage status, and last order ID.
+func (r *Repository) UpdateHedgePosition(pos *HedgePosition) error {
+	return r.db.Save(pos).Error
+}
+
+// UpsertCreatorExchangeKey stores (or replaces) a creator's encrypted API credentials
+// for an exchange.
+func (r *Repository) UpsertCreatorExchangeKey(key *CreatorExchangeKey) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "creator"}, {Name: "exchange"}},
+		DoUpdates: clause.AssignmentColumns([]string{"encrypted_api_key", "encrypted_api_secret", "updated_at"}),
+	}).Create(key).Error
+}
+
+// GetCreatorExchangeKey loads a creator's encrypted API credentials for an exchange.
+func (r *Repository) GetCreatorExchangeKey(creator, exchange string) (*CreatorExchangeKey, error) {
+	var key CreatorExchangeKey
+	err := r.db.Where("creator = ? AND exchange = ?", creator, exchange).First(&key).Error
+	return &key, err
+}
+
+// Funnel events
+
+// CreateFunnelEvent persists a conversion-funnel event
+func (r *Repository) CreateFunnelEvent(event *FunnelEvent) error {
+	return r.db.Create(event).Error
+}
+
+// CountFunnelEventUsers counts the distinct users who recorded eventType within [start, end)
+func (r *Repository) CountFunnelEventUsers(eventType string, start, end time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&FunnelEvent{}).
+		Where("event_type = ? AND occurred_at >= ? AND occurred_at < ?", eventType, start, end).
+		Distinct("user_id").
+		Count(&count).Error
+	return count, err
+}
+
+// GetCohortUsers returns the distinct users whose first-ever bet fell within [start, end),
+// used to build a retention cohort keyed by first-bet day
+func (r *Repository) GetCohortUsers(start, end time.Time) ([]string, error) {
+	var userIDs []string
+	err := r.db.Model(&PositionView{}).
+		Select("owner").
+		Group("owner").
+		Having("MIN(created_at) >= ? AND MIN(created_at) < ?", start, end).
+		Scan(&userIDs).Error
+	return userIDs, err
+}
+
+// CountActiveUsersOnDay counts how many of userIDs placed a bet within [start, end)
+func (r *Repository) CountActiveUsersOnDay(userIDs []string, start, end time.Time) (int64, error) {
+	if len(userIDs) == 0 {
+		return 0, nil
+	}
+	var count int64
+	err := r.db.Model(&PositionView{}).
+		Where("owner IN ? AND created_at >= ? AND created_at < ?", userIDs, start, end).
+		Distinct("owner").
+		Count(&count).Error
+	return count, err
+}
+
+// Job runs
+
+// CreateJobRun persists a new job run record, typically in "running" status
+// when a job starts executing.
+func (r *Repository) CreateJobRun(run *JobRun) error {
+	return r.db.Create(run).Error
+}
+
+// UpdateJobRun saves status/finished_at/error changes to an existing job run.
+func (r *Repository) UpdateJobRun(run *JobRun) error {
+	return r.db.Save(run).Error
+}
+
+// GetNewestJobByStatusAndType returns the most recently started run of jobID in
+// the given status, or nil if none exists.
+func (r *Repository) GetNewestJobByStatusAndType(jobID, status string) (*JobRun, error) {
+	var run JobRun
+	err := r.db.Where("job_id = ? AND status = ?", jobID, status).
+		Order("started_at DESC").
+		First(&run).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// GetCountByStatusAndType counts runs of jobID currently in the given status,
+// used by the scheduler to detect overlapping runs of the same job.
+func (r *Repository) GetCountByStatusAndType(jobID, status string) (int64, error) {
+	var count int64
+	err := r.db.Model(&JobRun{}).Where("job_id = ? AND status = ?", jobID, status).Count(&count).Error
+	return count, err
+}
+
+// ClaimPendingJob atomically claims the oldest pending run of jobID, flipping it
+// to "running" under a SELECT ... FOR UPDATE SKIP LOCKED, so concurrent callers
+// racing to pick up the same manually-triggered run don't both execute it.
+// Returns nil, nil if no pending run exists.
+func (r *Repository) ClaimPendingJob(jobID string) (*JobRun, error) {
+	var run JobRun
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("job_id = ? AND status = ?", jobID, JobRunStatusPending).
+			Order("started_at ASC").
+			First(&run).Error
+		if err != nil {
+			return err
+		}
+
+		run.Status = JobRunStatusRunning
+		return tx.Save(&run).Error
+	})
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// GetRecentJobRuns returns the most recent job runs across all jobs, newest
+// first, for the admin job-status view.
+func (r *Repository) GetRecentJobRuns(limit int) ([]JobRun, error) {
+	var runs []JobRun
+	err := r.db.Order("started_at DESC").Limit(limit).Find(&runs).Error
+	return runs, err
+}
+
+// Historical event backfill
+
+// CreateBackfillChunksIfNotExist enqueues [startSlot, endSlot) for program as pending
+// chunkSize-wide chunks, skipping any chunk that already exists (by program/start/end)
+// so re-running a backfill over an overlapping range doesn't duplicate work or clobber
+// an in-flight/completed chunk's state.
+func (r *Repository) CreateBackfillChunksIfNotExist(program string, startSlot, endSlot, chunkSize uint64) error {
+	now := time.Now()
+	for slot := startSlot; slot < endSlot; slot += chunkSize {
+		chunkEnd := slot + chunkSize
+		if chunkEnd > endSlot {
+			chunkEnd = endSlot
+		}
+
+		err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&BackfillChunk{
+			Program:       program,
+			StartSlot:     slot,
+			EndSlot:       chunkEnd,
+			Status:        BackfillChunkStatusPending,
+			NextAttemptAt: now,
+		}).Error
+		if err != nil {
+			return fmt.Errorf("failed to enqueue backfill chunk [%d, %d): %w", slot, chunkEnd, err)
+		}
+	}
+	return nil
+}
+
+// ClaimBackfillChunk atomically claims the oldest pending-or-retry-ready chunk for
+// program, flipping it to in_progress under a SELECT ... FOR UPDATE SKIP LOCKED so
+// concurrent workers don't claim the same chunk. Returns nil, nil if none is ready.
+func (r *Repository) ClaimBackfillChunk(program string) (*BackfillChunk, error) {
+	var chunk BackfillChunk
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("program = ? AND status IN ? AND next_attempt_at <= ?",
+				program, []string{BackfillChunkStatusPending, BackfillChunkStatusFailed}, time.Now()).
+			Order("start_slot ASC").
+			First(&chunk).Error
+		if err != nil {
+			return err
+		}
+
+		chunk.Status = BackfillChunkStatusInProgress
+		chunk.Attempt++
+		now := time.Now()
+		chunk.StartedAt = &now
+		return tx.Save(&chunk).Error
+	})
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &chunk, nil
+}
+
+// FinishBackfillChunk marks chunk done, or failed with backoff retryAfter before it
+// becomes claimable again (DoRetry=false leaves it failed permanently, e.g. once
+// Attempt exceeds a caller-enforced max).
+func (r *Repository) FinishBackfillChunk(chunk *BackfillChunk, failErr error, retryAfter time.Duration) error {
+	now := time.Now()
+	chunk.FinishedAt = &now
+	if failErr == nil {
+		chunk.Status = BackfillChunkStatusDone
+		chunk.Error = ""
+	} else {
+		chunk.Status = BackfillChunkStatusFailed
+		chunk.Error = failErr.Error()
+		chunk.NextAttemptAt = now.Add(retryAfter)
+	}
+	return r.db.Save(chunk).Error
+}
+
+// EnqueueReindexRange force-requeues [startSlot, endSlot) as pending chunkSize-wide
+// chunks for program, resetting any existing chunk in the range back to pending even
+// if it previously completed — unlike CreateBackfillChunksIfNotExist, which leaves an
+// existing chunk's state untouched. Used by the admin "reindex range" endpoint.
+func (r *Repository) EnqueueReindexRange(program string, startSlot, endSlot, chunkSize uint64) error {
+	now := time.Now()
+	for slot := startSlot; slot < endSlot; slot += chunkSize {
+		chunkEnd := slot + chunkSize
+		if chunkEnd > endSlot {
+			chunkEnd = endSlot
+		}
+
+		err := r.db.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "program"}, {Name: "start_slot"}, {Name: "end_slot"}},
+			DoUpdates: clause.AssignmentColumns([]string{"status", "attempt", "next_attempt_at", "error", "started_at", "finished_at"}),
+		}).Create(&BackfillChunk{
+			Program:       program,
+			StartSlot:     slot,
+			EndSlot:       chunkEnd,
+			Status:        BackfillChunkStatusPending,
+			NextAttemptAt: now,
+		}).Error
+		if err != nil {
+			return fmt.Errorf("failed to re-enqueue backfill chunk [%d, %d): %w", slot, chunkEnd, err)
+		}
+	}
+	return nil
+}
+
+// Raw event queue
+
+// EnqueueRawEvent records a transaction observed by an EventSource running outside the
+// EventIndexer's own process (the webhook receiver), for a worker's EventIndexer to
+// claim and decode.
+func (r *Repository) EnqueueRawEvent(event *RawEventQueue) error {
+	return r.db.Create(event).Error
+}
+
+// ClaimRawEvents atomically claims up to limit pending raw events for processing,
+// oldest first, under a SELECT ... FOR UPDATE SKIP LOCKED so concurrent indexer
+// instances don't decode the same row twice.
+func (r *Repository) ClaimRawEvents(limit int) ([]RawEventQueue, error) {
+	var events []RawEventQueue
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ?", RawEventQueueStatusPending).
+			Order("id ASC").Limit(limit).Find(&events).Error; err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+		ids := make([]uint, len(events))
+		for i, e := range events {
+			ids[i] = e.ID
+		}
+		return tx.Model(&RawEventQueue{}).Where("id IN ?", ids).Update("status", RawEventQueueStatusDone).Error
+	})
+	return events, err
+}
+
+// BackfillProgress summarizes a program's backfill chunk counts for status reporting.
+type BackfillProgress struct {
+	Pending    int64
+	InProgress int64
+	Done       int64
+	Failed     int64
+}
+
+// GetBackfillProgress counts program's chunks by status.
+func (r *Repository) GetBackfillProgress(program string) (*BackfillProgress, error) {
+	rows, err := r.db.Model(&BackfillChunk{}).
+		Select("status, count(*) as count").
+		Where("program = ?", program).
+		Group("status").
+		Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	progress := &BackfillProgress{}
+	for rows.Next() {
+		var status string
+		var count int64
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, err
+		}
+		switch status {
+		case BackfillChunkStatusPending:
+			progress.Pending = count
+		case BackfillChunkStatusInProgress:
+			progress.InProgress = count
+		case BackfillChunkStatusDone:
+			progress.Done = count
+		case BackfillChunkStatusFailed:
+			progress.Failed = count
+		}
+	}
+	return progress, rows.Err()
+}
+
+// GetRecentCompletedBackfillChunks returns the most recently finished (done or failed)
+// chunks for program, newest first, used to estimate slots/sec for an ETA.
+func (r *Repository) GetRecentCompletedBackfillChunks(program string, limit int) ([]BackfillChunk, error) {
+	var chunks []BackfillChunk
+	err := r.db.Where("program = ? AND finished_at IS NOT NULL", program).
+		Order("finished_at DESC").
+		Limit(limit).
+		Find(&chunks).Error
+	return chunks, err
+}
+
+// Data retention
+
+// DeleteEventsOlderThan deletes EventLog rows created before cutoff in batches
+// of batchSize, so a large backlog doesn't hold one long-running delete lock.
+// It checks ctx.Done() between batches and returns the total rows deleted.
+func (r *Repository) DeleteEventsOlderThan(ctx context.Context, cutoff time.Time, batchSize int) (int64, error) {
+	return r.deleteOlderThanInBatches(ctx, &EventLog{}, "created_at", cutoff, batchSize)
+}
+
+// DeleteOldJobRuns deletes JobRun rows started before cutoff in batches of
+// batchSize, pruning the history built up by the job-run persistence feature.
+func (r *Repository) DeleteOldJobRuns(ctx context.Context, cutoff time.Time, batchSize int) (int64, error) {
+	return r.deleteOlderThanInBatches(ctx, &JobRun{}, "started_at", cutoff, batchSize)
+}
+
+// deleteOlderThanInBatches repeatedly selects up to batchSize IDs of model
+// whose timeColumn is before cutoff and deletes just those rows, rather than a
+// single unbounded DELETE, so cleanup jobs don't hold a long-running lock on
+// large tables.
+func (r *Repository) deleteOlderThanInBatches(ctx context.Context, model interface{}, timeColumn string, cutoff time.Time, batchSize int) (int64, error) {
+	var total int64
+	for {
+		select {
+		case <-ctx.Done():
+			return total, ctx.Err()
+		default:
+		}
+
+		var ids []uint
+		if err := r.db.WithContext(ctx).Model(model).
+			Where(timeColumn+" < ?", cutoff).
+			Limit(batchSize).
+			Pluck("id", &ids).Error; err != nil {
+			return total, err
+		}
+		if len(ids) == 0 {
+			return total, nil
+		}
+
+		result := r.db.WithContext(ctx).Where("id IN ?", ids).Delete(model)
+		if result.Error != nil {
+			return total, result.Error
+		}
+		total += result.RowsAffected
+
+		if len(ids) < batchSize {
+			return total, nil
+		}
+	}
+}
+
+// GetOldestEventTime returns the CreatedAt of the oldest remaining EventLog
+// row, or nil if the table is empty, so cleanup jobs can report how far back
+// retention now reaches.
+func (r *Repository) GetOldestEventTime(ctx context.Context) (*time.Time, error) {
+	var event EventLog
+	err := r.db.WithContext(ctx).Order("created_at ASC").First(&event).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &event.CreatedAt, nil
+}
+
 // Health check
 func (r *Repository) Health() error {
 	return r.db.Health()
 }
-
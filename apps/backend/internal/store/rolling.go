@@ -0,0 +1,223 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// rollingBucketSize is the granularity counters are accumulated at. A delta recorded
+// at time t is folded into the bucket t.Truncate(rollingBucketSize) falls into; Sum and
+// MemberCount discard buckets older than the requested window.
+const rollingBucketSize = time.Minute
+
+// Backend persists the sliding-window counters a RollingAccumulator maintains.
+// InMemoryBackend is the default, in-process implementation; RedisBackend lets
+// multiple API/worker instances share the same window.
+type Backend interface {
+	// Add folds delta into the bucket key falls into at time t.
+	Add(ctx context.Context, key string, t time.Time, delta float64) error
+	// AddMember records member as active in the bucket key falls into at time t, for
+	// distinct-count counters such as active users.
+	AddMember(ctx context.Context, key string, t time.Time, member string) error
+	// Sum returns the sum of all buckets recorded for key within the last window.
+	Sum(ctx context.Context, key string, window time.Duration) (float64, error)
+	// MemberCount returns the number of distinct members recorded for key within the
+	// last window.
+	MemberCount(ctx context.Context, key string, window time.Duration) (int, error)
+}
+
+func bucketTimestamp(t time.Time) int64 {
+	return t.Truncate(rollingBucketSize).Unix()
+}
+
+// InMemoryBackend is the default Backend: sliding-window counters kept in process
+// memory, pruned lazily as buckets age out of whatever window is queried.
+type InMemoryBackend struct {
+	mu      sync.Mutex
+	sums    map[string]map[int64]float64
+	members map[string]map[int64]map[string]struct{}
+}
+
+// NewInMemoryBackend creates an empty in-memory rolling-window backend.
+func NewInMemoryBackend() *InMemoryBackend {
+	return &InMemoryBackend{
+		sums:    make(map[string]map[int64]float64),
+		members: make(map[string]map[int64]map[string]struct{}),
+	}
+}
+
+func (b *InMemoryBackend) Add(ctx context.Context, key string, t time.Time, delta float64) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	buckets, ok := b.sums[key]
+	if !ok {
+		buckets = make(map[int64]float64)
+		b.sums[key] = buckets
+	}
+	buckets[bucketTimestamp(t)] += delta
+	return nil
+}
+
+func (b *InMemoryBackend) AddMember(ctx context.Context, key string, t time.Time, member string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	buckets, ok := b.members[key]
+	if !ok {
+		buckets = make(map[int64]map[string]struct{})
+		b.members[key] = buckets
+	}
+	ts := bucketTimestamp(t)
+	if buckets[ts] == nil {
+		buckets[ts] = make(map[string]struct{})
+	}
+	buckets[ts][member] = struct{}{}
+	return nil
+}
+
+func (b *InMemoryBackend) Sum(ctx context.Context, key string, window time.Duration) (float64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	buckets := b.sums[key]
+	cutoff := time.Now().Add(-window).Unix()
+	var total float64
+	for ts, v := range buckets {
+		if ts < cutoff {
+			delete(buckets, ts)
+			continue
+		}
+		total += v
+	}
+	return total, nil
+}
+
+func (b *InMemoryBackend) MemberCount(ctx context.Context, key string, window time.Duration) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	buckets := b.members[key]
+	cutoff := time.Now().Add(-window).Unix()
+	seen := make(map[string]struct{})
+	for ts, members := range buckets {
+		if ts < cutoff {
+			delete(buckets, ts)
+			continue
+		}
+		for m := range members {
+			seen[m] = struct{}{}
+		}
+	}
+	return len(seen), nil
+}
+
+// Rolling counter keys. marketVolumeKey namespaces per-market volume counters.
+const (
+	rollingKeyVolume      = "volume"
+	rollingKeyBetsPlaced  = "bets_placed"
+	rollingKeyActiveUsers = "active_users"
+)
+
+func marketVolumeKey(marketID string) string {
+	return "market_volume:" + marketID
+}
+
+// RollingSnapshot is a point-in-time read of the sliding window maintained by
+// RollingAccumulator.
+type RollingSnapshot struct {
+	Volume      uint64
+	BetsPlaced  int
+	ActiveUsers int
+}
+
+// RollingAccumulator maintains sliding-window counters (volume, bets placed, active
+// users, per-market volume) over a pluggable Backend, so GetPlatformOverview and the
+// Prometheus collector can serve recent-activity stats without re-scanning the SQL
+// store on every call. It's updated directly from the indexer's position-write path
+// via RecordPosition, and seeded at boot from recent DB rows via Backfill so a
+// restart doesn't momentarily zero the window.
+type RollingAccumulator struct {
+	backend Backend
+	window  time.Duration
+}
+
+// NewRollingAccumulator creates a RollingAccumulator over backend that reports sums
+// and distinct-member counts for the trailing window.
+func NewRollingAccumulator(backend Backend, window time.Duration) *RollingAccumulator {
+	return &RollingAccumulator{backend: backend, window: window}
+}
+
+// RecordPosition folds a newly ingested position into the rolling window. Safe to
+// call from the indexer for every PositionView write as it lands.
+func (ra *RollingAccumulator) RecordPosition(ctx context.Context, pos *PositionView) error {
+	return ra.recordPositionAt(ctx, pos, time.Now())
+}
+
+func (ra *RollingAccumulator) recordPositionAt(ctx context.Context, pos *PositionView, at time.Time) error {
+	if err := ra.backend.Add(ctx, rollingKeyVolume, at, float64(pos.Amount)); err != nil {
+		return fmt.Errorf("failed to record rolling volume: %w", err)
+	}
+	if err := ra.backend.Add(ctx, rollingKeyBetsPlaced, at, 1); err != nil {
+		return fmt.Errorf("failed to record rolling bets placed: %w", err)
+	}
+	if err := ra.backend.AddMember(ctx, rollingKeyActiveUsers, at, pos.Owner); err != nil {
+		return fmt.Errorf("failed to record rolling active user: %w", err)
+	}
+	if err := ra.backend.Add(ctx, marketVolumeKey(pos.MarketID), at, float64(pos.Amount)); err != nil {
+		return fmt.Errorf("failed to record rolling market volume: %w", err)
+	}
+	return nil
+}
+
+// Snapshot reads the current window's volume, bets placed, and distinct active users.
+func (ra *RollingAccumulator) Snapshot(ctx context.Context) (*RollingSnapshot, error) {
+	volume, err := ra.backend.Sum(ctx, rollingKeyVolume, ra.window)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum rolling volume: %w", err)
+	}
+	bets, err := ra.backend.Sum(ctx, rollingKeyBetsPlaced, ra.window)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum rolling bets placed: %w", err)
+	}
+	activeUsers, err := ra.backend.MemberCount(ctx, rollingKeyActiveUsers, ra.window)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count rolling active users: %w", err)
+	}
+
+	return &RollingSnapshot{
+		Volume:      uint64(volume),
+		BetsPlaced:  int(bets),
+		ActiveUsers: activeUsers,
+	}, nil
+}
+
+// MarketVolume reads the current window's volume for a single market.
+func (ra *RollingAccumulator) MarketVolume(ctx context.Context, marketID string) (uint64, error) {
+	volume, err := ra.backend.Sum(ctx, marketVolumeKey(marketID), ra.window)
+	if err != nil {
+		return 0, fmt.Errorf("failed to sum rolling market volume: %w", err)
+	}
+	return uint64(volume), nil
+}
+
+// Backfill seeds the window from positions created within the last window so a
+// restart doesn't momentarily report zeroed metrics. Call once at boot before the
+// accumulator starts serving reads.
+func (ra *RollingAccumulator) Backfill(ctx context.Context, repo *Repository) error {
+	positions, err := repo.GetPositionsCreatedSince(time.Now().Add(-ra.window))
+	if err != nil {
+		return fmt.Errorf("failed to load positions for rolling backfill: %w", err)
+	}
+
+	for i := range positions {
+		pos := &positions[i]
+		if err := ra.recordPositionAt(ctx, pos, pos.CreatedAt); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
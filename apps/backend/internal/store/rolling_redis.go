@@ -0,0 +1,101 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisBucketRetention is how long a bucket's Redis keys are kept around past the
+// bucket's own duration, so a window can be widened (e.g. RollingWindowSec config
+// change) without immediately losing history.
+const redisBucketRetention = 48 * time.Hour
+
+// RedisBackend is the optional Backend implementation backing RollingAccumulator with
+// Redis, so counters are shared across every API/worker instance instead of being
+// per-process.
+type RedisBackend struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisBackend creates a Backend that stores each bucket as its own Redis key
+// under prefix, expiring keys after redisBucketRetention.
+func NewRedisBackend(client *redis.Client, prefix string) *RedisBackend {
+	return &RedisBackend{client: client, prefix: prefix}
+}
+
+func (b *RedisBackend) sumKey(key string, ts int64) string {
+	return fmt.Sprintf("%s:sum:%s:%d", b.prefix, key, ts)
+}
+
+func (b *RedisBackend) memberKey(key string, ts int64) string {
+	return fmt.Sprintf("%s:members:%s:%d", b.prefix, key, ts)
+}
+
+// bucketRange returns every bucket timestamp from window-ago through now, inclusive.
+func bucketRange(window time.Duration) []int64 {
+	now := time.Now()
+	start := bucketTimestamp(now.Add(-window))
+	end := bucketTimestamp(now)
+
+	var buckets []int64
+	step := int64(rollingBucketSize.Seconds())
+	for ts := start; ts <= end; ts += step {
+		buckets = append(buckets, ts)
+	}
+	return buckets
+}
+
+func (b *RedisBackend) Add(ctx context.Context, key string, t time.Time, delta float64) error {
+	k := b.sumKey(key, bucketTimestamp(t))
+	pipe := b.client.Pipeline()
+	pipe.IncrByFloat(ctx, k, delta)
+	pipe.Expire(ctx, k, redisBucketRetention)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (b *RedisBackend) AddMember(ctx context.Context, key string, t time.Time, member string) error {
+	k := b.memberKey(key, bucketTimestamp(t))
+	pipe := b.client.Pipeline()
+	pipe.SAdd(ctx, k, member)
+	pipe.Expire(ctx, k, redisBucketRetention)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (b *RedisBackend) Sum(ctx context.Context, key string, window time.Duration) (float64, error) {
+	var total float64
+	for _, ts := range bucketRange(window) {
+		v, err := b.client.Get(ctx, b.sumKey(key, ts)).Float64()
+		if err != nil && err != redis.Nil {
+			return 0, err
+		}
+		total += v
+	}
+	return total, nil
+}
+
+func (b *RedisBackend) MemberCount(ctx context.Context, key string, window time.Duration) (int, error) {
+	buckets := bucketRange(window)
+	if len(buckets) == 0 {
+		return 0, nil
+	}
+
+	keys := make([]string, len(buckets))
+	for i, ts := range buckets {
+		keys[i] = b.memberKey(key, ts)
+	}
+
+	tmpKey := fmt.Sprintf("%s:members:%s:union", b.prefix, key)
+	n, err := b.client.SUnionStore(ctx, tmpKey, keys...).Result()
+	if err != nil {
+		return 0, err
+	}
+	b.client.Expire(ctx, tmpKey, time.Minute)
+
+	return int(n), nil
+}
@@ -0,0 +1,185 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// These mirror core.EventBetPlaced/core.EventClaimed. store can't import core (core
+// already imports store), so the backfill path matches on the same literal values
+// event_logs.event_type was written with instead.
+const (
+	eventTypeBetPlaced = "BetPlaced"
+	eventTypeClaimed   = "Claimed"
+)
+
+// UserStatsAggregator incrementally maintains each user's UserStats row as
+// BetPlaced/Claimed events land, so a leaderboard or profile read never has to
+// rescan the user's full position history the way Analytics.ComputeRealizedPnL does.
+// Each Record call runs inside its own transaction, row-locking the user's stats so
+// concurrent events for the same user serialize instead of racing a read-modify-write.
+type UserStatsAggregator struct {
+	repo *Repository
+}
+
+// NewUserStatsAggregator creates an aggregator backed by repo.
+func NewUserStatsAggregator(repo *Repository) *UserStatsAggregator {
+	return &UserStatsAggregator{repo: repo}
+}
+
+// lockOrCreateStats fetches userID's stats row for update within tx, creating it with
+// zero values if it doesn't exist yet.
+func lockOrCreateStats(tx *gorm.DB, userID string) (*UserStats, error) {
+	var stats UserStats
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ?", userID).
+		FirstOrCreate(&stats, UserStats{UserID: userID}).Error
+	return &stats, err
+}
+
+// RecordBetPlaced adds amount to userID's gross volume.
+func (a *UserStatsAggregator) RecordBetPlaced(ctx context.Context, userID string, amount uint64) error {
+	return a.repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		stats, err := lockOrCreateStats(tx, userID)
+		if err != nil {
+			return err
+		}
+		stats.GrossVolume += amount
+		return tx.Save(stats).Error
+	})
+}
+
+// RecordClaim folds a resolved position's outcome into userID's stats: realized P&L,
+// win/loss streaks, and a sharpe-like risk score (mean net profit per claim divided by
+// its standard deviation, tracked via running sums rather than replaying every claim).
+func (a *UserStatsAggregator) RecordClaim(ctx context.Context, userID string, netProfit int64, won bool) error {
+	return a.repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		stats, err := lockOrCreateStats(tx, userID)
+		if err != nil {
+			return err
+		}
+
+		stats.RealizedPnL += netProfit
+
+		if won {
+			stats.BetsWon++
+			if stats.CurrentStreak < 0 {
+				stats.CurrentStreak = 0
+			}
+			stats.CurrentStreak++
+			if stats.CurrentStreak > stats.LongestWinStreak {
+				stats.LongestWinStreak = stats.CurrentStreak
+			}
+		} else {
+			stats.BetsLost++
+			if stats.CurrentStreak > 0 {
+				stats.CurrentStreak = 0
+			}
+			stats.CurrentStreak--
+			if -stats.CurrentStreak > stats.LongestLossStreak {
+				stats.LongestLossStreak = -stats.CurrentStreak
+			}
+		}
+
+		stats.ClaimCount++
+		stats.NetProfitSum += netProfit
+		stats.NetProfitSumSq += float64(netProfit) * float64(netProfit)
+		stats.RiskScore = riskScore(stats.NetProfitSum, stats.NetProfitSumSq, stats.ClaimCount)
+
+		return tx.Save(stats).Error
+	})
+}
+
+// riskScore computes mean(x)/stddev(x) from the running sum, sum-of-squares, and count
+// of per-claim net profit, returning 0 until there are at least two claims to derive a
+// standard deviation from, or if profit has zero variance.
+func riskScore(sum int64, sumSq float64, count int) float64 {
+	if count < 2 {
+		return 0
+	}
+	n := float64(count)
+	mean := float64(sum) / n
+	variance := sumSq/n - mean*mean
+	if variance <= 0 {
+		return 0
+	}
+	return mean / math.Sqrt(variance)
+}
+
+// Backfill replays every historical BetPlaced and Claimed event through the
+// aggregator, for seeding UserStats after the table is introduced by a migration. Call
+// once; re-running against an already-backfilled table double-counts events.
+func (a *UserStatsAggregator) Backfill(ctx context.Context) error {
+	betEvents, err := a.repo.GetEventsByType(eventTypeBetPlaced)
+	if err != nil {
+		return fmt.Errorf("failed to load bet placed events for backfill: %w", err)
+	}
+	for i := range betEvents {
+		owner, amount, ok := decodeBetPlacedEventData(betEvents[i].Data)
+		if !ok {
+			continue
+		}
+		if err := a.RecordBetPlaced(ctx, owner, amount); err != nil {
+			return fmt.Errorf("failed to backfill bet placed event %d: %w", betEvents[i].ID, err)
+		}
+	}
+
+	claimEvents, err := a.repo.GetEventsByType(eventTypeClaimed)
+	if err != nil {
+		return fmt.Errorf("failed to load claimed events for backfill: %w", err)
+	}
+	for i := range claimEvents {
+		event := &claimEvents[i]
+		owner, ok := decodeClaimedEventOwner(event.Data)
+		if !ok || event.MarketID == nil {
+			continue
+		}
+
+		market, err := a.repo.GetMarket(*event.MarketID)
+		if err != nil {
+			continue
+		}
+		position, err := a.repo.GetUserPosition(*event.MarketID, owner)
+		if err != nil {
+			continue
+		}
+
+		won := market.Outcome != nil && *market.Outcome == position.Side
+		netProfit := computePositionPnL(market, position).NetProfit
+		if err := a.RecordClaim(ctx, owner, netProfit, won); err != nil {
+			return fmt.Errorf("failed to backfill claimed event %d: %w", event.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// decodeBetPlacedEventData extracts the owner and amount fields a BetPlaced
+// event_logs.data JSON blob was written with (see solana.EventIndexer.processEvent).
+func decodeBetPlacedEventData(data string) (owner string, amount uint64, ok bool) {
+	var decoded struct {
+		Owner  string  `json:"owner"`
+		Amount float64 `json:"amount"`
+	}
+	if err := json.Unmarshal([]byte(data), &decoded); err != nil || decoded.Owner == "" {
+		return "", 0, false
+	}
+	return decoded.Owner, uint64(decoded.Amount), true
+}
+
+// decodeClaimedEventOwner extracts the owner field a Claimed event_logs.data JSON
+// blob was written with.
+func decodeClaimedEventOwner(data string) (owner string, ok bool) {
+	var decoded struct {
+		Owner string `json:"owner"`
+	}
+	if err := json.Unmarshal([]byte(data), &decoded); err != nil || decoded.Owner == "" {
+		return "", false
+	}
+	return decoded.Owner, true
+}
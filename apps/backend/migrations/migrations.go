@@ -0,0 +1,10 @@
+// Package migrations embeds the SQL migration files applied by store.Migrate via
+// rockhopper. Keeping the embed.FS here (rather than in internal/store) lets the
+// migrations directory live at the repo root, next to the SQL files themselves,
+// instead of nested inside the store package.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS